@@ -0,0 +1,170 @@
+package geoserver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/monitor"
+	"github.com/hishamkaram/geoserver/v2/rest/namespaces"
+	"github.com/hishamkaram/geoserver/v2/rest/security"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/wmslayers"
+	"github.com/hishamkaram/geoserver/v2/rest/wmsstores"
+	"github.com/hishamkaram/geoserver/v2/rest/wmtslayers"
+	"github.com/hishamkaram/geoserver/v2/rest/wmtsstores"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// TestBaseURLOverrides_RESTUnaffected asserts the OWS / GWC overrides
+// leave every REST sub-client on the server URL.
+func TestBaseURLOverrides_RESTUnaffected(t *testing.T) {
+	var restPaths []string
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		restPaths = append(restPaths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"workspace":{"name":"topp"}}`))
+	}))
+	t.Cleanup(rest.Close)
+	other := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("REST request hit an override base: %s", r.URL.Path)
+	}))
+	t.Cleanup(other.Close)
+
+	c, err := geoserver.New(rest.URL+"/geoserver/",
+		geoserver.WithOWSBaseURL(other.URL+"/ows-cdn"),
+		geoserver.WithGWCBaseURL(other.URL+"/gwc-svc"),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.Workspaces.Get(ctx, "topp"); err != nil {
+		t.Fatalf("Workspaces.Get: %v", err)
+	}
+	if err := c.About.Ping(ctx); err != nil {
+		t.Fatalf("About.Ping: %v", err)
+	}
+	if len(restPaths) != 2 || restPaths[0] != "/geoserver/rest/workspaces/topp" || restPaths[1] != "/geoserver/rest/about/version" {
+		t.Fatalf("REST paths = %v", restPaths)
+	}
+}
+
+func TestBaseURLOverrides_Invalid(t *testing.T) {
+	for _, opt := range []geoserver.Option{
+		geoserver.WithOWSBaseURL(""),
+		geoserver.WithOWSBaseURL("ftp://example.com/"),
+		geoserver.WithGWCBaseURL("://bad"),
+	} {
+		if _, err := geoserver.New("http://localhost:8080/geoserver", opt); err == nil {
+			t.Fatal("expected option error")
+		}
+	}
+}
+
+// TestBaseURL_SubPathEveryRESTClient walks one call of every REST
+// sub-client under a base URL with a sub-path and asserts the path it
+// requests. Responses are empty JSON objects; decode errors are
+// irrelevant here.
+func TestBaseURL_SubPathEveryRESTClient(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		last string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		last = r.URL.Path
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	c, err := geoserver.New(srv.URL + "/proxy/geoserver")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	cases := []struct {
+		name string
+		call func() error
+		want string
+	}{
+		{"Workspaces", func() error { _, err := c.Workspaces.List(ctx, workspaces.ListOptions{}); return err }, "/rest/workspaces"},
+		{"Namespaces", func() error { _, err := c.Namespaces.List(ctx, namespaces.ListOptions{}); return err }, "/rest/namespaces"},
+		{"Datastores", func() error {
+			_, err := c.Datastores.InWorkspace("topp").List(ctx, datastores.ListOptions{})
+			return err
+		}, "/rest/workspaces/topp/datastores"},
+		{"FeatureTypes", func() error {
+			_, err := c.FeatureTypes.InWorkspace("topp").InDatastore("pg").List(ctx, featuretypes.ListOptions{})
+			return err
+		}, "/rest/workspaces/topp/datastores/pg/featuretypes"},
+		{"CoverageStores", func() error {
+			_, err := c.CoverageStores.InWorkspace("topp").List(ctx, coveragestores.ListOptions{})
+			return err
+		}, "/rest/workspaces/topp/coveragestores"},
+		{"Coverages", func() error {
+			_, err := c.Coverages.InWorkspace("topp").InCoverageStore("dem").List(ctx, coverages.ListOptions{})
+			return err
+		}, "/rest/workspaces/topp/coveragestores/dem/coverages"},
+		{"Layers", func() error { _, err := c.Layers.InWorkspace("topp").List(ctx, layers.ListOptions{}); return err }, "/rest/workspaces/topp/layers"},
+		{"LayerGroups", func() error {
+			_, err := c.LayerGroups.InWorkspace("topp").List(ctx, layergroups.ListOptions{})
+			return err
+		}, "/rest/workspaces/topp/layergroups"},
+		{"Styles", func() error { _, err := c.Styles.List(ctx, styles.ListOptions{}); return err }, "/rest/styles"},
+		{"Settings", func() error { _, err := c.Settings.Get(ctx); return err }, "/rest/settings"},
+		{"About", func() error { _, err := c.About.Version(ctx); return err }, "/rest/about/version"},
+		{"Security", func() error { _, err := c.Security.Users().List(ctx, security.ListOptions{}); return err }, "/rest/security/usergroup/service/default/users"},
+		{"ACL", func() error { _, err := c.ACL.Layers().List(ctx, acl.ListOptions{}); return err }, "/rest/security/acl/layers"},
+		{"System", func() error { return c.System.Reload(ctx) }, "/rest/reload"},
+		{"Imports", func() error { _, err := c.Imports.List(ctx); return err }, "/rest/imports"},
+		{"Services", func() error { _, err := c.Services.WMS().Get(ctx); return err }, "/rest/services/wms/settings"},
+		{"Resources", func() error { _, err := c.Resources.List(ctx, "styles"); return err }, "/rest/resource/styles"},
+		{"Templates", func() error { _, err := c.Templates.List(ctx); return err }, "/rest/templates"},
+		{"URLChecks", func() error { _, err := c.URLChecks.List(ctx); return err }, "/rest/urlchecks"},
+		{"WMSStores", func() error {
+			_, err := c.WMSStores.InWorkspace("topp").List(ctx, wmsstores.ListOptions{})
+			return err
+		}, "/rest/workspaces/topp/wmsstores"},
+		{"WMSLayers", func() error {
+			_, err := c.WMSLayers.InWorkspace("topp").List(ctx, wmslayers.ListOptions{})
+			return err
+		}, "/rest/workspaces/topp/wmslayers"},
+		{"WMTSStores", func() error {
+			_, err := c.WMTSStores.InWorkspace("topp").List(ctx, wmtsstores.ListOptions{})
+			return err
+		}, "/rest/workspaces/topp/wmtsstores"},
+		{"WMTSLayers", func() error {
+			_, err := c.WMTSLayers.InWorkspace("topp").List(ctx, wmtslayers.ListOptions{})
+			return err
+		}, "/rest/workspaces/topp/wmtslayers"},
+		{"WFSTransforms", func() error { _, err := c.WFSTransforms.List(ctx); return err }, "/rest/services/wfs/transforms"},
+		{"Logging", func() error { _, err := c.Logging.Get(ctx); return err }, "/rest/logging"},
+		{"Fonts", func() error { _, err := c.Fonts.List(ctx); return err }, "/rest/fonts"},
+		{"Monitor", func() error { _, err := c.Monitor.List(ctx, monitor.ListOptions{}); return err }, "/rest/monitor/requests.csv"},
+		{"GWC", func() error { _, err := c.GWC.Layers().List(ctx); return err }, "/gwc/rest/layers"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_ = tc.call()
+			mu.Lock()
+			got := last
+			last = ""
+			mu.Unlock()
+			if want := "/proxy/geoserver" + tc.want; got != want && got != want+".json" && got != want+".xml" {
+				t.Fatalf("path = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Package bulk runs many catalog operations at once: enable or disable
+// hundreds of layers, move a workspace onto a new default style, or
+// delete a batch of stale feature types.
+//
+//	res := bulk.Run(ctx, c, bulk.SetLayerEnabled("topp", names, false), bulk.Options{Workers: 16})
+//	for _, r := range res.Failed() {
+//		log.Printf("%s: %v", r.Ref, r.Err)
+//	}
+//
+// An [Op] is one call against the ordinary sub-clients of the
+// [*geoserver.Client] passed to [Run], so every request still goes
+// through the client's retry, rate-limit and concurrency settings.
+// Options.Workers bounds how many operations run at once on top of
+// that.
+package bulk
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+)
+
+// defaultWorkers is the concurrency of [Run] when [Options.Workers] is
+// unset.
+const defaultWorkers = 8
+
+// ErrSkipped is the result of an operation that [Options.FailFast]
+// kept from starting.
+var ErrSkipped = errors.New("bulk: skipped after an earlier failure")
+
+// Op is one operation in a batch.
+type Op struct {
+	// Ref names the object the operation acts on. It is copied to the
+	// operation's [Result].
+	Ref geoserver.Ref
+
+	// Do performs the operation.
+	Do func(ctx context.Context, c *geoserver.Client) error
+}
+
+// Options controls [Run].
+type Options struct {
+	// Workers bounds the number of operations in flight. Default 8.
+	Workers int
+
+	// FailFast stops starting operations after the first failure. The
+	// ones already running finish; the rest report [ErrSkipped].
+	FailFast bool
+
+	// Progress, when set, receives each result as its operation
+	// finishes. Calls are serialized.
+	Progress func(Result)
+}
+
+// Result is the outcome of one [Op].
+type Result struct {
+	Ref geoserver.Ref
+
+	// Err is nil when the operation succeeded. It is [ErrSkipped] for
+	// an operation FailFast kept from starting, and the context's error
+	// for one not started before ctx ended.
+	Err error
+
+	// APIError is the *geoserver.APIError in Err's chain, when the
+	// server answered with an error status.
+	APIError *geoserver.APIError
+}
+
+// Results holds one [Result] per [Op], in the order of the ops.
+type Results []Result
+
+// Failed returns the results whose Err is set.
+func (rs Results) Failed() Results {
+	var out Results
+	for _, r := range rs {
+		if r.Err != nil {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Err joins the failures, each prefixed with its Ref, or returns nil
+// when every operation succeeded.
+func (rs Results) Err() error {
+	var errs []error
+	for _, r := range rs {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Ref, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run performs ops against c with up to opts.Workers in flight, and
+// returns their results in the order of ops. Operations start in
+// order; once ctx ends, the ones not yet started report its error.
+func Run(ctx context.Context, c *geoserver.Client, ops []Op, opts Options) Results {
+	res := make(Results, len(ops))
+	for i, op := range ops {
+		res[i].Ref = op.Ref
+	}
+	if c == nil {
+		for i := range res {
+			res[i].Err = errors.New("bulk: nil client")
+		}
+		return res
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex // serializes Progress
+		failed atomic.Bool
+		sem    = make(chan struct{}, cmp.Or(max(opts.Workers, 0), defaultWorkers))
+	)
+	finish := func(i int, err error) {
+		res[i].Err = err
+		if err != nil {
+			failed.Store(true)
+			errors.As(err, &res[i].APIError)
+		}
+		if opts.Progress != nil {
+			mu.Lock()
+			defer mu.Unlock()
+			opts.Progress(res[i])
+		}
+	}
+	for i, op := range ops {
+		if err := ctx.Err(); err != nil {
+			finish(i, err)
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			finish(i, ctx.Err())
+			continue
+		}
+		if opts.FailFast && failed.Load() {
+			<-sem
+			finish(i, ErrSkipped)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if op.Do == nil {
+				finish(i, errors.New("bulk: nil Do"))
+				return
+			}
+			finish(i, op.Do(ctx, c))
+		}()
+	}
+	wg.Wait()
+	return res
+}
@@ -0,0 +1,124 @@
+package bulk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/bulk"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+)
+
+// catalog starts a fake server with n feature types (and layers)
+// l0…l{n-1} in topp:pg, and returns a client for it and their names.
+func catalog(t *testing.T, n int, opts ...geoserver.Option) (*geoserver.Client, []string) {
+	t.Helper()
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("l%d", i)
+	}
+	c := geoservertest.Seed(t, geoservertest.Fixture{"topp": {Datastores: map[string][]string{"pg": names}}}, opts...)
+	return c, names
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	c, names := catalog(t, 20)
+	lc := c.Layers.InWorkspace("topp")
+
+	var progress int
+	res := bulk.Run(ctx, c, bulk.SetLayerEnabled("topp", names, false), bulk.Options{
+		Workers:  4,
+		Progress: func(bulk.Result) { progress++ },
+	})
+	if err := res.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(names) || progress != len(names) || res[3].Ref.Name != "l3" {
+		t.Fatalf("results = %+v (progress %d)", res, progress)
+	}
+	for _, name := range names {
+		if l, err := lc.Get(ctx, name); err != nil || l.Enabled {
+			t.Fatalf("layer %s = %+v, %v", name, l, err)
+		}
+	}
+
+	if err := c.Styles.Create(ctx, &styles.Style{Name: "roads"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bulk.Run(ctx, c, bulk.SetDefaultStyle("topp", names, "roads"), bulk.Options{}).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if l, err := lc.Get(ctx, "l7"); err != nil || l.DefaultStyle == nil || l.DefaultStyle.Name != "roads" {
+		t.Fatalf("layer l7 = %+v, %v", l, err)
+	}
+
+	res = bulk.Run(ctx, c, bulk.DeleteFeatureTypes("topp", "pg", []string{"l0", "missing", "l1"}, featuretypes.DeleteOptions{Recurse: true}), bulk.Options{})
+	failed := res.Failed()
+	if len(failed) != 1 || failed[0].Ref.Name != "missing" || failed[0].APIError == nil || failed[0].APIError.StatusCode != http.StatusNotFound {
+		t.Fatalf("failed = %+v", failed)
+	}
+	if !errors.Is(res.Err(), geoserver.ErrNotFound) {
+		t.Fatalf("Err = %v", res.Err())
+	}
+	if _, err := lc.Get(ctx, "l1"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("layer l1 after delete: %v", err)
+	}
+}
+
+func TestRun_FailFast(t *testing.T) {
+	ctx := context.Background()
+	c, names := catalog(t, 5)
+	ops := bulk.SetDefaultStyle("topp", names, "nosuchstyle")
+	res := bulk.Run(ctx, c, ops, bulk.Options{Workers: 1, FailFast: true})
+	if res[0].APIError == nil || res[0].APIError.StatusCode != http.StatusBadRequest {
+		t.Fatalf("first result = %+v", res[0])
+	}
+	for _, r := range res[1:] {
+		if !errors.Is(r.Err, bulk.ErrSkipped) {
+			t.Fatalf("result %s = %v, want ErrSkipped", r.Ref, r.Err)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	for _, r := range bulk.Run(cancelled, c, ops, bulk.Options{}) {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Fatalf("result %s = %v after cancel", r.Ref, r.Err)
+		}
+	}
+}
+
+// flaky answers 503 to the first PUT of every path.
+type flaky struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func (f *flaky) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	first := req.Method == http.MethodPut && !f.seen[req.URL.Path]
+	f.seen[req.URL.Path] = true
+	f.mu.Unlock()
+	if first {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRun_UsesClientRetry(t *testing.T) {
+	c, names := catalog(t, 3,
+		geoserver.WithTransport(&flaky{seen: map[string]bool{}}),
+		geoserver.WithRetry(geoserver.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	)
+	if err := bulk.Run(context.Background(), c, bulk.SetLayerEnabled("topp", names, false), bulk.Options{}).Err(); err != nil {
+		t.Fatalf("retried ops failed: %v", err)
+	}
+}
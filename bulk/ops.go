@@ -0,0 +1,51 @@
+package bulk
+
+import (
+	"context"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+)
+
+// SetLayerEnabled returns one op per name that enables or disables the
+// layer in workspace.
+func SetLayerEnabled(workspace string, names []string, enabled bool) []Op {
+	return layerOps(workspace, names, map[string]any{"enabled": enabled})
+}
+
+// SetDefaultStyle returns one op per name that makes style the default
+// style of the layer in workspace. Name a workspace style as
+// "workspace:style".
+func SetDefaultStyle(workspace string, names []string, style string) []Op {
+	return layerOps(workspace, names, map[string]any{"defaultStyle": map[string]string{"name": style}})
+}
+
+// layerOps patches each named layer in workspace with fields.
+func layerOps(workspace string, names []string, fields map[string]any) []Op {
+	ops := make([]Op, len(names))
+	for i, name := range names {
+		ops[i] = Op{
+			Ref: geoserver.Ref{Kind: geoserver.KindLayer, Workspace: workspace, Name: name},
+			Do: func(ctx context.Context, c *geoserver.Client) error {
+				return c.Layers.InWorkspace(workspace).Patch(ctx, name, fields)
+			},
+		}
+	}
+	return ops
+}
+
+// DeleteFeatureTypes returns one op per name that deletes the feature
+// type from store in workspace. Set opts.Recurse to delete its layer
+// too.
+func DeleteFeatureTypes(workspace, store string, names []string, opts featuretypes.DeleteOptions) []Op {
+	ops := make([]Op, len(names))
+	for i, name := range names {
+		ops[i] = Op{
+			Ref: geoserver.Ref{Kind: geoserver.KindFeatureType, Workspace: workspace, Store: store, Name: name},
+			Do: func(ctx context.Context, c *geoserver.Client) error {
+				return c.FeatureTypes.InWorkspace(workspace).InDatastore(store).Delete(ctx, name, opts)
+			},
+		}
+	}
+	return ops
+}
@@ -0,0 +1,386 @@
+// Package bundle exports a GeoServer catalog to a portable, versioned
+// snapshot and restores it onto another server — for example to
+// promote a workspace from staging to production.
+//
+//	b, err := bundle.Snapshot(ctx, staging, bundle.SnapshotOptions{Workspaces: []string{"topp"}})
+//	err = b.Save("topp.tar.gz")
+//	…
+//	b, err := bundle.Open("topp.tar.gz")
+//	err = bundle.Restore(ctx, prod, b, bundle.RestoreOptions{
+//		RewriteConnectionParameters: func(ws, ds string, params map[string]string) {
+//			params["host"], params["passwd"] = "prod-db", os.Getenv("PROD_DB_PASSWORD")
+//		},
+//	})
+//
+// A bundle holds workspaces with their namespace URI, datastores,
+// feature types, coverage stores, coverages, styles with their SLD
+// bodies, layers, layer groups, FreeMarker templates and GeoWebCache
+// tile layers, plus the global styles and templates. Hrefs and other
+// server-specific links are dropped when the snapshot is taken.
+//
+// On disk a bundle is a directory, or the same tree in a .tar.gz:
+//
+//	bundle.json                                    format, version, creation time, global style and template list
+//	styles/<name>.sld                              global style bodies
+//	templates/<name>.ftl                           global templates
+//	workspaces/<ws>/workspace.json                 the workspace's catalog objects
+//	workspaces/<ws>/styles/<name>.sld              workspace style bodies
+//	workspaces/<ws>/templates/<name>.ftl           workspace templates
+//	workspaces/<ws>/templates/<scope>/<name>.ftl   store and resource templates, e.g. templates/datastores/pg/featuretypes/roads/title.ftl
+//	workspaces/<ws>/gwc/<layer>.xml                tile layer configurations
+//
+// <scope> is [Template.Scope], the path of the store or resource the
+// template is attached to below its workspace.
+//
+// Style bodies are stored in SLD, the form [styles.Client.GetSLD]
+// returns; styles written in CSS, YSLD or MBStyle are restored as
+// their SLD translation. Datastore passwords are stored as the
+// source server encrypted them ("crypt1:…") and have to be supplied
+// again on restore, see [RestoreOptions].
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/gwc"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+)
+
+// Format identifies a bundle's bundle.json.
+const Format = "geoserver-bundle"
+
+// Version is the bundle layout version written by [Bundle.Save].
+// [Open] reads bundles of this version or older.
+const Version = 1
+
+// Bundle is a catalog snapshot.
+type Bundle struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+
+	// Styles and Templates are global.
+	Styles    []Style    `json:"styles,omitempty"`
+	Templates []Template `json:"templates,omitempty"`
+
+	// Workspaces are stored in their own directories; bundle.json
+	// only lists their names.
+	Workspaces []Workspace `json:"-"`
+}
+
+// Workspace is one workspace and everything published in it.
+type Workspace struct {
+	Name     string `json:"name"`
+	URI      string `json:"uri,omitempty"`
+	Isolated bool   `json:"isolated,omitempty"`
+
+	Datastores     []Datastore              `json:"datastores,omitempty"`
+	CoverageStores []CoverageStore          `json:"coverageStores,omitempty"`
+	Styles         []Style                  `json:"styles,omitempty"`
+	Layers         []layers.Layer           `json:"layers,omitempty"`
+	LayerGroups    []layergroups.LayerGroup `json:"layerGroups,omitempty"`
+	Templates      []Template               `json:"templates,omitempty"`
+
+	// TileLayers are the GeoWebCache configurations of the
+	// workspace's layers and layer groups, named "ws:layer".
+	TileLayers []gwc.LayerConfig `json:"-"`
+}
+
+// Datastore is a vector store and its feature types.
+type Datastore struct {
+	Store        datastores.Datastore       `json:"store"`
+	FeatureTypes []featuretypes.FeatureType `json:"featureTypes,omitempty"`
+}
+
+// CoverageStore is a raster store and its coverages.
+type CoverageStore struct {
+	Store     coveragestores.CoverageStore `json:"store"`
+	Coverages []coverages.Coverage         `json:"coverages,omitempty"`
+}
+
+// Style is a style's metadata and SLD body.
+type Style struct {
+	Style styles.Style `json:"style"`
+	Body  []byte       `json:"-"`
+}
+
+// Template is a FreeMarker template. Scope is the path of the object
+// it is attached to below its workspace — "" for the workspace (or
+// global) scope, "datastores/pg", "datastores/pg/featuretypes/roads",
+// "coveragestores/dem" or "coveragestores/dem/coverages/dem".
+type Template struct {
+	Scope string `json:"scope,omitempty"`
+	Name  string `json:"name"`
+	Body  []byte `json:"-"`
+}
+
+// index is bundle.json.
+type index struct {
+	*Bundle
+	WorkspaceNames []string `json:"workspaces,omitempty"`
+}
+
+// Save writes the bundle to path: a .tar.gz (or .tgz) archive if the
+// name says so, a directory otherwise. It fails if path exists.
+func (b *Bundle) Save(path string) error {
+	files, err := b.files()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("bundle: %s already exists", path)
+	}
+	if isArchive(path) {
+		err = saveArchive(path, files, b.Created)
+	} else {
+		err = saveDir(path, files)
+	}
+	if err != nil {
+		return fmt.Errorf("bundle: save %s: %w", path, err)
+	}
+	return nil
+}
+
+// Open reads a bundle written by [Bundle.Save].
+func Open(path string) (*Bundle, error) {
+	var files map[string][]byte
+	var err error
+	if isArchive(path) {
+		files, err = readArchive(path)
+	} else {
+		files, err = readDir(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bundle: open %s: %w", path, err)
+	}
+	b, err := fromFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %s: %w", path, err)
+	}
+	return b, nil
+}
+
+func isArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// files lays the bundle out as slash-separated relative paths.
+func (b *Bundle) files() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	put := func(name string, v any) error {
+		raw, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("bundle: encode %s: %w", name, err)
+		}
+		files[name] = append(raw, '\n')
+		return nil
+	}
+	idx := index{Bundle: b}
+	for _, ws := range b.Workspaces {
+		idx.WorkspaceNames = append(idx.WorkspaceNames, ws.Name)
+	}
+	if err := put("bundle.json", idx); err != nil {
+		return nil, err
+	}
+	putBodies(files, "", b.Styles, b.Templates)
+	for _, ws := range b.Workspaces {
+		dir := path.Join("workspaces", ws.Name)
+		if err := put(path.Join(dir, "workspace.json"), ws); err != nil {
+			return nil, err
+		}
+		putBodies(files, dir, ws.Styles, ws.Templates)
+		for _, tl := range ws.TileLayers {
+			raw, err := xml.MarshalIndent(tl, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("bundle: encode tile layer %s: %w", tl.Name, err)
+			}
+			_, layer, _ := strings.Cut(tl.Name, ":")
+			files[path.Join(dir, "gwc", layer+".xml")] = append(raw, '\n')
+		}
+	}
+	return files, nil
+}
+
+func putBodies(files map[string][]byte, dir string, sts []Style, tmpls []Template) {
+	for _, st := range sts {
+		files[path.Join(dir, "styles", st.Style.Name+".sld")] = st.Body
+	}
+	for _, t := range tmpls {
+		files[path.Join(dir, "templates", t.Scope, t.Name)] = t.Body
+	}
+}
+
+// fromFiles is the inverse of files.
+func fromFiles(files map[string][]byte) (*Bundle, error) {
+	raw, ok := files["bundle.json"]
+	if !ok {
+		return nil, errors.New("not a bundle: no bundle.json")
+	}
+	idx := index{Bundle: &Bundle{}}
+	if err := json.Unmarshal(raw, &idx); err != nil {
+		return nil, fmt.Errorf("decode bundle.json: %w", err)
+	}
+	b := idx.Bundle
+	switch {
+	case b.Format != Format:
+		return nil, fmt.Errorf("not a bundle: format %q", b.Format)
+	case b.Version < 1 || b.Version > Version:
+		return nil, fmt.Errorf("unsupported bundle version %d (this package reads up to %d)", b.Version, Version)
+	}
+	if err := readBodies(files, "", b.Styles, b.Templates); err != nil {
+		return nil, err
+	}
+	for _, name := range idx.WorkspaceNames {
+		dir := path.Join("workspaces", name)
+		var ws Workspace
+		raw, ok := files[path.Join(dir, "workspace.json")]
+		if !ok {
+			return nil, fmt.Errorf("workspace %s: missing workspace.json", name)
+		}
+		if err := json.Unmarshal(raw, &ws); err != nil {
+			return nil, fmt.Errorf("workspace %s: decode workspace.json: %w", name, err)
+		}
+		if err := readBodies(files, dir, ws.Styles, ws.Templates); err != nil {
+			return nil, fmt.Errorf("workspace %s: %w", name, err)
+		}
+		prefix := path.Join(dir, "gwc") + "/"
+		for _, file := range slices.Sorted(maps.Keys(files)) {
+			if !strings.HasPrefix(file, prefix) || !strings.HasSuffix(file, ".xml") {
+				continue
+			}
+			var tl gwc.LayerConfig
+			if err := xml.Unmarshal(files[file], &tl); err != nil {
+				return nil, fmt.Errorf("workspace %s: decode %s: %w", name, file, err)
+			}
+			ws.TileLayers = append(ws.TileLayers, tl)
+		}
+		b.Workspaces = append(b.Workspaces, ws)
+	}
+	return b, nil
+}
+
+func readBodies(files map[string][]byte, dir string, sts []Style, tmpls []Template) error {
+	for i := range sts {
+		name := path.Join(dir, "styles", sts[i].Style.Name+".sld")
+		body, ok := files[name]
+		if !ok {
+			return fmt.Errorf("missing %s", name)
+		}
+		sts[i].Body = body
+	}
+	for i := range tmpls {
+		name := path.Join(dir, "templates", tmpls[i].Scope, tmpls[i].Name)
+		body, ok := files[name]
+		if !ok {
+			return fmt.Errorf("missing %s", name)
+		}
+		tmpls[i].Body = body
+	}
+	return nil
+}
+
+func saveDir(dir string, files map[string][]byte) error {
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(full, files[name], 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readDir(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	root := os.DirFS(dir)
+	err := fs.WalkDir(root, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		files[name], err = fs.ReadFile(root, name)
+		return err
+	})
+	return files, err
+}
+
+// saveArchive writes files in name order with a fixed mtime, so the
+// same bundle always produces the same archive.
+func saveArchive(name string, files map[string][]byte, mtime time.Time) (err error) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, file := range slices.Sorted(maps.Keys(files)) {
+		hdr := &tar.Header{Name: file, Mode: 0o644, Size: int64(len(files[file])), ModTime: mtime, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[file]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func readArchive(name string) (map[string][]byte, error) {
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(hdr.Name)] = body
+	}
+}
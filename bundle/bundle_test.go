@@ -0,0 +1,225 @@
+package bundle_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/bundle"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/gwc"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/namespaces"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+const sld = `<StyledLayerDescriptor version="1.0.0"><NamedLayer><Name>roads</Name></NamedLayer></StyledLayerDescriptor>`
+
+// staging builds a source catalog: workspace "topp" with a PostGIS
+// store, a coverage store, a workspace style, nested layer groups,
+// templates at three scopes and a tile layer; plus workspace "other".
+func staging(t *testing.T) *geoserver.Client {
+	t.Helper()
+	srv := geoservertest.New(t, geoservertest.Options{})
+	srv.Seed(geoservertest.Fixture{"other": {}})
+	c, must := srv.Client(), srv.Must
+	ctx := context.Background()
+	must("namespace", c.Namespaces.Create(ctx, &namespaces.Namespace{Prefix: "topp", URI: "http://topp.example.org"}))
+	sc := c.Styles.InWorkspace("topp")
+	must("style", sc.Create(ctx, &styles.Style{Name: "roads"}))
+	must("style body", sc.UploadSLD(ctx, "roads", strings.NewReader(sld), styles.UploadOptions{}))
+	pg := datastores.PostGIS{Name: "pg", Host: "staging-db", Port: 5432, Database: "gis", User: "u", Password: "secret"}
+	must("datastore", c.Datastores.InWorkspace("topp").Create(ctx, pg))
+	must("feature type", c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Create(ctx, &featuretypes.FeatureType{Name: "roads", Title: "Roads"}))
+	must("coverage store", c.CoverageStores.InWorkspace("topp").Create(ctx, &coveragestores.CoverageStore{
+		Name: "dem", Type: "GeoTIFF", URL: "file:data/staging/dem.tif", Enabled: true,
+	}))
+	must("coverage", c.Coverages.InWorkspace("topp").InCoverageStore("dem").Create(ctx, &coverages.Coverage{Name: "dem", Title: "Elevation"}))
+	must("layer", c.Layers.InWorkspace("topp").Update(ctx, "roads", &layers.Layer{
+		DefaultStyle: &layers.Ref{Name: "topp:roads"}, Queryable: true,
+	}))
+	gc := c.LayerGroups.InWorkspace("topp")
+	must("group", gc.Create(ctx, &layergroups.LayerGroup{
+		Name: "base", Publishables: layergroups.Publishables{Published: layergroups.Published{{Type: "layer", Name: "roads"}}},
+	}))
+	must("nested group", gc.Create(ctx, &layergroups.LayerGroup{
+		Name: "all", Publishables: layergroups.Publishables{Published: layergroups.Published{
+			{Type: "layerGroup", Name: "topp:base"}, {Type: "layer", Name: "topp:dem"},
+		}},
+	}))
+	must("global template", c.Templates.PutString(ctx, "header", "<h1>GeoServer</h1>"))
+	must("workspace template", c.Templates.InWorkspace("topp").PutString(ctx, "content", "${features}"))
+	must("feature type template", c.Templates.InWorkspace("topp").InDatastore("pg").InFeatureType("roads").PutString(ctx, "title", "${name}"))
+	must("tile layer", c.GWC.Layers().Put(ctx, "topp:roads", &gwc.LayerConfig{
+		ID: "LayerInfoImpl-1", Name: "topp:roads", Enabled: true, MimeFormats: &gwc.MimeFormats{String: []string{"image/png"}},
+	}))
+	return c
+}
+
+func TestSnapshot(t *testing.T) {
+	c := staging(t)
+	b, err := bundle.Snapshot(context.Background(), c, bundle.SnapshotOptions{Workspaces: []string{"topp"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Version != bundle.Version || len(b.Styles) != 5 || len(b.Templates) != 1 {
+		t.Fatalf("bundle = version %d, %d global styles, %d global templates", b.Version, len(b.Styles), len(b.Templates))
+	}
+	if len(b.Workspaces) != 1 {
+		t.Fatalf("workspaces = %d, want only topp", len(b.Workspaces))
+	}
+	ws := b.Workspaces[0]
+	if ws.URI != "http://topp.example.org" || len(ws.Datastores) != 1 || len(ws.Datastores[0].FeatureTypes) != 1 ||
+		len(ws.CoverageStores) != 1 || len(ws.CoverageStores[0].Coverages) != 1 || len(ws.Layers) != 2 ||
+		len(ws.LayerGroups) != 2 || len(ws.Templates) != 2 || len(ws.TileLayers) != 1 {
+		t.Fatalf("workspace = %+v", ws)
+	}
+	if string(ws.Styles[0].Body) != sld {
+		t.Fatalf("style body = %q", ws.Styles[0].Body)
+	}
+	if ws.TileLayers[0].ID != "" {
+		t.Fatalf("tile layer ID = %q, want cleared", ws.TileLayers[0].ID)
+	}
+	for _, l := range ws.Layers {
+		if l.Resource != nil || (l.DefaultStyle != nil && l.DefaultStyle.Href != "") {
+			t.Fatalf("layer %s keeps server links: %+v", l.Name, l)
+		}
+	}
+
+	if _, err := bundle.Snapshot(context.Background(), c, bundle.SnapshotOptions{Workspaces: []string{"nope"}}); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("Snapshot of missing workspace = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSaveOpen(t *testing.T) {
+	b, err := bundle.Snapshot(context.Background(), staging(t), bundle.SnapshotOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"snap", "snap.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := b.Save(path); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			if err := b.Save(path); err == nil {
+				t.Fatal("Save over an existing path succeeded")
+			}
+			got, err := bundle.Open(path)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			if !got.Created.Equal(b.Created) || len(got.Workspaces) != 2 || got.Workspaces[1].Name != "topp" {
+				t.Fatalf("Open = %+v", got)
+			}
+			ws := got.Workspaces[1]
+			if string(ws.Styles[0].Body) != sld || len(ws.Templates) != 2 || string(ws.Templates[1].Body) != "${name}" ||
+				ws.Templates[1].Scope != "datastores/pg/featuretypes/roads" {
+				t.Fatalf("bodies = %+v", ws)
+			}
+			if len(ws.TileLayers) != 1 || ws.TileLayers[0].Name != "topp:roads" || ws.TileLayers[0].MimeFormats.String[0] != "image/png" {
+				t.Fatalf("tile layers = %+v", ws.TileLayers)
+			}
+		})
+	}
+}
+
+func TestOpen_Version(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bundle.json"), []byte(`{"format":"geoserver-bundle","version":99}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundle.Open(dir); err == nil || !strings.Contains(err.Error(), "unsupported bundle version 99") {
+		t.Fatalf("Open = %v", err)
+	}
+	if _, err := bundle.Open(t.TempDir()); err == nil {
+		t.Fatal("Open of an empty directory succeeded")
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	b, err := bundle.Snapshot(ctx, staging(t), bundle.SnapshotOptions{Workspaces: []string{"topp"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := geoservertest.New(t, geoservertest.Options{}).Client()
+	opts := bundle.RestoreOptions{
+		RenameWorkspace: func(ws string) string { return "prod" },
+		RewriteURI:      func(ws, uri string) string { return "http://prod.example.org" },
+		RewriteCoverageStoreURL: func(ws, store, url string) string {
+			return strings.Replace(url, "staging", "prod", 1)
+		},
+	}
+
+	err = bundle.Restore(ctx, dst, b, opts)
+	if err == nil || !strings.Contains(err.Error(), `parameter "passwd" is encrypted`) {
+		t.Fatalf("Restore with encrypted password = %v", err)
+	}
+	if list, _ := dst.Workspaces.List(ctx, workspaces.ListOptions{}); len(list) != 0 {
+		t.Fatalf("failed precheck created workspaces: %+v", list)
+	}
+
+	opts.RewriteConnectionParameters = func(ws, ds string, params map[string]string) {
+		params["host"], params["passwd"] = "prod-db", "prod-secret"
+	}
+	if err := bundle.Restore(ctx, dst, b, opts); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	ns, err := dst.Namespaces.Get(ctx, "prod")
+	if err != nil || ns.URI != "http://prod.example.org" {
+		t.Fatalf("namespace = %+v, %v", ns, err)
+	}
+	ds, err := dst.Datastores.InWorkspace("prod").Get(ctx, "pg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{}
+	for _, e := range ds.ConnectionParameters.Entry {
+		params[e.Key] = e.Value
+	}
+	if params["host"] != "prod-db" || params["database"] != "gis" || !strings.HasPrefix(params["passwd"], "crypt1:") ||
+		params["namespace"] != "http://prod.example.org" {
+		t.Fatalf("params = %v", params)
+	}
+	cs, err := dst.CoverageStores.InWorkspace("prod").Get(ctx, "dem")
+	if err != nil || cs.URL != "file:data/prod/dem.tif" {
+		t.Fatalf("coverage store = %+v, %v", cs, err)
+	}
+	l, err := dst.Layers.InWorkspace("prod").Get(ctx, "roads")
+	if err != nil || l.DefaultStyle == nil || l.DefaultStyle.Name != "prod:roads" || !l.Queryable {
+		t.Fatalf("layer = %+v, %v", l, err)
+	}
+	g, err := dst.LayerGroups.InWorkspace("prod").Get(ctx, "all")
+	if err != nil || len(g.Publishables.Published) != 2 || g.Publishables.Published[0].Name != "prod:base" {
+		t.Fatalf("group = %+v, %v", g, err)
+	}
+	if body, err := dst.Templates.InWorkspace("prod").InDatastore("pg").InFeatureType("roads").Get(ctx, "title"); err != nil || body != "${name}" {
+		t.Fatalf("template = %q, %v", body, err)
+	}
+	if body, err := dst.Templates.Get(ctx, "header"); err != nil || body != "<h1>GeoServer</h1>" {
+		t.Fatalf("global template = %q, %v", body, err)
+	}
+	if tl, err := dst.GWC.Layers().Get(ctx, "prod:roads"); err != nil || tl.Name != "prod:roads" {
+		t.Fatalf("tile layer = %+v, %v", tl, err)
+	}
+
+	if err := bundle.Restore(ctx, dst, b, opts); !errors.Is(err, geoserver.ErrAlreadyExists) {
+		t.Fatalf("second Restore = %v, want ErrAlreadyExists", err)
+	}
+	opts.Replace = true
+	if err := bundle.Restore(ctx, dst, b, opts); err != nil {
+		t.Fatalf("Restore with Replace: %v", err)
+	}
+}
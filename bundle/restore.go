@@ -0,0 +1,412 @@
+package bundle
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/namespaces"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/templates"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// RestoreOptions configures [Restore].
+type RestoreOptions struct {
+	// Workspaces limits the restore to the named bundle workspaces.
+	// Empty restores every workspace in the bundle.
+	Workspaces []string
+
+	// RenameWorkspace maps a bundle workspace name to its name on the
+	// target; returning "" keeps the name. References qualified with
+	// a renamed workspace — layer styles, layer group members and
+	// styles, tile layer names — follow the rename.
+	RenameWorkspace func(name string) string
+
+	// RewriteURI maps a workspace's namespace URI. It is called with
+	// the target workspace name. Namespace URIs must be unique per
+	// server, so restoring a renamed copy next to the original needs
+	// this.
+	RewriteURI func(workspace, uri string) string
+
+	// RewriteConnectionParameters may change a datastore's connection
+	// parameters in place before the store is created — database
+	// host, user and password, file paths. It is called with the
+	// target workspace name.
+	RewriteConnectionParameters func(workspace, datastore string, params map[string]string)
+
+	// RewriteCoverageStoreURL maps a coverage store's URL, e.g. a
+	// file path that differs between servers.
+	RewriteCoverageStoreURL func(workspace, store, url string) string
+
+	// KeepEncrypted sends "crypt1:…" connection parameters to the
+	// target unchanged, for servers that share the source's keystore.
+	// Without it, Restore fails before writing anything if such a
+	// parameter survives RewriteConnectionParameters.
+	KeepEncrypted bool
+
+	// Replace deletes target workspaces that already exist (with
+	// everything in them) before restoring them, and overwrites
+	// global styles and templates of the same name. Without it an
+	// existing workspace fails the restore before anything is
+	// written, and existing global styles and templates are kept.
+	Replace bool
+}
+
+// Restore writes the bundle's workspaces, and the global styles and
+// templates they may depend on, to c. Objects are created in
+// dependency order: styles, stores, resources, templates, layer
+// settings, layer groups, tile layers.
+//
+// Restore stops at the first failure. Workspaces restored before it
+// stay in place; rerun with Replace to start over.
+func Restore(ctx context.Context, c *geoserver.Client, b *Bundle, opts RestoreOptions) error {
+	if c == nil {
+		return errors.New("bundle: nil client")
+	}
+	if b == nil {
+		return errors.New("bundle: nil bundle")
+	}
+	r := &restorer{c: c, b: b, opts: opts}
+	var selected []Workspace
+	for _, ws := range b.Workspaces {
+		if len(opts.Workspaces) == 0 || slices.Contains(opts.Workspaces, ws.Name) {
+			selected = append(selected, r.rewrite(ws))
+		}
+	}
+	for _, want := range opts.Workspaces {
+		if !slices.ContainsFunc(b.Workspaces, func(ws Workspace) bool { return ws.Name == want }) {
+			return fmt.Errorf("bundle: restore: workspace %s not in bundle", want)
+		}
+	}
+	existing, err := r.check(ctx, selected)
+	if err != nil {
+		return err
+	}
+	if err := r.globals(ctx); err != nil {
+		return err
+	}
+	for _, ws := range selected {
+		if err := r.workspace(ctx, ws, existing[ws.Name]); err != nil {
+			return fmt.Errorf("bundle: restore workspace %s: %w", ws.Name, err)
+		}
+	}
+	return nil
+}
+
+type restorer struct {
+	c    *geoserver.Client
+	b    *Bundle
+	opts RestoreOptions
+}
+
+// rename returns the target name of a bundle workspace.
+func (r *restorer) rename(ws string) string {
+	if r.opts.RenameWorkspace == nil {
+		return ws
+	}
+	return cmp.Or(r.opts.RenameWorkspace(ws), ws)
+}
+
+// ref renames the workspace part of a "ws:name" reference.
+func (r *restorer) ref(name string) string {
+	ws, local, ok := strings.Cut(name, ":")
+	if !ok || !slices.ContainsFunc(r.b.Workspaces, func(w Workspace) bool { return w.Name == ws }) {
+		return name
+	}
+	return r.rename(ws) + ":" + local
+}
+
+// rewrite applies the rename and rewrite hooks to a copy of ws.
+func (r *restorer) rewrite(src Workspace) Workspace {
+	ws := src
+	ws.Name = r.rename(src.Name)
+	if r.opts.RewriteURI != nil {
+		ws.URI = r.opts.RewriteURI(ws.Name, ws.URI)
+	}
+	ws.Datastores = slices.Clone(src.Datastores)
+	for i, ds := range ws.Datastores {
+		params := map[string]string{}
+		for _, e := range ds.Store.ConnectionParameters.Entry {
+			params[e.Key] = e.Value
+		}
+		// The store publishes into the namespace its "namespace"
+		// parameter names, so it follows the workspace's target URI.
+		if _, ok := params["namespace"]; ok && (ws.Name != src.Name || ws.URI != src.URI) {
+			params["namespace"] = ws.URI
+		}
+		if r.opts.RewriteConnectionParameters != nil {
+			r.opts.RewriteConnectionParameters(ws.Name, ds.Store.Name, params)
+		}
+		var entries []datastores.ConnectionEntry
+		for _, k := range slices.Sorted(maps.Keys(params)) {
+			entries = append(entries, datastores.ConnectionEntry{Key: k, Value: params[k]})
+		}
+		ws.Datastores[i].Store.ConnectionParameters = datastores.ConnectionParameters{Entry: entries}
+	}
+	ws.CoverageStores = slices.Clone(src.CoverageStores)
+	for i, cs := range ws.CoverageStores {
+		if r.opts.RewriteCoverageStoreURL != nil {
+			ws.CoverageStores[i].Store.URL = r.opts.RewriteCoverageStoreURL(ws.Name, cs.Store.Name, cs.Store.URL)
+		}
+	}
+	ws.Layers = slices.Clone(src.Layers)
+	for i, l := range ws.Layers {
+		if l.DefaultStyle != nil {
+			ref := *l.DefaultStyle
+			ref.Name = r.ref(ref.Name)
+			ws.Layers[i].DefaultStyle = &ref
+		}
+		if l.Styles != nil {
+			sts := *l.Styles
+			sts.Style = slices.Clone(sts.Style)
+			for j := range sts.Style {
+				sts.Style[j].Name = r.ref(sts.Style[j].Name)
+			}
+			ws.Layers[i].Styles = &sts
+		}
+	}
+	ws.LayerGroups = slices.Clone(src.LayerGroups)
+	for i, g := range ws.LayerGroups {
+		pub := slices.Clone(g.Publishables.Published)
+		for j := range pub {
+			pub[j].Name = r.ref(pub[j].Name)
+		}
+		ws.LayerGroups[i].Publishables.Published = pub
+		sts := slices.Clone(g.Styles.Style)
+		for j := range sts {
+			sts[j].Name = r.ref(sts[j].Name)
+		}
+		ws.LayerGroups[i].Styles.Style = sts
+	}
+	ws.TileLayers = slices.Clone(src.TileLayers)
+	for i := range ws.TileLayers {
+		ws.TileLayers[i].Name = r.ref(ws.TileLayers[i].Name)
+	}
+	return ws
+}
+
+// check finds the target workspaces that already exist and rejects
+// the restore before anything is written if one does without Replace,
+// or if an encrypted parameter would be sent.
+func (r *restorer) check(ctx context.Context, selected []Workspace) (map[string]bool, error) {
+	list, err := r.c.Workspaces.List(ctx, workspaces.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("bundle: restore: %w", err)
+	}
+	existing := map[string]bool{}
+	for _, ws := range selected {
+		if !slices.ContainsFunc(list, func(w workspaces.Workspace) bool { return w.Name == ws.Name }) {
+			continue
+		}
+		if !r.opts.Replace {
+			return nil, fmt.Errorf("bundle: restore workspace %s: %w", ws.Name, geoserver.ErrAlreadyExists)
+		}
+		existing[ws.Name] = true
+	}
+	if r.opts.KeepEncrypted {
+		return existing, nil
+	}
+	for _, ws := range selected {
+		for _, ds := range ws.Datastores {
+			for _, e := range ds.Store.ConnectionParameters.Entry {
+				if strings.HasPrefix(e.Value, "crypt1:") {
+					return nil, fmt.Errorf("bundle: restore workspace %s: datastore %s: parameter %q is encrypted with the source server's key; set it with RewriteConnectionParameters",
+						ws.Name, ds.Store.Name, e.Key)
+				}
+			}
+		}
+	}
+	return existing, nil
+}
+
+func (r *restorer) globals(ctx context.Context) error {
+	for _, st := range r.b.Styles {
+		_, err := r.c.Styles.Get(ctx, st.Style.Name)
+		switch {
+		case err == nil && !r.opts.Replace:
+			continue
+		case err == nil:
+			err = r.c.Styles.UploadSLD(ctx, st.Style.Name, bytes.NewReader(st.Body), uploadOptions(st))
+		case errors.Is(err, geoserver.ErrNotFound):
+			err = createStyle(ctx, r.c.Styles, st)
+		}
+		if err != nil {
+			return fmt.Errorf("bundle: restore style %s: %w", st.Style.Name, err)
+		}
+	}
+	for _, t := range r.b.Templates {
+		if !r.opts.Replace {
+			_, err := r.c.Templates.Get(ctx, t.Name)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, geoserver.ErrNotFound) {
+				return fmt.Errorf("bundle: restore template %s: %w", t.Name, err)
+			}
+		}
+		if err := r.c.Templates.Put(ctx, t.Name, bytes.NewReader(t.Body)); err != nil {
+			return fmt.Errorf("bundle: restore template %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *restorer) workspace(ctx context.Context, ws Workspace, exists bool) error {
+	c := r.c
+	if exists {
+		if err := c.Workspaces.Delete(ctx, ws.Name, workspaces.DeleteOptions{Recurse: true}); err != nil {
+			return err
+		}
+	}
+	var err error
+	if ws.URI != "" {
+		err = c.Namespaces.Create(ctx, &namespaces.Namespace{Prefix: ws.Name, URI: ws.URI, Isolated: ws.Isolated})
+	} else {
+		err = c.Workspaces.Create(ctx, &workspaces.Workspace{Name: ws.Name, Isolated: ws.Isolated})
+	}
+	if err != nil {
+		return err
+	}
+
+	sc := c.Styles.InWorkspace(ws.Name)
+	for _, st := range ws.Styles {
+		if err := createStyle(ctx, sc, st); err != nil {
+			return fmt.Errorf("style %s: %w", st.Style.Name, err)
+		}
+	}
+	for _, ds := range ws.Datastores {
+		if err := c.Datastores.InWorkspace(ws.Name).Create(ctx, datastores.Raw(ds.Store)); err != nil {
+			return fmt.Errorf("datastore %s: %w", ds.Store.Name, err)
+		}
+		fc := c.FeatureTypes.InWorkspace(ws.Name).InDatastore(ds.Store.Name)
+		for _, ft := range ds.FeatureTypes {
+			if err := fc.Create(ctx, &ft); err != nil {
+				return fmt.Errorf("feature type %s: %w", ft.Name, err)
+			}
+		}
+	}
+	for _, cs := range ws.CoverageStores {
+		if err := c.CoverageStores.InWorkspace(ws.Name).Create(ctx, &cs.Store); err != nil {
+			return fmt.Errorf("coverage store %s: %w", cs.Store.Name, err)
+		}
+		vc := c.Coverages.InWorkspace(ws.Name).InCoverageStore(cs.Store.Name)
+		for _, cov := range cs.Coverages {
+			if err := vc.Create(ctx, &cov); err != nil {
+				return fmt.Errorf("coverage %s: %w", cov.Name, err)
+			}
+		}
+	}
+	for _, t := range ws.Templates {
+		tc, err := scoped(c.Templates.InWorkspace(ws.Name), t.Scope)
+		if err == nil {
+			err = tc.Put(ctx, t.Name, bytes.NewReader(t.Body))
+		}
+		if err != nil {
+			return fmt.Errorf("template %s: %w", path.Join(t.Scope, t.Name), err)
+		}
+	}
+	lc := c.Layers.InWorkspace(ws.Name)
+	for _, l := range ws.Layers {
+		if err := lc.Update(ctx, l.Name, &l); err != nil {
+			return fmt.Errorf("layer %s: %w", l.Name, err)
+		}
+	}
+	gc := c.LayerGroups.InWorkspace(ws.Name)
+	for _, g := range groupOrder(ws.Name, ws.LayerGroups) {
+		if err := gc.Create(ctx, &g); err != nil {
+			return fmt.Errorf("layer group %s: %w", g.Name, err)
+		}
+	}
+	for _, tl := range ws.TileLayers {
+		if err := c.GWC.Layers().Put(ctx, tl.Name, &tl); err != nil {
+			return fmt.Errorf("tile layer %s: %w", tl.Name, err)
+		}
+	}
+	return nil
+}
+
+func createStyle(ctx context.Context, sc *styles.Client, st Style) error {
+	meta := st.Style
+	meta.Format, meta.Filename = "sld", meta.Name+".sld"
+	if err := sc.Create(ctx, &meta); err != nil {
+		return err
+	}
+	return sc.UploadSLD(ctx, meta.Name, bytes.NewReader(st.Body), uploadOptions(st))
+}
+
+func uploadOptions(st Style) styles.UploadOptions {
+	if st.Style.LanguageVersion != nil && st.Style.LanguageVersion.Version == "1.1.0" {
+		return styles.UploadOptions{Format: "application/vnd.ogc.se+xml"}
+	}
+	return styles.UploadOptions{}
+}
+
+// scoped narrows a workspace templates client to a [Template.Scope].
+func scoped(tc *templates.Client, scope string) (*templates.Client, error) {
+	if scope == "" {
+		return tc, nil
+	}
+	parts := strings.Split(scope, "/")
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf("malformed scope %q", scope)
+	}
+	for i := 0; i < len(parts); i += 2 {
+		switch parts[i] {
+		case "datastores":
+			tc = tc.InDatastore(parts[i+1])
+		case "featuretypes":
+			tc = tc.InFeatureType(parts[i+1])
+		case "coveragestores":
+			tc = tc.InCoverageStore(parts[i+1])
+		case "coverages":
+			tc = tc.InCoverage(parts[i+1])
+		default:
+			return nil, fmt.Errorf("malformed scope %q", scope)
+		}
+	}
+	return tc, nil
+}
+
+// groupOrder returns the workspace's layer groups with every group
+// after the groups of the same workspace it contains.
+func groupOrder(ws string, groups []layergroups.LayerGroup) []layergroups.LayerGroup {
+	var out []layergroups.LayerGroup
+	done := map[string]bool{}
+	for len(out) < len(groups) {
+		progress := false
+		for _, g := range groups {
+			if done[g.Name] {
+				continue
+			}
+			ready := true
+			for _, p := range g.Publishables.Published {
+				local, ok := strings.CutPrefix(p.Name, ws+":")
+				if p.Type == "layerGroup" && ok && !done[local] && slices.ContainsFunc(groups, func(o layergroups.LayerGroup) bool { return o.Name == local }) {
+					ready = false
+				}
+			}
+			if ready {
+				out, done[g.Name], progress = append(out, g), true, true
+			}
+		}
+		if !progress {
+			// A cycle; let GeoServer reject it.
+			for _, g := range groups {
+				if !done[g.Name] {
+					out = append(out, g)
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,204 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/templates"
+)
+
+// SnapshotOptions configures [Snapshot].
+type SnapshotOptions struct {
+	// Workspaces limits the snapshot to the named workspaces. Empty
+	// takes every workspace. Global styles and templates are always
+	// included, since layers may use them.
+	Workspaces []string
+}
+
+// Snapshot reads the catalog into a [Bundle]. The catalog tree is
+// crawled concurrently by [geoserver.Catalog.Inventory]. Tile layers
+// are skipped when the server has no GeoWebCache REST API.
+func Snapshot(ctx context.Context, c *geoserver.Client, opts SnapshotOptions) (*Bundle, error) {
+	if c == nil {
+		return nil, errors.New("bundle: nil client")
+	}
+	inv, err := c.Catalog().Inventory(ctx, geoserver.InventoryOptions{Workspaces: opts.Workspaces})
+	if err == nil {
+		err = inv.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bundle: snapshot: %w", err)
+	}
+	for _, want := range opts.Workspaces {
+		if inv.Workspace(want) == nil {
+			return nil, fmt.Errorf("bundle: snapshot workspace %s: %w", want, geoserver.ErrNotFound)
+		}
+	}
+	b := &Bundle{Format: Format, Version: Version, Created: time.Now().UTC().Truncate(time.Second)}
+	if b.Styles, err = snapshotStyles(ctx, c.Styles, inv.Styles); err != nil {
+		return nil, fmt.Errorf("bundle: snapshot global styles: %w", err)
+	}
+	if b.Templates, err = snapshotTemplates(ctx, c.Templates, ""); err != nil {
+		return nil, fmt.Errorf("bundle: snapshot global templates: %w", err)
+	}
+	tileLayers, err := c.GWC.Layers().List(ctx)
+	if errors.Is(err, geoserver.ErrNotFound) {
+		tileLayers, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bundle: snapshot tile layers: %w", err)
+	}
+	for _, iw := range inv.Workspaces {
+		ws, err := snapshotWorkspace(ctx, c, iw, tileLayers)
+		if err != nil {
+			return nil, fmt.Errorf("bundle: snapshot workspace %s: %w", iw.Workspace.Name, err)
+		}
+		b.Workspaces = append(b.Workspaces, *ws)
+	}
+	return b, nil
+}
+
+// snapshotWorkspace converts one crawled workspace, reading what the
+// crawl doesn't cover: its namespace, style bodies, templates and tile
+// layers.
+func snapshotWorkspace(ctx context.Context, c *geoserver.Client, iw *geoserver.InventoryWorkspace, tileLayers []string) (*Workspace, error) {
+	name := iw.Workspace.Name
+	ns, err := c.Namespaces.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ws := &Workspace{Name: name, URI: ns.URI, Isolated: ns.Isolated}
+	if ws.Styles, err = snapshotStyles(ctx, c.Styles.InWorkspace(name), iw.Styles); err != nil {
+		return nil, err
+	}
+	tc := c.Templates.InWorkspace(name)
+	if ws.Templates, err = snapshotTemplates(ctx, tc, ""); err != nil {
+		return nil, err
+	}
+
+	// The crawled documents are ours to trim in place.
+	for _, s := range iw.Datastores {
+		ds := s.Datastore
+		ds.Workspace, ds.FeatureTypes = nil, ""
+		out := Datastore{Store: *ds}
+		scope := "datastores/" + ds.Name
+		if err := ws.addTemplates(ctx, tc.InDatastore(ds.Name), scope); err != nil {
+			return nil, err
+		}
+		for _, f := range s.FeatureTypes {
+			ft := f.FeatureType
+			ft.Namespace, ft.Store = nil, nil
+			out.FeatureTypes = append(out.FeatureTypes, *ft)
+			if err := ws.addTemplates(ctx, tc.InDatastore(ds.Name).InFeatureType(ft.Name), scope+"/featuretypes/"+ft.Name); err != nil {
+				return nil, err
+			}
+		}
+		ws.Datastores = append(ws.Datastores, out)
+	}
+
+	for _, s := range iw.CoverageStores {
+		cs := s.CoverageStore
+		cs.Workspace, cs.Coverages = nil, ""
+		out := CoverageStore{Store: *cs}
+		scope := "coveragestores/" + cs.Name
+		if err := ws.addTemplates(ctx, tc.InCoverageStore(cs.Name), scope); err != nil {
+			return nil, err
+		}
+		for _, v := range s.Coverages {
+			cov := v.Coverage
+			cov.Namespace, cov.Store = nil, nil
+			out.Coverages = append(out.Coverages, *cov)
+			if err := ws.addTemplates(ctx, tc.InCoverageStore(cs.Name).InCoverage(cov.Name), scope+"/coverages/"+cov.Name); err != nil {
+				return nil, err
+			}
+		}
+		ws.CoverageStores = append(ws.CoverageStores, out)
+	}
+
+	for _, l := range iw.Layers {
+		layer := l.Layer
+		layer.Resource = nil
+		if layer.DefaultStyle != nil {
+			layer.DefaultStyle.Href = ""
+		}
+		if layer.Styles != nil {
+			for i := range layer.Styles.Style {
+				layer.Styles.Style[i].Href = ""
+			}
+		}
+		ws.Layers = append(ws.Layers, *layer)
+	}
+
+	for _, g := range iw.LayerGroups {
+		group := g.LayerGroup
+		group.Workspace = nil
+		for i := range group.Publishables.Published {
+			group.Publishables.Published[i].Href = ""
+		}
+		for i := range group.Styles.Style {
+			group.Styles.Style[i].Href = ""
+		}
+		ws.LayerGroups = append(ws.LayerGroups, *group)
+	}
+
+	for _, tl := range tileLayers {
+		if !strings.HasPrefix(tl, name+":") {
+			continue
+		}
+		cfg, err := c.GWC.Layers().Get(ctx, tl)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ID = "" // assigned by the server
+		ws.TileLayers = append(ws.TileLayers, *cfg)
+	}
+	return ws, nil
+}
+
+func snapshotStyles(ctx context.Context, sc *styles.Client, list []*geoserver.InventoryStyle) ([]Style, error) {
+	var out []Style
+	for _, s := range list {
+		body, err := readAll(sc.GetSLD(ctx, s.Style.Name))
+		if err != nil {
+			return nil, fmt.Errorf("style %s: %w", s.Style.Name, err)
+		}
+		out = append(out, Style{Style: *s.Style, Body: body})
+	}
+	return out, nil
+}
+
+func readAll(r io.ReadCloser, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return io.ReadAll(r)
+}
+
+func snapshotTemplates(ctx context.Context, tc *templates.Client, scope string) ([]Template, error) {
+	refs, err := tc.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Template
+	for _, ref := range refs {
+		body, err := tc.Get(ctx, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Template{Scope: scope, Name: ref.Name, Body: []byte(body)})
+	}
+	return out, nil
+}
+
+func (ws *Workspace) addTemplates(ctx context.Context, tc *templates.Client, scope string) error {
+	ts, err := snapshotTemplates(ctx, tc, scope)
+	ws.Templates = append(ws.Templates, ts...)
+	return err
+}
@@ -0,0 +1,52 @@
+package geoserver
+
+import "strings"
+
+// Catalog object kinds, as carried by [Ref.Kind] and reported by
+// [Cluster.Verify] in [ClusterDrift.Kind].
+const (
+	KindWorkspace       = "workspace"
+	KindDatastore       = "datastore"
+	KindCoverageStore   = "coveragestore"
+	KindLayer           = "layer"
+	KindStyle           = "style"
+	KindFeatureType     = "featuretype"
+	KindCoverage        = "coverage"
+	KindLayerGroup      = "layergroup"
+	KindACLRule         = "aclrule"
+	KindServiceSettings = "servicesettings"
+	KindTileLayer       = "tilelayer"
+	KindTemplate        = "template"
+)
+
+// Catalog groups operations that span several sub-clients. Get one
+// with [Client.Catalog].
+type Catalog struct {
+	c *Client
+}
+
+// Catalog returns the catalog-wide operations of c.
+func (c *Client) Catalog() *Catalog { return &Catalog{c: c} }
+
+// Ref names one catalog object.
+//
+// Workspace is the object's workspace. Store is the datastore or
+// coverage store of a KindFeatureType or KindCoverage; for a
+// KindTemplate it is the template's scope below the workspace, e.g.
+// "datastores/pg/featuretypes/roads". A KindACLRule has no Workspace
+// and its Name is the encoded rule ("topp.roads.r").
+type Ref struct {
+	Kind      string
+	Workspace string
+	Store     string
+	Name      string
+}
+
+// String renders r as "kind ws:store:name" ("kind ws/scope/name" for a
+// template).
+func (r Ref) String() string {
+	if r.Kind == KindTemplate {
+		return r.Kind + " " + strings.Join(nonEmpty(r.Workspace, r.Store, r.Name), "/")
+	}
+	return r.Kind + " " + strings.Join(nonEmpty(r.Workspace, r.Store, r.Name), ":")
+}
@@ -0,0 +1,178 @@
+package catalogdiff_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/catalogdiff"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/namespaces"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+const sld = `<StyledLayerDescriptor version="1.0.0"><NamedLayer><Name>roads</Name></NamedLayer></StyledLayerDescriptor>`
+
+// seed starts a fake server holding workspace "topp" with a PostGIS
+// store, a feature type, a workspace style and an ACL rule. host is
+// the store's database host and body the style's SLD.
+func seed(t *testing.T, host, body string) *geoserver.Client {
+	t.Helper()
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c, must := srv.Client(), srv.Must
+	ctx := context.Background()
+	must("namespace", c.Namespaces.Create(ctx, &namespaces.Namespace{Prefix: "topp", URI: "http://topp.example.org"}))
+	sc := c.Styles.InWorkspace("topp")
+	must("style", sc.Create(ctx, &styles.Style{Name: "roads"}))
+	must("style body", sc.UploadSLD(ctx, "roads", strings.NewReader(body), styles.UploadOptions{}))
+	pg := datastores.PostGIS{Name: "pg", Host: host, Port: 5432, Database: "gis", User: "u", Password: "secret"}
+	must("datastore", c.Datastores.InWorkspace("topp").Create(ctx, pg))
+	must("feature type", c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Create(ctx, &featuretypes.FeatureType{Name: "roads", Title: "Roads"}))
+	must("acl", c.ACL.Layers().Add(ctx, acl.Rule{Workspace: "topp", Layer: "*", Operation: acl.OpRead, Roles: []string{"ROLE_B", "ROLE_A"}}))
+	return c
+}
+
+func TestBetween_Equal(t *testing.T) {
+	from, to := seed(t, "db", sld), seed(t, "db", sld)
+
+	d, err := catalogdiff.Between(context.Background(), from, to, catalogdiff.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		t.Fatalf("identical catalogs differ (encrypted passwords must compare equal):\n%s", d)
+	}
+	if got := d.String(); got != "0 added, 0 removed, 0 changed\n" {
+		t.Fatalf("String = %q", got)
+	}
+}
+
+func TestBetween_Changes(t *testing.T) {
+	ctx := context.Background()
+	from := seed(t, "staging-db", sld)
+	to := seed(t, "prod-db", strings.Replace(sld, "roads", "highways", 1))
+	if err := to.Layers.InWorkspace("topp").Update(ctx, "roads", &layers.Layer{DefaultStyle: &layers.Ref{Name: "topp:roads"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := to.FeatureTypes.InWorkspace("topp").InDatastore("pg").Create(ctx, &featuretypes.FeatureType{Name: "rivers"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := from.ACL.REST().Add(ctx, acl.RESTRule{Pattern: "/rest/styles/**", Methods: []string{"GET"}, Roles: []string{"ADMIN"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := catalogdiff.Between(ctx, from, to, catalogdiff.Options{Workspaces: []string{"topp"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	find := func(typ catalogdiff.ChangeType, kind, name string) *catalogdiff.Change {
+		t.Helper()
+		for i, c := range d.Changes {
+			if c.Type == typ && c.Kind == kind && c.Name == name {
+				return &d.Changes[i]
+			}
+		}
+		t.Fatalf("no %s %s %s in\n%s", typ, kind, name, d)
+		return nil
+	}
+	ds := find(catalogdiff.Changed, geoserver.KindDatastore, "topp:pg")
+	if len(ds.Fields) != 1 || ds.Fields[0].Path != "connectionParameters.entry.host" ||
+		ds.Fields[0].From != "staging-db" || ds.Fields[0].To != "prod-db" {
+		t.Fatalf("datastore fields = %+v", ds.Fields)
+	}
+	if st := find(catalogdiff.Changed, geoserver.KindStyle, "topp:roads"); len(st.Fields) != 1 || st.Fields[0].Path != "sha256" {
+		t.Fatalf("style fields = %+v", st.Fields)
+	}
+	if l := find(catalogdiff.Changed, geoserver.KindLayer, "topp:roads"); len(l.Fields) != 1 || l.Fields[0].Path != "defaultStyle.name" {
+		t.Fatalf("layer fields = %+v", l.Fields)
+	}
+	find(catalogdiff.Added, geoserver.KindFeatureType, "topp:pg:rivers")
+	find(catalogdiff.Added, geoserver.KindLayer, "topp:rivers")
+	find(catalogdiff.Removed, geoserver.KindACLRule, "rest /rest/styles/**:GET")
+	if len(d.Changes) != 6 {
+		t.Fatalf("changes:\n%s", d)
+	}
+
+	text := d.String()
+	for _, want := range []string{
+		"~ datastore topp:pg\n    connectionParameters.entry.host: \"staging-db\" → \"prod-db\"\n",
+		"+ featuretype topp:pg:rivers\n",
+		"- aclrule rest /rest/styles/**:GET\n",
+		"2 added, 1 removed, 3 changed\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("String missing %q:\n%s", want, text)
+		}
+	}
+
+	raw, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back catalogdiff.Diff
+	if err := json.Unmarshal(raw, &back); err != nil || back.String() != text {
+		t.Fatalf("JSON round trip = %v\n%s", err, back.String())
+	}
+}
+
+func TestCompare_SavedInventory(t *testing.T) {
+	ctx := context.Background()
+	c := seed(t, "db", sld)
+	before, err := catalogdiff.Load(ctx, c, catalogdiff.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved catalogdiff.Inventory
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if d := catalogdiff.Compare(&saved, before); !d.Empty() {
+		t.Fatalf("saved inventory differs from itself:\n%s", d)
+	}
+
+	if err := c.Workspaces.Delete(ctx, "topp", workspaces.DeleteOptions{Recurse: true}); err != nil {
+		t.Fatal(err)
+	}
+	after, err := catalogdiff.Load(ctx, c, catalogdiff.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := catalogdiff.Compare(&saved, after)
+	for _, c := range d.Changes {
+		if c.Type != catalogdiff.Removed {
+			t.Fatalf("unexpected change %+v", c)
+		}
+	}
+	if !strings.Contains(d.String(), "- workspace topp\n") {
+		t.Fatalf("diff:\n%s", d)
+	}
+}
+
+func TestCompare_Normalization(t *testing.T) {
+	obj := func(doc string) *catalogdiff.Inventory {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(doc), &m); err != nil {
+			t.Fatal(err)
+		}
+		return &catalogdiff.Inventory{Objects: []catalogdiff.Object{{Kind: geoserver.KindFeatureType, Name: "ws:s:ft", Doc: m}}}
+	}
+	// Inventory docs are compared as given; normalization happens in
+	// Load. Check that named list elements are matched by name.
+	a := obj(`{"attributes":{"attribute":[{"name":"geom","binding":"Point"},{"name":"id","binding":"Integer"}]}}`)
+	b := obj(`{"attributes":{"attribute":[{"name":"id","binding":"Integer"},{"name":"geom","binding":"LineString"}]}}`)
+	d := catalogdiff.Compare(a, b)
+	if len(d.Changes) != 1 || len(d.Changes[0].Fields) != 1 || d.Changes[0].Fields[0].Path != "attributes.attribute[geom].binding" {
+		t.Fatalf("diff:\n%s", d)
+	}
+}
@@ -0,0 +1,190 @@
+package catalogdiff
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ChangeType says whether an object was added, removed or changed.
+type ChangeType string
+
+// Change types.
+const (
+	Added   ChangeType = "added"
+	Removed ChangeType = "removed"
+	Changed ChangeType = "changed"
+)
+
+// Diff is the difference between two inventories.
+type Diff struct {
+	// Changes are sorted by Kind, then Name.
+	Changes []Change `json:"changes"`
+}
+
+// Change is one object that differs.
+type Change struct {
+	Type ChangeType `json:"type"`
+	Kind string     `json:"kind"`
+	Name string     `json:"name"`
+
+	// Fields lists the differing fields of a Changed object.
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is one field that differs. Path is dotted; list
+// elements are addressed by name when every element has one
+// ("attributes.attribute[the_geom].binding") and by index otherwise.
+// From is absent when the field was added, To when it was removed.
+type FieldChange struct {
+	Path string `json:"path"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+// Empty reports whether the inventories were equal.
+func (d *Diff) Empty() bool { return len(d.Changes) == 0 }
+
+// String renders the diff one object per line: "+" added, "-"
+// removed, "~" changed followed by its fields, then a summary.
+func (d *Diff) String() string {
+	var b strings.Builder
+	counts := map[ChangeType]int{}
+	for _, c := range d.Changes {
+		counts[c.Type]++
+		sign := map[ChangeType]string{Added: "+", Removed: "-", Changed: "~"}[c.Type]
+		fmt.Fprintf(&b, "%s %s %s\n", sign, c.Kind, c.Name)
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "    %s: %s → %s\n", f.Path, render(f.From), render(f.To))
+		}
+	}
+	fmt.Fprintf(&b, "%d added, %d removed, %d changed\n", counts[Added], counts[Removed], counts[Changed])
+	return b.String()
+}
+
+func render(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
+
+// Compare reports what changed from from to to. Either may be nil,
+// meaning an empty catalog.
+func Compare(from, to *Inventory) *Diff {
+	type key struct{ kind, name string }
+	index := func(inv *Inventory) map[key]Object {
+		m := map[key]Object{}
+		if inv != nil {
+			for _, o := range inv.Objects {
+				m[key{o.Kind, o.Name}] = o
+			}
+		}
+		return m
+	}
+	a, b := index(from), index(to)
+	d := &Diff{}
+	for k, o := range a {
+		n, ok := b[k]
+		if !ok {
+			d.Changes = append(d.Changes, Change{Type: Removed, Kind: k.kind, Name: k.name})
+			continue
+		}
+		var fields []FieldChange
+		diffValue("", map[string]any(o.Doc), map[string]any(n.Doc), &fields)
+		if len(fields) > 0 {
+			slices.SortFunc(fields, func(x, y FieldChange) int { return cmp.Compare(x.Path, y.Path) })
+			d.Changes = append(d.Changes, Change{Type: Changed, Kind: k.kind, Name: k.name, Fields: fields})
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			d.Changes = append(d.Changes, Change{Type: Added, Kind: k.kind, Name: k.name})
+		}
+	}
+	slices.SortFunc(d.Changes, func(x, y Change) int {
+		return cmp.Or(cmp.Compare(x.Kind, y.Kind), cmp.Compare(x.Name, y.Name))
+	})
+	return d
+}
+
+func diffValue(path string, a, b any, out *[]FieldChange) {
+	switch x := a.(type) {
+	case map[string]any:
+		if y, ok := b.(map[string]any); ok {
+			keys := slices.Sorted(maps.Keys(x))
+			for k := range y {
+				if _, ok := x[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			for _, k := range keys {
+				diffValue(join(path, k), x[k], y[k], out)
+			}
+			return
+		}
+	case []any:
+		if y, ok := b.([]any); ok {
+			if xn, yn := named(x), named(y); xn != nil && yn != nil {
+				for _, k := range slices.Sorted(maps.Keys(xn)) {
+					diffValue(fmt.Sprintf("%s[%s]", path, k), xn[k], yn[k], out)
+				}
+				for _, k := range slices.Sorted(maps.Keys(yn)) {
+					if _, ok := xn[k]; !ok {
+						diffValue(fmt.Sprintf("%s[%s]", path, k), nil, yn[k], out)
+					}
+				}
+				return
+			}
+			if len(x) == len(y) {
+				for i := range x {
+					diffValue(fmt.Sprintf("%s[%d]", path, i), x[i], y[i], out)
+				}
+				return
+			}
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*out = append(*out, FieldChange{Path: path, From: a, To: b})
+	}
+}
+
+// named indexes xs by element name, or returns nil unless every element
+// is an object with a distinct "name".
+func named(xs []any) map[string]any {
+	m := make(map[string]any, len(xs))
+	for _, x := range xs {
+		e, ok := x.(map[string]any)
+		if !ok {
+			return nil
+		}
+		name, ok := e["name"].(string)
+		if !ok {
+			return nil
+		}
+		if _, dup := m[name]; dup {
+			return nil
+		}
+		m[name] = e
+	}
+	return m
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,319 @@
+// Package catalogdiff compares the catalogs of two GeoServers — or two
+// saved inventories of them — and reports what was added, removed and
+// changed, down to the field.
+//
+//	d, err := catalogdiff.Between(ctx, staging, prod, catalogdiff.Options{})
+//	fmt.Print(d)                  // text
+//	json.NewEncoder(os.Stdout).Encode(d)
+//
+// An [Inventory] covers workspaces with their namespace URI,
+// datastores, coverage stores, feature types with their attributes,
+// coverages, layers, styles (with a SHA-256 of the SLD body), layer
+// groups, ACL rules and OWS service settings. It is plain JSON, so an
+// inventory saved before a release can be compared with the live
+// server after it.
+//
+// Documents are normalized before comparison so wire quirks don't
+// show up as changes: hrefs, "@class" markers and empty values ("",
+// null, {}, []) are dropped, {"$": v} wrappers collapse to v,
+// {"@key","$"} entry lists become maps, a one-element list and the
+// bare object GeoServer sometimes writes instead compare equal, and
+// so do encrypted ("crypt1:…") values, which differ per server.
+package catalogdiff
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+)
+
+// Inventory is the normalized catalog of one server.
+type Inventory struct {
+	// Objects are sorted by Kind, then Name.
+	Objects []Object `json:"objects"`
+}
+
+// Object is one catalog object.
+type Object struct {
+	// Kind is one of the geoserver.Kind* constants.
+	Kind string `json:"kind"`
+
+	// Name is qualified: "ws", "ws:store", "ws:store:resource",
+	// "ws:layer", "ws:style" or "style" for a global style. ACL rules
+	// are named "<set> <rule>" (e.g. "layers topp.*.r") and service
+	// settings "wms" or "ws:wms".
+	Name string `json:"name"`
+
+	// Doc is the object's normalized JSON document.
+	Doc map[string]any `json:"doc"`
+}
+
+// Options configures [Load] and [Between].
+type Options struct {
+	// Workspaces limits the inventory to the named workspaces, or to
+	// those matching a pattern (see [geoserver.InventoryOptions]).
+	// Empty takes every workspace. Global styles, ACL rules and global
+	// service settings are always included.
+	Workspaces []string
+}
+
+// Between loads the inventories of from and to and compares them.
+func Between(ctx context.Context, from, to *geoserver.Client, opts Options) (*Diff, error) {
+	a, err := Load(ctx, from, opts)
+	if err != nil {
+		return nil, fmt.Errorf("catalogdiff: from: %w", err)
+	}
+	b, err := Load(ctx, to, opts)
+	if err != nil {
+		return nil, fmt.Errorf("catalogdiff: to: %w", err)
+	}
+	return Compare(a, b), nil
+}
+
+// Load reads the inventory of c. The catalog tree is crawled
+// concurrently by [geoserver.Catalog.Inventory].
+func Load(ctx context.Context, c *geoserver.Client, opts Options) (*Inventory, error) {
+	if c == nil {
+		return nil, errors.New("catalogdiff: nil client")
+	}
+	tree, err := c.Catalog().Inventory(ctx, geoserver.InventoryOptions{Workspaces: opts.Workspaces})
+	if err == nil {
+		err = tree.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("catalogdiff: %w", err)
+	}
+	l := &loader{c: c, inv: &Inventory{}}
+	steps := []func(context.Context) error{
+		func(ctx context.Context) error { return l.styles(ctx, "", tree.Styles) },
+		func(ctx context.Context) error { return l.services(ctx, "") },
+		l.acl,
+	}
+	for _, iw := range tree.Workspaces {
+		steps = append(steps, func(ctx context.Context) error {
+			if err := l.workspace(ctx, iw); err != nil {
+				return fmt.Errorf("workspace %s: %w", iw.Workspace.Name, err)
+			}
+			return nil
+		})
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return nil, fmt.Errorf("catalogdiff: %w", err)
+		}
+	}
+	slices.SortFunc(l.inv.Objects, func(a, b Object) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+	return l.inv, nil
+}
+
+type loader struct {
+	c   *geoserver.Client
+	inv *Inventory
+}
+
+func (l *loader) add(kind, name string, v any) error {
+	doc, err := wire.NormalizeDoc(v)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", kind, name, err)
+	}
+	l.inv.Objects = append(l.inv.Objects, Object{Kind: kind, Name: name, Doc: doc})
+	return nil
+}
+
+func qualify(parts ...string) string {
+	return strings.Join(slices.DeleteFunc(parts, func(s string) bool { return s == "" }), ":")
+}
+
+// workspace adds the crawled contents of one workspace, plus its
+// namespace and service settings, which the crawl doesn't cover.
+func (l *loader) workspace(ctx context.Context, iw *geoserver.InventoryWorkspace) error {
+	name := iw.Workspace.Name
+	ns, err := l.c.Namespaces.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := l.add(geoserver.KindWorkspace, name, ns); err != nil {
+		return err
+	}
+	if err := l.styles(ctx, name, iw.Styles); err != nil {
+		return err
+	}
+	for _, s := range iw.Datastores {
+		ds := *s.Datastore
+		ds.FeatureTypes = "" // a link
+		if err := l.add(geoserver.KindDatastore, qualify(name, ds.Name), ds); err != nil {
+			return err
+		}
+		for _, ft := range s.FeatureTypes {
+			if err := l.add(geoserver.KindFeatureType, qualify(name, ds.Name, ft.FeatureType.Name), ft.FeatureType); err != nil {
+				return err
+			}
+		}
+	}
+	for _, s := range iw.CoverageStores {
+		cs := *s.CoverageStore
+		cs.Coverages = "" // a link
+		if err := l.add(geoserver.KindCoverageStore, qualify(name, cs.Name), cs); err != nil {
+			return err
+		}
+		for _, cov := range s.Coverages {
+			if err := l.add(geoserver.KindCoverage, qualify(name, cs.Name, cov.Coverage.Name), cov.Coverage); err != nil {
+				return err
+			}
+		}
+	}
+	for _, layer := range iw.Layers {
+		if err := l.add(geoserver.KindLayer, qualify(name, layer.Layer.Name), layer.Layer); err != nil {
+			return err
+		}
+	}
+	for _, g := range iw.LayerGroups {
+		if err := l.add(geoserver.KindLayerGroup, qualify(name, g.LayerGroup.Name), g.LayerGroup); err != nil {
+			return err
+		}
+	}
+	return l.services(ctx, name)
+}
+
+// styleDoc is a style's metadata plus the hash of its SLD body.
+type styleDoc struct {
+	*styles.Style
+	SHA256 string `json:"sha256"`
+}
+
+// styles adds the crawled styles of ws ("" for the global ones), each
+// with the hash of its SLD body.
+func (l *loader) styles(ctx context.Context, ws string, list []*geoserver.InventoryStyle) error {
+	sc := l.c.Styles
+	if ws != "" {
+		sc = sc.InWorkspace(ws)
+	}
+	for _, s := range list {
+		st := s.Style
+		body, err := sc.GetSLD(ctx, st.Name)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, body)
+		_ = body.Close()
+		if err != nil {
+			return fmt.Errorf("style %s: read body: %w", st.Name, err)
+		}
+		if err := l.add(geoserver.KindStyle, qualify(ws, st.Name), styleDoc{Style: st, SHA256: hex.EncodeToString(h.Sum(nil))}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// services adds the WMS, WFS, WCS and WMTS settings: the global ones
+// for ws == "", otherwise the workspace's overrides. A missing
+// override, or a service that isn't installed, is skipped.
+func (l *loader) services(ctx context.Context, ws string) error {
+	svc := l.c.Services
+	type getter struct {
+		slug string
+		get  func(context.Context) (any, error)
+	}
+	getters := []getter{
+		{"wms", func(ctx context.Context) (any, error) {
+			if ws != "" {
+				return svc.WMS().InWorkspace(ws).Get(ctx)
+			}
+			return svc.WMS().Get(ctx)
+		}},
+		{"wfs", func(ctx context.Context) (any, error) {
+			if ws != "" {
+				return svc.WFS().InWorkspace(ws).Get(ctx)
+			}
+			return svc.WFS().Get(ctx)
+		}},
+		{"wcs", func(ctx context.Context) (any, error) {
+			if ws != "" {
+				return svc.WCS().InWorkspace(ws).Get(ctx)
+			}
+			return svc.WCS().Get(ctx)
+		}},
+		{"wmts", func(ctx context.Context) (any, error) {
+			if ws != "" {
+				return svc.WMTS().InWorkspace(ws).Get(ctx)
+			}
+			return svc.WMTS().Get(ctx)
+		}},
+	}
+	for _, g := range getters {
+		settings, err := g.get(ctx)
+		if errors.Is(err, geoserver.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s settings: %w", g.slug, err)
+		}
+		if err := l.add(geoserver.KindServiceSettings, qualify(ws, g.slug), settings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleDoc is the document of an ACL rule: its roles, sorted.
+type ruleDoc struct {
+	Roles []string `json:"roles"`
+}
+
+func (l *loader) acl(ctx context.Context) error {
+	lrules, err := l.c.ACL.Layers().List(ctx, acl.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, r := range lrules {
+		rule, roles := r.Encode()
+		if err := l.addRule("layers", rule, roles); err != nil {
+			return err
+		}
+	}
+	srules, err := l.c.ACL.Services().List(ctx, acl.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, r := range srules {
+		rule, roles := r.Encode()
+		if err := l.addRule("services", rule, roles); err != nil {
+			return err
+		}
+	}
+	rrules, err := l.c.ACL.REST().List(ctx, acl.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, r := range rrules {
+		rule, roles := r.Encode()
+		if err := l.addRule("rest", rule, roles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *loader) addRule(set, r, roles string) error {
+	rs := strings.Split(roles, ",")
+	for i := range rs {
+		rs[i] = strings.TrimSpace(rs[i])
+	}
+	slices.Sort(rs)
+	return l.add(geoserver.KindACLRule, set+" "+r, ruleDoc{Roles: rs})
+}
@@ -0,0 +1,629 @@
+package geoserver
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// ErrNoHealthyNode is returned by [Cluster] reads when no node answers
+// [about.Client.Ping]. The per-node ping failures are joined onto it.
+var ErrNoHealthyNode = errors.New("geoserver: no healthy cluster node")
+
+// clusterHealthTTL is how long a node that answered Ping keeps serving
+// reads before it is pinged again.
+const clusterHealthTTL = 5 * time.Second
+
+// Cluster drives several GeoServer nodes that keep separate data
+// directories in sync by replaying the same REST writes on each.
+//
+// Mutating calls on the typed fields (Workspaces, Datastores, Layers,
+// Styles) are sent to every node concurrently and return a
+// [*ClusterResult] with one entry per node; [Cluster.Each] does the
+// same for any other operation. Reads go to a single healthy node: the
+// first, in the order given to [NewCluster], that answers
+// [about.Client.Ping]. A read that fails at the connection level, or
+// with 502/503/504, is retried on the next healthy node.
+//
+//	cl, _ := geoserver.NewCluster(node1, node2, node3)
+//	res := cl.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"})
+//	if err := res.Err(); err != nil { /* one or more nodes failed */ }
+//	report, _ := cl.Verify(ctx)
+//	for _, d := range report.Drift { log.Println(d) }
+//
+// Cluster adds no cross-node transaction: a write that fails on some
+// nodes leaves the others changed. Use [Cluster.Verify] to find the
+// resulting drift.
+type Cluster struct {
+	nodes []*Client
+	names []string
+
+	// Workspaces fans workspace writes out to every node.
+	Workspaces *ClusterWorkspaces
+
+	// Datastores fans datastore writes out to every node. Scope with
+	// [ClusterDatastores.InWorkspace].
+	Datastores *ClusterDatastores
+
+	// Layers fans layer writes out to every node. Scope with
+	// [ClusterLayers.InWorkspace].
+	Layers *ClusterLayers
+
+	// Styles fans style writes out to every node. Global by default;
+	// scope with [ClusterStyles.InWorkspace].
+	Styles *ClusterStyles
+
+	mu      sync.Mutex
+	healthy int // index of the node serving reads; -1 when unknown
+	checked time.Time
+}
+
+// NewCluster builds a [*Cluster] over nodes. Each node is an ordinary
+// [*Client] with its own options (auth, retry, …); nodes are named by
+// their server URL in results and reports. Node order sets read
+// preference.
+func NewCluster(nodes ...*Client) (*Cluster, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("geoserver: NewCluster: no nodes")
+	}
+	cl := &Cluster{healthy: -1}
+	for i, n := range nodes {
+		if n == nil {
+			return nil, fmt.Errorf("geoserver: NewCluster: node %d is nil", i)
+		}
+		cl.nodes = append(cl.nodes, n)
+		cl.names = append(cl.names, strings.TrimSuffix(n.core.baseURL, "/"))
+	}
+	cl.Workspaces = &ClusterWorkspaces{cl: cl}
+	cl.Datastores = &ClusterDatastores{cl: cl}
+	cl.Layers = &ClusterLayers{cl: cl}
+	cl.Styles = &ClusterStyles{cl: cl}
+	return cl, nil
+}
+
+// Nodes returns the node names (server URLs) in cluster order.
+func (cl *Cluster) Nodes() []string { return slices.Clone(cl.names) }
+
+// NodeResult is one node's outcome of a fanned-out call.
+type NodeResult struct {
+	// Node is the node's server URL.
+	Node string
+	// Err is nil on success; otherwise typically an [*APIError].
+	Err error
+}
+
+// ClusterResult aggregates the per-node outcomes of one fanned-out
+// call. Nodes is in cluster order.
+type ClusterResult struct {
+	Op    string
+	Nodes []NodeResult
+}
+
+// OK reports whether the call succeeded on every node.
+func (r *ClusterResult) OK() bool { return len(r.Failed()) == 0 }
+
+// Failed returns the entries for nodes where the call failed.
+func (r *ClusterResult) Failed() []NodeResult {
+	var out []NodeResult
+	for _, n := range r.Nodes {
+		if n.Err != nil {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// Err joins the per-node failures, each prefixed with its node name,
+// or returns nil if every node succeeded. The node errors stay
+// reachable through errors.Is / errors.As:
+//
+//	errors.Is(res.Err(), geoserver.ErrAlreadyExists)
+func (r *ClusterResult) Err() error {
+	var errs []error
+	for _, n := range r.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", n.Node, n.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// Each runs fn against every node concurrently and collects the
+// outcomes. op labels the result. Use it for writes the typed fields
+// don't cover (feature types, layer groups, …):
+//
+//	res := cl.Each(ctx, "FeatureTypes.Create", func(ctx context.Context, c *geoserver.Client) error {
+//		return c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Create(ctx, ft)
+//	})
+func (cl *Cluster) Each(ctx context.Context, op string, fn func(ctx context.Context, c *Client) error) *ClusterResult {
+	return cl.eachIndex(ctx, op, func(ctx context.Context, i int) error {
+		return fn(ctx, cl.nodes[i])
+	})
+}
+
+// eachIndex is [Cluster.Each] keyed by node index.
+func (cl *Cluster) eachIndex(ctx context.Context, op string, fn func(ctx context.Context, i int) error) *ClusterResult {
+	res := &ClusterResult{Op: op, Nodes: make([]NodeResult, len(cl.nodes))}
+	var wg sync.WaitGroup
+	for i := range cl.nodes {
+		res.Nodes[i].Node = cl.names[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res.Nodes[i].Err = fn(ctx, i)
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+// Healthy returns the node currently serving reads, pinging nodes in
+// cluster order if the last choice is stale or unknown.
+func (cl *Cluster) Healthy(ctx context.Context) (*Client, error) {
+	i, err := cl.pick(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cl.nodes[i], nil
+}
+
+// pick returns the index of a healthy node, skipping nodes marked in
+// tried. A cached choice younger than clusterHealthTTL is reused.
+func (cl *Cluster) pick(ctx context.Context, tried []bool) (int, error) {
+	cl.mu.Lock()
+	if cl.healthy >= 0 && time.Since(cl.checked) < clusterHealthTTL && (tried == nil || !tried[cl.healthy]) {
+		i := cl.healthy
+		cl.mu.Unlock()
+		return i, nil
+	}
+	cl.mu.Unlock()
+
+	errs := []error{ErrNoHealthyNode}
+	for i, n := range cl.nodes {
+		if tried != nil && tried[i] {
+			continue
+		}
+		if err := n.About.Ping(ctx); err != nil {
+			if ctx.Err() != nil {
+				return -1, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", cl.names[i], err))
+			continue
+		}
+		cl.mu.Lock()
+		cl.healthy, cl.checked = i, time.Now()
+		cl.mu.Unlock()
+		return i, nil
+	}
+	return -1, errors.Join(errs...)
+}
+
+// markDown forgets node i as the read node so the next pick re-pings.
+func (cl *Cluster) markDown(i int) {
+	cl.mu.Lock()
+	if cl.healthy == i {
+		cl.healthy = -1
+	}
+	cl.mu.Unlock()
+}
+
+// clusterRead runs fn on a healthy node, failing over to the next
+// healthy node when the error suggests the node itself is down.
+func clusterRead[T any](ctx context.Context, cl *Cluster, fn func(c *Client) (T, error)) (T, error) {
+	var zero T
+	tried := make([]bool, len(cl.nodes))
+	for {
+		i, err := cl.pick(ctx, tried)
+		if err != nil {
+			return zero, err
+		}
+		v, err := fn(cl.nodes[i])
+		if err == nil || ctx.Err() != nil || !nodeUnavailable(err) {
+			return v, err
+		}
+		tried[i] = true
+		cl.markDown(i)
+	}
+}
+
+// nodeUnavailable reports whether err means the node could not serve
+// the request at all, as opposed to a catalog-level answer like 404.
+func nodeUnavailable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return !errors.Is(err, ErrResponseTooLarge)
+	}
+	switch apiErr.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// ClusterWorkspaces is the workspace surface of a [Cluster].
+type ClusterWorkspaces struct {
+	cl *Cluster
+}
+
+// List reads the workspace list from a healthy node.
+func (w *ClusterWorkspaces) List(ctx context.Context, opts workspaces.ListOptions) ([]workspaces.Workspace, error) {
+	return clusterRead(ctx, w.cl, func(c *Client) ([]workspaces.Workspace, error) {
+		return c.Workspaces.List(ctx, opts)
+	})
+}
+
+// Get reads one workspace from a healthy node.
+func (w *ClusterWorkspaces) Get(ctx context.Context, name string) (*workspaces.Workspace, error) {
+	return clusterRead(ctx, w.cl, func(c *Client) (*workspaces.Workspace, error) {
+		return c.Workspaces.Get(ctx, name)
+	})
+}
+
+// Create creates the workspace on every node.
+func (w *ClusterWorkspaces) Create(ctx context.Context, ws *workspaces.Workspace) *ClusterResult {
+	return w.cl.Each(ctx, "Workspaces.Create", func(ctx context.Context, c *Client) error {
+		return c.Workspaces.Create(ctx, clonePtr(ws))
+	})
+}
+
+// Update patches the workspace on every node.
+func (w *ClusterWorkspaces) Update(ctx context.Context, name string, patch *workspaces.WorkspacePatch) *ClusterResult {
+	return w.cl.Each(ctx, "Workspaces.Update", func(ctx context.Context, c *Client) error {
+		return c.Workspaces.Update(ctx, name, patch)
+	})
+}
+
+// Delete deletes the workspace on every node.
+func (w *ClusterWorkspaces) Delete(ctx context.Context, name string, opts workspaces.DeleteOptions) *ClusterResult {
+	return w.cl.Each(ctx, "Workspaces.Delete", func(ctx context.Context, c *Client) error {
+		return c.Workspaces.Delete(ctx, name, opts)
+	})
+}
+
+// ClusterDatastores is the datastore surface of a [Cluster]. Methods
+// need a workspace scope — see [ClusterDatastores.InWorkspace].
+type ClusterDatastores struct {
+	cl        *Cluster
+	workspace string
+}
+
+// InWorkspace returns a copy scoped to the named workspace.
+func (d *ClusterDatastores) InWorkspace(workspace string) *ClusterDatastores {
+	return &ClusterDatastores{cl: d.cl, workspace: workspace}
+}
+
+// List reads the datastore list from a healthy node.
+func (d *ClusterDatastores) List(ctx context.Context, opts datastores.ListOptions) ([]datastores.Datastore, error) {
+	return clusterRead(ctx, d.cl, func(c *Client) ([]datastores.Datastore, error) {
+		return c.Datastores.InWorkspace(d.workspace).List(ctx, opts)
+	})
+}
+
+// Get reads one datastore from a healthy node.
+func (d *ClusterDatastores) Get(ctx context.Context, name string) (*datastores.Datastore, error) {
+	return clusterRead(ctx, d.cl, func(c *Client) (*datastores.Datastore, error) {
+		return c.Datastores.InWorkspace(d.workspace).Get(ctx, name)
+	})
+}
+
+// Create creates the datastore on every node.
+func (d *ClusterDatastores) Create(ctx context.Context, conn datastores.Connector) *ClusterResult {
+	return d.cl.Each(ctx, "Datastores.Create", func(ctx context.Context, c *Client) error {
+		return c.Datastores.InWorkspace(d.workspace).Create(ctx, conn)
+	})
+}
+
+// Update patches the datastore on every node.
+func (d *ClusterDatastores) Update(ctx context.Context, name string, patch *datastores.Patch) *ClusterResult {
+	return d.cl.Each(ctx, "Datastores.Update", func(ctx context.Context, c *Client) error {
+		return c.Datastores.InWorkspace(d.workspace).Update(ctx, name, patch)
+	})
+}
+
+// Delete deletes the datastore on every node.
+func (d *ClusterDatastores) Delete(ctx context.Context, name string, opts datastores.DeleteOptions) *ClusterResult {
+	return d.cl.Each(ctx, "Datastores.Delete", func(ctx context.Context, c *Client) error {
+		return c.Datastores.InWorkspace(d.workspace).Delete(ctx, name, opts)
+	})
+}
+
+// ClusterLayers is the layer surface of a [Cluster]. Methods need a
+// workspace scope — see [ClusterLayers.InWorkspace]. Layers are
+// created by publishing a feature type or coverage; use [Cluster.Each]
+// for that.
+type ClusterLayers struct {
+	cl        *Cluster
+	workspace string
+}
+
+// InWorkspace returns a copy scoped to the named workspace.
+func (l *ClusterLayers) InWorkspace(workspace string) *ClusterLayers {
+	return &ClusterLayers{cl: l.cl, workspace: workspace}
+}
+
+// List reads the layer list from a healthy node.
+func (l *ClusterLayers) List(ctx context.Context, opts layers.ListOptions) ([]layers.Layer, error) {
+	return clusterRead(ctx, l.cl, func(c *Client) ([]layers.Layer, error) {
+		return c.Layers.InWorkspace(l.workspace).List(ctx, opts)
+	})
+}
+
+// Get reads one layer from a healthy node.
+func (l *ClusterLayers) Get(ctx context.Context, name string) (*layers.Layer, error) {
+	return clusterRead(ctx, l.cl, func(c *Client) (*layers.Layer, error) {
+		return c.Layers.InWorkspace(l.workspace).Get(ctx, name)
+	})
+}
+
+// Update updates the layer on every node.
+func (l *ClusterLayers) Update(ctx context.Context, name string, layer *layers.Layer) *ClusterResult {
+	return l.cl.Each(ctx, "Layers.Update", func(ctx context.Context, c *Client) error {
+		return c.Layers.InWorkspace(l.workspace).Update(ctx, name, layer)
+	})
+}
+
+// Delete deletes the layer on every node.
+func (l *ClusterLayers) Delete(ctx context.Context, name string, opts layers.DeleteOptions) *ClusterResult {
+	return l.cl.Each(ctx, "Layers.Delete", func(ctx context.Context, c *Client) error {
+		return c.Layers.InWorkspace(l.workspace).Delete(ctx, name, opts)
+	})
+}
+
+// ClusterStyles is the style surface of a [Cluster]. It targets global
+// styles unless scoped with [ClusterStyles.InWorkspace].
+type ClusterStyles struct {
+	cl        *Cluster
+	workspace string
+}
+
+// InWorkspace returns a copy scoped to the named workspace.
+func (s *ClusterStyles) InWorkspace(workspace string) *ClusterStyles {
+	return &ClusterStyles{cl: s.cl, workspace: workspace}
+}
+
+func (s *ClusterStyles) on(c *Client) *styles.Client {
+	if s.workspace == "" {
+		return c.Styles
+	}
+	return c.Styles.InWorkspace(s.workspace)
+}
+
+// List reads the style list from a healthy node.
+func (s *ClusterStyles) List(ctx context.Context, opts styles.ListOptions) ([]styles.Style, error) {
+	return clusterRead(ctx, s.cl, func(c *Client) ([]styles.Style, error) {
+		return s.on(c).List(ctx, opts)
+	})
+}
+
+// Get reads one style from a healthy node.
+func (s *ClusterStyles) Get(ctx context.Context, name string) (*styles.Style, error) {
+	return clusterRead(ctx, s.cl, func(c *Client) (*styles.Style, error) {
+		return s.on(c).Get(ctx, name)
+	})
+}
+
+// Create registers the style metadata on every node.
+func (s *ClusterStyles) Create(ctx context.Context, style *styles.Style) *ClusterResult {
+	return s.cl.Each(ctx, "Styles.Create", func(ctx context.Context, c *Client) error {
+		return s.on(c).Create(ctx, clonePtr(style))
+	})
+}
+
+// UploadSLD uploads the style body to every node. body is read into
+// memory once so each node receives the same bytes.
+func (s *ClusterStyles) UploadSLD(ctx context.Context, name string, body io.Reader, opts styles.UploadOptions) *ClusterResult {
+	const op = "Styles.UploadSLD"
+	if body == nil {
+		return s.cl.Each(ctx, op, func(ctx context.Context, c *Client) error {
+			return s.on(c).UploadSLD(ctx, name, nil, opts)
+		})
+	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return s.cl.Each(ctx, op, func(context.Context, *Client) error {
+			return fmt.Errorf("%s: read body: %w", op, err)
+		})
+	}
+	return s.cl.Each(ctx, op, func(ctx context.Context, c *Client) error {
+		return s.on(c).UploadSLD(ctx, name, bytes.NewReader(raw), opts)
+	})
+}
+
+// Update updates the style metadata on every node.
+func (s *ClusterStyles) Update(ctx context.Context, name string, style *styles.Style) *ClusterResult {
+	return s.cl.Each(ctx, "Styles.Update", func(ctx context.Context, c *Client) error {
+		return s.on(c).Update(ctx, name, style)
+	})
+}
+
+// Delete deletes the style on every node.
+func (s *ClusterStyles) Delete(ctx context.Context, name string, opts styles.DeleteOptions) *ClusterResult {
+	return s.cl.Each(ctx, "Styles.Delete", func(ctx context.Context, c *Client) error {
+		return s.on(c).Delete(ctx, name, opts)
+	})
+}
+
+// clonePtr returns a shallow copy of *p so per-node calls that fill in
+// defaults on their argument don't race.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// ClusterDrift is one catalog object that exists on some nodes but
+// not on others, or whose document differs between nodes.
+type ClusterDrift struct {
+	// Kind is KindWorkspace, KindDatastore, KindCoverageStore,
+	// KindLayer or KindStyle.
+	Kind string
+	// Name is qualified with its workspace ("topp:states") except for
+	// workspaces and global styles.
+	Name      string
+	PresentOn []string
+	MissingOn []string
+	// DifferentOn lists the nodes whose document differs from the one
+	// on the first node in PresentOn, and Fields the differing JSON
+	// field paths (e.g. "title" or "connectionParameters.host").
+	DifferentOn []string
+	Fields      []string
+}
+
+// String renders the drift as a one-line summary.
+func (d ClusterDrift) String() string {
+	var parts []string
+	if len(d.MissingOn) > 0 {
+		parts = append(parts, "missing on "+strings.Join(d.MissingOn, ", "))
+	}
+	if len(d.DifferentOn) > 0 {
+		parts = append(parts, fmt.Sprintf("differs on %s (%s)", strings.Join(d.DifferentOn, ", "), strings.Join(d.Fields, ", ")))
+	}
+	return d.Kind + " " + d.Name + " " + strings.Join(parts, "; ")
+}
+
+// ClusterReport is the outcome of [Cluster.Verify].
+type ClusterReport struct {
+	// Nodes lists every node in cluster order.
+	Nodes []string
+	// Drift lists the objects not present on every inventoried node
+	// or not the same on all of them, sorted by kind then name.
+	Drift []ClusterDrift
+	// Errors lists nodes whose inventory could not be read. They are
+	// left out of the drift comparison.
+	Errors []NodeResult
+}
+
+// Converged reports whether every node was inventoried and no drift
+// was found.
+func (r *ClusterReport) Converged() bool { return len(r.Drift) == 0 && len(r.Errors) == 0 }
+
+type inventoryKey struct{ kind, name string }
+
+// Verify reads the workspaces, datastores, coverage stores, layers and
+// styles (global and per-workspace) on every node and reports objects
+// missing from some of them or differing between them. Documents are
+// normalized before they are compared, so hrefs, timestamps,
+// encrypted passwords and a store's links to its children never count
+// as drift. The returned error is non-nil only if ctx ends first.
+func (cl *Cluster) Verify(ctx context.Context) (*ClusterReport, error) {
+	docs := make([]map[inventoryKey]map[string]any, len(cl.nodes))
+	res := cl.eachIndex(ctx, "Cluster.Verify", func(ctx context.Context, i int) error {
+		set, err := clusterInventory(ctx, cl.nodes[i])
+		docs[i] = set
+		return err
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &ClusterReport{Nodes: cl.Nodes(), Errors: res.Failed()}
+	union := map[inventoryKey]bool{}
+	for _, set := range docs {
+		for k := range set {
+			union[k] = true
+		}
+	}
+	for k := range union {
+		d := ClusterDrift{Kind: k.kind, Name: k.name}
+		var ref map[string]any
+		for i, set := range docs {
+			if set == nil {
+				continue
+			}
+			doc, ok := set[k]
+			if !ok {
+				d.MissingOn = append(d.MissingOn, cl.names[i])
+				continue
+			}
+			d.PresentOn = append(d.PresentOn, cl.names[i])
+			if ref == nil {
+				ref = doc
+				continue
+			}
+			if fields := docDiff(ref, doc); len(fields) > 0 {
+				d.DifferentOn = append(d.DifferentOn, cl.names[i])
+				d.Fields = append(d.Fields, fields...)
+			}
+		}
+		if len(d.MissingOn) > 0 || len(d.DifferentOn) > 0 {
+			slices.Sort(d.Fields)
+			d.Fields = slices.Compact(d.Fields)
+			report.Drift = append(report.Drift, d)
+		}
+	}
+	slices.SortFunc(report.Drift, func(a, b ClusterDrift) int {
+		return cmp.Or(cmp.Compare(kindOrder(a.Kind), kindOrder(b.Kind)), cmp.Compare(a.Name, b.Name))
+	})
+	return report, nil
+}
+
+// docDiff returns the field paths that differ between two normalized
+// documents in either direction.
+func docDiff(a, b map[string]any) []string {
+	ab, _ := wire.Diff(a, b)
+	ba, _ := wire.Diff(b, a)
+	return append(ab, ba...)
+}
+
+func kindOrder(kind string) int {
+	return slices.Index([]string{KindWorkspace, KindDatastore, KindCoverageStore, KindLayer, KindStyle}, kind)
+}
+
+// clusterInventory reads one node's catalog as normalized documents.
+func clusterInventory(ctx context.Context, c *Client) (map[inventoryKey]map[string]any, error) {
+	inv, err := c.Catalog().Inventory(ctx, InventoryOptions{
+		Kinds: []string{KindDatastore, KindCoverageStore, KindLayer, KindStyle},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := inv.Err(); err != nil {
+		return nil, err
+	}
+	set := map[inventoryKey]map[string]any{}
+	var firstErr error
+	add := func(kind, name string, v any) {
+		doc, err := wire.NormalizeDoc(v)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s %s: %w", kind, name, err)
+		}
+		// A store links its children by URL, which names the node.
+		delete(doc, "featureTypes")
+		delete(doc, "coverages")
+		set[inventoryKey{kind, name}] = doc
+	}
+	for _, s := range inv.Styles {
+		add(KindStyle, s.Style.Name, s.Style)
+	}
+	for _, iw := range inv.Workspaces {
+		ws := iw.Workspace.Name
+		add(KindWorkspace, ws, iw.Workspace)
+		for _, s := range iw.Datastores {
+			add(KindDatastore, ws+":"+s.Datastore.Name, s.Datastore)
+		}
+		for _, s := range iw.CoverageStores {
+			add(KindCoverageStore, ws+":"+s.CoverageStore.Name, s.CoverageStore)
+		}
+		for _, l := range iw.Layers {
+			add(KindLayer, ws+":"+l.Layer.Name, l.Layer)
+		}
+		for _, s := range iw.Styles {
+			add(KindStyle, ws+":"+s.Style.Name, s.Style)
+		}
+	}
+	return set, firstErr
+}
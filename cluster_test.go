@@ -0,0 +1,340 @@
+package geoserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// fakeNode is a minimal GeoServer catalog: workspaces, datastores,
+// layers and styles by name, enough for the cluster tests.
+type fakeNode struct {
+	mu         sync.Mutex
+	down       bool // answer 503 to everything
+	workspaces []string
+	datastores map[string][]string // workspace → store names
+	layers     map[string][]string // workspace → layer names
+	styles     []string            // global
+	hits       []string            // "METHOD path"
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.hits = append(n.hits, r.Method+" "+r.URL.Path)
+	if n.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/rest/"), "/"), "/")
+	list := func(outer, inner string, names []string) {
+		entries := make([]map[string]string, 0, len(names))
+		for _, name := range names {
+			entries = append(entries, map[string]string{"name": name})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{outer: map[string]any{inner: entries}})
+	}
+	item := func(key, name string, names []string) {
+		if !slices.Contains(names, name) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{key: map[string]string{"name": name}})
+	}
+	switch {
+	case parts[0] == "about":
+		_, _ = io.WriteString(w, `{"about":{"resource":[]}}`)
+	case len(parts) == 1 && parts[0] == "workspaces" && r.Method == http.MethodPost:
+		var body struct{ Workspace workspaces.Workspace }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, ws := range n.workspaces {
+			if ws == body.Workspace.Name {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, "Workspace named '%s' already exists.", ws)
+				return
+			}
+		}
+		n.workspaces = append(n.workspaces, body.Workspace.Name)
+		w.WriteHeader(http.StatusCreated)
+	case len(parts) == 1 && parts[0] == "workspaces":
+		list("workspaces", "workspace", n.workspaces)
+	case len(parts) == 2 && parts[0] == "workspaces":
+		for _, ws := range n.workspaces {
+			if ws == parts[1] {
+				_ = json.NewEncoder(w).Encode(map[string]any{"workspace": map[string]string{"name": ws}})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case len(parts) == 1 && parts[0] == "styles":
+		list("styles", "style", n.styles)
+	case len(parts) == 2 && parts[0] == "styles":
+		item("style", parts[1], n.styles)
+	case len(parts) == 4 && parts[2] == "datastores":
+		item("dataStore", parts[3], n.datastores[parts[1]])
+	case len(parts) == 4 && parts[2] == "layers":
+		item("layer", parts[3], n.layers[parts[1]])
+	case len(parts) == 3 && parts[2] == "datastores":
+		list("dataStores", "dataStore", n.datastores[parts[1]])
+	case len(parts) == 3 && parts[2] == "coveragestores":
+		list("coverageStores", "coverageStore", nil)
+	case len(parts) == 3 && parts[2] == "layers":
+		list("layers", "layer", n.layers[parts[1]])
+	case len(parts) == 3 && parts[2] == "styles":
+		list("styles", "style", nil)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (n *fakeNode) hitCount(prefix string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	count := 0
+	for _, h := range n.hits {
+		if strings.HasPrefix(h, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+// newFakeCluster starts one server per node and returns the cluster
+// plus the node URLs.
+func newFakeCluster(t *testing.T, nodes ...*fakeNode) (*geoserver.Cluster, []string) {
+	t.Helper()
+	var clients []*geoserver.Client
+	var urls []string
+	for _, n := range nodes {
+		srv := httptest.NewServer(n)
+		t.Cleanup(srv.Close)
+		c, err := geoserver.New(srv.URL)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		clients = append(clients, c)
+		urls = append(urls, srv.URL)
+	}
+	cl, err := geoserver.NewCluster(clients...)
+	if err != nil {
+		t.Fatalf("NewCluster: %v", err)
+	}
+	return cl, urls
+}
+
+func TestNewCluster_Validation(t *testing.T) {
+	if _, err := geoserver.NewCluster(); err == nil {
+		t.Fatal("expected error for no nodes")
+	}
+	if _, err := geoserver.NewCluster(nil); err == nil {
+		t.Fatal("expected error for nil node")
+	}
+}
+
+func TestCluster_WriteFansOut(t *testing.T) {
+	a, b, c := &fakeNode{}, &fakeNode{workspaces: []string{"topp"}}, &fakeNode{}
+	cl, urls := newFakeCluster(t, a, b, c)
+
+	res := cl.Workspaces.Create(context.Background(), &workspaces.Workspace{Name: "topp"})
+	if res.Op != "Workspaces.Create" || len(res.Nodes) != 3 {
+		t.Fatalf("result = %+v", res)
+	}
+	for _, n := range []*fakeNode{a, b, c} {
+		if got := n.hitCount("POST /rest/workspaces"); got != 1 {
+			t.Fatalf("POST count = %d, want 1 per node", got)
+		}
+	}
+	if res.OK() {
+		t.Fatal("OK() = true, want false")
+	}
+	failed := res.Failed()
+	if len(failed) != 1 || failed[0].Node != urls[1] {
+		t.Fatalf("Failed() = %+v, want node %s", failed, urls[1])
+	}
+	err := res.Err()
+	if !errors.Is(err, geoserver.ErrAlreadyExists) {
+		t.Fatalf("Err() = %v, want ErrAlreadyExists", err)
+	}
+	if !strings.Contains(err.Error(), urls[1]) {
+		t.Fatalf("Err() = %q, want node name", err)
+	}
+}
+
+func TestCluster_WriteAllSucceed(t *testing.T) {
+	cl, _ := newFakeCluster(t, &fakeNode{}, &fakeNode{})
+	res := cl.Workspaces.Create(context.Background(), &workspaces.Workspace{Name: "topp"})
+	if !res.OK() || res.Err() != nil {
+		t.Fatalf("result = %+v", res)
+	}
+}
+
+func TestCluster_ReadsGoToHealthyNode(t *testing.T) {
+	a := &fakeNode{down: true}
+	b := &fakeNode{workspaces: []string{"topp"}}
+	c := &fakeNode{workspaces: []string{"topp"}}
+	cl, _ := newFakeCluster(t, a, b, c)
+	ctx := context.Background()
+
+	for range 3 {
+		ws, err := cl.Workspaces.Get(ctx, "topp")
+		if err != nil || ws.Name != "topp" {
+			t.Fatalf("Get = %+v, %v", ws, err)
+		}
+	}
+	if got := b.hitCount("GET /rest/workspaces/topp"); got != 3 {
+		t.Fatalf("node b reads = %d, want 3", got)
+	}
+	if got := b.hitCount("GET /rest/about"); got != 1 {
+		t.Fatalf("node b pings = %d, want 1 (cached)", got)
+	}
+	if got := c.hitCount("GET"); got != 0 {
+		t.Fatalf("node c hits = %d, want 0", got)
+	}
+}
+
+func TestCluster_ReadFailsOver(t *testing.T) {
+	a := &fakeNode{workspaces: []string{"topp"}}
+	b := &fakeNode{workspaces: []string{"topp"}}
+	cl, _ := newFakeCluster(t, a, b)
+	ctx := context.Background()
+
+	if _, err := cl.Workspaces.List(ctx, workspaces.ListOptions{}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	a.mu.Lock()
+	a.down = true
+	a.mu.Unlock()
+	got, err := cl.Workspaces.List(ctx, workspaces.ListOptions{})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("List after failover = %+v, %v", got, err)
+	}
+	if b.hitCount("GET /rest/workspaces") != 1 {
+		t.Fatal("read did not fail over to node b")
+	}
+}
+
+func TestCluster_ReadNotFoundDoesNotFailOver(t *testing.T) {
+	a, b := &fakeNode{}, &fakeNode{workspaces: []string{"topp"}}
+	cl, _ := newFakeCluster(t, a, b)
+	_, err := cl.Workspaces.Get(context.Background(), "topp")
+	if !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound from the first node", err)
+	}
+	if b.hitCount("GET") != 0 {
+		t.Fatal("404 must not fail over")
+	}
+}
+
+func TestCluster_NoHealthyNode(t *testing.T) {
+	cl, _ := newFakeCluster(t, &fakeNode{down: true}, &fakeNode{down: true})
+	_, err := cl.Styles.List(context.Background(), styles.ListOptions{})
+	if !errors.Is(err, geoserver.ErrNoHealthyNode) {
+		t.Fatalf("err = %v, want ErrNoHealthyNode", err)
+	}
+	if !errors.Is(err, geoserver.ErrServiceUnavailable) {
+		t.Fatalf("err = %v, want the joined ping failures", err)
+	}
+}
+
+func TestCluster_Verify(t *testing.T) {
+	a := &fakeNode{
+		workspaces: []string{"topp", "nurc"},
+		datastores: map[string][]string{"topp": {"states_pg"}},
+		layers:     map[string][]string{"topp": {"states"}},
+		styles:     []string{"point", "line"},
+	}
+	b := &fakeNode{
+		workspaces: []string{"topp"},
+		datastores: map[string][]string{"topp": {"states_pg"}},
+		layers:     map[string][]string{"topp": {"states", "roads"}},
+		styles:     []string{"point", "line"},
+	}
+	cl, urls := newFakeCluster(t, a, b)
+
+	report, err := cl.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if report.Converged() {
+		t.Fatal("Converged() = true, want false")
+	}
+	want := []string{
+		"workspace nurc missing on " + urls[1],
+		"layer topp:roads missing on " + urls[0],
+	}
+	var got []string
+	for _, d := range report.Drift {
+		got = append(got, d.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("drift =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("Errors = %+v", report.Errors)
+	}
+}
+
+func TestCluster_VerifyNodeError(t *testing.T) {
+	a := &fakeNode{workspaces: []string{"topp"}}
+	b := &fakeNode{down: true}
+	cl, urls := newFakeCluster(t, a, b)
+
+	report, err := cl.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(report.Drift) != 0 {
+		t.Fatalf("Drift = %+v, want none (failed nodes are excluded)", report.Drift)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Node != urls[1] || report.Converged() {
+		t.Fatalf("Errors = %+v", report.Errors)
+	}
+}
+
+func TestCluster_VerifyContentDrift(t *testing.T) {
+	ctx := context.Background()
+	var nodes []*geoserver.Client
+	for i := range 2 {
+		var opts []geoserver.Option
+		if i == 1 {
+			opts = append(opts, geoserver.WithTransport(failPath{fail: "/layers/roads", method: http.MethodPut, next: http.DefaultTransport}))
+		}
+		nodes = append(nodes, geoservertest.Seed(t, geoservertest.Fixture{"topp": {Datastores: map[string][]string{"pg": {"roads"}}}}, opts...))
+	}
+	cl, err := geoserver.NewCluster(nodes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := cl.Verify(ctx)
+	if err != nil || !report.Converged() {
+		t.Fatalf("before update: %+v, %v", report, err)
+	}
+	res := cl.Layers.InWorkspace("topp").Update(ctx, "roads", &layers.Layer{DefaultStyle: &layers.Ref{Name: "line"}})
+	if len(res.Failed()) != 1 {
+		t.Fatalf("update = %+v, want one failed node", res)
+	}
+
+	report, err = cl.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "layer topp:roads differs on " + report.Nodes[1] + " (defaultStyle.name)"
+	if len(report.Drift) != 1 || report.Drift[0].String() != want || report.Converged() {
+		t.Fatalf("drift = %v, want %s", report.Drift, want)
+	}
+}
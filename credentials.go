@@ -0,0 +1,236 @@
+package geoserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials is one resolved set of request credentials. When Token
+// is non-empty it is sent as a bearer token; otherwise Username and
+// Password are sent as HTTP basic auth.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+
+	// ExpiresAt, if non-zero, is when the client should stop reusing
+	// these credentials and ask the provider again. Zero means reuse
+	// until the server answers 401.
+	ExpiresAt time.Time
+}
+
+// CredentialsProvider supplies credentials for outgoing requests. See
+// [WithCredentialsProvider] for how results are cached. Implementations
+// must be safe for concurrent use.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsProviderFunc adapts a plain function to
+// [CredentialsProvider].
+type CredentialsProviderFunc func(ctx context.Context) (Credentials, error)
+
+// Credentials implements [CredentialsProvider].
+func (f CredentialsProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// WithCredentialsProvider resolves credentials per request from p
+// instead of a static username/password or token. The client caches
+// the last result until its [Credentials.ExpiresAt] passes or the
+// server answers 401 [ErrUnauthorized]; on a 401 the cache is dropped
+// and the request is replayed once with fresh credentials.
+//
+// Mutually exclusive with [WithBasicAuth] / [WithBearerToken]; later
+// option wins.
+func WithCredentialsProvider(p CredentialsProvider) Option {
+	return func(cfg *clientConfig) error {
+		if p == nil {
+			return errors.New("geoserver: WithCredentialsProvider: provider is nil")
+		}
+		cfg.auth = authCredentials{kind: authProvider, provider: p}
+		return nil
+	}
+}
+
+// credentialCache adapts a [CredentialsProvider] to the transport
+// layer's CredentialSource. Fetches are serialized by mu so a burst of
+// requests after an invalidation triggers a single provider call, and
+// gen numbers each fetch so that concurrent 401s against the same
+// credentials invalidate them once.
+type credentialCache struct {
+	provider CredentialsProvider
+
+	mu     sync.Mutex
+	cached *Credentials
+	gen    uint64
+}
+
+// Apply implements transport.CredentialSource.
+func (c *credentialCache) Apply(req *http.Request) (uint64, error) {
+	creds, gen, err := c.get(req.Context())
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case creds.Token != "":
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	case creds.Username != "":
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+	return gen, nil
+}
+
+// Invalidate implements transport.CredentialSource.
+func (c *credentialCache) Invalidate(gen uint64) {
+	c.mu.Lock()
+	if gen == c.gen {
+		c.cached = nil
+	}
+	c.mu.Unlock()
+}
+
+func (c *credentialCache) get(ctx context.Context) (Credentials, uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached != nil && (c.cached.ExpiresAt.IsZero() || time.Now().Before(c.cached.ExpiresAt)) {
+		return *c.cached, c.gen, nil
+	}
+	creds, err := c.provider.Credentials(ctx)
+	if err != nil {
+		return Credentials{}, 0, err
+	}
+	c.cached = &creds
+	c.gen++
+	return creds, c.gen, nil
+}
+
+// EnvCredentials reads credentials from environment variables each
+// time the client asks — after a 401, or on first use. If TokenVar
+// names a non-empty variable its value is used as a bearer token;
+// otherwise UsernameVar / PasswordVar supply basic auth.
+type EnvCredentials struct {
+	UsernameVar string
+	PasswordVar string
+	TokenVar    string
+}
+
+// Credentials implements [CredentialsProvider].
+func (e EnvCredentials) Credentials(context.Context) (Credentials, error) {
+	if e.TokenVar != "" {
+		if tok := os.Getenv(e.TokenVar); tok != "" {
+			return Credentials{Token: tok}, nil
+		}
+	}
+	if e.UsernameVar != "" {
+		if user := os.Getenv(e.UsernameVar); user != "" {
+			return Credentials{Username: user, Password: os.Getenv(e.PasswordVar)}, nil
+		}
+	}
+	vars := nonEmpty(e.TokenVar, e.UsernameVar)
+	if len(vars) == 0 {
+		return Credentials{}, errors.New("geoserver: EnvCredentials: no variable names configured; set TokenVar or UsernameVar")
+	}
+	return Credentials{}, fmt.Errorf("geoserver: EnvCredentials: none of %s set", strings.Join(vars, ", "))
+}
+
+// defaultFileRefresh is how long the client reuses credentials read by
+// [FileCredentials] before checking the file again.
+const defaultFileRefresh = 30 * time.Second
+
+// FileCredentials reads credentials from files on disk, re-reading
+// them whenever a file's modification time or size changes — suited
+// to a Kubernetes-mounted secret, which the kubelet rotates in place.
+//
+// Set TokenFile for bearer auth, or UsernameFile / PasswordFile for
+// basic auth; TokenFile wins when both are set. Surrounding whitespace
+// (the trailing newline most secret tooling writes) is trimmed.
+//
+// The client re-checks the files every RefreshInterval (default 30s)
+// and immediately after a 401.
+type FileCredentials struct {
+	TokenFile       string
+	UsernameFile    string
+	PasswordFile    string
+	RefreshInterval time.Duration
+
+	mu    sync.Mutex
+	files map[string]fileSnapshot
+}
+
+type fileSnapshot struct {
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+// Credentials implements [CredentialsProvider].
+func (f *FileCredentials) Credentials(context.Context) (Credentials, error) {
+	refresh := f.RefreshInterval
+	if refresh <= 0 {
+		refresh = defaultFileRefresh
+	}
+	expires := time.Now().Add(refresh)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.TokenFile != "" {
+		tok, err := f.read(f.TokenFile)
+		if err != nil {
+			return Credentials{}, err
+		}
+		return Credentials{Token: tok, ExpiresAt: expires}, nil
+	}
+	if f.UsernameFile == "" {
+		return Credentials{}, errors.New("geoserver: FileCredentials: no TokenFile or UsernameFile set")
+	}
+	user, err := f.read(f.UsernameFile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	var pass string
+	if f.PasswordFile != "" {
+		if pass, err = f.read(f.PasswordFile); err != nil {
+			return Credentials{}, err
+		}
+	}
+	return Credentials{Username: user, Password: pass, ExpiresAt: expires}, nil
+}
+
+// read returns the trimmed contents of path, re-reading only when the
+// file changed since the last call. Callers hold f.mu.
+func (f *FileCredentials) read(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("geoserver: FileCredentials: %w", err)
+	}
+	if snap, ok := f.files[path]; ok && snap.modTime.Equal(info.ModTime()) && snap.size == info.Size() {
+		return snap.value, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("geoserver: FileCredentials: %w", err)
+	}
+	value := strings.TrimSpace(string(raw))
+	if f.files == nil {
+		f.files = map[string]fileSnapshot{}
+	}
+	f.files[path] = fileSnapshot{modTime: info.ModTime(), size: info.Size(), value: value}
+	return value, nil
+}
+
+func nonEmpty(ss ...string) []string {
+	out := make([]string, 0, len(ss))
+	for _, s := range ss {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
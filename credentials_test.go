@@ -0,0 +1,232 @@
+package geoserver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+)
+
+// tokenServer accepts only "Bearer <want>" and answers 401 otherwise.
+func tokenServer(t *testing.T, want *atomic.Value) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+want.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWithCredentialsProvider_CachesAndRefreshesOn401(t *testing.T) {
+	var current atomic.Value
+	current.Store("t1")
+	srv := tokenServer(t, &current)
+
+	var fetches atomic.Int32
+	provider := geoserver.CredentialsProviderFunc(func(context.Context) (geoserver.Credentials, error) {
+		fetches.Add(1)
+		return geoserver.Credentials{Token: current.Load().(string)}, nil
+	})
+	c, err := geoserver.New(srv.URL, geoserver.WithCredentialsProvider(provider))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	for range 3 {
+		if err := c.About.Ping(ctx); err != nil {
+			t.Fatalf("Ping: %v", err)
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("provider called %d times, want 1 (cached)", got)
+	}
+
+	current.Store("t2") // rotate server-side; cache is now stale
+	if err := c.About.Ping(ctx); err != nil {
+		t.Fatalf("Ping after rotation: %v", err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("provider called %d times, want 2 (refresh on 401)", got)
+	}
+}
+
+func TestWithCredentialsProvider_CoalescesConcurrent401s(t *testing.T) {
+	const n = 8
+	var stale sync.WaitGroup
+	stale.Add(n)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t2" {
+			// Hold every stale request until all n have arrived, so
+			// their 401s land together.
+			stale.Done()
+			stale.Wait()
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	var fetches atomic.Int32
+	provider := geoserver.CredentialsProviderFunc(func(context.Context) (geoserver.Credentials, error) {
+		if fetches.Add(1) == 1 {
+			return geoserver.Credentials{Token: "t1"}, nil
+		}
+		return geoserver.Credentials{Token: "t2"}, nil
+	})
+	c, err := geoserver.New(srv.URL, geoserver.WithCredentialsProvider(provider))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for range n {
+		wg.Go(func() { errs <- c.About.Ping(context.Background()) })
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Ping: %v", err)
+		}
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("provider called %d times, want 2 (one refresh for all 401s)", got)
+	}
+}
+
+func TestWithCredentialsProvider_PersistentUnauthorized(t *testing.T) {
+	var current atomic.Value
+	current.Store("right")
+	srv := tokenServer(t, &current)
+
+	var fetches atomic.Int32
+	provider := geoserver.CredentialsProviderFunc(func(context.Context) (geoserver.Credentials, error) {
+		fetches.Add(1)
+		return geoserver.Credentials{Token: "wrong"}, nil
+	})
+	c, err := geoserver.New(srv.URL, geoserver.WithCredentialsProvider(provider))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	err = c.About.Ping(context.Background())
+	if !errors.Is(err, geoserver.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("provider called %d times, want 2 (one retry only)", got)
+	}
+}
+
+func TestWithCredentialsProvider_ProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("request should not reach the server")
+	}))
+	t.Cleanup(srv.Close)
+
+	boom := errors.New("vault sealed")
+	c, err := geoserver.New(srv.URL, geoserver.WithCredentialsProvider(
+		geoserver.CredentialsProviderFunc(func(context.Context) (geoserver.Credentials, error) {
+			return geoserver.Credentials{}, boom
+		})))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.About.Ping(context.Background()); !errors.Is(err, boom) {
+		t.Fatalf("expected provider error, got %v", err)
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("GS_TEST_USER", "admin")
+	t.Setenv("GS_TEST_PASS", "geoserver")
+	t.Setenv("GS_TEST_TOKEN", "")
+
+	p := geoserver.EnvCredentials{UsernameVar: "GS_TEST_USER", PasswordVar: "GS_TEST_PASS", TokenVar: "GS_TEST_TOKEN"}
+	creds, err := p.Credentials(context.Background())
+	if err != nil || creds.Username != "admin" || creds.Password != "geoserver" || creds.Token != "" {
+		t.Fatalf("creds=%+v err=%v", creds, err)
+	}
+
+	t.Setenv("GS_TEST_TOKEN", "tok")
+	creds, err = p.Credentials(context.Background())
+	if err != nil || creds.Token != "tok" {
+		t.Fatalf("creds=%+v err=%v", creds, err)
+	}
+
+	t.Setenv("GS_TEST_USER", "")
+	t.Setenv("GS_TEST_TOKEN", "")
+	if _, err := p.Credentials(context.Background()); err == nil {
+		t.Fatal("expected error when no variable is set")
+	}
+	if _, err := (geoserver.EnvCredentials{}).Credentials(context.Background()); err == nil || !strings.Contains(err.Error(), "TokenVar or UsernameVar") {
+		t.Fatalf("unconfigured EnvCredentials error = %v", err)
+	}
+}
+
+func TestFileCredentials_ReReadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("t1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var current atomic.Value
+	current.Store("t1")
+	srv := tokenServer(t, &current)
+
+	provider := &geoserver.FileCredentials{TokenFile: path, RefreshInterval: time.Hour}
+	c, err := geoserver.New(srv.URL, geoserver.WithCredentialsProvider(provider))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if err := c.About.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	// Simulate a secret rotation: new contents, new mtime.
+	if err := os.WriteFile(path, []byte("t2-rotated\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	current.Store("t2-rotated")
+
+	// RefreshInterval is an hour, so only the 401 forces a re-check.
+	if err := c.About.Ping(ctx); err != nil {
+		t.Fatalf("Ping after rotation: %v", err)
+	}
+}
+
+func TestFileCredentials_BasicAuth(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "username")
+	passFile := filepath.Join(dir, "password")
+	_ = os.WriteFile(userFile, []byte("admin"), 0o600)
+	_ = os.WriteFile(passFile, []byte("geoserver\n"), 0o600)
+
+	p := &geoserver.FileCredentials{UsernameFile: userFile, PasswordFile: passFile}
+	creds, err := p.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Credentials: %v", err)
+	}
+	if creds.Username != "admin" || creds.Password != "geoserver" || creds.ExpiresAt.IsZero() {
+		t.Fatalf("creds = %+v", creds)
+	}
+}
@@ -0,0 +1,267 @@
+package declarative_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/declarative"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/services"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+const sld = `<StyledLayerDescriptor version="1.0.0">
+  <NamedLayer><Name>roads</Name></NamedLayer>
+</StyledLayerDescriptor>`
+
+func manifest() *declarative.Manifest {
+	disabled, queryable := false, true
+	return &declarative.Manifest{
+		Workspaces: []declarative.Workspace{{
+			Name: "topp",
+			URI:  "http://topp.example.org",
+			Datastores: []declarative.Datastore{{
+				Name: "pg",
+				ConnectionParameters: map[string]string{
+					"dbtype": "postgis", "host": "db", "database": "gis",
+				},
+				FeatureTypes: []featuretypes.FeatureType{{Name: "roads", Title: "Roads"}},
+			}, {
+				Name:                 "archive",
+				ConnectionParameters: map[string]string{"dbtype": "postgis", "host": "old"},
+				Enabled:              &disabled,
+			}},
+			Styles: []declarative.Style{{Name: "roads", Body: sld}},
+			Layers: []declarative.Layer{{Name: "roads", DefaultStyle: "roads", Queryable: &queryable}},
+			LayerGroups: []declarative.LayerGroup{{
+				Name: "base", Title: "Base map", Layers: []string{"roads"},
+			}},
+			Services: &declarative.Services{WMS: &services.WMSSettings{ServiceInfo: services.ServiceInfo{Title: "Topp WMS"}}},
+		}},
+		ACL: &declarative.ACL{Layers: map[string]string{"topp.*.r": "ROLE_READER"}},
+	}
+}
+
+func apply(t *testing.T, c *geoserver.Client, m *declarative.Manifest, opts declarative.Options) *declarative.Plan {
+	t.Helper()
+	var out bytes.Buffer
+	opts.Out = &out
+	plan, err := declarative.Apply(context.Background(), c, m, opts)
+	if err != nil {
+		t.Fatalf("Apply: %v\n%s", err, out.String())
+	}
+	return plan
+}
+
+func lines(p *declarative.Plan) []string {
+	var out []string
+	for _, ch := range p.Changes {
+		out = append(out, ch.String())
+	}
+	return out
+}
+
+func TestApply_CreateThenConverged(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+
+	plan := apply(t, c, manifest(), declarative.Options{})
+	want := []string{
+		"+ workspace topp",
+		"+ servicesettings topp:wms",
+		"+ style topp:roads",
+		"+ datastore topp:pg",
+		"+ datastore topp:archive",
+		"+ featuretype topp:pg:roads",
+		"~ layer topp:roads (defaultStyle, queryable)",
+		"+ layergroup topp:base",
+		"+ aclrule layers topp.*.r",
+	}
+	if got := lines(plan); !slices.Equal(got, want) {
+		t.Fatalf("plan =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	ds, err := c.Datastores.InWorkspace("topp").Get(ctx, "archive")
+	if err != nil || ds.Enabled {
+		t.Fatalf("archive = %+v, %v; want disabled", ds, err)
+	}
+	l, err := c.Layers.InWorkspace("topp").Get(ctx, "roads")
+	if err != nil || l.DefaultStyle == nil || l.DefaultStyle.Name != "topp:roads" {
+		t.Fatalf("layer = %+v, %v", l, err)
+	}
+
+	again, err := declarative.NewPlan(ctx, c, manifest(), declarative.Options{})
+	if err != nil {
+		t.Fatalf("NewPlan: %v", err)
+	}
+	if !again.Empty() {
+		t.Fatalf("second plan not empty:\n%s", again)
+	}
+}
+
+func TestNewPlan_Updates(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+	apply(t, c, manifest(), declarative.Options{})
+
+	m := manifest()
+	ws := &m.Workspaces[0]
+	ws.Datastores[0].ConnectionParameters["host"] = "db2"
+	ws.Datastores[0].FeatureTypes[0].Title = "Main roads"
+	ws.Styles[0].Body = strings.Replace(sld, "roads", "streets", 1)
+	m.ACL.Layers["topp.*.r"] = "ROLE_READER,ROLE_EDITOR"
+	notQueryable := false
+	ws.Layers[0].Queryable = &notQueryable
+
+	plan := apply(t, c, m, declarative.Options{})
+	want := []string{
+		"~ style topp:roads (body)",
+		"~ datastore topp:pg (connectionParameters.host)",
+		"~ featuretype topp:pg:roads (title)",
+		"~ layer topp:roads (queryable)",
+		"~ aclrule layers topp.*.r (roles)",
+	}
+	if got := lines(plan); !slices.Equal(got, want) {
+		t.Fatalf("plan =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	ds, err := c.Datastores.InWorkspace("topp").Get(ctx, "pg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{}
+	for _, e := range ds.ConnectionParameters.Entry {
+		params[e.Key] = e.Value
+	}
+	if params["host"] != "db2" || params["database"] != "gis" {
+		t.Fatalf("params = %v, want host updated and database kept", params)
+	}
+	if l, err := c.Layers.InWorkspace("topp").Get(ctx, "roads"); err != nil || l.Queryable || l.DefaultStyle.Name != "topp:roads" {
+		t.Fatalf("layer = %+v, %v; want non-queryable with its style kept", l, err)
+	}
+	if again, err := declarative.NewPlan(ctx, c, m, declarative.Options{}); err != nil || !again.Empty() {
+		t.Fatalf("plan after update = %v, %v", again, err)
+	}
+}
+
+func TestNewPlan_Prune(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+	apply(t, c, manifest(), declarative.Options{})
+	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "scratch"}); err != nil {
+		t.Fatal(err)
+	}
+	tmp := datastores.PostGIS{Name: "tmp", Host: "db", Database: "gis"}
+	if err := c.Datastores.InWorkspace("topp").Create(ctx, tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ACL.Layers().Add(ctx, acl.Rule{Workspace: "scratch", Layer: "*", Operation: acl.OpRead, Roles: []string{"*"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := manifest()
+	if plan, err := declarative.NewPlan(ctx, c, m, declarative.Options{}); err != nil || !plan.Empty() {
+		t.Fatalf("plan without Prune = %v, %v", plan, err)
+	}
+	plan := apply(t, c, m, declarative.Options{Prune: true})
+	want := []string{
+		"- aclrule layers *.*.r",
+		"- aclrule layers *.*.w",
+		"- aclrule layers scratch.*.r",
+		"- datastore topp:tmp",
+		"- workspace scratch",
+	}
+	if got := lines(plan); !slices.Equal(got, want) {
+		t.Fatalf("plan =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	list, err := c.Workspaces.List(ctx, workspaces.ListOptions{})
+	if err != nil || len(list) != 1 || list[0].Name != "topp" {
+		t.Fatalf("workspaces = %+v, %v", list, err)
+	}
+}
+
+func TestApply_DryRun(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	var out bytes.Buffer
+	plan, err := declarative.Apply(context.Background(), c, manifest(), declarative.Options{DryRun: true, Out: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "+ workspace topp\n") ||
+		!strings.HasSuffix(out.String(), "Plan: 8 to create, 1 to update, 0 to delete.\n") {
+		t.Fatalf("output =\n%s", out.String())
+	}
+	if plan.Empty() {
+		t.Fatal("plan empty")
+	}
+	for _, r := range srv.Requests() {
+		if r.Method != "GET" {
+			t.Fatalf("dry run sent %s %s", r.Method, r.Path)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	if _, err := declarative.Decode(strings.NewReader(`{"workspaces":[{"name":"a","colour":"red"}]}`)); err == nil {
+		t.Fatal("unknown field accepted")
+	}
+	_, err := declarative.Decode(strings.NewReader(`{"workspaces":[{"name":"a"},{"name":"a"},
+		{"name":"b","layerGroups":[{"name":"g","layers":[]}]}]}`))
+	if err == nil || !strings.Contains(err.Error(), `workspace "a": duplicate`) ||
+		!strings.Contains(err.Error(), `layer group "g": no layers`) {
+		t.Fatalf("Decode = %v", err)
+	}
+}
+
+func TestDecodeWith(t *testing.T) {
+	// unmarshal stands in for a YAML library: it yields the generic
+	// document a YAML manifest decodes to.
+	unmarshal := func(doc map[string]any) func([]byte, any) error {
+		return func(_ []byte, v any) error {
+			*v.(*any) = doc
+			return nil
+		}
+	}
+	m, err := declarative.DecodeWith(strings.NewReader("workspaces: …"), unmarshal(map[string]any{
+		"workspaces": []any{map[string]any{"name": "topp", "uri": "http://topp.example.org"}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Workspaces[0].Name != "topp" || m.Workspaces[0].URI != "http://topp.example.org" {
+		t.Fatalf("manifest = %+v", m.Workspaces[0])
+	}
+	if _, err := declarative.DecodeWith(strings.NewReader(""), unmarshal(map[string]any{"workspace": []any{}})); err == nil {
+		t.Fatal("unknown field accepted")
+	}
+}
+
+func TestLoad_SLDFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "roads.sld"), []byte(sld), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "geoserver.json")
+	if err := os.WriteFile(path, []byte(`{"styles":[{"name":"roads","sldFile":"roads.sld"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := declarative.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Styles[0].Body != sld {
+		t.Fatalf("Body = %q", m.Styles[0].Body)
+	}
+}
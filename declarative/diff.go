@@ -0,0 +1,53 @@
+package declarative
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
+)
+
+// diffFields returns the field paths set in desired that differ in
+// live; see [wire.Diff].
+func diffFields(desired, live any) ([]string, error) {
+	fields, err := wire.Diff(desired, live)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: %w", err)
+	}
+	return fields, nil
+}
+
+func toJSONValue(v any) (any, error) {
+	out, err := wire.JSONValue(v)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: %w", err)
+	}
+	return out, nil
+}
+
+var interTagSpace = regexp.MustCompile(`>\s+<`)
+
+// sameBody reports whether two style bodies are equal ignoring
+// surrounding and inter-tag whitespace.
+func sameBody(a, b string) bool {
+	norm := func(s string) string { return interTagSpace.ReplaceAllString(strings.TrimSpace(s), "><") }
+	return norm(a) == norm(b)
+}
+
+// sameRoles reports whether two comma-separated role lists hold the
+// same roles, in any order.
+func sameRoles(a, b string) bool {
+	split := func(s string) []string {
+		var out []string
+		for r := range strings.SplitSeq(s, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				out = append(out, r)
+			}
+		}
+		slices.Sort(out)
+		return out
+	}
+	return slices.Equal(split(a), split(b))
+}
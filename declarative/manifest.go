@@ -0,0 +1,382 @@
+// Package declarative reconciles a GeoServer catalog with a
+// desired-state [Manifest], the way infrastructure-as-code tools do:
+// compute a [Plan] of creates, updates and deletes against the live
+// server, review it, then apply it.
+//
+//	m, err := declarative.Load("geoserver.json")
+//	plan, err := declarative.NewPlan(ctx, c, m, declarative.Options{Prune: true})
+//	fmt.Print(plan)          // dry run
+//	err = plan.Apply(ctx)
+//
+// A manifest describes workspaces (with their namespace URI),
+// datastores, feature types, coverage stores, coverages, styles with
+// their SLD bodies, layer publishing settings, layer groups, ACL rules
+// and OWS service settings. Manifests are JSON; [LoadWith] and
+// [DecodeWith] take another format's unmarshal function, so a YAML
+// manifest needs only a YAML library on the caller's side:
+//
+//	m, err := declarative.LoadWith("geoserver.yaml", yaml.Unmarshal)
+//
+// Comparison is semantic: only fields set in the manifest are
+// compared, so server-populated fields (hrefs, bounding boxes,
+// attribute lists, dates) never show up as drift. Encrypted
+// ("crypt1:…") connection parameters cannot be compared and are
+// skipped. Style bodies are compared for SLD styles only.
+//
+// Changes are applied in dependency order — workspaces, service
+// settings, styles, stores, resources, layers, layer groups, ACL
+// rules — and deletes after that in reverse order. Without
+// [Options.Prune], objects missing from the manifest are left alone.
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/services"
+)
+
+// Manifest is the desired state of a GeoServer catalog.
+type Manifest struct {
+	Workspaces []Workspace `json:"workspaces,omitempty"`
+
+	// Styles are global styles.
+	Styles []Style `json:"styles,omitempty"`
+
+	// ACL, when set, manages access-control rules. Nil leaves all
+	// rules alone.
+	ACL *ACL `json:"acl,omitempty"`
+
+	// Services holds the global OWS service settings.
+	Services *Services `json:"services,omitempty"`
+}
+
+// Workspace is a workspace together with everything published in it.
+type Workspace struct {
+	Name string `json:"name"`
+
+	// URI is the namespace URI. Empty means GeoServer's default for a
+	// new workspace and is not compared afterwards.
+	URI      string `json:"uri,omitempty"`
+	Isolated bool   `json:"isolated,omitempty"`
+
+	Datastores     []Datastore     `json:"datastores,omitempty"`
+	CoverageStores []CoverageStore `json:"coverageStores,omitempty"`
+	Styles         []Style         `json:"styles,omitempty"`
+	Layers         []Layer         `json:"layers,omitempty"`
+	LayerGroups    []LayerGroup    `json:"layerGroups,omitempty"`
+
+	// Services holds per-workspace service setting overrides.
+	Services *Services `json:"services,omitempty"`
+}
+
+// Datastore is a vector store and the feature types published from
+// it. Set either Connector (from Go) or ConnectionParameters (from
+// JSON); Connector wins if both are set.
+type Datastore struct {
+	Name string `json:"name"`
+
+	Connector            datastores.Connector `json:"-"`
+	ConnectionParameters map[string]string    `json:"connectionParameters,omitempty"`
+
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	FeatureTypes []featuretypes.FeatureType `json:"featureTypes,omitempty"`
+}
+
+// CoverageStore is a raster store and the coverages published from it.
+type CoverageStore struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	Coverages []coverages.Coverage `json:"coverages,omitempty"`
+}
+
+// Style is a style and its body.
+type Style struct {
+	Name string `json:"name"`
+
+	// Format is "sld" (default), "css", "ysld" or "mbstyle".
+	Format string `json:"format,omitempty"`
+
+	// LanguageVersion is the SLD version, "1.0.0" (default) or
+	// "1.1.0" for Symbology Encoding.
+	LanguageVersion string `json:"languageVersion,omitempty"`
+
+	// Body is the style document. SLDFile names a file to read it
+	// from instead, relative to the manifest; [Load] fills Body.
+	Body    string `json:"body,omitempty"`
+	SLDFile string `json:"sldFile,omitempty"`
+}
+
+// Layer is the publishing settings of the layer GeoServer creates for
+// a feature type or coverage of the same name.
+type Layer struct {
+	Name string `json:"name"`
+
+	// DefaultStyle and Styles name styles in the layer's workspace
+	// or global styles; "ws:name" addresses another workspace.
+	DefaultStyle string   `json:"defaultStyle,omitempty"`
+	Styles       []string `json:"styles,omitempty"`
+
+	// Queryable and Opaque are left as they are when unset.
+	Queryable   *bool               `json:"queryable,omitempty"`
+	Opaque      *bool               `json:"opaque,omitempty"`
+	Attribution *layers.Attribution `json:"attribution,omitempty"`
+}
+
+// LayerGroup is a layer group in the enclosing workspace.
+type LayerGroup struct {
+	Name  string `json:"name"`
+	Title string `json:"title,omitempty"`
+
+	// Mode defaults to "SINGLE".
+	Mode string `json:"mode,omitempty"`
+
+	// Layers are the members, bottom first: layer or layer group
+	// names in the workspace, or "ws:name".
+	Layers []string `json:"layers"`
+
+	// Styles, if set, has one entry per member; "" keeps the
+	// member's default style.
+	Styles []string `json:"styles,omitempty"`
+}
+
+// ACL holds access-control rules in GeoServer's own wire form: rule
+// string to comma-separated roles ("*" for any role). A nil map leaves
+// that rule set alone; otherwise rules missing from the map are
+// deleted under [Options.Prune].
+//
+//	"layers":   {"topp.*.r": "ROLE_READER"}
+//	"services": {"wfs.Transaction": "ROLE_EDITOR"}
+//	"rest":     {"/rest/**:GET": "ADMIN"}
+type ACL struct {
+	Layers   map[string]string `json:"layers,omitempty"`
+	Services map[string]string `json:"services,omitempty"`
+	REST     map[string]string `json:"rest,omitempty"`
+}
+
+// Services holds OWS service settings. Only fields that are set are
+// compared and written.
+type Services struct {
+	WMS  *services.WMSSettings  `json:"wms,omitempty"`
+	WFS  *services.WFSSettings  `json:"wfs,omitempty"`
+	WCS  *services.WCSSettings  `json:"wcs,omitempty"`
+	WMTS *services.WMTSSettings `json:"wmts,omitempty"`
+}
+
+// Load reads a JSON manifest from path. Style SLDFile paths are
+// resolved relative to the manifest's directory.
+func Load(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	m, err := decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: %s: %w", path, err)
+	}
+	if err := m.readStyleFiles(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Decode reads a JSON manifest from r and validates it. Unknown fields
+// are rejected so typos don't silently drop configuration. SLDFile
+// paths are not resolved; use [Load] for that.
+func Decode(r io.Reader) (*Manifest, error) {
+	m, err := decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: %w", err)
+	}
+	return m, nil
+}
+
+// LoadWith is [Load] for a manifest in another format: unmarshal (for
+// example yaml.Unmarshal) decodes the file into an any, whose keys are
+// the manifest's JSON field names. Unknown fields are rejected as by
+// [Load]. unmarshal must produce JSON-compatible values — maps keyed
+// by string, slices, strings, numbers, booleans and nil.
+func LoadWith(path string, unmarshal func([]byte, any) error) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: %w", err)
+	}
+	m, err := decodeWith(data, unmarshal)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: %s: %w", path, err)
+	}
+	if err := m.readStyleFiles(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DecodeWith is [Decode] for a manifest in another format; see
+// [LoadWith].
+func DecodeWith(r io.Reader, unmarshal func([]byte, any) error) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: %w", err)
+	}
+	m, err := decodeWith(data, unmarshal)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: %w", err)
+	}
+	return m, nil
+}
+
+// decodeWith unmarshals data into a generic document and re-encodes
+// it as JSON, so the strict JSON decoder checks field names and types.
+func decodeWith(data []byte, unmarshal func([]byte, any) error) (*Manifest, error) {
+	var doc any
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	return decode(bytes.NewReader(js))
+}
+
+func decode(r io.Reader) (*Manifest, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (m *Manifest) readStyleFiles(dir string) error {
+	read := func(st *Style) error {
+		if st.SLDFile == "" || st.Body != "" {
+			return nil
+		}
+		path := st.SLDFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("declarative: style %s: %w", st.Name, err)
+		}
+		st.Body = string(body)
+		return nil
+	}
+	for i := range m.Styles {
+		if err := read(&m.Styles[i]); err != nil {
+			return err
+		}
+	}
+	for i := range m.Workspaces {
+		for j := range m.Workspaces[i].Styles {
+			if err := read(&m.Workspaces[i].Styles[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the manifest for missing and duplicate names and
+// malformed layer groups. References between objects are checked by
+// GeoServer when the plan is applied, since they may point at objects
+// the manifest doesn't manage.
+func (m *Manifest) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+	unique := func(kind, scope string, names []string) {
+		seen := map[string]bool{}
+		for _, n := range names {
+			switch {
+			case n == "":
+				fail("%s%s: empty name", scope, kind)
+			case seen[n]:
+				fail("%s%s %q: duplicate", scope, kind, n)
+			}
+			seen[n] = true
+		}
+	}
+	unique("workspace", "", names(m.Workspaces, func(w Workspace) string { return w.Name }))
+	unique("style", "", names(m.Styles, func(s Style) string { return s.Name }))
+	for _, ws := range m.Workspaces {
+		scope := "workspace " + ws.Name + ": "
+		var stores, resources []string
+		for _, ds := range ws.Datastores {
+			stores = append(stores, ds.Name)
+			for _, ft := range ds.FeatureTypes {
+				resources = append(resources, ft.Name)
+			}
+		}
+		for _, cs := range ws.CoverageStores {
+			stores = append(stores, cs.Name)
+			for _, cov := range cs.Coverages {
+				resources = append(resources, cov.Name)
+			}
+		}
+		unique("store", scope, stores)
+		unique("resource", scope, resources)
+		unique("style", scope, names(ws.Styles, func(s Style) string { return s.Name }))
+		unique("layer", scope, names(ws.Layers, func(l Layer) string { return l.Name }))
+		groups := names(ws.LayerGroups, func(g LayerGroup) string { return g.Name })
+		unique("layer group", scope, groups)
+		for _, g := range ws.LayerGroups {
+			if len(g.Layers) == 0 {
+				fail("%slayer group %q: no layers", scope, g.Name)
+			}
+			if len(g.Styles) > 0 && len(g.Styles) != len(g.Layers) {
+				fail("%slayer group %q: %d styles for %d layers", scope, g.Name, len(g.Styles), len(g.Layers))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func names[T any](items []T, name func(T) string) []string {
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = name(it)
+	}
+	return out
+}
+
+// datastore returns the payload to create or compare the store with.
+func (ds Datastore) datastore() datastores.Datastore {
+	if ds.Connector != nil {
+		out := ds.Connector.Datastore()
+		out.Name = ds.Name
+		return out
+	}
+	out := datastores.Datastore{Name: ds.Name}
+	for _, k := range slices.Sorted(maps.Keys(ds.ConnectionParameters)) {
+		out.ConnectionParameters.Entry = append(out.ConnectionParameters.Entry, datastores.ConnectionEntry{Key: k, Value: ds.ConnectionParameters[k]})
+	}
+	return out
+}
+
+func enabled(b *bool) bool { return b == nil || *b }
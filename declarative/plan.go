@@ -0,0 +1,233 @@
+package declarative
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+)
+
+// Action is what a [Change] does to its object.
+type Action string
+
+// Change actions.
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is one step of a [Plan].
+type Change struct {
+	Action Action
+
+	// Kind is one of the geoserver.Kind* constants.
+	Kind string
+
+	// Name is the object's qualified name: "ws", "ws:store",
+	// "ws:store:resource", "ws:layer", "ws:style" or "style" for a
+	// global style. ACL rules are named "<set> <rule>" (for example
+	// "layers topp.*.r") and service settings "wms" or "ws:wms".
+	Name string
+
+	// Fields lists the JSON field paths that differ, for updates.
+	Fields []string
+
+	stage int
+	apply func(context.Context) error
+}
+
+// String renders the change as one plan line: "+" create, "~" update,
+// "-" delete.
+func (ch Change) String() string {
+	sign := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[ch.Action]
+	s := fmt.Sprintf("%s %s %s", sign, ch.Kind, ch.Name)
+	if len(ch.Fields) > 0 {
+		s += " (" + strings.Join(ch.Fields, ", ") + ")"
+	}
+	return s
+}
+
+// Dependency stages. Creates and updates run in ascending order,
+// deletes after them in descending order.
+const (
+	stageWorkspace = iota
+	stageServices
+	stageStyle
+	stageStore
+	stageResource
+	stageLayer
+	stageLayerGroup
+	stageACL
+)
+
+// Plan is the ordered list of changes that brings the server to a
+// manifest's state.
+type Plan struct {
+	Changes []Change
+}
+
+// Empty reports whether the server already matches the manifest.
+func (p *Plan) Empty() bool { return len(p.Changes) == 0 }
+
+// String renders the plan one change per line, followed by a summary.
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "No changes.\n"
+	}
+	var b strings.Builder
+	counts := map[Action]int{}
+	for _, ch := range p.Changes {
+		b.WriteString(ch.String())
+		b.WriteByte('\n')
+		counts[ch.Action]++
+	}
+	fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete.\n",
+		counts[ActionCreate], counts[ActionUpdate], counts[ActionDelete])
+	return b.String()
+}
+
+// Apply executes the changes in order and stops at the first failure.
+// Changes before the failing one stay applied; computing a new plan
+// picks up from there.
+func (p *Plan) Apply(ctx context.Context) error {
+	for _, ch := range p.Changes {
+		if err := ch.apply(ctx); err != nil {
+			return fmt.Errorf("declarative: %s: %w", ch, err)
+		}
+	}
+	return nil
+}
+
+// Options configures [NewPlan] and [Apply].
+type Options struct {
+	// Prune deletes objects the manifest doesn't list: workspaces,
+	// and within managed workspaces and stores, stores, resources,
+	// layer groups and styles; global styles other than GeoServer's
+	// built-in ones; and ACL rules in the rule sets the manifest
+	// manages. Deleting a store or resource also deletes its layers.
+	Prune bool
+
+	// DryRun makes [Apply] print the plan without changing anything.
+	DryRun bool
+
+	// Out receives the plan printed by [Apply]. Default os.Stdout.
+	Out io.Writer
+}
+
+// Apply computes the plan for m and, unless opts.DryRun is set,
+// applies it. The plan is printed to opts.Out either way and is
+// returned for inspection.
+func Apply(ctx context.Context, c *geoserver.Client, m *Manifest, opts Options) (*Plan, error) {
+	plan, err := NewPlan(ctx, c, m, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if _, err := io.WriteString(out, plan.String()); err != nil {
+		return plan, fmt.Errorf("declarative: print plan: %w", err)
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+	return plan, plan.Apply(ctx)
+}
+
+// NewPlan reads the live state of everything m manages and returns
+// the changes needed to match it. Nothing is modified.
+func NewPlan(ctx context.Context, c *geoserver.Client, m *Manifest, opts Options) (*Plan, error) {
+	if c == nil {
+		return nil, errors.New("declarative: nil client")
+	}
+	if m == nil {
+		return nil, errors.New("declarative: nil manifest")
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("declarative: %w", err)
+	}
+	crawl := geoserver.InventoryOptions{Workspaces: names(m.Workspaces, func(w Workspace) string { return w.Name })}
+	if len(crawl.Workspaces) == 0 {
+		crawl.ExcludeWorkspaces = []string{"*"} // only global objects are managed
+	}
+	inv, err := c.Catalog().Inventory(ctx, crawl)
+	if err == nil {
+		err = inv.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("declarative: plan: %w", err)
+	}
+	p := &planner{c: c, inv: inv, prune: opts.Prune, plan: &Plan{}}
+	steps := []func(context.Context) error{
+		func(ctx context.Context) error { return p.workspaces(ctx, m.Workspaces) },
+		func(ctx context.Context) error { return p.styles(ctx, "", m.Styles, inv.Styles) },
+		func(ctx context.Context) error { return p.services(ctx, "", m.Services, true) },
+		func(ctx context.Context) error { return p.acl(ctx, m.ACL) },
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return nil, fmt.Errorf("declarative: plan: %w", err)
+		}
+	}
+	slices.SortStableFunc(p.plan.Changes, func(a, b Change) int {
+		ad, bd := a.Action == ActionDelete, b.Action == ActionDelete
+		switch {
+		case ad != bd:
+			if ad {
+				return 1
+			}
+			return -1
+		case ad:
+			return cmp.Compare(b.stage, a.stage)
+		}
+		return cmp.Compare(a.stage, b.stage)
+	})
+	return p.plan, nil
+}
+
+// planner accumulates changes while walking the manifest. inv is the
+// live catalog of the managed workspaces, crawled up front.
+type planner struct {
+	c     *geoserver.Client
+	inv   *geoserver.Inventory
+	prune bool
+	plan  *Plan
+}
+
+func (p *planner) add(stage int, action Action, kind, name string, fields []string, apply func(context.Context) error) {
+	p.plan.Changes = append(p.plan.Changes, Change{
+		Action: action,
+		Kind:   kind,
+		Name:   name,
+		Fields: fields,
+		stage:  stage,
+		apply:  apply,
+	})
+}
+
+// qualify joins name parts with ":", skipping empty ones.
+func qualify(parts ...string) string {
+	return strings.Join(slices.DeleteFunc(parts, func(s string) bool { return s == "" }), ":")
+}
+
+// unmanaged returns the live names not in desired, sorted.
+func unmanaged(live []string, desired []string) []string {
+	var out []string
+	for _, n := range live {
+		if !slices.Contains(desired, n) {
+			out = append(out, n)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func notFound(err error) bool { return errors.Is(err, geoserver.ErrNotFound) }
@@ -0,0 +1,729 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/namespaces"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// ---- workspaces ------------------------------------------------------------
+
+func (p *planner) workspaces(ctx context.Context, desired []Workspace) error {
+	list, err := p.c.Workspaces.List(ctx, workspaces.ListOptions{})
+	if err != nil {
+		return err
+	}
+	live := names(list, func(w workspaces.Workspace) string { return w.Name })
+	for _, ws := range desired {
+		exists := slices.Contains(live, ws.Name)
+		if exists {
+			if err := p.updateWorkspace(ctx, ws); err != nil {
+				return err
+			}
+		} else {
+			p.add(stageWorkspace, ActionCreate, geoserver.KindWorkspace, ws.Name, nil, func(ctx context.Context) error {
+				if ws.URI != "" {
+					return p.c.Namespaces.Create(ctx, &namespaces.Namespace{Prefix: ws.Name, URI: ws.URI, Isolated: ws.Isolated})
+				}
+				return p.c.Workspaces.Create(ctx, &workspaces.Workspace{Name: ws.Name, Isolated: ws.Isolated})
+			})
+		}
+		iw := p.inv.Workspace(ws.Name)
+		if iw == nil {
+			iw = &geoserver.InventoryWorkspace{}
+		}
+		if err := p.workspaceContents(ctx, ws, iw, exists); err != nil {
+			return fmt.Errorf("workspace %s: %w", ws.Name, err)
+		}
+	}
+	if !p.prune {
+		return nil
+	}
+	for _, name := range unmanaged(live, names(desired, func(w Workspace) string { return w.Name })) {
+		p.add(stageWorkspace, ActionDelete, geoserver.KindWorkspace, name, nil, func(ctx context.Context) error {
+			return p.c.Workspaces.Delete(ctx, name, workspaces.DeleteOptions{Recurse: true})
+		})
+	}
+	return nil
+}
+
+func (p *planner) updateWorkspace(ctx context.Context, ws Workspace) error {
+	ns, err := p.c.Namespaces.Get(ctx, ws.Name)
+	if err != nil {
+		return err
+	}
+	var fields []string
+	patch := &namespaces.Patch{}
+	if ws.URI != "" && ns.URI != ws.URI {
+		fields = append(fields, "uri")
+		patch.URI = &ws.URI
+	}
+	if ns.Isolated != ws.Isolated {
+		fields = append(fields, "isolated")
+		patch.Isolated = &ws.Isolated
+	}
+	if len(fields) > 0 {
+		p.add(stageWorkspace, ActionUpdate, geoserver.KindWorkspace, ws.Name, fields, func(ctx context.Context) error {
+			return p.c.Namespaces.Update(ctx, ws.Name, patch)
+		})
+	}
+	return nil
+}
+
+// workspaceContents plans the contents of ws against iw, its crawled
+// live state (empty if it doesn't exist yet).
+func (p *planner) workspaceContents(ctx context.Context, ws Workspace, iw *geoserver.InventoryWorkspace, exists bool) error {
+	steps := []func(context.Context) error{
+		func(ctx context.Context) error { return p.styles(ctx, ws.Name, ws.Styles, iw.Styles) },
+		func(context.Context) error { return p.datastores(ws, iw) },
+		func(context.Context) error { return p.coverageStores(ws, iw) },
+		func(context.Context) error { return p.layers(ws, iw) },
+		func(context.Context) error { return p.layerGroups(ws, iw) },
+		func(ctx context.Context) error { return p.services(ctx, ws.Name, ws.Services, exists) },
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ---- styles ----------------------------------------------------------------
+
+// builtinStyles ship with every GeoServer and can't be deleted.
+var builtinStyles = []string{"generic", "line", "point", "polygon", "raster"}
+
+// metadata is the style document compared and written alongside the
+// body. Unset fields are left to GeoServer.
+func (st Style) metadata() *styles.Style {
+	out := &styles.Style{Name: st.Name, Format: st.Format}
+	if st.LanguageVersion != "" {
+		out.LanguageVersion = &styles.LanguageVersion{Version: st.LanguageVersion}
+	}
+	return out
+}
+
+// contentType is the upload Content-Type for the style's format; ""
+// selects the client's SLD 1.0 default.
+func (st Style) contentType() string {
+	switch st.Format {
+	case "css":
+		return "application/vnd.geoserver.geocss+css"
+	case "ysld":
+		return "application/vnd.geoserver.ysld+yaml"
+	case "mbstyle":
+		return "application/vnd.geoserver.mbstyle+json"
+	}
+	if st.LanguageVersion == "1.1.0" {
+		return "application/vnd.ogc.se+xml"
+	}
+	return ""
+}
+
+// isSLD reports whether GeoServer returns the body as uploaded, so it
+// can be compared.
+func (st Style) isSLD() bool { return st.Format == "" || st.Format == "sld" }
+
+func (p *planner) styles(ctx context.Context, ws string, desired []Style, crawled []*geoserver.InventoryStyle) error {
+	sc := p.c.Styles
+	if ws != "" {
+		sc = sc.InWorkspace(ws)
+	}
+	live := names(crawled, func(s *geoserver.InventoryStyle) string { return s.Style.Name })
+	for _, st := range desired {
+		name := qualify(ws, st.Name)
+		upload := func(ctx context.Context) error {
+			if st.Body == "" {
+				return nil
+			}
+			return sc.UploadSLD(ctx, st.Name, strings.NewReader(st.Body), styles.UploadOptions{Format: st.contentType()})
+		}
+		i := slices.Index(live, st.Name)
+		if i < 0 {
+			p.add(stageStyle, ActionCreate, geoserver.KindStyle, name, nil, func(ctx context.Context) error {
+				meta := st.metadata()
+				if meta.Format == "" {
+					meta.Format = "sld"
+				}
+				if err := sc.Create(ctx, meta); err != nil {
+					return err
+				}
+				return upload(ctx)
+			})
+			continue
+		}
+		fields, err := diffFields(st.metadata(), crawled[i].Style)
+		if err != nil {
+			return err
+		}
+		bodyChanged := false
+		if st.Body != "" && st.isSLD() {
+			bodyChanged, err = styleBodyChanged(ctx, sc, st)
+			if err != nil {
+				return err
+			}
+		}
+		if len(fields) == 0 && !bodyChanged {
+			continue
+		}
+		if bodyChanged {
+			fields = append(fields, "body")
+		}
+		metaChanged := len(fields) > 0 && fields[0] != "body"
+		p.add(stageStyle, ActionUpdate, geoserver.KindStyle, name, fields, func(ctx context.Context) error {
+			if metaChanged {
+				if err := sc.Update(ctx, st.Name, st.metadata()); err != nil {
+					return err
+				}
+			}
+			return upload(ctx)
+		})
+	}
+	if !p.prune {
+		return nil
+	}
+	keep := names(desired, func(s Style) string { return s.Name })
+	if ws == "" {
+		keep = append(keep, builtinStyles...)
+	}
+	for _, st := range unmanaged(live, keep) {
+		p.add(stageStyle, ActionDelete, geoserver.KindStyle, qualify(ws, st), nil, func(ctx context.Context) error {
+			return sc.Delete(ctx, st, styles.DeleteOptions{Purge: true})
+		})
+	}
+	return nil
+}
+
+func styleBodyChanged(ctx context.Context, sc *styles.Client, st Style) (bool, error) {
+	body, err := sc.GetSLD(ctx, st.Name)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = body.Close() }()
+	live, err := io.ReadAll(body)
+	if err != nil {
+		return false, fmt.Errorf("read style %s: %w", st.Name, err)
+	}
+	return !sameBody(st.Body, string(live)), nil
+}
+
+// styleRef resolves a style name used by a layer or layer group in
+// ws to the name GeoServer reports back: "ws:name" for the
+// workspace's own styles, the plain name for global ones.
+func styleRef(ws Workspace, name string) string {
+	if name == "" || strings.Contains(name, ":") {
+		return name
+	}
+	if slices.ContainsFunc(ws.Styles, func(s Style) bool { return s.Name == name }) {
+		return ws.Name + ":" + name
+	}
+	return name
+}
+
+// ---- datastores and feature types ------------------------------------------
+
+func (p *planner) datastores(ws Workspace, iw *geoserver.InventoryWorkspace) error {
+	dc := p.c.Datastores.InWorkspace(ws.Name)
+	live := names(iw.Datastores, func(d *geoserver.InventoryDatastore) string { return d.Datastore.Name })
+	for _, ds := range ws.Datastores {
+		name := qualify(ws.Name, ds.Name)
+		want := ds.datastore()
+		got := iw.Datastore(ds.Name)
+		if got == nil {
+			p.add(stageStore, ActionCreate, geoserver.KindDatastore, name, nil, func(ctx context.Context) error {
+				if err := dc.Create(ctx, datastores.Raw(want)); err != nil {
+					return err
+				}
+				if enabled(ds.Enabled) {
+					return nil
+				}
+				disabled := false
+				return dc.Update(ctx, ds.Name, &datastores.Patch{Enabled: &disabled})
+			})
+			got = &geoserver.InventoryDatastore{}
+		} else {
+			p.updateDatastore(dc, name, ds, want, got.Datastore)
+		}
+		if err := p.featureTypes(ws.Name, ds, got); err != nil {
+			return err
+		}
+	}
+	if !p.prune {
+		return nil
+	}
+	for _, name := range unmanaged(live, names(ws.Datastores, func(d Datastore) string { return d.Name })) {
+		p.add(stageStore, ActionDelete, geoserver.KindDatastore, qualify(ws.Name, name), nil, func(ctx context.Context) error {
+			return dc.Delete(ctx, name, datastores.DeleteOptions{Recurse: true})
+		})
+	}
+	return nil
+}
+
+func (p *planner) updateDatastore(dc *datastores.WorkspaceClient, name string, ds Datastore, want datastores.Datastore, got *datastores.Datastore) {
+	var fields []string
+	patch := &datastores.Patch{}
+	if got.Enabled != enabled(ds.Enabled) {
+		fields = append(fields, "enabled")
+		en := enabled(ds.Enabled)
+		patch.Enabled = &en
+	}
+	if merged, changed := got.ConnectionParameters.Merge(want.ConnectionParameters); len(changed) > 0 {
+		for _, k := range changed {
+			fields = append(fields, "connectionParameters."+k)
+		}
+		patch.ConnectionParameters = &merged
+	}
+	if len(fields) > 0 {
+		p.add(stageStore, ActionUpdate, geoserver.KindDatastore, name, fields, func(ctx context.Context) error {
+			return dc.Update(ctx, ds.Name, patch)
+		})
+	}
+}
+
+func (p *planner) featureTypes(ws string, ds Datastore, store *geoserver.InventoryDatastore) error {
+	fc := p.c.FeatureTypes.InWorkspace(ws).InDatastore(ds.Name)
+	live := names(store.FeatureTypes, func(f *geoserver.InventoryFeatureType) string { return f.FeatureType.Name })
+	for _, ft := range ds.FeatureTypes {
+		name := qualify(ws, ds.Name, ft.Name)
+		got := store.FeatureType(ft.Name)
+		if got == nil {
+			p.add(stageResource, ActionCreate, geoserver.KindFeatureType, name, nil, func(ctx context.Context) error {
+				return fc.Create(ctx, &ft)
+			})
+			continue
+		}
+		fields, err := diffFields(ft, got.FeatureType)
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			p.add(stageResource, ActionUpdate, geoserver.KindFeatureType, name, fields, func(ctx context.Context) error {
+				return fc.Update(ctx, ft.Name, &ft)
+			})
+		}
+	}
+	if !p.prune {
+		return nil
+	}
+	for _, name := range unmanaged(live, names(ds.FeatureTypes, func(f featuretypes.FeatureType) string { return f.Name })) {
+		p.add(stageResource, ActionDelete, geoserver.KindFeatureType, qualify(ws, ds.Name, name), nil, func(ctx context.Context) error {
+			return fc.Delete(ctx, name, featuretypes.DeleteOptions{Recurse: true})
+		})
+	}
+	return nil
+}
+
+// ---- coverage stores and coverages -----------------------------------------
+
+func (p *planner) coverageStores(ws Workspace, iw *geoserver.InventoryWorkspace) error {
+	cc := p.c.CoverageStores.InWorkspace(ws.Name)
+	live := names(iw.CoverageStores, func(s *geoserver.InventoryCoverageStore) string { return s.CoverageStore.Name })
+	for _, cs := range ws.CoverageStores {
+		name := qualify(ws.Name, cs.Name)
+		got := iw.CoverageStore(cs.Name)
+		if got == nil {
+			p.add(stageStore, ActionCreate, geoserver.KindCoverageStore, name, nil, func(ctx context.Context) error {
+				store := &coveragestores.CoverageStore{Name: cs.Name, Type: cs.Type, URL: cs.URL, Description: cs.Description, Enabled: true}
+				if err := cc.Create(ctx, store); err != nil {
+					return err
+				}
+				if enabled(cs.Enabled) {
+					return nil
+				}
+				disabled := false
+				return cc.Update(ctx, cs.Name, &coveragestores.Patch{Enabled: &disabled})
+			})
+			got = &geoserver.InventoryCoverageStore{}
+		} else if err := p.updateCoverageStore(cc, name, cs, got.CoverageStore); err != nil {
+			return err
+		}
+		if err := p.coverages(ws.Name, cs, got); err != nil {
+			return err
+		}
+	}
+	if !p.prune {
+		return nil
+	}
+	for _, name := range unmanaged(live, names(ws.CoverageStores, func(s CoverageStore) string { return s.Name })) {
+		p.add(stageStore, ActionDelete, geoserver.KindCoverageStore, qualify(ws.Name, name), nil, func(ctx context.Context) error {
+			return cc.Delete(ctx, name, coveragestores.DeleteOptions{Recurse: true})
+		})
+	}
+	return nil
+}
+
+func (p *planner) updateCoverageStore(cc *coveragestores.WorkspaceClient, name string, cs CoverageStore, got *coveragestores.CoverageStore) error {
+	fields, err := diffFields(coveragestores.CoverageStore{Type: cs.Type, URL: cs.URL, Description: cs.Description}, got)
+	if err != nil {
+		return err
+	}
+	en := enabled(cs.Enabled)
+	if got.Enabled != en {
+		fields = append(fields, "enabled")
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	patch := &coveragestores.Patch{Enabled: &en}
+	if cs.Type != "" {
+		patch.Type = &cs.Type
+	}
+	if cs.URL != "" {
+		patch.URL = &cs.URL
+	}
+	if cs.Description != "" {
+		patch.Description = &cs.Description
+	}
+	p.add(stageStore, ActionUpdate, geoserver.KindCoverageStore, name, fields, func(ctx context.Context) error {
+		return cc.Update(ctx, cs.Name, patch)
+	})
+	return nil
+}
+
+func (p *planner) coverages(ws string, cs CoverageStore, store *geoserver.InventoryCoverageStore) error {
+	cc := p.c.Coverages.InWorkspace(ws).InCoverageStore(cs.Name)
+	live := names(store.Coverages, func(c *geoserver.InventoryCoverage) string { return c.Coverage.Name })
+	for _, cov := range cs.Coverages {
+		name := qualify(ws, cs.Name, cov.Name)
+		got := store.Coverage(cov.Name)
+		if got == nil {
+			p.add(stageResource, ActionCreate, geoserver.KindCoverage, name, nil, func(ctx context.Context) error {
+				return cc.Create(ctx, &cov)
+			})
+			continue
+		}
+		fields, err := diffFields(cov, got.Coverage)
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			p.add(stageResource, ActionUpdate, geoserver.KindCoverage, name, fields, func(ctx context.Context) error {
+				return cc.Update(ctx, cov.Name, &cov)
+			})
+		}
+	}
+	if !p.prune {
+		return nil
+	}
+	for _, name := range unmanaged(live, names(cs.Coverages, func(c coverages.Coverage) string { return c.Name })) {
+		p.add(stageResource, ActionDelete, geoserver.KindCoverage, qualify(ws, cs.Name, name), nil, func(ctx context.Context) error {
+			return cc.Delete(ctx, name, coverages.DeleteOptions{Recurse: true})
+		})
+	}
+	return nil
+}
+
+// ---- layers and layer groups -----------------------------------------------
+
+func (l Layer) layer(ws Workspace) *layers.Layer {
+	out := &layers.Layer{Name: l.Name, Attribution: l.Attribution}
+	if l.DefaultStyle != "" {
+		out.DefaultStyle = &layers.Ref{Name: styleRef(ws, l.DefaultStyle)}
+	}
+	if len(l.Styles) > 0 {
+		out.Styles = &layers.Styles{}
+		for _, st := range l.Styles {
+			out.Styles.Style = append(out.Styles.Style, layers.Ref{Name: styleRef(ws, st)})
+		}
+	}
+	return out
+}
+
+// layers plans layer setting updates. Layers are created by GeoServer
+// when their resource is published, so a layer that doesn't exist yet
+// is planned as an update of every field, applied after the resource
+// is created.
+func (p *planner) layers(ws Workspace, iw *geoserver.InventoryWorkspace) error {
+	lc := p.c.Layers.InWorkspace(ws.Name)
+	for _, l := range ws.Layers {
+		want := l.layer(ws)
+		var got *layers.Layer
+		if il := iw.Layer(l.Name); il != nil {
+			got = il.Layer
+		}
+		var live any = got
+		if got == nil {
+			live = struct{}{}
+		}
+		fields, err := diffFields(want, live)
+		if err != nil {
+			return err
+		}
+		fields = slices.DeleteFunc(fields, func(f string) bool { return f == "name" })
+		// Flags are compared by hand: layers.Layer drops false on the
+		// wire, so a desired false can only be sent through Patch.
+		patch, err := toJSONValue(want)
+		if err != nil {
+			return err
+		}
+		doc := patch.(map[string]any)
+		for field, flag := range map[string]struct{ want, got *bool }{
+			"queryable": {l.Queryable, flagOf(got, func(l *layers.Layer) bool { return l.Queryable })},
+			"opaque":    {l.Opaque, flagOf(got, func(l *layers.Layer) bool { return l.Opaque })},
+		} {
+			if flag.want == nil {
+				continue
+			}
+			doc[field] = *flag.want
+			if flag.got == nil || *flag.got != *flag.want {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		slices.Sort(fields)
+		p.add(stageLayer, ActionUpdate, geoserver.KindLayer, qualify(ws.Name, l.Name), fields, func(ctx context.Context) error {
+			return lc.Patch(ctx, l.Name, doc)
+		})
+	}
+	return nil
+}
+
+// flagOf returns a boolean field of l, or nil if l is nil.
+func flagOf(l *layers.Layer, field func(*layers.Layer) bool) *bool {
+	if l == nil {
+		return nil
+	}
+	v := field(l)
+	return &v
+}
+
+func (g LayerGroup) layerGroup(ws Workspace) *layergroups.LayerGroup {
+	out := &layergroups.LayerGroup{Name: g.Name, Title: g.Title, Mode: cmpOr(g.Mode, "SINGLE")}
+	for _, member := range g.Layers {
+		item := layergroups.PublishedItem{Type: "layer", Name: member}
+		if !strings.Contains(member, ":") {
+			if slices.ContainsFunc(ws.LayerGroups, func(o LayerGroup) bool { return o.Name == member }) {
+				item.Type = "layerGroup"
+			}
+			item.Name = ws.Name + ":" + member
+		}
+		out.Publishables.Published = append(out.Publishables.Published, item)
+	}
+	for _, st := range g.Styles {
+		out.Styles.Style = append(out.Styles.Style, layergroups.Ref{Name: styleRef(ws, st)})
+	}
+	return out
+}
+
+// groupFields compares the parts of a layer group the manifest sets.
+func groupFields(want, got *layergroups.LayerGroup) []string {
+	var fields []string
+	if want.Title != "" && want.Title != got.Title {
+		fields = append(fields, "title")
+	}
+	if want.Mode != got.Mode {
+		fields = append(fields, "mode")
+	}
+	member := func(i layergroups.PublishedItem) string { return i.Name }
+	if !slices.Equal(names(want.Publishables.Published, member), names(got.Publishables.Published, member)) {
+		fields = append(fields, "publishables")
+	}
+	style := func(r layergroups.Ref) string { return r.Name }
+	if len(want.Styles.Style) > 0 && !slices.Equal(names(want.Styles.Style, style), names(got.Styles.Style, style)) {
+		fields = append(fields, "styles")
+	}
+	return fields
+}
+
+func (p *planner) layerGroups(ws Workspace, iw *geoserver.InventoryWorkspace) error {
+	gc := p.c.LayerGroups.InWorkspace(ws.Name)
+	live := names(iw.LayerGroups, func(g *geoserver.InventoryLayerGroup) string { return g.LayerGroup.Name })
+	for _, g := range ws.LayerGroups {
+		name := qualify(ws.Name, g.Name)
+		want := g.layerGroup(ws)
+		got := iw.LayerGroup(g.Name)
+		if got == nil {
+			p.add(stageLayerGroup, ActionCreate, geoserver.KindLayerGroup, name, nil, func(ctx context.Context) error {
+				return gc.Create(ctx, want)
+			})
+			continue
+		}
+		if fields := groupFields(want, got.LayerGroup); len(fields) > 0 {
+			p.add(stageLayerGroup, ActionUpdate, geoserver.KindLayerGroup, name, fields, func(ctx context.Context) error {
+				return gc.Update(ctx, g.Name, want)
+			})
+		}
+	}
+	if !p.prune {
+		return nil
+	}
+	for _, name := range unmanaged(live, names(ws.LayerGroups, func(g LayerGroup) string { return g.Name })) {
+		p.add(stageLayerGroup, ActionDelete, geoserver.KindLayerGroup, qualify(ws.Name, name), nil, func(ctx context.Context) error {
+			return gc.Delete(ctx, name)
+		})
+	}
+	return nil
+}
+
+func cmpOr(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// ---- service settings ------------------------------------------------------
+
+func (p *planner) services(ctx context.Context, ws string, desired *Services, wsExists bool) error {
+	if desired == nil {
+		return nil
+	}
+	svc := p.c.Services
+	var err error
+	if desired.WMS != nil {
+		get, put := svc.WMS().Get, svc.WMS().Update
+		if ws != "" {
+			get, put = svc.WMS().InWorkspace(ws).Get, svc.WMS().InWorkspace(ws).Update
+		}
+		err = planService(ctx, p, ws, "wms", desired.WMS, wsExists, get, put)
+	}
+	if err == nil && desired.WFS != nil {
+		get, put := svc.WFS().Get, svc.WFS().Update
+		if ws != "" {
+			get, put = svc.WFS().InWorkspace(ws).Get, svc.WFS().InWorkspace(ws).Update
+		}
+		err = planService(ctx, p, ws, "wfs", desired.WFS, wsExists, get, put)
+	}
+	if err == nil && desired.WCS != nil {
+		get, put := svc.WCS().Get, svc.WCS().Update
+		if ws != "" {
+			get, put = svc.WCS().InWorkspace(ws).Get, svc.WCS().InWorkspace(ws).Update
+		}
+		err = planService(ctx, p, ws, "wcs", desired.WCS, wsExists, get, put)
+	}
+	if err == nil && desired.WMTS != nil {
+		get, put := svc.WMTS().Get, svc.WMTS().Update
+		if ws != "" {
+			get, put = svc.WMTS().InWorkspace(ws).Get, svc.WMTS().InWorkspace(ws).Update
+		}
+		err = planService(ctx, p, ws, "wmts", desired.WMTS, wsExists, get, put)
+	}
+	return err
+}
+
+// planService compares one service's settings. A workspace without an
+// override gets one created.
+func planService[T any](ctx context.Context, p *planner, ws, slug string, want *T, wsExists bool,
+	get func(context.Context) (*T, error), put func(context.Context, *T) error,
+) error {
+	name := qualify(ws, slug)
+	apply := func(ctx context.Context) error { return put(ctx, want) }
+	var got *T
+	if wsExists {
+		var err error
+		got, err = get(ctx)
+		if err != nil && !(ws != "" && notFound(err)) {
+			return err
+		}
+	}
+	if got == nil {
+		p.add(stageServices, ActionCreate, geoserver.KindServiceSettings, name, nil, apply)
+		return nil
+	}
+	fields, err := diffFields(want, got)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		p.add(stageServices, ActionUpdate, geoserver.KindServiceSettings, name, fields, apply)
+	}
+	return nil
+}
+
+// ---- ACL rules -------------------------------------------------------------
+
+func (p *planner) acl(ctx context.Context, desired *ACL) error {
+	if desired == nil {
+		return nil
+	}
+	if desired.Layers != nil {
+		lc := p.c.ACL.Layers()
+		live, err := lc.List(ctx, acl.ListOptions{})
+		if err != nil {
+			return err
+		}
+		if err := planACL(p, "layers", desired.Layers, live, acl.DecodeRule, lc.Add, lc.Update, lc.Delete); err != nil {
+			return err
+		}
+	}
+	if desired.Services != nil {
+		sc := p.c.ACL.Services()
+		live, err := sc.List(ctx, acl.ListOptions{})
+		if err != nil {
+			return err
+		}
+		if err := planACL(p, "services", desired.Services, live, acl.DecodeServiceRule, sc.Add, sc.Update, sc.Delete); err != nil {
+			return err
+		}
+	}
+	if desired.REST != nil {
+		rc := p.c.ACL.REST()
+		live, err := rc.List(ctx, acl.ListOptions{})
+		if err != nil {
+			return err
+		}
+		if err := planACL(p, "rest", desired.REST, live, acl.DecodeRESTRule, rc.Add, rc.Update, rc.Delete); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodedRule is implemented by the acl rule types.
+type encodedRule interface {
+	Encode() (rule, roles string)
+}
+
+func planACL[R encodedRule](p *planner, set string, desired map[string]string, live []R,
+	decode func(rule, roles string) (R, error), add, update, del func(context.Context, R) error,
+) error {
+	liveRoles := map[string]string{}
+	liveRules := map[string]R{}
+	for _, r := range live {
+		rule, roles := r.Encode()
+		liveRoles[rule], liveRules[rule] = roles, r
+	}
+	var keys []string
+	for _, rule := range slices.Sorted(maps.Keys(desired)) {
+		r, err := decode(rule, desired[rule])
+		if err != nil {
+			return fmt.Errorf("acl %s %q: %w", set, rule, err)
+		}
+		// Key on the encoded form so defaults ("*") compare equal.
+		key, roles := r.Encode()
+		keys = append(keys, key)
+		name := set + " " + key
+		switch have, ok := liveRoles[key]; {
+		case !ok:
+			p.add(stageACL, ActionCreate, geoserver.KindACLRule, name, nil, func(ctx context.Context) error { return add(ctx, r) })
+		case !sameRoles(roles, have):
+			p.add(stageACL, ActionUpdate, geoserver.KindACLRule, name, []string{"roles"}, func(ctx context.Context) error { return update(ctx, r) })
+		}
+	}
+	if !p.prune {
+		return nil
+	}
+	for _, key := range unmanaged(slices.Collect(maps.Keys(liveRules)), keys) {
+		r := liveRules[key]
+		p.add(stageACL, ActionDelete, geoserver.KindACLRule, set+" "+key, nil, func(ctx context.Context) error { return del(ctx, r) })
+	}
+	return nil
+}
@@ -0,0 +1,597 @@
+package geoserver
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/templates"
+)
+
+// DeleteAction is what a [DeleteNode] does to its object.
+type DeleteAction string
+
+// Delete actions.
+const (
+	// DeleteRemove deletes the object.
+	DeleteRemove DeleteAction = "delete"
+	// DeleteDetach updates a layer group to drop the members being
+	// deleted. The group itself survives.
+	DeleteDetach DeleteAction = "detach"
+)
+
+// DeleteNode is one object affected by a [DeletePlan].
+type DeleteNode struct {
+	Ref    Ref
+	Action DeleteAction
+
+	// Because lists the planned objects whose removal pulls this one
+	// in: the edges of the graph. It is empty for the plan's root.
+	Because []Ref
+
+	// Members lists the qualified names a DeleteDetach drops from the
+	// group.
+	Members []string
+
+	run func(ctx context.Context) error
+}
+
+// DeletePlan is everything removed or changed by deleting one catalog
+// object, computed by [Catalog.DeletePlan].
+//
+// Nodes are in execution order: every object comes before the objects
+// it depends on, so ACL rules, tile layers and layer groups go first,
+// then layers, styles, templates and resources, and the root last.
+type DeletePlan struct {
+	Root  Ref
+	Nodes []DeleteNode
+}
+
+// Count returns the number of nodes of the given kind.
+func (p *DeletePlan) Count(kind string) int {
+	n := 0
+	for _, node := range p.Nodes {
+		if node.Ref.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// String renders the plan one node per line in execution order: "-"
+// for a delete, "~" for a detach, followed by the causing objects.
+func (p *DeletePlan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "delete %s: %d objects\n", p.Root, len(p.Nodes))
+	for _, n := range p.Nodes {
+		sign := "-"
+		if n.Action == DeleteDetach {
+			sign = "~"
+		}
+		fmt.Fprintf(&b, "%s %s", sign, n.Ref)
+		if len(n.Members) > 0 {
+			fmt.Fprintf(&b, " (drop %s)", strings.Join(n.Members, ", "))
+		}
+		if len(n.Because) > 0 {
+			causes := make([]string, len(n.Because))
+			for i, r := range n.Because {
+				causes[i] = r.String()
+			}
+			fmt.Fprintf(&b, " ← %s", strings.Join(causes, ", "))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Execute applies the plan bottom-up, in [DeletePlan.Nodes] order, and
+// stops at the first failure; nodes already applied stay applied. An
+// object that is already gone counts as deleted.
+//
+// Every delete is issued without recurse, so an object that appeared
+// after planning makes its parent's delete fail instead of being
+// removed unseen.
+func (p *DeletePlan) Execute(ctx context.Context) error {
+	for _, n := range p.Nodes {
+		err := n.run(ctx)
+		if n.Action == DeleteRemove && errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("geoserver: delete %s: %s %s: %w", p.Root, n.Action, n.Ref, err)
+		}
+	}
+	return nil
+}
+
+// DeletePlan computes what deleting ref removes or breaks, without
+// changing anything. ref.Kind is one of KindDatastore,
+// KindCoverageStore, KindFeatureType, KindCoverage, KindLayer or
+// KindLayerGroup.
+//
+// The plan covers the store's feature types or coverages, the layers
+// publishing them, layer groups in any workspace that reference those
+// layers (detached, or deleted once empty, recursively), workspace
+// styles used only by deleted layers and groups, GeoWebCache tile
+// layers and layer ACL rules naming a deleted layer or group, and
+// templates scoped to the deleted store or resources. Global styles
+// and layer groups outside any workspace are never touched.
+//
+//	plan, _ := c.Catalog().DeletePlan(ctx, geoserver.Ref{Kind: geoserver.KindDatastore, Workspace: "topp", Name: "pg"})
+//	fmt.Print(plan) // review
+//	err := plan.Execute(ctx)
+func (cat *Catalog) DeletePlan(ctx context.Context, ref Ref) (*DeletePlan, error) {
+	const op = "Catalog.DeletePlan"
+	if ref.Workspace == "" || ref.Name == "" {
+		return nil, errors.New(op + ": empty workspace or name")
+	}
+	if !slices.Contains([]string{KindDatastore, KindCoverageStore, KindFeatureType, KindCoverage, KindLayer, KindLayerGroup}, ref.Kind) {
+		return nil, fmt.Errorf("%s: unsupported kind %q", op, ref.Kind)
+	}
+	p := &deletePlanner{c: cat.c, ws: ref.Workspace, nodes: map[Ref]*DeleteNode{}}
+	if err := p.plan(ctx, ref); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &DeletePlan{Root: ref, Nodes: p.ordered()}, nil
+}
+
+// deletePlanner accumulates the nodes of one plan.
+type deletePlanner struct {
+	c      *Client
+	ws     string
+	nodes  map[Ref]*DeleteNode
+	layers map[string]*layers.Layer // the workspace's layers by name
+	groups []plannedGroup           // every workspace's layer groups
+	level  map[Ref]int              // layer-group nesting depth
+}
+
+// crawl reads the parts of the catalog a plan can reach: the stores,
+// resources and layers of the target workspace, and the layer groups
+// of every workspace, which may nest its layers.
+func (p *deletePlanner) crawl(ctx context.Context) (*InventoryWorkspace, error) {
+	cat := p.c.Catalog()
+	inv, err := cat.Inventory(ctx, InventoryOptions{
+		Workspaces: []string{p.ws},
+		Kinds:      []string{KindDatastore, KindFeatureType, KindCoverageStore, KindCoverage, KindLayer},
+	})
+	if err == nil {
+		err = inv.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	iw := inv.Workspace(p.ws)
+	if iw == nil {
+		return nil, fmt.Errorf("workspace %s: %w", p.ws, ErrNotFound)
+	}
+	groups, err := cat.Inventory(ctx, InventoryOptions{Kinds: []string{KindLayerGroup}})
+	if err == nil {
+		err = groups.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.layers = map[string]*layers.Layer{}
+	for _, l := range iw.Layers {
+		p.layers[l.Layer.Name] = l.Layer
+	}
+	for _, gw := range groups.Workspaces {
+		for _, g := range gw.LayerGroups {
+			p.groups = append(p.groups, plannedGroup{ws: gw.Workspace.Name, group: g.LayerGroup})
+		}
+	}
+	return iw, nil
+}
+
+type plannedGroup struct {
+	ws    string
+	group *layergroups.LayerGroup
+}
+
+func (p *deletePlanner) add(ref Ref, cause *Ref, run func(context.Context) error) {
+	n := p.nodes[ref]
+	if n == nil {
+		n = &DeleteNode{Ref: ref, Action: DeleteRemove, run: run}
+		p.nodes[ref] = n
+	}
+	if cause != nil && !slices.Contains(n.Because, *cause) {
+		n.Because = append(n.Because, *cause)
+	}
+}
+
+func (p *deletePlanner) plan(ctx context.Context, ref Ref) error {
+	c, ws := p.c, p.ws
+	iw, err := p.crawl(ctx)
+	if err != nil {
+		return err
+	}
+	missing := func() error { return fmt.Errorf("%s: %w", ref, ErrNotFound) }
+
+	tc := c.Templates.InWorkspace(ws)
+	switch ref.Kind {
+	case KindDatastore:
+		store := iw.Datastore(ref.Name)
+		if store == nil {
+			return missing()
+		}
+		dc := c.Datastores.InWorkspace(ws)
+		p.add(ref, nil, func(ctx context.Context) error {
+			return dc.Delete(ctx, ref.Name, datastores.DeleteOptions{})
+		})
+		if err := p.templates(ctx, tc.InDatastore(ref.Name), "datastores/"+ref.Name, ref); err != nil {
+			return err
+		}
+		for _, ft := range store.FeatureTypes {
+			if err := p.featureType(ctx, Ref{Kind: KindFeatureType, Workspace: ws, Store: ref.Name, Name: ft.FeatureType.Name}, &ref); err != nil {
+				return err
+			}
+		}
+	case KindCoverageStore:
+		store := iw.CoverageStore(ref.Name)
+		if store == nil {
+			return missing()
+		}
+		cc := c.CoverageStores.InWorkspace(ws)
+		p.add(ref, nil, func(ctx context.Context) error {
+			return cc.Delete(ctx, ref.Name, coveragestores.DeleteOptions{})
+		})
+		if err := p.templates(ctx, tc.InCoverageStore(ref.Name), "coveragestores/"+ref.Name, ref); err != nil {
+			return err
+		}
+		for _, cov := range store.Coverages {
+			if err := p.coverage(ctx, Ref{Kind: KindCoverage, Workspace: ws, Store: ref.Name, Name: cov.Coverage.Name}, &ref); err != nil {
+				return err
+			}
+		}
+	case KindFeatureType:
+		if ref.Store == "" {
+			return errors.New("empty store")
+		}
+		if store := iw.Datastore(ref.Store); store == nil || store.FeatureType(ref.Name) == nil {
+			return missing()
+		}
+		if err := p.featureType(ctx, ref, nil); err != nil {
+			return err
+		}
+	case KindCoverage:
+		if ref.Store == "" {
+			return errors.New("empty store")
+		}
+		if store := iw.CoverageStore(ref.Store); store == nil || store.Coverage(ref.Name) == nil {
+			return missing()
+		}
+		if err := p.coverage(ctx, ref, nil); err != nil {
+			return err
+		}
+	case KindLayer:
+		if p.layers[ref.Name] == nil {
+			return missing()
+		}
+		p.layer(ref.Name, nil)
+	case KindLayerGroup:
+		if !slices.ContainsFunc(p.groups, func(pg plannedGroup) bool { return pg.ws == ws && pg.group.Name == ref.Name }) {
+			return missing()
+		}
+		gc := c.LayerGroups.InWorkspace(ws)
+		p.add(ref, nil, func(ctx context.Context) error { return gc.Delete(ctx, ref.Name) })
+	}
+
+	p.layerGroups()
+	p.styles()
+	if err := p.tileLayers(ctx); err != nil {
+		return err
+	}
+	return p.aclRules(ctx)
+}
+
+func (p *deletePlanner) featureType(ctx context.Context, ref Ref, cause *Ref) error {
+	fc := p.c.FeatureTypes.InWorkspace(p.ws).InDatastore(ref.Store)
+	p.add(ref, cause, func(ctx context.Context) error {
+		return fc.Delete(ctx, ref.Name, featuretypes.DeleteOptions{})
+	})
+	tc := p.c.Templates.InWorkspace(p.ws).InDatastore(ref.Store).InFeatureType(ref.Name)
+	if err := p.templates(ctx, tc, "datastores/"+ref.Store+"/featuretypes/"+ref.Name, ref); err != nil {
+		return err
+	}
+	p.publishedBy(ref)
+	return nil
+}
+
+func (p *deletePlanner) coverage(ctx context.Context, ref Ref, cause *Ref) error {
+	vc := p.c.Coverages.InWorkspace(p.ws).InCoverageStore(ref.Store)
+	p.add(ref, cause, func(ctx context.Context) error {
+		return vc.Delete(ctx, ref.Name, coverages.DeleteOptions{})
+	})
+	tc := p.c.Templates.InWorkspace(p.ws).InCoverageStore(ref.Store).InCoverage(ref.Name)
+	if err := p.templates(ctx, tc, "coveragestores/"+ref.Store+"/coverages/"+ref.Name, ref); err != nil {
+		return err
+	}
+	p.publishedBy(ref)
+	return nil
+}
+
+// publishedBy adds the layers publishing resource. A layer's resource
+// link names the resource ("ws:name"); a layer without one is matched
+// by name, GeoServer's default for published resources.
+func (p *deletePlanner) publishedBy(resource Ref) {
+	for name, l := range p.layers {
+		match := name == resource.Name
+		if l.Resource != nil && l.Resource.Name != "" {
+			match = l.Resource.Name == p.ws+":"+resource.Name || l.Resource.Name == resource.Name
+		}
+		if match {
+			p.layer(name, &resource)
+		}
+	}
+}
+
+func (p *deletePlanner) layer(name string, cause *Ref) {
+	lc := p.c.Layers.InWorkspace(p.ws)
+	p.add(Ref{Kind: KindLayer, Workspace: p.ws, Name: name}, cause, func(ctx context.Context) error {
+		return lc.Delete(ctx, name, layers.DeleteOptions{})
+	})
+}
+
+// templates adds the templates at one scope.
+func (p *deletePlanner) templates(ctx context.Context, tc *templates.Client, scope string, cause Ref) error {
+	refs, err := tc.List(ctx)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, t := range refs {
+		p.add(Ref{Kind: KindTemplate, Workspace: p.ws, Store: scope, Name: t.Name}, &cause, func(ctx context.Context) error {
+			return tc.Delete(ctx, t.Name)
+		})
+	}
+	return nil
+}
+
+// deleted reports whether the plan removes the layer or layer group
+// with the qualified name.
+func (p *deletePlanner) deleted(kind, qualified string) (Ref, bool) {
+	ws, name, _ := strings.Cut(qualified, ":")
+	ref := Ref{Kind: kind, Workspace: ws, Name: name}
+	n := p.nodes[ref]
+	return ref, n != nil && n.Action == DeleteRemove
+}
+
+// layerGroups plans a detach for every layer group referencing
+// deleted members, or a delete once none of its members survive.
+// Deleting a group can empty the groups that nest it, so this repeats
+// until nothing changes.
+func (p *deletePlanner) layerGroups() {
+	c := p.c
+	p.level = map[Ref]int{}
+	for changed := true; changed; {
+		changed = false
+		for _, pg := range p.groups {
+			ref := Ref{Kind: KindLayerGroup, Workspace: pg.ws, Name: pg.group.Name}
+			if n := p.nodes[ref]; n != nil && n.Action == DeleteRemove {
+				continue
+			}
+			var dropped []string
+			var causes []Ref
+			for _, m := range pg.group.Publishables.Published {
+				if cause, ok := p.member(pg.ws, m); ok {
+					dropped = append(dropped, qualifiedMember(pg.ws, m.Name))
+					causes = append(causes, cause)
+				}
+			}
+			if len(dropped) == 0 {
+				continue
+			}
+			if len(dropped) == len(pg.group.Publishables.Published) {
+				delete(p.nodes, ref)
+				gc := c.LayerGroups.InWorkspace(pg.ws)
+				name := pg.group.Name
+				for _, cause := range causes {
+					p.add(ref, &cause, func(ctx context.Context) error { return gc.Delete(ctx, name) })
+				}
+				changed = true
+				continue
+			}
+			n := &DeleteNode{Ref: ref, Action: DeleteDetach, Because: causes, Members: dropped, run: p.detach(pg, dropped)}
+			p.nodes[ref] = n
+		}
+	}
+
+	// A group must be handled before the groups it nests.
+	var depth func(ref Ref, seen map[Ref]bool) int
+	depth = func(ref Ref, seen map[Ref]bool) int {
+		if seen[ref] {
+			return 0
+		}
+		seen[ref] = true
+		d := 0
+		for _, pg := range p.groups {
+			outer := Ref{Kind: KindLayerGroup, Workspace: pg.ws, Name: pg.group.Name}
+			if p.nodes[outer] == nil {
+				continue
+			}
+			for _, m := range pg.group.Publishables.Published {
+				if m.Type == "layerGroup" && qualifiedMember(pg.ws, m.Name) == ref.Workspace+":"+ref.Name {
+					d = max(d, depth(outer, seen)+1)
+				}
+			}
+		}
+		return d
+	}
+	for ref := range p.nodes {
+		if ref.Kind == KindLayerGroup {
+			p.level[ref] = depth(ref, map[Ref]bool{})
+		}
+	}
+}
+
+// member reports whether a group member is being deleted, and which
+// node deletes it.
+func (p *deletePlanner) member(ws string, m layergroups.PublishedItem) (Ref, bool) {
+	kind := KindLayer
+	if m.Type == "layerGroup" {
+		kind = KindLayerGroup
+	}
+	return p.deleted(kind, qualifiedMember(ws, m.Name))
+}
+
+func qualifiedMember(ws, name string) string {
+	if strings.Contains(name, ":") {
+		return name
+	}
+	return ws + ":" + name
+}
+
+// detach returns the update dropping members from a group. The style
+// list runs parallel to the member list and is trimmed with it.
+func (p *deletePlanner) detach(pg plannedGroup, dropped []string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		g := *pg.group
+		g.Publishables.Published = nil
+		g.Styles.Style = nil
+		for i, m := range pg.group.Publishables.Published {
+			if slices.Contains(dropped, qualifiedMember(pg.ws, m.Name)) {
+				continue
+			}
+			g.Publishables.Published = append(g.Publishables.Published, m)
+			if i < len(pg.group.Styles.Style) {
+				g.Styles.Style = append(g.Styles.Style, pg.group.Styles.Style[i])
+			}
+		}
+		return p.c.LayerGroups.InWorkspace(pg.ws).Update(ctx, g.Name, &g)
+	}
+}
+
+// styles adds the workspace styles referenced by deleted layers and
+// groups and by nothing that survives.
+func (p *deletePlanner) styles() {
+	prefix := p.ws + ":"
+	used := map[string]bool{}
+	users := map[string][]Ref{}
+	note := func(style string, user Ref, gone bool) {
+		if !strings.HasPrefix(style, prefix) {
+			return
+		}
+		if gone {
+			users[style] = append(users[style], user)
+		} else {
+			used[style] = true
+		}
+	}
+	for name, l := range p.layers {
+		ref, gone := p.deleted(KindLayer, p.ws+":"+name)
+		if l.DefaultStyle != nil {
+			note(l.DefaultStyle.Name, ref, gone)
+		}
+		if l.Styles != nil {
+			for _, s := range l.Styles.Style {
+				note(s.Name, ref, gone)
+			}
+		}
+	}
+	for _, pg := range p.groups {
+		ref, gone := p.deleted(KindLayerGroup, pg.ws+":"+pg.group.Name)
+		for i, s := range pg.group.Styles.Style {
+			// A dropped member's style goes with it.
+			if i < len(pg.group.Publishables.Published) && !gone {
+				if member, ok := p.member(pg.ws, pg.group.Publishables.Published[i]); ok {
+					note(s.Name, member, true)
+					continue
+				}
+			}
+			note(s.Name, ref, gone)
+		}
+	}
+	sc := p.c.Styles.InWorkspace(p.ws)
+	for style, refs := range users {
+		if used[style] {
+			continue
+		}
+		name := strings.TrimPrefix(style, prefix)
+		for _, cause := range refs {
+			p.add(Ref{Kind: KindStyle, Workspace: p.ws, Name: name}, &cause, func(ctx context.Context) error {
+				return sc.Delete(ctx, name, styles.DeleteOptions{})
+			})
+		}
+	}
+}
+
+// tileLayers adds the GeoWebCache tile layers of deleted layers and
+// groups. A server without the GeoWebCache REST API has none.
+func (p *deletePlanner) tileLayers(ctx context.Context) error {
+	names, err := p.c.GWC.Layers().List(ctx)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		for _, kind := range []string{KindLayer, KindLayerGroup} {
+			cause, ok := p.deleted(kind, name)
+			if !ok {
+				continue
+			}
+			p.add(Ref{Kind: KindTileLayer, Workspace: cause.Workspace, Name: cause.Name}, &cause, func(ctx context.Context) error {
+				return p.c.GWC.Layers().Delete(ctx, name)
+			})
+		}
+	}
+	return nil
+}
+
+// aclRules adds the layer ACL rules naming a deleted layer or group.
+func (p *deletePlanner) aclRules(ctx context.Context) error {
+	rules, err := p.c.ACL.Layers().List(ctx, acl.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		for _, kind := range []string{KindLayer, KindLayerGroup} {
+			cause, ok := p.deleted(kind, r.Workspace+":"+r.Layer)
+			if !ok {
+				continue
+			}
+			encoded, _ := r.Encode()
+			p.add(Ref{Kind: KindACLRule, Name: encoded}, &cause, func(ctx context.Context) error {
+				return p.c.ACL.Layers().Delete(ctx, r)
+			})
+		}
+	}
+	return nil
+}
+
+// deleteRank orders kinds for execution: dependents first.
+var deleteRank = []string{
+	KindACLRule, KindTileLayer, KindLayerGroup, KindLayer, KindStyle,
+	KindTemplate, KindFeatureType, KindCoverage, KindDatastore, KindCoverageStore,
+}
+
+func (p *deletePlanner) ordered() []DeleteNode {
+	refs := slices.Collect(maps.Keys(p.nodes))
+	slices.SortFunc(refs, func(a, b Ref) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(deleteRank, a.Kind), slices.Index(deleteRank, b.Kind)),
+			cmp.Compare(p.level[a], p.level[b]),
+			cmp.Compare(a.String(), b.String()),
+		)
+	})
+	out := make([]DeleteNode, len(refs))
+	for i, ref := range refs {
+		out[i] = *p.nodes[ref]
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package geoserver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/gwc"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+)
+
+// deleteCatalog builds workspace "topp" with datastore "pg" (roads,
+// rivers) and datastore "other" (parks). Style "roads" is used only by
+// roads, "shared" by roads and parks. Group "base" holds roads and
+// rivers, "mixed" roads and parks, and "outer" nests base. opts are
+// passed to [geoservertest.Server.Client].
+func deleteCatalog(t *testing.T, opts ...geoserver.Option) *geoserver.Client {
+	t.Helper()
+	srv := geoservertest.New(t, geoservertest.Options{})
+	srv.Seed(geoservertest.Fixture{"topp": {
+		Datastores: map[string][]string{"pg": {"roads", "rivers"}, "other": {"parks"}},
+		Styles:     []string{"roads", "shared"},
+	}})
+	c, must := srv.Client(opts...), srv.Must
+	ctx := context.Background()
+	lc := c.Layers.InWorkspace("topp")
+	must("layer", lc.Update(ctx, "roads", &layers.Layer{
+		DefaultStyle: &layers.Ref{Name: "topp:roads"}, Styles: &layers.Styles{Style: []layers.Ref{{Name: "topp:shared"}}},
+	}))
+	must("layer", lc.Update(ctx, "parks", &layers.Layer{DefaultStyle: &layers.Ref{Name: "topp:shared"}}))
+	gc := c.LayerGroups.InWorkspace("topp")
+	group := func(name string, members ...layergroups.PublishedItem) *layergroups.LayerGroup {
+		return &layergroups.LayerGroup{Name: name, Publishables: layergroups.Publishables{Published: members}}
+	}
+	must("group", gc.Create(ctx, group("base", layergroups.PublishedItem{Type: "layer", Name: "topp:roads"}, layergroups.PublishedItem{Type: "layer", Name: "topp:rivers"})))
+	mixed := group("mixed", layergroups.PublishedItem{Type: "layer", Name: "topp:roads"}, layergroups.PublishedItem{Type: "layer", Name: "topp:parks"})
+	mixed.Styles.Style = []layergroups.Ref{{Name: "topp:roads"}, {Name: ""}}
+	must("group", gc.Create(ctx, mixed))
+	must("group", gc.Create(ctx, group("outer", layergroups.PublishedItem{Type: "layerGroup", Name: "topp:base"})))
+	must("acl", c.ACL.Layers().Add(ctx, acl.Rule{Workspace: "topp", Layer: "roads", Operation: acl.OpRead, Roles: []string{"ROLE_A"}}))
+	must("acl", c.ACL.Layers().Add(ctx, acl.Rule{Workspace: "topp", Layer: "parks", Operation: acl.OpRead, Roles: []string{"ROLE_A"}}))
+	must("tile layer", c.GWC.Layers().Put(ctx, "topp:roads", &gwc.LayerConfig{Name: "topp:roads", Enabled: true}))
+	must("template", c.Templates.InWorkspace("topp").InDatastore("pg").PutString(ctx, "header", "<h1/>"))
+	must("template", c.Templates.InWorkspace("topp").InDatastore("pg").InFeatureType("roads").PutString(ctx, "title", "${name}"))
+	return c
+}
+
+func TestCatalog_DeletePlan(t *testing.T) {
+	ctx := context.Background()
+	c := deleteCatalog(t)
+	ref := geoserver.Ref{Kind: geoserver.KindDatastore, Workspace: "topp", Name: "pg"}
+	plan, err := c.Catalog().DeletePlan(ctx, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"- aclrule topp.roads.r ← layer topp:roads",
+		"- tilelayer topp:roads ← layer topp:roads",
+		"~ layergroup topp:mixed (drop topp:roads) ← layer topp:roads",
+		"- layergroup topp:outer ← layergroup topp:base",
+		"- layergroup topp:base ← layer topp:roads, layer topp:rivers",
+		"- layer topp:rivers ← featuretype topp:pg:rivers",
+		"- layer topp:roads ← featuretype topp:pg:roads",
+		"- style topp:roads ← layer topp:roads",
+		"- template topp/datastores/pg/featuretypes/roads/title.ftl ← featuretype topp:pg:roads",
+		"- template topp/datastores/pg/header.ftl ← datastore topp:pg",
+		"- featuretype topp:pg:rivers ← datastore topp:pg",
+		"- featuretype topp:pg:roads ← datastore topp:pg",
+		"- datastore topp:pg",
+	}
+	if got := strings.Split(strings.TrimSpace(plan.String()), "\n")[1:]; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("plan:\n%s\nwant:\n%s", plan, strings.Join(want, "\n"))
+	}
+	if plan.Count(geoserver.KindLayer) != 2 || plan.Nodes[len(plan.Nodes)-1].Ref != ref {
+		t.Fatalf("plan = %+v", plan)
+	}
+
+	if err := plan.Execute(ctx); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if _, err := c.Datastores.InWorkspace("topp").Get(ctx, "pg"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("datastore after Execute: %v", err)
+	}
+	g, err := c.LayerGroups.InWorkspace("topp").Get(ctx, "mixed")
+	if err != nil || len(g.Publishables.Published) != 1 || g.Publishables.Published[0].Name != "topp:parks" {
+		t.Fatalf("mixed = %+v, %v", g, err)
+	}
+	if _, err := c.Styles.InWorkspace("topp").Get(ctx, "shared"); err != nil {
+		t.Fatalf("shared style: %v", err)
+	}
+	rules, err := c.ACL.Layers().List(ctx, acl.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var layerRules []string
+	for _, r := range rules {
+		if r.Workspace == "topp" {
+			layerRules = append(layerRules, r.Layer)
+		}
+	}
+	if len(layerRules) != 1 || layerRules[0] != "parks" {
+		t.Fatalf("topp rules = %v, want only parks", layerRules)
+	}
+}
+
+func TestCatalog_DeletePlanLayer(t *testing.T) {
+	ctx := context.Background()
+	c := deleteCatalog(t)
+	plan, err := c.Catalog().DeletePlan(ctx, geoserver.Ref{Kind: geoserver.KindLayer, Workspace: "topp", Name: "parks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Group "mixed" keeps roads; "shared" is still used by roads.
+	if len(plan.Nodes) != 3 || plan.Nodes[1].Action != geoserver.DeleteDetach || plan.Count(geoserver.KindStyle) != 0 {
+		t.Fatalf("plan:\n%s", plan)
+	}
+	if err := plan.Execute(ctx); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if _, err := c.FeatureTypes.InWorkspace("topp").InDatastore("other").Get(ctx, "parks"); err != nil {
+		t.Fatalf("feature type deleted with its layer: %v", err)
+	}
+}
+
+func TestCatalog_DeletePlanErrors(t *testing.T) {
+	ctx := context.Background()
+	c := deleteCatalog(t)
+	if _, err := c.Catalog().DeletePlan(ctx, geoserver.Ref{Kind: geoserver.KindDatastore, Workspace: "topp", Name: "nope"}); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("missing datastore = %v, want ErrNotFound", err)
+	}
+	if _, err := c.Catalog().DeletePlan(ctx, geoserver.Ref{Kind: geoserver.KindWorkspace, Workspace: "topp", Name: "topp"}); err == nil {
+		t.Fatal("unsupported kind accepted")
+	}
+	if _, err := c.Catalog().DeletePlan(ctx, geoserver.Ref{Kind: geoserver.KindLayer, Name: "roads"}); err == nil {
+		t.Fatal("empty workspace accepted")
+	}
+	if _, err := c.Catalog().DeletePlan(ctx, geoserver.Ref{Kind: geoserver.KindLayer, Workspace: "nope", Name: "roads"}); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("missing workspace = %v, want ErrNotFound", err)
+	}
+
+	// A plan over a partial crawl could miss dependents, so any crawl
+	// failure fails it.
+	c = deleteCatalog(t, geoserver.WithTransport(failPath{fail: "/layergroups/outer", next: http.DefaultTransport}))
+	var invErr *geoserver.InventoryError
+	if _, err := c.Catalog().DeletePlan(ctx, geoserver.Ref{Kind: geoserver.KindDatastore, Workspace: "topp", Name: "pg"}); !errors.As(err, &invErr) {
+		t.Fatalf("plan over a failed crawl = %v, want *InventoryError", err)
+	}
+}
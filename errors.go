@@ -1,9 +1,16 @@
 package geoserver
 
 import (
+	"bytes"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"html"
 	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hishamkaram/geoserver/v2/internal/transport"
 )
 
 // Sentinel errors. APIError.Is wraps these so callers can match status
@@ -27,6 +34,29 @@ var (
 	ErrGatewayTimeout       = errors.New("geoserver: gateway timeout")
 )
 
+// Semantic sentinels. Unlike the status sentinels above these are
+// derived from the parsed error message, because GeoServer reports
+// the same failure under different status codes across endpoints and
+// versions — a duplicate Create is a 409 on some resources and a 500
+// "already exists" on others. An *APIError matching one of these also
+// still matches its status sentinel.
+var (
+	// ErrAlreadyExists matches a Create rejected because a resource
+	// with the same name already exists.
+	ErrAlreadyExists = errors.New("geoserver: already exists")
+
+	// ErrStillReferenced matches a Delete rejected because other
+	// catalog objects still depend on the target (a style used by a
+	// layer, a non-empty store or workspace without recurse).
+	ErrStillReferenced = errors.New("geoserver: still referenced")
+)
+
+// ErrResponseTooLarge is wrapped by the error returned when a 2xx
+// response body exceeds the limit set with [WithMaxResponseBytes]. It
+// is a client-side failure, not an [*APIError] — the server answered
+// successfully, the client refused to buffer the whole payload.
+var ErrResponseTooLarge = transport.ErrResponseTooLarge
+
 // statusToSentinel maps HTTP status codes to package sentinel errors.
 var statusToSentinel = map[int]error{
 	http.StatusBadRequest:           ErrBadRequest,
@@ -65,8 +95,15 @@ type APIError struct {
 	// Body is the response body, truncated to a fixed cap (8 KiB)
 	// to avoid unbounded retention. Useful for diagnostics; do not
 	// parse for control flow — use errors.Is against the package
-	// sentinels instead.
+	// sentinels, or the parsed [APIError.Message],
+	// [APIError.ExceptionCode] and [APIError.Locator] accessors.
 	Body []byte
+
+	// Parsed from Body at construction; see parseErrorBody.
+	message       string
+	exceptionCode string
+	locator       string
+	semantic      error
 }
 
 // Error returns a stable, parseable message of the form
@@ -103,15 +140,34 @@ func (e *APIError) Unwrap() error {
 func (e *APIError) HTTPStatusCode() int { return e.StatusCode }
 
 // Is reports whether target matches the sentinel for this APIError's
-// status code. Lets callers use errors.Is(err, ErrNotFound) directly on
-// an *APIError.
+// status code, or the semantic sentinel ([ErrAlreadyExists],
+// [ErrStillReferenced]) derived from its message. Lets callers use
+// errors.Is(err, ErrNotFound) directly on an *APIError.
 func (e *APIError) Is(target error) bool {
 	if sentinel, ok := statusToSentinel[e.StatusCode]; ok && sentinel == target {
 		return true
 	}
-	return false
+	return e.semantic != nil && e.semantic == target
 }
 
+// Message returns the human-readable error message GeoServer sent,
+// extracted from whichever body shape came back: the ExceptionText of
+// an OWS ExceptionReport, the text of a WMS ServiceExceptionReport,
+// the message paragraph of an HTML error page, or the first line of a
+// plain-text / Java exception body with the exception class stripped.
+// Empty if the body was empty.
+func (e *APIError) Message() string { return e.message }
+
+// ExceptionCode returns the OGC exceptionCode (OWS ExceptionReport)
+// or code (WMS ServiceExceptionReport) attribute — e.g.,
+// "InvalidParameterValue", "LayerNotDefined". Empty for REST errors,
+// which don't carry one.
+func (e *APIError) ExceptionCode() string { return e.exceptionCode }
+
+// Locator returns the OGC locator attribute naming the offending
+// request parameter (e.g., "typeName"). Empty when not reported.
+func (e *APIError) Locator() string { return e.locator }
+
 // newAPIError constructs an *APIError, truncating the body to bodyCap.
 const bodyCap = 8 << 10 // 8 KiB
 
@@ -119,11 +175,123 @@ func newAPIError(op, method, url string, statusCode int, body []byte) *APIError
 	if len(body) > bodyCap {
 		body = body[:bodyCap]
 	}
-	return &APIError{
+	e := &APIError{
 		Op:         op,
 		URL:        url,
 		Method:     method,
 		StatusCode: statusCode,
 		Body:       body,
 	}
+	e.message, e.exceptionCode, e.locator = parseErrorBody(body)
+	e.semantic = semanticSentinel(statusCode, e.message)
+	return e
+}
+
+// owsExceptionReport covers both OGC exception shapes GeoServer emits:
+// OWS 1.x `<ows:ExceptionReport><ows:Exception exceptionCode locator>
+// <ows:ExceptionText>` (WFS 1.1+/2.0, WCS, WMTS) and WMS 1.1.1/1.3.0
+// `<ServiceExceptionReport><ServiceException code locator>text`.
+// Matching is on local names so the namespace prefix doesn't matter.
+type owsExceptionReport struct {
+	XMLName    xml.Name
+	Exceptions []struct {
+		Code    string   `xml:"exceptionCode,attr"`
+		Locator string   `xml:"locator,attr"`
+		Texts   []string `xml:"ExceptionText"`
+	} `xml:"Exception"`
+	ServiceExceptions []struct {
+		Code    string `xml:"code,attr"`
+		Locator string `xml:"locator,attr"`
+		Text    string `xml:",chardata"`
+	} `xml:"ServiceException"`
+}
+
+var (
+	// javaExceptionPrefix matches a leading fully-qualified Java
+	// exception class, e.g. "java.lang.IllegalArgumentException: ".
+	javaExceptionPrefix = regexp.MustCompile(`^(?:[a-z_$][\w$]*\.)+[A-Z][\w$]*(?:Exception|Error):\s*`)
+	htmlTag             = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlTitle           = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	// tomcatMessage matches the "<b>Message</b> …</p>" paragraph of
+	// Tomcat's default error page.
+	tomcatMessage = regexp.MustCompile(`(?is)<b>\s*(?:Message|Description)\s*</b>(.*?)</p>`)
+)
+
+// parseErrorBody extracts message / exceptionCode / locator from an
+// error body. Best-effort: unrecognized shapes fall back to the first
+// non-empty line of text.
+func parseErrorBody(body []byte) (message, code, locator string) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return "", "", ""
+	}
+	if trimmed[0] == '<' {
+		var report owsExceptionReport
+		if xml.Unmarshal(trimmed, &report) == nil {
+			switch {
+			case len(report.Exceptions) > 0:
+				ex := report.Exceptions[0]
+				return collapseSpace(strings.Join(ex.Texts, " ")), ex.Code, ex.Locator
+			case len(report.ServiceExceptions) > 0:
+				ex := report.ServiceExceptions[0]
+				return collapseSpace(ex.Text), ex.Code, ex.Locator
+			}
+		}
+		if m := tomcatMessage.FindSubmatch(trimmed); m != nil {
+			if msg := htmlText(m[1]); msg != "" {
+				return msg, "", ""
+			}
+		}
+		if m := htmlTitle.FindSubmatch(trimmed); m != nil {
+			if msg := htmlText(m[1]); msg != "" {
+				return msg, "", ""
+			}
+		}
+		return htmlText(trimmed), "", ""
+	}
+	line, _, _ := strings.Cut(string(trimmed), "\n")
+	line = strings.TrimSpace(line)
+	line = javaExceptionPrefix.ReplaceAllString(line, "")
+	return line, "", ""
+}
+
+func htmlText(b []byte) string {
+	return collapseSpace(html.UnescapeString(htmlTag.ReplaceAllString(string(b), " ")))
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// stillReferenced matches the messages GeoServer answers a delete of
+// an object that still has dependants with: "Unable to delete
+// non-empty workspace.", "Workspace 'topp' not empty", "datastore not
+// empty", "coveragestore not empty", "Layer
+// 'topp:roads' is referenced by layer group 'base'", "Can't delete
+// style referenced by existing layers.". They are anchored so that an
+// unrelated 4xx mentioning "non-empty" (a validation message such as
+// "name must be non-empty") is not mistaken for one.
+var stillReferenced = []*regexp.Regexp{
+	regexp.MustCompile(`^unable to delete non-empty \w`),
+	regexp.MustCompile(`^(?:workspace|namespace|datastore|data store|coverage ?store|store) (?:\S+ )?(?:is )?not empty\b`),
+	regexp.MustCompile(`^\w+ '[^']*' is referenced by (?:layer|layer group|layergroup)\b`),
+	regexp.MustCompile(`^(?:unable to|can't|cannot) delete style\b.*\breferenced by\b`),
+}
+
+// semanticSentinel classifies a parsed error message into one of the
+// semantic sentinels, or nil.
+func semanticSentinel(status int, message string) error {
+	if status < 400 || message == "" {
+		return nil
+	}
+	m := strings.ToLower(message)
+	if strings.Contains(m, "already exists") {
+		return ErrAlreadyExists
+	}
+	for _, re := range stillReferenced {
+		if re.MatchString(m) {
+			return ErrStillReferenced
+		}
+	}
+	return nil
 }
@@ -0,0 +1,192 @@
+package geoserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/ows/wfs"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// errorServer answers every request with the given status and body.
+func errorServer(t *testing.T, status int, contentType, body string) *geoserver.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := geoserver.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestAPIError_ParsedFields(t *testing.T) {
+	cases := []struct {
+		name, contentType, body        string
+		status                         int
+		wantMsg, wantCode, wantLocator string
+	}{
+		{
+			name:        "plain text",
+			status:      http.StatusInternalServerError,
+			contentType: "text/plain",
+			body:        "Workspace named 'topp' already exists.",
+			wantMsg:     "Workspace named 'topp' already exists.",
+		},
+		{
+			name:        "java exception with stack",
+			status:      http.StatusInternalServerError,
+			contentType: "text/plain",
+			body:        "java.lang.IllegalArgumentException: Store 'nyc' already exists in workspace 'topp'\n\tat org.geoserver.catalog.impl.CatalogImpl.validate(CatalogImpl.java:123)\n",
+			wantMsg:     "Store 'nyc' already exists in workspace 'topp'",
+		},
+		{
+			name:        "ows exception report",
+			status:      http.StatusBadRequest,
+			contentType: "application/xml",
+			body: `<?xml version="1.0" encoding="UTF-8"?>
+<ows:ExceptionReport xmlns:ows="http://www.opengis.net/ows/1.1" version="2.0.0">
+  <ows:Exception exceptionCode="InvalidParameterValue" locator="typeName">
+    <ows:ExceptionText>Feature type topp:nope unknown</ows:ExceptionText>
+  </ows:Exception>
+</ows:ExceptionReport>`,
+			wantMsg:     "Feature type topp:nope unknown",
+			wantCode:    "InvalidParameterValue",
+			wantLocator: "typeName",
+		},
+		{
+			name:        "wms service exception",
+			status:      http.StatusBadRequest,
+			contentType: "application/vnd.ogc.se_xml",
+			body: `<ServiceExceptionReport version="1.1.1">
+  <ServiceException code="LayerNotDefined" locator="layers">
+      Could not find layer topp:nope
+  </ServiceException>
+</ServiceExceptionReport>`,
+			wantMsg:     "Could not find layer topp:nope",
+			wantCode:    "LayerNotDefined",
+			wantLocator: "layers",
+		},
+		{
+			name:        "tomcat html",
+			status:      http.StatusInternalServerError,
+			contentType: "text/html",
+			body:        `<!doctype html><html><head><title>HTTP Status 500 – Internal Server Error</title></head><body><h1>HTTP Status 500</h1><p><b>Message</b> Request processing failed &amp; aborted</p></body></html>`,
+			wantMsg:     "Request processing failed & aborted",
+		},
+		{
+			name:        "html title only",
+			status:      http.StatusBadGateway,
+			contentType: "text/html",
+			body:        `<html><head><title>502 Bad Gateway</title></head><body><center><h1>502 Bad Gateway</h1></center></body></html>`,
+			wantMsg:     "502 Bad Gateway",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := errorServer(t, tc.status, tc.contentType, tc.body)
+			_, err := c.Workspaces.Get(context.Background(), "topp")
+			var apiErr *geoserver.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected *APIError, got %T: %v", err, err)
+			}
+			if apiErr.Message() != tc.wantMsg {
+				t.Errorf("Message() = %q, want %q", apiErr.Message(), tc.wantMsg)
+			}
+			if apiErr.ExceptionCode() != tc.wantCode {
+				t.Errorf("ExceptionCode() = %q, want %q", apiErr.ExceptionCode(), tc.wantCode)
+			}
+			if apiErr.Locator() != tc.wantLocator {
+				t.Errorf("Locator() = %q, want %q", apiErr.Locator(), tc.wantLocator)
+			}
+		})
+	}
+}
+
+func TestAPIError_DoXMLPopulatesException(t *testing.T) {
+	c := errorServer(t, http.StatusBadRequest, "application/xml",
+		`<ows:ExceptionReport xmlns:ows="http://www.opengis.net/ows"><ows:Exception exceptionCode="MissingParameterValue" locator="service"><ows:ExceptionText>No service</ows:ExceptionText></ows:Exception></ows:ExceptionReport>`)
+	_, err := c.WFS.GetCapabilities(context.Background(), wfs.GetCapabilitiesOptions{})
+	var apiErr *geoserver.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.ExceptionCode() != "MissingParameterValue" || apiErr.Locator() != "service" || apiErr.Message() != "No service" {
+		t.Fatalf("parsed = %q / %q / %q", apiErr.ExceptionCode(), apiErr.Locator(), apiErr.Message())
+	}
+}
+
+func TestAPIError_SemanticSentinels(t *testing.T) {
+	t.Run("already exists", func(t *testing.T) {
+		c := errorServer(t, http.StatusInternalServerError, "text/plain", "Store 'nyc' already exists in workspace 'topp'")
+		err := c.Workspaces.Create(context.Background(), &workspaces.Workspace{Name: "topp"})
+		if !errors.Is(err, geoserver.ErrAlreadyExists) {
+			t.Fatalf("expected ErrAlreadyExists, got %v", err)
+		}
+		if !errors.Is(err, geoserver.ErrServerError) {
+			t.Fatalf("status sentinel lost: %v", err)
+		}
+		if errors.Is(err, geoserver.ErrStillReferenced) {
+			t.Fatalf("unexpected ErrStillReferenced")
+		}
+	})
+	t.Run("still referenced", func(t *testing.T) {
+		c := errorServer(t, http.StatusConflict, "text/plain", "Unable to delete style 'roads': style is referenced by layer topp:roads")
+		err := c.Workspaces.Delete(context.Background(), "topp", workspaces.DeleteOptions{})
+		if !errors.Is(err, geoserver.ErrStillReferenced) || !errors.Is(err, geoserver.ErrConflict) {
+			t.Fatalf("expected ErrStillReferenced + ErrConflict, got %v", err)
+		}
+	})
+	t.Run("non-empty workspace", func(t *testing.T) {
+		c := errorServer(t, http.StatusForbidden, "text/plain", "Unable to delete non-empty workspace.")
+		err := c.Workspaces.Delete(context.Background(), "topp", workspaces.DeleteOptions{})
+		if !errors.Is(err, geoserver.ErrStillReferenced) {
+			t.Fatalf("expected ErrStillReferenced, got %v", err)
+		}
+	})
+	for _, msg := range []string{
+		"Workspace 'topp' not empty",
+		"datastore not empty",
+		"coveragestore not empty",
+		"Layer 'topp:roads' is referenced by layer group 'base'",
+		"Can't delete style referenced by existing layers.",
+	} {
+		t.Run(msg, func(t *testing.T) {
+			c := errorServer(t, http.StatusForbidden, "text/plain", msg)
+			err := c.Workspaces.Delete(context.Background(), "topp", workspaces.DeleteOptions{})
+			if !errors.Is(err, geoserver.ErrStillReferenced) {
+				t.Fatalf("expected ErrStillReferenced, got %v", err)
+			}
+		})
+	}
+	for _, msg := range []string{
+		"Attribute 'name' must be non-empty",
+		"Parameter 'bbox' is not empty but malformed",
+		"Invalid filter: property referenced by the sort order does not exist",
+		"Lock still in use by another transaction",
+	} {
+		t.Run("not referenced/"+msg, func(t *testing.T) {
+			c := errorServer(t, http.StatusBadRequest, "text/plain", msg)
+			err := c.Workspaces.Create(context.Background(), &workspaces.Workspace{Name: "topp"})
+			if errors.Is(err, geoserver.ErrStillReferenced) || !errors.Is(err, geoserver.ErrBadRequest) {
+				t.Fatalf("%q classified as %v", msg, err)
+			}
+		})
+	}
+	t.Run("unrelated", func(t *testing.T) {
+		c := errorServer(t, http.StatusInternalServerError, "text/plain", "NullPointerException")
+		_, err := c.Workspaces.Get(context.Background(), "topp")
+		if errors.Is(err, geoserver.ErrAlreadyExists) || errors.Is(err, geoserver.ErrStillReferenced) {
+			t.Fatalf("unexpected semantic sentinel on %v", err)
+		}
+	})
+}
@@ -1,7 +1,7 @@
-// publish-postgis is a runnable v2 example: end-to-end flow that creates
-// a workspace, registers a PostGIS datastore, publishes a feature type,
-// and reads the resulting layer back. Demonstrates the hierarchical
-// sub-clients (InWorkspace, InDatastore).
+// publish-postgis is a runnable v2 example: publishes a PostGIS table
+// with [publish.PostGISTable], which creates the workspace, datastore,
+// feature type and layer, then inspects the result through the
+// hierarchical sub-clients (InWorkspace, InDatastore).
 //
 // Requires the make-compose-up PostGIS stack with the lbldyt table
 // pre-loaded (docker/postgis/init/01-lbldyt.sql).
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/publish"
 	"github.com/hishamkaram/geoserver/v2/rest/datastores"
 	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
 	"github.com/hishamkaram/geoserver/v2/rest/layers"
@@ -58,56 +59,44 @@ func main() {
 	// Best-effort cleanup from a previous run, in workspace-recurse order.
 	_ = c.Workspaces.Delete(ctx, workspace, workspaces.DeleteOptions{Recurse: true})
 
-	// 1. Workspace.
-	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: workspace}); err != nil {
-		fatal("create workspace: %v", err)
+	// 1. Workspace, PostGIS datastore, feature type and layer in one
+	// idempotent call. Running it again changes nothing.
+	res, err := publish.PostGISTable(ctx, c, publish.PublishPostGISRequest{
+		Workspace: workspace,
+		Store:     datastore,
+		PostGIS: datastores.PostGIS{
+			Host:     dbHost,
+			Port:     dbPort,
+			Database: dbName,
+			User:     dbUser,
+			Password: dbPass,
+		},
+		// Native table name — must exist in the PostGIS DB.
+		Table: nativeTable,
+		Layer: featureType,
+		SRS:   "EPSG:4326",
+	})
+	if err != nil {
+		fatal("publish: %v", err)
 	}
-	fmt.Printf("created workspace %q\n", workspace)
-
-	// 2. PostGIS datastore.
-	if err := c.Datastores.InWorkspace(workspace).Create(ctx, datastores.PostGIS{
-		Name:     datastore,
-		Host:     dbHost,
-		Port:     dbPort,
-		Database: dbName,
-		User:     dbUser,
-		Password: dbPass,
-	}); err != nil {
-		fatal("create datastore: %v", err)
+	for _, step := range res.Steps {
+		fmt.Println(step)
 	}
-	fmt.Printf("created PostGIS datastore %q in %q\n", datastore, workspace)
+	fmt.Printf("layer: name=%q type=%q resource=%q queryable=%t\n",
+		res.Layer.Name, res.Layer.Type,
+		safeName(res.Layer.Resource),
+		res.Layer.Queryable)
+	fmt.Printf("WMS: %s\nWFS: %s\n", res.WMS, res.WFS)
 
-	// 3. Discover available tables (should include nativeTable).
+	// 2. Discover the other tables the store could publish.
 	available, err := c.FeatureTypes.InWorkspace(workspace).InDatastore(datastore).
 		Discover(ctx, featuretypes.DiscoverOptions{Kind: featuretypes.DiscoverAvailableWithGeometry})
 	if err != nil {
 		fatal("discover tables: %v", err)
 	}
-	fmt.Printf("discovered %d available tables: %v\n", len(available), available)
-
-	// 4. Publish the feature type. NativeName must match the DB table.
-	if err := c.FeatureTypes.InWorkspace(workspace).InDatastore(datastore).
-		Create(ctx, &featuretypes.FeatureType{
-			Name:       featureType,
-			NativeName: nativeTable,
-			SRS:        "EPSG:4326",
-			Enabled:    true,
-		}); err != nil {
-		fatal("publish feature type: %v", err)
-	}
-	fmt.Printf("published feature type %q (native %q)\n", featureType, nativeTable)
-
-	// 5. Fetch the auto-created layer back.
-	layer, err := c.Layers.InWorkspace(workspace).Get(ctx, featureType)
-	if err != nil {
-		fatal("fetch layer: %v", err)
-	}
-	fmt.Printf("layer: name=%q type=%q resource=%q queryable=%t\n",
-		layer.Name, layer.Type,
-		safeName(layer.Resource),
-		layer.Queryable)
+	fmt.Printf("discovered %d unpublished tables: %v\n", len(available), available)
 
-	// 6. Inspect the feature type document — verify the geometry column was discovered.
+	// 3. Inspect the feature type document — verify the geometry column was discovered.
 	ft, err := c.FeatureTypes.InWorkspace(workspace).InDatastore(datastore).
 		Get(ctx, featureType)
 	if err != nil {
@@ -124,7 +113,7 @@ func main() {
 	}
 	fmt.Printf("feature type has %s column: %t\n", expectedAttr, hasGeom)
 
-	// 7. Cleanup. Recurse=true through the workspace cleans the whole tree.
+	// 4. Cleanup. Recurse=true through the workspace cleans the whole tree.
 	if err := c.Workspaces.Delete(ctx, workspace, workspaces.DeleteOptions{Recurse: true}); err != nil {
 		// Don't error out — the example has already done its job.
 		fmt.Fprintf(os.Stderr, "warn: cleanup failed: %v\n", err)
@@ -7,8 +7,6 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
-	"net/url"
-	"strings"
 	"time"
 
 	"github.com/hishamkaram/geoserver/v2/internal/transport"
@@ -138,16 +136,16 @@ type Client struct {
 	// (store / raster / schema caches). Both require admin auth.
 	System *system.Client
 
-	// WMS is the entry point for WMS service operations — currently
-	// GetCapabilities (XML, decoded into [wms.Capabilities]). Use
-	// [wms.Client.InWorkspace] for a workspace-scoped capabilities
-	// document.
+	// WMS is the entry point for WMS service operations —
+	// GetCapabilities (XML, decoded into [wms.Capabilities]), GetMap,
+	// GetFeatureInfo and GetLegendGraphic. Use
+	// [wms.Client.InWorkspace] for the workspace-scoped endpoint.
 	WMS *wms.Client
 
-	// WFS is the entry point for WFS service operations — currently
-	// GetCapabilities (XML, decoded into [wfs.Capabilities]). Use
-	// [wfs.Client.InWorkspace] for a workspace-scoped capabilities
-	// document.
+	// WFS is the entry point for WFS service operations —
+	// GetCapabilities (XML, decoded into [wfs.Capabilities]),
+	// DescribeFeatureType and GetFeature. Use
+	// [wfs.Client.InWorkspace] for the workspace-scoped endpoint.
 	WFS *wfs.Client
 
 	// WCS is the entry point for WCS service operations — currently
@@ -253,6 +251,18 @@ type clientCore struct {
 	baseURL    string // ends with "/"
 	httpClient *http.Client
 	logger     *slog.Logger
+
+	// maxResponseBytes caps 2xx body reads; zero means the transport
+	// default. See [WithMaxResponseBytes].
+	maxResponseBytes int64
+
+	// observer is nil unless [WithObserver] was supplied.
+	observer Observer
+
+	// owsBaseURL and gwcBaseURL are the [WithOWSBaseURL] and
+	// [WithGWCBaseURL] overrides, or "" for baseURL.
+	owsBaseURL string
+	gwcBaseURL string
 }
 
 // New constructs an immutable [*Client] for the GeoServer instance at
@@ -264,15 +274,9 @@ type clientCore struct {
 // parse — misconfiguration surfaces immediately rather than at
 // first-call time.
 func New(serverURL string, opts ...Option) (*Client, error) {
-	if serverURL == "" {
-		return nil, errors.New("geoserver: empty server URL")
-	}
-	parsed, err := url.Parse(serverURL)
+	base, err := normalizeBaseURL(serverURL)
 	if err != nil {
-		return nil, fmt.Errorf("geoserver: parse server URL: %w", err)
-	}
-	if parsed.Scheme != "http" && parsed.Scheme != "https" {
-		return nil, fmt.Errorf("geoserver: server URL scheme must be http or https, got %q", parsed.Scheme)
+		return nil, fmt.Errorf("geoserver: server URL: %w", err)
 	}
 
 	cfg := &clientConfig{
@@ -288,19 +292,35 @@ func New(serverURL string, opts ...Option) (*Client, error) {
 
 	httpClient := buildHTTPClient(cfg)
 
-	base := serverURL
-	if !strings.HasSuffix(base, "/") {
-		base += "/"
-	}
-
 	core := &clientCore{
-		baseURL:    base,
-		httpClient: httpClient,
-		logger:     cfg.logger,
+		baseURL:          base,
+		httpClient:       httpClient,
+		logger:           cfg.logger,
+		maxResponseBytes: cfg.maxResponseBytes,
+		owsBaseURL:       cfg.owsBaseURL,
+		gwcBaseURL:       cfg.gwcBaseURL,
 	}
+	switch len(cfg.observers) {
+	case 0:
+	case 1:
+		core.observer = cfg.observers[0]
+	default:
+		core.observer = multiObserver(cfg.observers)
+	}
+	return newClient(core), nil
+}
 
+// newClient builds a [*Client] and its sub-clients around core.
+func newClient(core *clientCore) *Client {
 	c := &Client{core: core}
-	adapter := coreAdapter{core: core}
+	adapter := coreAdapter{core: core, baseURL: core.baseURL}
+	owsAdapter, gwcAdapter := adapter, adapter
+	if core.owsBaseURL != "" {
+		owsAdapter.baseURL = core.owsBaseURL
+	}
+	if core.gwcBaseURL != "" {
+		gwcAdapter.baseURL = core.gwcBaseURL
+	}
 	c.Workspaces = workspaces.New(adapter)
 	c.Datastores = datastores.New(adapter)
 	c.FeatureTypes = featuretypes.New(adapter)
@@ -315,11 +335,11 @@ func New(serverURL string, opts ...Option) (*Client, error) {
 	c.Security = security.New(adapter)
 	c.ACL = acl.New(adapter)
 	c.System = system.New(adapter)
-	c.WMS = wms.New(adapter)
-	c.WFS = wfs.New(adapter)
-	c.WCS = wcs.New(adapter)
+	c.WMS = wms.New(owsAdapter)
+	c.WFS = wfs.New(owsAdapter)
+	c.WCS = wcs.New(owsAdapter)
 	c.Services = services.New(adapter)
-	c.GWC = gwc.New(adapter)
+	c.GWC = gwc.New(gwcAdapter)
 	c.Imports = imports.New(adapter)
 	c.Resources = resources.New(adapter)
 	c.Templates = templates.New(adapter)
@@ -332,15 +352,17 @@ func New(serverURL string, opts ...Option) (*Client, error) {
 	c.Logging = logging.New(adapter)
 	c.Fonts = fonts.New(adapter)
 	c.Monitor = monitor.New(adapter)
-	return c, nil
+	return c
 }
 
 // buildHTTPClient resolves the user's transport options into a single
 // *http.Client whose Transport stack is:
 //
 //	HeaderRoundTripper(user-agent + extra headers) →
-//	    AuthRoundTripper(basic | bearer | none) →
-//	        cfg.transport or cfg.httpClient.Transport or http.DefaultTransport
+//	    RetryRoundTripper(only with WithRetry) →
+//	        LimitRoundTripper(only with WithRateLimit / WithMaxConcurrentRequests) →
+//	            AuthRoundTripper(basic | bearer | provider | none) →
+//	                cfg.transport or cfg.httpClient.Transport or http.DefaultTransport
 //
 // If cfg.httpClient is supplied, its Transport is the base and Timeout
 // carries through. Otherwise a fresh client is created with cfg.timeout.
@@ -360,6 +382,29 @@ func buildHTTPClient(cfg *clientConfig) *http.Client {
 		Apply: applyAuth(cfg.auth),
 		Base:  base,
 	}
+	if cfg.auth.kind == authProvider {
+		authed.Source = &credentialCache{provider: cfg.auth.provider}
+	}
+
+	// Limit layer wraps auth directly so queued requests hold no slot
+	// until they are actually about to hit the wire.
+	var inner http.RoundTripper = authed
+	if cfg.rateLimit > 0 || cfg.maxConcurrent > 0 {
+		limited := &transport.LimitRoundTripper{Logger: cfg.logger, Base: authed}
+		if cfg.rateLimit > 0 {
+			limited.Limiter = transport.NewRateLimiter(cfg.rateLimit, cfg.rateBurst)
+		}
+		if cfg.maxConcurrent > 0 {
+			limited.Slots = make(chan struct{}, cfg.maxConcurrent)
+		}
+		inner = limited
+	}
+
+	// Retry layer sits above auth and the limiter so every attempt is
+	// re-authenticated and counted against the rate limit.
+	if cfg.retry != nil {
+		inner = newRetryRoundTripper(cfg.retry, cfg.logger, inner)
+	}
 
 	// Default headers layer (User-Agent + WithHeader entries).
 	headers := http.Header{}
@@ -373,7 +418,7 @@ func buildHTTPClient(cfg *clientConfig) *http.Client {
 	}
 	headed := &transport.HeaderRoundTripper{
 		Headers: headers,
-		Base:    authed,
+		Base:    inner,
 	}
 
 	if cfg.httpClient != nil {
@@ -401,7 +446,9 @@ func applyAuth(auth authCredentials) func(*http.Request) {
 		return func(r *http.Request) {
 			r.Header.Set("Authorization", "Bearer "+token)
 		}
-	case authNone:
+	case authNone, authProvider:
+		// Provider credentials are resolved per request via
+		// AuthRoundTripper.Source instead.
 		return nil
 	default:
 		return nil
@@ -413,26 +460,41 @@ func applyAuth(auth authCredentials) func(*http.Request) {
 // without the resource subpackages having to import the root package —
 // that would create an import cycle since the root imports each
 // rest/<resource>.
+//
+// Each adapter carries the base URL its sub-clients build against:
+// the server URL for REST, or the [WithOWSBaseURL] / [WithGWCBaseURL]
+// override for the OWS and GWC sub-clients.
 type coreAdapter struct {
-	core *clientCore
+	core    *clientCore
+	baseURL string // ends with "/"
 }
 
 // URL builds a fully-qualified URL by joining segments onto the
-// configured base URL.
+// adapter's base URL.
 func (a coreAdapter) URL(parts ...string) (string, error) {
-	return transport.BuildURL(a.core.baseURL, parts)
+	return transport.BuildURL(a.baseURL, parts)
 }
 
-// Do issues a request, decoding the JSON response into out (if non-nil).
-// On non-2xx responses, returns a *APIError wrapping the transport-layer
+// Do issues a request, stream-decoding the JSON response into out (if
+// non-nil). On non-2xx responses, returns a *APIError wrapping the transport-layer
 // error. On transport failure, returns the wrapped transport error.
 func (a coreAdapter) Do(ctx context.Context, op string, method, requestURL string, body any, query map[string]string, out any) error {
-	_, err := transport.DoJSON(ctx, a.core.httpClient, a.core.logger, op, transport.Request{
-		Method: method,
-		URL:    requestURL,
-		Body:   body,
-		Query:  query,
+	ctx, doer, end := a.core.observe(ctx, op, method, requestURL)
+	status, err := transport.DoJSON(ctx, doer, a.core.logger, op, transport.Request{
+		Method:           method,
+		URL:              requestURL,
+		Body:             body,
+		Query:            query,
+		MaxResponseBytes: a.core.maxResponseBytes,
 	}, out)
+	err = asAPIError(err)
+	end(status, err)
+	return err
+}
+
+// asAPIError rewraps a transport-layer non-2xx [*transport.Error] as
+// the public [*APIError]. Other errors (and nil) pass through.
+func asAPIError(err error) error {
 	if err == nil {
 		return nil
 	}
@@ -452,9 +514,25 @@ func (a coreAdapter) Do(ctx context.Context, op string, method, requestURL strin
 // On non-2xx, drains and closes the body, returns a [*APIError].
 // On transport failure, returns the wrapped transport error.
 func (a coreAdapter) DoStream(ctx context.Context, op string, method, requestURL string, query map[string]string) (io.ReadCloser, int, error) {
-	httpReq, err := http.NewRequestWithContext(ctx, method, requestURL, http.NoBody)
+	body, status, _, err := a.stream(ctx, op, method, requestURL, query)
+	return body, status, err
+}
+
+// DoStreamHeader is [coreAdapter.DoStream] for callers that need the
+// response headers, such as the OWS sub-clients, which return the
+// Content-Type of a map image alongside its body.
+func (a coreAdapter) DoStreamHeader(ctx context.Context, op, method, requestURL string, query map[string]string) (io.ReadCloser, http.Header, error) {
+	body, _, header, err := a.stream(ctx, op, method, requestURL, query)
+	return body, header, err
+}
+
+func (a coreAdapter) stream(ctx context.Context, op string, method, requestURL string, query map[string]string) (io.ReadCloser, int, http.Header, error) {
+	ctx, doer, end := a.core.observe(ctx, op, method, requestURL)
+	httpReq, err := http.NewRequestWithContext(transport.WithOp(ctx, op), method, requestURL, http.NoBody)
 	if err != nil {
-		return nil, 0, fmt.Errorf("%s: build request: %w", op, err)
+		err = fmt.Errorf("%s: build request: %w", op, err)
+		end(0, err)
+		return nil, 0, nil, err
 	}
 	httpReq.Header.Set("Accept", "*/*")
 	if len(query) > 0 {
@@ -464,36 +542,39 @@ func (a coreAdapter) DoStream(ctx context.Context, op string, method, requestURL
 		}
 		httpReq.URL.RawQuery = q.Encode()
 	}
-	resp, err := a.core.httpClient.Do(httpReq)
+	resp, err := doer.Do(httpReq)
 	if err != nil {
-		return nil, 0, fmt.Errorf("%s: %s %s: %w", op, method, requestURL, err)
+		err = fmt.Errorf("%s: %s %s: %w", op, method, requestURL, err)
+		end(0, err)
+		return nil, 0, nil, err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 8<<10))
 		_ = resp.Body.Close()
-		return nil, resp.StatusCode, newAPIError(op, method, requestURL, resp.StatusCode, body)
+		apiErr := newAPIError(op, method, requestURL, resp.StatusCode, body)
+		end(resp.StatusCode, apiErr)
+		return nil, resp.StatusCode, resp.Header, apiErr
+	}
+	if a.core.observer == nil {
+		return resp.Body, resp.StatusCode, resp.Header, nil
 	}
-	return resp.Body, resp.StatusCode, nil
+	return &endOnClose{ReadCloser: resp.Body, status: resp.StatusCode, end: end}, resp.StatusCode, resp.Header, nil
 }
 
 // DoXML issues a GET-style request and decodes the response as XML.
 // Used by the OWS sub-clients (WMS / WFS / WCS) for GetCapabilities
-// and similar XML endpoints. Response body cap is 32 MiB to handle
-// large capabilities documents; the JSON 8 KiB cap on [Do] would
-// truncate them.
+// and similar XML endpoints. Shares the success-path body cap with
+// [coreAdapter.Do] (32 MiB unless overridden via [WithMaxResponseBytes]).
 func (a coreAdapter) DoXML(ctx context.Context, op, method, requestURL string, query map[string]string, out any) error {
-	_, err := transport.DoXML(ctx, a.core.httpClient, a.core.logger, op, transport.Request{
-		Method: method,
-		URL:    requestURL,
-		Query:  query,
+	ctx, doer, end := a.core.observe(ctx, op, method, requestURL)
+	status, err := transport.DoXML(ctx, doer, a.core.logger, op, transport.Request{
+		Method:           method,
+		URL:              requestURL,
+		Query:            query,
+		MaxResponseBytes: a.core.maxResponseBytes,
 	}, out)
-	if err == nil {
-		return nil
-	}
-	var tErr *transport.Error
-	if errors.As(err, &tErr) {
-		return newAPIError(tErr.Op, tErr.Method, tErr.URL, tErr.StatusCode, tErr.Body)
-	}
+	err = asAPIError(err)
+	end(status, err)
 	return err
 }
 
@@ -520,13 +601,9 @@ func (a coreAdapter) SynthesizeError(op, method, requestURL string, statusCode i
 // is empty "application/octet-stream" is used. If accept is empty
 // "application/json" is used.
 func (a coreAdapter) DoRaw(ctx context.Context, op, method, requestURL string, body io.Reader, contentType, accept string, query map[string]string) error {
-	_, err := transport.DoRaw(ctx, a.core.httpClient, a.core.logger, op, method, requestURL, body, contentType, accept, query, nil)
-	if err == nil {
-		return nil
-	}
-	var tErr *transport.Error
-	if errors.As(err, &tErr) {
-		return newAPIError(tErr.Op, tErr.Method, tErr.URL, tErr.StatusCode, tErr.Body)
-	}
+	ctx, doer, end := a.core.observe(ctx, op, method, requestURL)
+	status, err := transport.DoRaw(ctx, doer, a.core.logger, op, method, requestURL, body, contentType, accept, query, nil)
+	err = asAPIError(err)
+	end(status, err)
 	return err
 }
@@ -0,0 +1,1736 @@
+package geoservertest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/namespaces"
+	"github.com/hishamkaram/geoserver/v2/rest/security"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// stockStyles are the global styles every GeoServer install ships.
+var stockStyles = []string{"generic", "line", "point", "polygon", "raster"}
+
+// catalog is the in-memory state behind a [Server]. Callers hold
+// Server.mu.
+type catalog struct {
+	workspaces map[string]*workspace
+	styles     map[string]*style // global
+
+	users  map[string]map[string]*security.User // service → name → user
+	groups map[string]map[string]bool           // service → group names
+	roles  map[string]map[string]bool           // role → assigned user names
+
+	acl      map[string]map[string]string         // layers|services|rest → rule → roles
+	services map[string]map[string]map[string]any // wms|wfs|wcs|wmts → workspace ("" global) → settings
+
+	templates map[string]map[string][]byte // scope path ("" global) → name.ftl → body
+	gwcLayers map[string][]byte            // qualified layer name → XML config
+}
+
+type workspace struct {
+	ws             workspaces.Workspace
+	ns             namespaces.Namespace
+	datastores     map[string]*datastore
+	coverageStores map[string]*coverageStore
+	layers         map[string]*layers.Layer
+	layerGroups    map[string]*layergroups.LayerGroup
+	styles         map[string]*style
+}
+
+type datastore struct {
+	ds           datastores.Datastore
+	featureTypes map[string]*featuretypes.FeatureType
+}
+
+type coverageStore struct {
+	cs        coveragestores.CoverageStore
+	coverages map[string]*coverages.Coverage
+}
+
+type style struct {
+	st   styles.Style
+	body []byte
+}
+
+func newCatalog(admin string) *catalog {
+	c := &catalog{
+		workspaces: map[string]*workspace{},
+		styles:     map[string]*style{},
+		users:      map[string]map[string]*security.User{security.DefaultService: {admin: {Name: admin, Enabled: true}}},
+		groups:     map[string]map[string]bool{security.DefaultService: {}},
+		roles:      map[string]map[string]bool{"ADMIN": {admin: true}, "GROUP_ADMIN": {}},
+		acl: map[string]map[string]string{
+			"layers":   {"*.*.r": "*", "*.*.w": "*"},
+			"services": {},
+			"rest":     {"/**:GET": "ADMIN", "/**:POST,DELETE,PUT": "ADMIN"},
+		},
+		services:  map[string]map[string]map[string]any{},
+		templates: map[string]map[string][]byte{},
+		gwcLayers: map[string][]byte{},
+	}
+	for _, slug := range serviceSlugs {
+		c.services[slug] = map[string]map[string]any{"": {"enabled": true, "name": strings.ToUpper(slug)}}
+	}
+	for _, name := range stockStyles {
+		c.styles[name] = newStyle(styles.Style{Name: name})
+	}
+	return c
+}
+
+func newStyle(st styles.Style) *style {
+	if st.Format == "" {
+		st.Format = "sld"
+	}
+	if st.Filename == "" {
+		st.Filename = st.Name + ".sld"
+	}
+	if st.LanguageVersion == nil {
+		st.LanguageVersion = &styles.LanguageVersion{Version: "1.0.0"}
+	}
+	return &style{st: st}
+}
+
+func (c *catalog) addWorkspace(name, uri string, isolated bool) *workspace {
+	ws := &workspace{
+		ws:             workspaces.Workspace{Name: name, Isolated: isolated},
+		ns:             namespaces.Namespace{Prefix: name, URI: uri, Isolated: isolated},
+		datastores:     map[string]*datastore{},
+		coverageStores: map[string]*coverageStore{},
+		layers:         map[string]*layers.Layer{},
+		layerGroups:    map[string]*layergroups.LayerGroup{},
+		styles:         map[string]*style{},
+	}
+	c.workspaces[name] = ws
+	return ws
+}
+
+// removeWorkspace drops a workspace, its namespace, its service
+// setting overrides, the templates in its scopes and the tile layers
+// of its layers.
+func (c *catalog) removeWorkspace(name string) {
+	delete(c.workspaces, name)
+	for _, bySlug := range c.services {
+		delete(bySlug, name)
+	}
+	scope := "workspaces/" + name
+	for key := range c.templates {
+		if key == scope || strings.HasPrefix(key, scope+"/") {
+			delete(c.templates, key)
+		}
+	}
+	for layer := range c.gwcLayers {
+		if strings.HasPrefix(layer, name+":") {
+			delete(c.gwcLayers, layer)
+		}
+	}
+}
+
+func (ws *workspace) empty() bool {
+	return len(ws.datastores) == 0 && len(ws.coverageStores) == 0 && len(ws.layerGroups) == 0 && len(ws.styles) == 0
+}
+
+func (ws *workspace) hasStore(name string) bool {
+	return ws.datastores[name] != nil || ws.coverageStores[name] != nil
+}
+
+// removeLayer deletes a layer and drops it from every layer group in
+// the workspace.
+func (ws *workspace) removeLayer(name string) {
+	delete(ws.layers, name)
+	qualified := ws.ws.Name + ":" + name
+	for _, g := range ws.layerGroups {
+		for i := len(g.Publishables.Published) - 1; i >= 0; i-- {
+			if p := g.Publishables.Published[i]; p.Type != "layerGroup" && p.Name == qualified {
+				g.Publishables.Published = slices.Delete(g.Publishables.Published, i, i+1)
+				if i < len(g.Styles.Style) {
+					g.Styles.Style = slices.Delete(g.Styles.Style, i, i+1)
+				}
+			}
+		}
+	}
+}
+
+// groupsUsing returns the names of layer groups publishing the layer.
+func (ws *workspace) groupsUsing(layer string) []string {
+	qualified := ws.ws.Name + ":" + layer
+	var out []string
+	for name, g := range ws.layerGroups {
+		for _, p := range g.Publishables.Published {
+			if p.Type != "layerGroup" && p.Name == qualified {
+				out = append(out, name)
+				break
+			}
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+var routes = []route{
+	{http.MethodGet, "about/version", (*Server).aboutVersion},
+
+	{http.MethodGet, "workspaces", (*Server).listWorkspaces},
+	{http.MethodPost, "workspaces", (*Server).createWorkspace},
+	{http.MethodGet, "workspaces/*", (*Server).getWorkspace},
+	{http.MethodPut, "workspaces/*", (*Server).updateWorkspace},
+	{http.MethodDelete, "workspaces/*", (*Server).deleteWorkspace},
+
+	{http.MethodGet, "namespaces", (*Server).listNamespaces},
+	{http.MethodPost, "namespaces", (*Server).createNamespace},
+	{http.MethodGet, "namespaces/*", (*Server).getNamespace},
+	{http.MethodPut, "namespaces/*", (*Server).updateNamespace},
+	{http.MethodDelete, "namespaces/*", (*Server).deleteNamespace},
+
+	{http.MethodGet, "workspaces/*/datastores", (*Server).listDatastores},
+	{http.MethodPost, "workspaces/*/datastores", (*Server).createDatastore},
+	{http.MethodGet, "workspaces/*/datastores/*", (*Server).getDatastore},
+	{http.MethodPut, "workspaces/*/datastores/*", (*Server).updateDatastore},
+	{http.MethodDelete, "workspaces/*/datastores/*", (*Server).deleteDatastore},
+	{http.MethodPut, "workspaces/*/datastores/*/*", (*Server).uploadDatastore},
+
+	{http.MethodGet, "workspaces/*/datastores/*/featuretypes", (*Server).listFeatureTypes},
+	{http.MethodPost, "workspaces/*/datastores/*/featuretypes", (*Server).createFeatureType},
+	{http.MethodGet, "workspaces/*/datastores/*/featuretypes/*", (*Server).getFeatureType},
+	{http.MethodPut, "workspaces/*/datastores/*/featuretypes/*", (*Server).updateFeatureType},
+	{http.MethodDelete, "workspaces/*/datastores/*/featuretypes/*", (*Server).deleteFeatureType},
+
+	{http.MethodGet, "workspaces/*/coveragestores", (*Server).listCoverageStores},
+	{http.MethodPost, "workspaces/*/coveragestores", (*Server).createCoverageStore},
+	{http.MethodGet, "workspaces/*/coveragestores/*", (*Server).getCoverageStore},
+	{http.MethodPut, "workspaces/*/coveragestores/*", (*Server).updateCoverageStore},
+	{http.MethodDelete, "workspaces/*/coveragestores/*", (*Server).deleteCoverageStore},
+
+	{http.MethodGet, "workspaces/*/coveragestores/*/coverages", (*Server).listCoverages},
+	{http.MethodPost, "workspaces/*/coveragestores/*/coverages", (*Server).createCoverage},
+	{http.MethodGet, "workspaces/*/coveragestores/*/coverages/*", (*Server).getCoverage},
+	{http.MethodPut, "workspaces/*/coveragestores/*/coverages/*", (*Server).updateCoverage},
+	{http.MethodDelete, "workspaces/*/coveragestores/*/coverages/*", (*Server).deleteCoverage},
+	// After the coverages routes, which the upload patterns also match.
+	{http.MethodPut, "workspaces/*/coveragestores/*/*", (*Server).uploadCoverageStore},
+	{http.MethodPost, "workspaces/*/coveragestores/*/*", (*Server).harvestCoverageStore},
+
+	{http.MethodGet, "workspaces/*/layers", (*Server).listLayers},
+	{http.MethodGet, "workspaces/*/layers/*", (*Server).getLayer},
+	{http.MethodPut, "workspaces/*/layers/*", (*Server).updateLayer},
+	{http.MethodDelete, "workspaces/*/layers/*", (*Server).deleteLayer},
+	{http.MethodGet, "layers/*", (*Server).getQualifiedLayer},
+	{http.MethodPut, "layers/*", (*Server).updateQualifiedLayer},
+	{http.MethodGet, "layers/*/styles", (*Server).listLayerStyles},
+	{http.MethodPost, "layers/*/styles", (*Server).addLayerStyle},
+
+	{http.MethodGet, "workspaces/*/layergroups", (*Server).listLayerGroups},
+	{http.MethodPost, "workspaces/*/layergroups", (*Server).createLayerGroup},
+	{http.MethodGet, "workspaces/*/layergroups/*", (*Server).getLayerGroup},
+	{http.MethodPut, "workspaces/*/layergroups/*", (*Server).updateLayerGroup},
+	{http.MethodDelete, "workspaces/*/layergroups/*", (*Server).deleteLayerGroup},
+
+	{http.MethodGet, "styles", (*Server).listStyles},
+	{http.MethodPost, "styles", (*Server).createStyle},
+	{http.MethodGet, "styles/*", (*Server).getStyle},
+	{http.MethodPut, "styles/*", (*Server).updateStyle},
+	{http.MethodDelete, "styles/*", (*Server).deleteStyle},
+	{http.MethodGet, "workspaces/*/styles", (*Server).listStyles},
+	{http.MethodPost, "workspaces/*/styles", (*Server).createStyle},
+	{http.MethodGet, "workspaces/*/styles/*", (*Server).getStyle},
+	{http.MethodPut, "workspaces/*/styles/*", (*Server).updateStyle},
+	{http.MethodDelete, "workspaces/*/styles/*", (*Server).deleteStyle},
+
+	{http.MethodGet, "security/acl/*", (*Server).listACL},
+	{http.MethodPost, "security/acl/*", (*Server).addACL},
+	{http.MethodPut, "security/acl/*", (*Server).updateACL},
+	{http.MethodDelete, "security/acl/*/**", (*Server).deleteACL},
+
+	{http.MethodGet, "services/*/settings", (*Server).getServiceSettings},
+	{http.MethodPut, "services/*/settings", (*Server).updateServiceSettings},
+	{http.MethodGet, "services/*/workspaces/*/settings", (*Server).getServiceSettings},
+	{http.MethodPut, "services/*/workspaces/*/settings", (*Server).updateServiceSettings},
+	{http.MethodDelete, "services/*/workspaces/*/settings", (*Server).deleteServiceSettings},
+
+	{http.MethodGet, "templates", templateHandler(listTemplates)},
+	{http.MethodGet, "templates/*", templateHandler(getTemplate)},
+	{http.MethodPut, "templates/*", templateHandler(putTemplate)},
+	{http.MethodDelete, "templates/*", templateHandler(deleteTemplate)},
+	{http.MethodGet, "workspaces/*/templates", templateHandler(listTemplates, "workspaces")},
+	{http.MethodGet, "workspaces/*/templates/*", templateHandler(getTemplate, "workspaces")},
+	{http.MethodPut, "workspaces/*/templates/*", templateHandler(putTemplate, "workspaces")},
+	{http.MethodDelete, "workspaces/*/templates/*", templateHandler(deleteTemplate, "workspaces")},
+	{http.MethodGet, "workspaces/*/datastores/*/templates", templateHandler(listTemplates, "workspaces", "datastores")},
+	{http.MethodGet, "workspaces/*/datastores/*/templates/*", templateHandler(getTemplate, "workspaces", "datastores")},
+	{http.MethodPut, "workspaces/*/datastores/*/templates/*", templateHandler(putTemplate, "workspaces", "datastores")},
+	{http.MethodDelete, "workspaces/*/datastores/*/templates/*", templateHandler(deleteTemplate, "workspaces", "datastores")},
+	{http.MethodGet, "workspaces/*/datastores/*/featuretypes/*/templates", templateHandler(listTemplates, "workspaces", "datastores", "featuretypes")},
+	{http.MethodGet, "workspaces/*/datastores/*/featuretypes/*/templates/*", templateHandler(getTemplate, "workspaces", "datastores", "featuretypes")},
+	{http.MethodPut, "workspaces/*/datastores/*/featuretypes/*/templates/*", templateHandler(putTemplate, "workspaces", "datastores", "featuretypes")},
+	{http.MethodDelete, "workspaces/*/datastores/*/featuretypes/*/templates/*", templateHandler(deleteTemplate, "workspaces", "datastores", "featuretypes")},
+	{http.MethodGet, "workspaces/*/coveragestores/*/templates", templateHandler(listTemplates, "workspaces", "coveragestores")},
+	{http.MethodGet, "workspaces/*/coveragestores/*/templates/*", templateHandler(getTemplate, "workspaces", "coveragestores")},
+	{http.MethodPut, "workspaces/*/coveragestores/*/templates/*", templateHandler(putTemplate, "workspaces", "coveragestores")},
+	{http.MethodDelete, "workspaces/*/coveragestores/*/templates/*", templateHandler(deleteTemplate, "workspaces", "coveragestores")},
+	{http.MethodGet, "workspaces/*/coveragestores/*/coverages/*/templates", templateHandler(listTemplates, "workspaces", "coveragestores", "coverages")},
+	{http.MethodGet, "workspaces/*/coveragestores/*/coverages/*/templates/*", templateHandler(getTemplate, "workspaces", "coveragestores", "coverages")},
+	{http.MethodPut, "workspaces/*/coveragestores/*/coverages/*/templates/*", templateHandler(putTemplate, "workspaces", "coveragestores", "coverages")},
+	{http.MethodDelete, "workspaces/*/coveragestores/*/coverages/*/templates/*", templateHandler(deleteTemplate, "workspaces", "coveragestores", "coverages")},
+
+	{http.MethodGet, "gwc/layers", (*Server).listGWCLayers},
+	{http.MethodGet, "gwc/layers/*", (*Server).getGWCLayer},
+	{http.MethodPut, "gwc/layers/*", (*Server).putGWCLayer},
+	{http.MethodDelete, "gwc/layers/*", (*Server).deleteGWCLayer},
+
+	{http.MethodGet, "security/usergroup/service/*/users", (*Server).listUsers},
+	{http.MethodPost, "security/usergroup/service/*/users", (*Server).createUser},
+	{http.MethodPost, "security/usergroup/service/*/user/*", (*Server).updateUser},
+	{http.MethodDelete, "security/usergroup/service/*/user/*", (*Server).deleteUser},
+	{http.MethodGet, "security/usergroup/service/*/groups", (*Server).listGroups},
+	{http.MethodPost, "security/usergroup/service/*/group/*", (*Server).createGroup},
+	{http.MethodDelete, "security/usergroup/service/*/group/*", (*Server).deleteGroup},
+	{http.MethodGet, "security/roles", (*Server).listRoles},
+	{http.MethodPost, "security/roles/role/*", (*Server).createRole},
+	{http.MethodDelete, "security/roles/role/*", (*Server).deleteRole},
+	{http.MethodGet, "security/roles/user/*", (*Server).userRoles},
+	{http.MethodPost, "security/roles/role/*/user/*", (*Server).assignRole},
+	{http.MethodDelete, "security/roles/role/*/user/*", (*Server).unassignRole},
+}
+
+func (s *Server) aboutVersion(w http.ResponseWriter, _ *http.Request, _ []string) {
+	writeJSON(w, http.StatusOK, map[string]any{"about": map[string]any{"resource": []map[string]string{
+		{"@name": "GeoServer", "Version": "2.28.0"},
+		{"@name": "GeoTools", "Version": "34.0"},
+		{"@name": "GeoWebCache", "Version": "1.28.0"},
+	}}})
+}
+
+// ---- lookups ---------------------------------------------------------------
+
+func (s *Server) workspace(w http.ResponseWriter, name string) (*workspace, bool) {
+	ws := s.cat.workspaces[name]
+	if ws == nil {
+		writeError(w, http.StatusNotFound, "No such workspace: '%s' found", name)
+		return nil, false
+	}
+	return ws, true
+}
+
+func (s *Server) datastore(w http.ResponseWriter, wsName, name string) (*workspace, *datastore, bool) {
+	ws, ok := s.workspace(w, wsName)
+	if !ok {
+		return nil, nil, false
+	}
+	ds := ws.datastores[name]
+	if ds == nil {
+		writeError(w, http.StatusNotFound, "No such datastore: %s,%s", wsName, name)
+		return nil, nil, false
+	}
+	return ws, ds, true
+}
+
+func (s *Server) coverageStore(w http.ResponseWriter, wsName, name string) (*workspace, *coverageStore, bool) {
+	ws, ok := s.workspace(w, wsName)
+	if !ok {
+		return nil, nil, false
+	}
+	cs := ws.coverageStores[name]
+	if cs == nil {
+		writeError(w, http.StatusNotFound, "No such coverage store: %s,%s", wsName, name)
+		return nil, nil, false
+	}
+	return ws, cs, true
+}
+
+// resolveStyle finds a style by plain or "ws:name" reference, looking
+// in the given workspace before the global styles. It returns the
+// canonical reference name ("name" or "ws:name").
+func (s *Server) resolveStyle(wsName, ref string) (string, bool) {
+	if prefix, name, ok := strings.Cut(ref, ":"); ok {
+		if ws := s.cat.workspaces[prefix]; ws != nil && ws.styles[name] != nil {
+			return ref, true
+		}
+		return "", false
+	}
+	if ws := s.cat.workspaces[wsName]; ws != nil && ws.styles[ref] != nil {
+		return wsName + ":" + ref, true
+	}
+	if s.cat.styles[ref] != nil {
+		return ref, true
+	}
+	return "", false
+}
+
+func (s *Server) styleHref(canonical string) string {
+	if ws, name, ok := strings.Cut(canonical, ":"); ok {
+		return s.href("workspaces", ws, "styles", name)
+	}
+	return s.href("styles", canonical)
+}
+
+// ---- workspaces ------------------------------------------------------------
+
+func (s *Server) listWorkspaces(w http.ResponseWriter, _ *http.Request, _ []string) {
+	var out []namedRef
+	for _, name := range slices.Sorted(maps.Keys(s.cat.workspaces)) {
+		out = append(out, namedRef{Name: name, Href: s.href("workspaces", name)})
+	}
+	writeList(w, "workspaces", "workspace", out, false)
+}
+
+func (s *Server) createWorkspace(w http.ResponseWriter, r *http.Request, _ []string) {
+	var body struct {
+		Workspace workspaces.Workspace `json:"workspace"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	name := body.Workspace.Name
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "Workspace name must not be empty")
+		return
+	}
+	if s.cat.workspaces[name] != nil {
+		writeError(w, http.StatusInternalServerError, "Workspace named '%s' already exists.", name)
+		return
+	}
+	s.cat.addWorkspace(name, "http://"+name, body.Workspace.Isolated)
+	s.created(w, name, "workspaces", name)
+}
+
+func (s *Server) getWorkspace(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"workspace": map[string]any{
+		"name":           ws.ws.Name,
+		"isolated":       ws.ws.Isolated,
+		"dataStores":     s.href("workspaces", v[0], "datastores"),
+		"coverageStores": s.href("workspaces", v[0], "coveragestores"),
+		"wmsStores":      s.href("workspaces", v[0], "wmsstores"),
+		"wmtsStores":     s.href("workspaces", v[0], "wmtsstores"),
+	}})
+}
+
+func (s *Server) updateWorkspace(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	updated := ws.ws
+	body := struct {
+		Workspace *workspaces.Workspace `json:"workspace"`
+	}{&updated}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	updated.Name = ws.ws.Name
+	ws.ws = updated
+	ws.ns.Isolated = updated.Isolated
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteWorkspace(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	if !ws.empty() && !queryTrue(r, "recurse") {
+		writeError(w, http.StatusForbidden, "Unable to delete non-empty workspace.")
+		return
+	}
+	s.cat.removeWorkspace(v[0])
+	w.WriteHeader(http.StatusOK)
+}
+
+// ---- namespaces ------------------------------------------------------------
+
+func (s *Server) listNamespaces(w http.ResponseWriter, _ *http.Request, _ []string) {
+	var out []namedRef
+	for _, name := range slices.Sorted(maps.Keys(s.cat.workspaces)) {
+		out = append(out, namedRef{Name: name, Href: s.href("namespaces", name)})
+	}
+	writeList(w, "namespaces", "namespace", out, false)
+}
+
+func (s *Server) createNamespace(w http.ResponseWriter, r *http.Request, _ []string) {
+	var body struct {
+		Namespace namespaces.Namespace `json:"namespace"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	ns := body.Namespace
+	if ns.Prefix == "" || ns.URI == "" {
+		writeError(w, http.StatusBadRequest, "Namespace prefix and URI are required")
+		return
+	}
+	if s.cat.workspaces[ns.Prefix] != nil {
+		writeError(w, http.StatusInternalServerError, "Namespace with prefix '%s' already exists.", ns.Prefix)
+		return
+	}
+	s.cat.addWorkspace(ns.Prefix, ns.URI, ns.Isolated)
+	s.created(w, ns.Prefix, "namespaces", ns.Prefix)
+}
+
+func (s *Server) getNamespace(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws := s.cat.workspaces[v[0]]
+	if ws == nil {
+		writeError(w, http.StatusNotFound, "No such namespace: '%s' found", v[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"namespace": ws.ns})
+}
+
+func (s *Server) updateNamespace(w http.ResponseWriter, r *http.Request, v []string) {
+	ws := s.cat.workspaces[v[0]]
+	if ws == nil {
+		writeError(w, http.StatusNotFound, "No such namespace: '%s' found", v[0])
+		return
+	}
+	updated := ws.ns
+	body := struct {
+		Namespace *namespaces.Namespace `json:"namespace"`
+	}{&updated}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	updated.Prefix = ws.ns.Prefix
+	ws.ns = updated
+	ws.ws.Isolated = updated.Isolated
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteNamespace(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws := s.cat.workspaces[v[0]]
+	if ws == nil {
+		writeError(w, http.StatusNotFound, "No such namespace: '%s' found", v[0])
+		return
+	}
+	if !ws.empty() {
+		writeError(w, http.StatusForbidden, "Unable to delete non-empty namespace.")
+		return
+	}
+	s.cat.removeWorkspace(v[0])
+	w.WriteHeader(http.StatusOK)
+}
+
+// ---- datastores ------------------------------------------------------------
+
+// storeTypes maps a connection's dbtype to the type name GeoServer
+// reports.
+var storeTypes = map[string]string{
+	"postgis":      "PostGIS",
+	"postgis-jndi": "PostGIS (JNDI)",
+	"geopkg":       "GeoPackage",
+	"oracle":       "Oracle NG",
+	"h2":           "H2",
+}
+
+func datastoreType(ds datastores.Datastore) string {
+	var dbtype, jndi, fileURL string
+	for _, e := range ds.ConnectionParameters.Entry {
+		switch e.Key {
+		case "dbtype":
+			dbtype = e.Value
+		case "jndiReferenceName":
+			jndi = e.Value
+		case "url":
+			fileURL = e.Value
+		}
+	}
+	switch {
+	case jndi != "" && dbtype == "postgis":
+		return storeTypes["postgis-jndi"]
+	case storeTypes[dbtype] != "":
+		return storeTypes[dbtype]
+	case strings.HasSuffix(fileURL, ".shp"):
+		return "Shapefile"
+	case strings.HasSuffix(fileURL, ".properties"):
+		return "Properties"
+	}
+	return ds.Type
+}
+
+// encryptedParams are connection parameters GeoServer stores with its
+// password encoder and returns encrypted.
+var encryptedParams = map[string]bool{"passwd": true, "password": true}
+
+func (s *Server) listDatastores(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	var out []namedRef
+	for _, name := range slices.Sorted(maps.Keys(ws.datastores)) {
+		out = append(out, namedRef{Name: name, Href: s.href("workspaces", v[0], "datastores", name)})
+	}
+	writeList(w, "dataStores", "dataStore", out, true)
+}
+
+func (s *Server) createDatastore(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	raw, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		DataStore datastores.Datastore `json:"dataStore"`
+	}
+	if !unmarshalBody(w, raw, &body) {
+		return
+	}
+	ds := body.DataStore
+	if ds.Name == "" {
+		writeError(w, http.StatusBadRequest, "Store name must not be empty")
+		return
+	}
+	if ws.hasStore(ds.Name) {
+		writeError(w, http.StatusInternalServerError, "Store '%s' already exists in workspace '%s'", ds.Name, v[0])
+		return
+	}
+	ds.Enabled = enabledOr(raw, "dataStore", true)
+	ds.Type = datastoreType(ds)
+	ds.Workspace = &datastores.WorkspaceRef{Name: v[0]}
+	// GeoServer fills in the namespace parameter from the workspace
+	// when the client leaves it out.
+	if !slices.ContainsFunc(ds.ConnectionParameters.Entry, func(e datastores.ConnectionEntry) bool { return e.Key == "namespace" }) {
+		ds.ConnectionParameters.Entry = append(slices.Clone(ds.ConnectionParameters.Entry), datastores.ConnectionEntry{Key: "namespace", Value: ws.ns.URI})
+	}
+	ws.datastores[ds.Name] = &datastore{ds: ds, featureTypes: map[string]*featuretypes.FeatureType{}}
+	s.created(w, ds.Name, "workspaces", v[0], "datastores", ds.Name)
+}
+
+func (s *Server) getDatastore(w http.ResponseWriter, _ *http.Request, v []string) {
+	_, ds, ok := s.datastore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	out := ds.ds
+	out.FeatureTypes = s.href("workspaces", v[0], "datastores", v[1], "featuretypes")
+	out.ConnectionParameters.Entry = slices.Clone(out.ConnectionParameters.Entry)
+	for i, e := range out.ConnectionParameters.Entry {
+		if encryptedParams[e.Key] && !strings.HasPrefix(e.Value, "crypt1:") {
+			out.ConnectionParameters.Entry[i].Value = "crypt1:" + base64.StdEncoding.EncodeToString([]byte(e.Value))
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"dataStore": struct {
+		datastores.Datastore
+		Workspace namedRef `json:"workspace"`
+	}{out, namedRef{Name: v[0], Href: s.href("workspaces", v[0])}}})
+}
+
+func (s *Server) updateDatastore(w http.ResponseWriter, r *http.Request, v []string) {
+	_, ds, ok := s.datastore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	updated := ds.ds
+	updated.ConnectionParameters.Entry = slices.Clone(updated.ConnectionParameters.Entry)
+	body := struct {
+		DataStore *datastores.Datastore `json:"dataStore"`
+	}{&updated}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	updated.Name = ds.ds.Name
+	updated.Type = datastoreType(updated)
+	updated.Workspace = ds.ds.Workspace
+	ds.ds = updated
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteDatastore(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ds, ok := s.datastore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	if len(ds.featureTypes) > 0 {
+		if !queryTrue(r, "recurse") {
+			writeError(w, http.StatusForbidden, "datastore not empty")
+			return
+		}
+		for name := range ds.featureTypes {
+			ws.removeLayer(name)
+		}
+	}
+	delete(ws.datastores, v[1])
+	w.WriteHeader(http.StatusOK)
+}
+
+// uploadDatastore handles PUT …/datastores/{ds}/{file|url|external}[.ext]:
+// it creates the store if needed plus a feature type and layer named
+// after the store.
+func (s *Server) uploadDatastore(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	method, ext, _ := strings.Cut(v[2], ".")
+	if method != "file" && method != "url" && method != "external" {
+		writeError(w, http.StatusNotFound, "No such resource: %s", r.URL.Path)
+		return
+	}
+	_, _ = io.Copy(io.Discard, r.Body)
+	if ws.coverageStores[v[1]] != nil {
+		writeError(w, http.StatusInternalServerError, "Store '%s' already exists in workspace '%s'", v[1], v[0])
+		return
+	}
+	ds := ws.datastores[v[1]]
+	if ds == nil {
+		store := datastores.Datastore{
+			Name:      v[1],
+			Enabled:   true,
+			Workspace: &datastores.WorkspaceRef{Name: v[0]},
+			ConnectionParameters: datastores.ConnectionParameters{Entry: []datastores.ConnectionEntry{
+				{Key: "url", Value: "file:data/" + v[0] + "/" + v[1] + "/" + v[1] + "." + ext},
+				{Key: "namespace", Value: ws.ns.URI},
+			}},
+		}
+		store.Type = datastoreType(store)
+		ds = &datastore{ds: store, featureTypes: map[string]*featuretypes.FeatureType{}}
+		ws.datastores[v[1]] = ds
+	}
+	if ds.featureTypes[v[1]] == nil && ws.layers[v[1]] == nil {
+		s.publishFeatureType(ws, ds, featuretypes.FeatureType{Name: v[1]})
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// ---- feature types ---------------------------------------------------------
+
+func (s *Server) listFeatureTypes(w http.ResponseWriter, r *http.Request, v []string) {
+	_, ds, ok := s.datastore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	if r.URL.Query().Get("list") != "" {
+		// Discovery: the fake has no backing tables to offer.
+		writeJSON(w, http.StatusOK, map[string]any{"list": map[string]any{"string": []string{}}})
+		return
+	}
+	var out []namedRef
+	for _, name := range slices.Sorted(maps.Keys(ds.featureTypes)) {
+		out = append(out, namedRef{Name: name, Href: s.href("workspaces", v[0], "datastores", v[1], "featuretypes", name)})
+	}
+	writeList(w, "featureTypes", "featureType", out, false)
+}
+
+func (s *Server) createFeatureType(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ds, ok := s.datastore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	var body struct {
+		FeatureType featuretypes.FeatureType `json:"featureType"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	ft := body.FeatureType
+	if ft.Name == "" {
+		writeError(w, http.StatusBadRequest, "Resource name must not be empty")
+		return
+	}
+	if ds.featureTypes[ft.Name] != nil || ws.layers[ft.Name] != nil {
+		writeError(w, http.StatusInternalServerError, "Resource named '%s' already exists in store: '%s'", ft.Name, v[1])
+		return
+	}
+	s.publishFeatureType(ws, ds, ft)
+	s.created(w, ft.Name, "workspaces", v[0], "datastores", v[1], "featuretypes", ft.Name)
+}
+
+// publishFeatureType fills in the defaults GeoServer computes for a new
+// feature type and creates its layer.
+func (s *Server) publishFeatureType(ws *workspace, ds *datastore, ft featuretypes.FeatureType) {
+	if ft.NativeName == "" {
+		ft.NativeName = ft.Name
+	}
+	if ft.Title == "" {
+		ft.Title = ft.Name
+	}
+	if ft.SRS == "" {
+		ft.SRS = "EPSG:4326"
+	}
+	if ft.ProjectionPolicy == "" {
+		ft.ProjectionPolicy = "FORCE_DECLARED"
+	}
+	ft.Enabled = true
+	ft.Namespace = &featuretypes.Ref{Name: ws.ws.Name}
+	ft.Store = &featuretypes.Ref{Name: ws.ws.Name + ":" + ds.ds.Name}
+	ds.featureTypes[ft.Name] = &ft
+	ws.layers[ft.Name] = &layers.Layer{
+		Name:         ft.Name,
+		Type:         "VECTOR",
+		Path:         "/",
+		DefaultStyle: &layers.Ref{Name: defaultVectorStyle(ft)},
+		Resource:     &layers.Ref{Class: "featureType", Name: ws.ws.Name + ":" + ft.Name},
+		Queryable:    true,
+		Enabled:      true,
+	}
+}
+
+// defaultVectorStyle picks the stock style GeoServer assigns from the
+// geometry attribute's binding.
+func defaultVectorStyle(ft featuretypes.FeatureType) string {
+	if ft.Attributes == nil {
+		return "generic"
+	}
+	for _, a := range ft.Attributes.Attribute {
+		switch {
+		case strings.Contains(a.Binding, "Point"):
+			return "point"
+		case strings.Contains(a.Binding, "LineString"):
+			return "line"
+		case strings.Contains(a.Binding, "Polygon"):
+			return "polygon"
+		}
+	}
+	return "generic"
+}
+
+func (s *Server) getFeatureType(w http.ResponseWriter, _ *http.Request, v []string) {
+	_, ds, ok := s.datastore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	ft := ds.featureTypes[v[2]]
+	if ft == nil {
+		writeError(w, http.StatusNotFound, "No such feature type: %s,%s,%s", v[0], v[1], v[2])
+		return
+	}
+	out := *ft
+	out.Namespace = &featuretypes.Ref{Name: v[0], Href: s.href("namespaces", v[0])}
+	out.Store = &featuretypes.Ref{Name: ft.Store.Name, Href: s.href("workspaces", v[0], "datastores", v[1])}
+	writeJSON(w, http.StatusOK, map[string]any{"featureType": out})
+}
+
+func (s *Server) updateFeatureType(w http.ResponseWriter, r *http.Request, v []string) {
+	_, ds, ok := s.datastore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	ft := ds.featureTypes[v[2]]
+	if ft == nil {
+		writeError(w, http.StatusNotFound, "No such feature type: %s,%s,%s", v[0], v[1], v[2])
+		return
+	}
+	updated := *ft
+	body := struct {
+		FeatureType *featuretypes.FeatureType `json:"featureType"`
+	}{&updated}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	updated.Name, updated.Namespace, updated.Store = ft.Name, ft.Namespace, ft.Store
+	*ft = updated
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteFeatureType(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ds, ok := s.datastore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	if ds.featureTypes[v[2]] == nil {
+		writeError(w, http.StatusNotFound, "No such feature type: %s,%s,%s", v[0], v[1], v[2])
+		return
+	}
+	if ws.layers[v[2]] != nil {
+		if !queryTrue(r, "recurse") {
+			writeError(w, http.StatusForbidden, "featureType '%s' is referenced by layer '%s:%s'", v[2], v[0], v[2])
+			return
+		}
+		ws.removeLayer(v[2])
+	}
+	delete(ds.featureTypes, v[2])
+	w.WriteHeader(http.StatusOK)
+}
+
+// ---- coverage stores and coverages -----------------------------------------
+
+func (s *Server) listCoverageStores(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	var out []namedRef
+	for _, name := range slices.Sorted(maps.Keys(ws.coverageStores)) {
+		out = append(out, namedRef{Name: name, Href: s.href("workspaces", v[0], "coveragestores", name)})
+	}
+	writeList(w, "coverageStores", "coverageStore", out, false)
+}
+
+func (s *Server) createCoverageStore(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	raw, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		CoverageStore coveragestores.CoverageStore `json:"coverageStore"`
+	}
+	if !unmarshalBody(w, raw, &body) {
+		return
+	}
+	cs := body.CoverageStore
+	if cs.Name == "" {
+		writeError(w, http.StatusBadRequest, "Store name must not be empty")
+		return
+	}
+	if ws.hasStore(cs.Name) {
+		writeError(w, http.StatusInternalServerError, "Store '%s' already exists in workspace '%s'", cs.Name, v[0])
+		return
+	}
+	cs.Enabled = enabledOr(raw, "coverageStore", true)
+	cs.Workspace = &coveragestores.WorkspaceRef{Name: v[0]}
+	ws.coverageStores[cs.Name] = &coverageStore{cs: cs, coverages: map[string]*coverages.Coverage{}}
+	s.created(w, cs.Name, "workspaces", v[0], "coveragestores", cs.Name)
+}
+
+func (s *Server) getCoverageStore(w http.ResponseWriter, _ *http.Request, v []string) {
+	_, cs, ok := s.coverageStore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	out := cs.cs
+	out.Coverages = s.href("workspaces", v[0], "coveragestores", v[1], "coverages")
+	writeJSON(w, http.StatusOK, map[string]any{"coverageStore": struct {
+		coveragestores.CoverageStore
+		Workspace namedRef `json:"workspace"`
+	}{out, namedRef{Name: v[0], Href: s.href("workspaces", v[0])}}})
+}
+
+func (s *Server) updateCoverageStore(w http.ResponseWriter, r *http.Request, v []string) {
+	_, cs, ok := s.coverageStore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	updated := cs.cs
+	body := struct {
+		CoverageStore *coveragestores.CoverageStore `json:"coverageStore"`
+	}{&updated}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	updated.Name, updated.Workspace = cs.cs.Name, cs.cs.Workspace
+	cs.cs = updated
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteCoverageStore(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, cs, ok := s.coverageStore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	if len(cs.coverages) > 0 {
+		if !queryTrue(r, "recurse") {
+			writeError(w, http.StatusForbidden, "coveragestore not empty")
+			return
+		}
+		for name := range cs.coverages {
+			ws.removeLayer(name)
+		}
+	}
+	delete(ws.coverageStores, v[1])
+	w.WriteHeader(http.StatusOK)
+}
+
+// coverageStoreTypes maps an upload extension to the store type
+// GeoServer reports.
+var coverageStoreTypes = map[string]string{
+	"geotiff":     "GeoTIFF",
+	"worldimage":  "WorldImage",
+	"imagemosaic": "ImageMosaic",
+	"arcgrid":     "ArcGrid",
+}
+
+// uploadCoverageStore handles PUT …/coveragestores/{cs}/{file|url|external}[.ext]:
+// it creates the store if needed plus a coverage and layer named
+// after the store.
+func (s *Server) uploadCoverageStore(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	method, ext, _ := strings.Cut(v[2], ".")
+	if method != "file" && method != "url" && method != "external" {
+		writeError(w, http.StatusNotFound, "No such resource: %s", r.URL.Path)
+		return
+	}
+	_, _ = io.Copy(io.Discard, r.Body)
+	if ws.datastores[v[1]] != nil {
+		writeError(w, http.StatusInternalServerError, "Store '%s' already exists in workspace '%s'", v[1], v[0])
+		return
+	}
+	cs := ws.coverageStores[v[1]]
+	if cs == nil {
+		typ := coverageStoreTypes[ext]
+		if typ == "" {
+			typ = ext
+		}
+		cs = &coverageStore{
+			cs: coveragestores.CoverageStore{
+				Name:      v[1],
+				Type:      typ,
+				Enabled:   true,
+				URL:       "file:data/" + v[0] + "/" + v[1] + "/" + v[1] + "." + ext,
+				Workspace: &coveragestores.WorkspaceRef{Name: v[0]},
+			},
+			coverages: map[string]*coverages.Coverage{},
+		}
+		ws.coverageStores[v[1]] = cs
+	}
+	if cs.coverages[v[1]] == nil && ws.layers[v[1]] == nil {
+		s.publishCoverage(ws, cs, coverages.Coverage{Name: v[1]})
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) harvestCoverageStore(w http.ResponseWriter, r *http.Request, v []string) {
+	if _, _, ok := s.coverageStore(w, v[0], v[1]); !ok {
+		return
+	}
+	_, _ = io.Copy(io.Discard, r.Body)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) listCoverages(w http.ResponseWriter, r *http.Request, v []string) {
+	_, cs, ok := s.coverageStore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	if r.URL.Query().Get("list") != "" {
+		writeJSON(w, http.StatusOK, map[string]any{"list": map[string]any{"string": []string{}}})
+		return
+	}
+	var out []namedRef
+	for _, name := range slices.Sorted(maps.Keys(cs.coverages)) {
+		out = append(out, namedRef{Name: name, Href: s.href("workspaces", v[0], "coveragestores", v[1], "coverages", name)})
+	}
+	writeList(w, "coverages", "coverage", out, false)
+}
+
+func (s *Server) createCoverage(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, cs, ok := s.coverageStore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	var body struct {
+		Coverage coverages.Coverage `json:"coverage"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	cov := body.Coverage
+	if cov.Name == "" {
+		writeError(w, http.StatusBadRequest, "Resource name must not be empty")
+		return
+	}
+	if cs.coverages[cov.Name] != nil || ws.layers[cov.Name] != nil {
+		writeError(w, http.StatusInternalServerError, "Resource named '%s' already exists in store: '%s'", cov.Name, v[1])
+		return
+	}
+	s.publishCoverage(ws, cs, cov)
+	s.created(w, cov.Name, "workspaces", v[0], "coveragestores", v[1], "coverages", cov.Name)
+}
+
+// publishCoverage fills in the defaults GeoServer computes for a new
+// coverage and creates its layer.
+func (s *Server) publishCoverage(ws *workspace, cs *coverageStore, cov coverages.Coverage) {
+	if cov.NativeName == "" {
+		cov.NativeName = cov.Name
+	}
+	if cov.NativeCoverageName == "" {
+		cov.NativeCoverageName = cov.NativeName
+	}
+	if cov.Title == "" {
+		cov.Title = cov.Name
+	}
+	if cov.SRS == "" {
+		cov.SRS = "EPSG:4326"
+	}
+	if cov.NativeFormat == "" {
+		cov.NativeFormat = cs.cs.Type
+	}
+	cov.Enabled = true
+	cov.Namespace = &coverages.Ref{Name: ws.ws.Name}
+	cov.Store = &coverages.Ref{Name: ws.ws.Name + ":" + cs.cs.Name}
+	cs.coverages[cov.Name] = &cov
+	ws.layers[cov.Name] = &layers.Layer{
+		Name:         cov.Name,
+		Type:         "RASTER",
+		Path:         "/",
+		DefaultStyle: &layers.Ref{Name: "raster"},
+		Resource:     &layers.Ref{Class: "coverage", Name: ws.ws.Name + ":" + cov.Name},
+		Queryable:    true,
+		Opaque:       false,
+		Enabled:      true,
+	}
+}
+
+func (s *Server) getCoverage(w http.ResponseWriter, _ *http.Request, v []string) {
+	_, cs, ok := s.coverageStore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	cov := cs.coverages[v[2]]
+	if cov == nil {
+		writeError(w, http.StatusNotFound, "No such coverage: %s,%s,%s", v[0], v[1], v[2])
+		return
+	}
+	out := *cov
+	out.Namespace = &coverages.Ref{Name: v[0], Href: s.href("namespaces", v[0])}
+	out.Store = &coverages.Ref{Name: cov.Store.Name, Href: s.href("workspaces", v[0], "coveragestores", v[1])}
+	writeJSON(w, http.StatusOK, map[string]any{"coverage": out})
+}
+
+func (s *Server) updateCoverage(w http.ResponseWriter, r *http.Request, v []string) {
+	_, cs, ok := s.coverageStore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	cov := cs.coverages[v[2]]
+	if cov == nil {
+		writeError(w, http.StatusNotFound, "No such coverage: %s,%s,%s", v[0], v[1], v[2])
+		return
+	}
+	updated := *cov
+	body := struct {
+		Coverage *coverages.Coverage `json:"coverage"`
+	}{&updated}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	updated.Name, updated.Namespace, updated.Store = cov.Name, cov.Namespace, cov.Store
+	*cov = updated
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteCoverage(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, cs, ok := s.coverageStore(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	if cs.coverages[v[2]] == nil {
+		writeError(w, http.StatusNotFound, "No such coverage: %s,%s,%s", v[0], v[1], v[2])
+		return
+	}
+	if ws.layers[v[2]] != nil {
+		if !queryTrue(r, "recurse") {
+			writeError(w, http.StatusForbidden, "coverage '%s' is referenced by layer '%s:%s'", v[2], v[0], v[2])
+			return
+		}
+		ws.removeLayer(v[2])
+	}
+	delete(cs.coverages, v[2])
+	w.WriteHeader(http.StatusOK)
+}
+
+// ---- layers ----------------------------------------------------------------
+
+func (s *Server) listLayers(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	var out []namedRef
+	for _, name := range slices.Sorted(maps.Keys(ws.layers)) {
+		out = append(out, namedRef{Name: name, Href: s.href("workspaces", v[0], "layers", name)})
+	}
+	writeList(w, "layers", "layer", out, false)
+}
+
+func (s *Server) layer(w http.ResponseWriter, wsName, name string) (*workspace, *layers.Layer, bool) {
+	ws, ok := s.workspace(w, wsName)
+	if !ok {
+		return nil, nil, false
+	}
+	l := ws.layers[name]
+	if l == nil {
+		writeError(w, http.StatusNotFound, "No such layer: %s:%s", wsName, name)
+		return nil, nil, false
+	}
+	return ws, l, true
+}
+
+// resourceHref links a layer's resource to its feature type or
+// coverage document.
+func (s *Server) resourceHref(ws *workspace, l *layers.Layer) string {
+	for dsName, ds := range ws.datastores {
+		if ds.featureTypes[l.Name] != nil {
+			return s.href("workspaces", ws.ws.Name, "datastores", dsName, "featuretypes", l.Name)
+		}
+	}
+	for csName, cs := range ws.coverageStores {
+		if cs.coverages[l.Name] != nil {
+			return s.href("workspaces", ws.ws.Name, "coveragestores", csName, "coverages", l.Name)
+		}
+	}
+	return ""
+}
+
+func (s *Server) getLayer(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws, l, ok := s.layer(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	out := *l
+	if l.DefaultStyle != nil {
+		out.DefaultStyle = &layers.Ref{Name: l.DefaultStyle.Name, Href: s.styleHref(l.DefaultStyle.Name)}
+	}
+	if l.Styles != nil {
+		out.Styles = &layers.Styles{Class: "linked-hash-set"}
+		for _, st := range l.Styles.Style {
+			out.Styles.Style = append(out.Styles.Style, layers.Ref{Name: st.Name, Href: s.styleHref(st.Name)})
+		}
+	}
+	if l.Resource != nil {
+		out.Resource = &layers.Ref{Class: l.Resource.Class, Name: l.Resource.Name, Href: s.resourceHref(ws, l)}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"layer": out})
+}
+
+func (s *Server) updateLayer(w http.ResponseWriter, r *http.Request, v []string) {
+	_, l, ok := s.layer(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	updated := *l
+	// Decode style references fresh rather than into l's; an omitted
+	// one keeps its current value unvalidated, as on GeoServer.
+	updated.DefaultStyle, updated.Styles = nil, nil
+	body := struct {
+		Layer *layers.Layer `json:"layer"`
+	}{&updated}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if updated.DefaultStyle != nil && updated.DefaultStyle.Name != "" {
+		canonical, ok := s.resolveStyle(v[0], updated.DefaultStyle.Name)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "No such style: %s", updated.DefaultStyle.Name)
+			return
+		}
+		updated.DefaultStyle = &layers.Ref{Name: canonical}
+	} else if updated.DefaultStyle == nil {
+		updated.DefaultStyle = l.DefaultStyle
+	}
+	if updated.Styles != nil {
+		refs := make([]layers.Ref, 0, len(updated.Styles.Style))
+		for _, st := range updated.Styles.Style {
+			canonical, ok := s.resolveStyle(v[0], st.Name)
+			if !ok {
+				writeError(w, http.StatusBadRequest, "No such style: %s", st.Name)
+				return
+			}
+			refs = append(refs, layers.Ref{Name: canonical})
+		}
+		updated.Styles = &layers.Styles{Style: refs}
+	} else {
+		updated.Styles = l.Styles
+	}
+	updated.Name, updated.Resource, updated.Type = l.Name, l.Resource, l.Type
+	*l = updated
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteLayer(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, l, ok := s.layer(w, v[0], v[1])
+	if !ok {
+		return
+	}
+	recurse := queryTrue(r, "recurse")
+	if groups := ws.groupsUsing(v[1]); len(groups) > 0 && !recurse {
+		writeError(w, http.StatusForbidden, "Layer '%s:%s' is referenced by layer group '%s'", v[0], v[1], groups[0])
+		return
+	}
+	ws.removeLayer(v[1])
+	if recurse {
+		for _, ds := range ws.datastores {
+			delete(ds.featureTypes, l.Name)
+		}
+		for _, cs := range ws.coverageStores {
+			delete(cs.coverages, l.Name)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// qualifiedLayer resolves the "ws:name" path segment used by the
+// /rest/layers/{layer}/styles endpoints.
+func (s *Server) qualifiedLayer(w http.ResponseWriter, qualified string) (string, *layers.Layer, bool) {
+	wsName, name, ok := strings.Cut(qualified, ":")
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such layer: %s", qualified)
+		return "", nil, false
+	}
+	_, l, ok := s.layer(w, wsName, name)
+	return wsName, l, ok
+}
+
+// getQualifiedLayer and updateQualifiedLayer serve /layers/{ws:layer}.
+func (s *Server) getQualifiedLayer(w http.ResponseWriter, r *http.Request, v []string) {
+	if wsName, name, ok := strings.Cut(v[0], ":"); ok {
+		s.getLayer(w, r, []string{wsName, name})
+		return
+	}
+	writeError(w, http.StatusNotFound, "No such layer: %s", v[0])
+}
+
+func (s *Server) updateQualifiedLayer(w http.ResponseWriter, r *http.Request, v []string) {
+	if wsName, name, ok := strings.Cut(v[0], ":"); ok {
+		s.updateLayer(w, r, []string{wsName, name})
+		return
+	}
+	writeError(w, http.StatusNotFound, "No such layer: %s", v[0])
+}
+
+func (s *Server) listLayerStyles(w http.ResponseWriter, _ *http.Request, v []string) {
+	_, l, ok := s.qualifiedLayer(w, v[0])
+	if !ok {
+		return
+	}
+	var out []namedRef
+	if l.Styles != nil {
+		for _, st := range l.Styles.Style {
+			out = append(out, namedRef{Name: st.Name, Href: s.styleHref(st.Name)})
+		}
+	}
+	writeList(w, "styles", "style", out, false)
+}
+
+func (s *Server) addLayerStyle(w http.ResponseWriter, r *http.Request, v []string) {
+	wsName, l, ok := s.qualifiedLayer(w, v[0])
+	if !ok {
+		return
+	}
+	var body struct {
+		Style struct {
+			Name string `json:"name"`
+		} `json:"style"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	canonical, ok := s.resolveStyle(wsName, body.Style.Name)
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such style: %s", body.Style.Name)
+		return
+	}
+	if l.Styles == nil {
+		l.Styles = &layers.Styles{}
+	}
+	if !slices.ContainsFunc(l.Styles.Style, func(ref layers.Ref) bool { return ref.Name == canonical }) {
+		l.Styles.Style = append(l.Styles.Style, layers.Ref{Name: canonical})
+	}
+	if queryTrue(r, "default") {
+		l.DefaultStyle = &layers.Ref{Name: canonical}
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// ---- layer groups ----------------------------------------------------------
+
+func (s *Server) listLayerGroups(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	var out []namedRef
+	for _, name := range slices.Sorted(maps.Keys(ws.layerGroups)) {
+		out = append(out, namedRef{Name: name, Href: s.href("workspaces", v[0], "layergroups", name)})
+	}
+	writeList(w, "layerGroups", "layerGroup", out, false)
+}
+
+// normalizeGroup validates a layer group's members and styles against
+// the catalog and rewrites references to their canonical names.
+func (s *Server) normalizeGroup(w http.ResponseWriter, ws *workspace, g *layergroups.LayerGroup) bool {
+	for i, p := range g.Publishables.Published {
+		wsName, name, ok := strings.Cut(p.Name, ":")
+		if !ok {
+			wsName, name = ws.ws.Name, p.Name
+		}
+		target := s.cat.workspaces[wsName]
+		found := target != nil && target.layers[name] != nil
+		if p.Type == "layerGroup" {
+			found = target != nil && target.layerGroups[name] != nil
+		} else if p.Type == "" {
+			g.Publishables.Published[i].Type = "layer"
+		}
+		if !found {
+			writeError(w, http.StatusBadRequest, "No such layer or layer group: %s", p.Name)
+			return false
+		}
+		g.Publishables.Published[i].Name = wsName + ":" + name
+		g.Publishables.Published[i].Href = ""
+	}
+	for i, st := range g.Styles.Style {
+		if st.Name == "" {
+			continue
+		}
+		canonical, ok := s.resolveStyle(ws.ws.Name, st.Name)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "No such style: %s", st.Name)
+			return false
+		}
+		g.Styles.Style[i] = layergroups.Ref{Name: canonical}
+	}
+	if g.Mode == "" {
+		g.Mode = "SINGLE"
+	}
+	g.Workspace = &layergroups.Ref{Name: ws.ws.Name}
+	return true
+}
+
+func (s *Server) createLayerGroup(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	var body struct {
+		LayerGroup layergroups.LayerGroup `json:"layerGroup"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	g := body.LayerGroup
+	if g.Name == "" {
+		writeError(w, http.StatusBadRequest, "Layer group name must not be empty")
+		return
+	}
+	if ws.layerGroups[g.Name] != nil {
+		writeError(w, http.StatusInternalServerError, "Layer group named '%s' already exists in workspace '%s'", g.Name, v[0])
+		return
+	}
+	if !s.normalizeGroup(w, ws, &g) {
+		return
+	}
+	ws.layerGroups[g.Name] = &g
+	s.created(w, g.Name, "workspaces", v[0], "layergroups", g.Name)
+}
+
+// getLayerGroup writes the group the way GeoServer does: a single
+// published entry as an object rather than an array, and default
+// styles as "" strings mixed in with style objects.
+func (s *Server) getLayerGroup(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	g := ws.layerGroups[v[1]]
+	if g == nil {
+		writeError(w, http.StatusNotFound, "No such layer group %s in workspace %s", v[1], v[0])
+		return
+	}
+	published := make([]any, 0, len(g.Publishables.Published))
+	styleRefs := make([]any, 0, len(g.Publishables.Published))
+	for i, p := range g.Publishables.Published {
+		wsName, name, _ := strings.Cut(p.Name, ":")
+		kind := "layers"
+		if p.Type == "layerGroup" {
+			kind = "layergroups"
+		}
+		published = append(published, map[string]string{"@type": p.Type, "name": p.Name, "href": s.href("workspaces", wsName, kind, name)})
+		if i < len(g.Styles.Style) && g.Styles.Style[i].Name != "" {
+			st := g.Styles.Style[i].Name
+			styleRefs = append(styleRefs, namedRef{Name: st, Href: s.styleHref(st)})
+		} else {
+			styleRefs = append(styleRefs, "")
+		}
+	}
+	out := map[string]any{
+		"name":      g.Name,
+		"mode":      g.Mode,
+		"workspace": map[string]string{"name": v[0]},
+	}
+	if g.Title != "" {
+		out["title"] = g.Title
+	}
+	switch len(published) {
+	case 0:
+	case 1:
+		out["publishables"] = map[string]any{"published": published[0]}
+		out["styles"] = map[string]any{"style": styleRefs[0]}
+	default:
+		out["publishables"] = map[string]any{"published": published}
+		out["styles"] = map[string]any{"style": styleRefs}
+	}
+	if g.Bounds != nil {
+		out["bounds"] = g.Bounds
+	}
+	if g.Keywords != nil {
+		out["keywords"] = g.Keywords
+	}
+	if len(g.MetadataLinks) > 0 {
+		out["metadataLinks"] = g.MetadataLinks
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"layerGroup": out})
+}
+
+func (s *Server) updateLayerGroup(w http.ResponseWriter, r *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	g := ws.layerGroups[v[1]]
+	if g == nil {
+		writeError(w, http.StatusNotFound, "No such layer group %s in workspace %s", v[1], v[0])
+		return
+	}
+	updated := *g
+	updated.Publishables.Published = slices.Clone(g.Publishables.Published)
+	updated.Styles.Style = slices.Clone(g.Styles.Style)
+	body := struct {
+		LayerGroup *layergroups.LayerGroup `json:"layerGroup"`
+	}{&updated}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	updated.Name = g.Name
+	if !s.normalizeGroup(w, ws, &updated) {
+		return
+	}
+	*g = updated
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteLayerGroup(w http.ResponseWriter, _ *http.Request, v []string) {
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return
+	}
+	if ws.layerGroups[v[1]] == nil {
+		writeError(w, http.StatusNotFound, "No such layer group %s in workspace %s", v[1], v[0])
+		return
+	}
+	delete(ws.layerGroups, v[1])
+	w.WriteHeader(http.StatusOK)
+}
+
+// ---- styles ----------------------------------------------------------------
+
+// styleScope returns the style map for the request: global for
+// /rest/styles, per-workspace for /rest/workspaces/{ws}/styles. The
+// workspace name, if any, is v[0]; the remaining vars follow.
+func (s *Server) styleScope(w http.ResponseWriter, r *http.Request, v []string) (wsName string, scope map[string]*style, rest []string, ok bool) {
+	if !strings.HasPrefix(r.URL.Path, "/rest/workspaces/") {
+		return "", s.cat.styles, v, true
+	}
+	ws, ok := s.workspace(w, v[0])
+	if !ok {
+		return "", nil, nil, false
+	}
+	return v[0], ws.styles, v[1:], true
+}
+
+func (s *Server) listStyles(w http.ResponseWriter, r *http.Request, v []string) {
+	wsName, scope, _, ok := s.styleScope(w, r, v)
+	if !ok {
+		return
+	}
+	var out []namedRef
+	for _, name := range slices.Sorted(maps.Keys(scope)) {
+		href := s.href("styles", name)
+		if wsName != "" {
+			href = s.href("workspaces", wsName, "styles", name)
+		}
+		out = append(out, namedRef{Name: name, Href: href})
+	}
+	writeList(w, "styles", "style", out, true)
+}
+
+func (s *Server) createStyle(w http.ResponseWriter, r *http.Request, v []string) {
+	wsName, scope, _, ok := s.styleScope(w, r, v)
+	if !ok {
+		return
+	}
+	var st styles.Style
+	var sld []byte
+	if isJSON(r) {
+		var body struct {
+			Style styles.Style `json:"style"`
+		}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+		st = body.Style
+	} else {
+		raw, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		st, sld = styles.Style{Name: r.URL.Query().Get("name")}, raw
+	}
+	if st.Name == "" {
+		writeError(w, http.StatusBadRequest, "Style name must not be empty")
+		return
+	}
+	if scope[st.Name] != nil {
+		writeError(w, http.StatusInternalServerError, "Style named '%s' already exists.", st.Name)
+		return
+	}
+	entry := newStyle(st)
+	entry.body = sld
+	scope[st.Name] = entry
+	if wsName != "" {
+		s.created(w, st.Name, "workspaces", wsName, "styles", st.Name)
+		return
+	}
+	s.created(w, st.Name, "styles", st.Name)
+}
+
+func (s *Server) getStyle(w http.ResponseWriter, r *http.Request, v []string) {
+	_, scope, rest, ok := s.styleScope(w, r, v)
+	if !ok {
+		return
+	}
+	name, wantBody := strings.CutSuffix(rest[0], ".sld")
+	wantBody = wantBody || strings.Contains(r.Header.Get("Accept"), "sld")
+	st := scope[name]
+	if st == nil {
+		writeError(w, http.StatusNotFound, "No such style: %s", name)
+		return
+	}
+	if wantBody {
+		w.Header().Set("Content-Type", "application/vnd.ogc.sld+xml")
+		_, _ = w.Write(st.body)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"style": st.st})
+}
+
+func (s *Server) updateStyle(w http.ResponseWriter, r *http.Request, v []string) {
+	_, scope, rest, ok := s.styleScope(w, r, v)
+	if !ok {
+		return
+	}
+	st := scope[rest[0]]
+	if st == nil {
+		writeError(w, http.StatusNotFound, "No such style: %s", rest[0])
+		return
+	}
+	if !isJSON(r) {
+		raw, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		st.body = raw
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	updated := st.st
+	body := struct {
+		Style *styles.Style `json:"style"`
+	}{&updated}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if updated.Name != rest[0] {
+		if updated.Name == "" || scope[updated.Name] != nil {
+			writeError(w, http.StatusInternalServerError, "Style named '%s' already exists.", updated.Name)
+			return
+		}
+		delete(scope, rest[0])
+		scope[updated.Name] = st
+	}
+	st.st = updated
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteStyle(w http.ResponseWriter, r *http.Request, v []string) {
+	wsName, scope, rest, ok := s.styleScope(w, r, v)
+	if !ok {
+		return
+	}
+	if scope[rest[0]] == nil {
+		writeError(w, http.StatusNotFound, "No such style: %s", rest[0])
+		return
+	}
+	canonical := rest[0]
+	if wsName != "" {
+		canonical = wsName + ":" + rest[0]
+	}
+	if user := s.styleUser(canonical); user != "" {
+		writeError(w, http.StatusForbidden, "Can't delete style '%s': referenced by %s", rest[0], user)
+		return
+	}
+	delete(scope, rest[0])
+	w.WriteHeader(http.StatusOK)
+}
+
+// styleUser returns a description of the first layer or layer group
+// referencing the style, or "" if none does.
+func (s *Server) styleUser(canonical string) string {
+	for _, wsName := range slices.Sorted(maps.Keys(s.cat.workspaces)) {
+		ws := s.cat.workspaces[wsName]
+		for _, name := range slices.Sorted(maps.Keys(ws.layers)) {
+			l := ws.layers[name]
+			if l.DefaultStyle != nil && l.DefaultStyle.Name == canonical {
+				return "layer " + wsName + ":" + name
+			}
+			if l.Styles != nil && slices.ContainsFunc(l.Styles.Style, func(r layers.Ref) bool { return r.Name == canonical }) {
+				return "layer " + wsName + ":" + name
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(ws.layerGroups)) {
+			if slices.ContainsFunc(ws.layerGroups[name].Styles.Style, func(r layergroups.Ref) bool { return r.Name == canonical }) {
+				return "layer group " + wsName + ":" + name
+			}
+		}
+	}
+	return ""
+}
+
+// ---- request helpers -------------------------------------------------------
+
+func isJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Content-Type"), "json")
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Error reading request body: %v", err)
+		return nil, false
+	}
+	return raw, true
+}
+
+func unmarshalBody(w http.ResponseWriter, raw []byte, v any) bool {
+	if err := json.Unmarshal(raw, v); err != nil {
+		writeError(w, http.StatusBadRequest, "Error parsing request body: %v", err)
+		return false
+	}
+	return true
+}
+
+// enabledOr reads the "enabled" flag from a `{"<root>":{…}}` body,
+// returning def when the body leaves it out — the sub-client types
+// drop enabled=false via omitempty, and GeoServer defaults new stores
+// to enabled.
+func enabledOr(raw []byte, root string, def bool) bool {
+	var probe map[string]struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil || probe[root].Enabled == nil {
+		return def
+	}
+	return *probe[root].Enabled
+}
@@ -0,0 +1,64 @@
+package geoservertest
+
+import (
+	"encoding/xml"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// GeoWebCache tile layers are kept as the XML documents PUT to them.
+// Unlike a real GeoServer, publishing a layer does not create its
+// tile layer.
+
+func (s *Server) listGWCLayers(w http.ResponseWriter, _ *http.Request, _ []string) {
+	names := slices.Sorted(maps.Keys(s.cat.gwcLayers))
+	if names == nil {
+		names = []string{}
+	}
+	writeJSON(w, http.StatusOK, names)
+}
+
+func (s *Server) getGWCLayer(w http.ResponseWriter, _ *http.Request, v []string) {
+	name := strings.TrimSuffix(v[0], ".xml")
+	body, ok := s.cat.gwcLayers[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Unknown layer: %s", name)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write(body)
+}
+
+func (s *Server) putGWCLayer(w http.ResponseWriter, r *http.Request, v []string) {
+	name := strings.TrimSuffix(v[0], ".xml")
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	var doc struct {
+		XMLName xml.Name `xml:"GeoServerLayer"`
+		Name    string   `xml:"name"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		writeError(w, http.StatusBadRequest, "Error parsing request body: %v", err)
+		return
+	}
+	if doc.Name != name {
+		writeError(w, http.StatusBadRequest, "Layer name %q does not match %q", doc.Name, name)
+		return
+	}
+	s.cat.gwcLayers[name] = body
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteGWCLayer(w http.ResponseWriter, _ *http.Request, v []string) {
+	name := strings.TrimSuffix(v[0], ".xml")
+	if _, ok := s.cat.gwcLayers[name]; !ok {
+		writeError(w, http.StatusNotFound, "Unknown layer: %s", name)
+		return
+	}
+	delete(s.cat.gwcLayers, name)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,255 @@
+package geoservertest
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/hishamkaram/geoserver/v2/rest/security"
+)
+
+// userService returns the named user/group service's users, answering
+// 404 for services the fake doesn't know.
+func (s *Server) userService(w http.ResponseWriter, name string) (map[string]*security.User, bool) {
+	users := s.cat.users[name]
+	if users == nil {
+		writeError(w, http.StatusNotFound, "No such user/group service: %s", name)
+		return nil, false
+	}
+	return users, true
+}
+
+// listUsers writes `{"users":[…]}`. Passwords are never returned.
+func (s *Server) listUsers(w http.ResponseWriter, _ *http.Request, v []string) {
+	users, ok := s.userService(w, v[0])
+	if !ok {
+		return
+	}
+	out := []security.User{}
+	for _, name := range slices.Sorted(maps.Keys(users)) {
+		out = append(out, security.User{Name: name, Enabled: users[name].Enabled})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"users": out})
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request, v []string) {
+	users, ok := s.userService(w, v[0])
+	if !ok {
+		return
+	}
+	var body struct {
+		User security.User `json:"user"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	u := body.User
+	if u.Name == "" {
+		writeError(w, http.StatusBadRequest, "User name must not be empty")
+		return
+	}
+	if users[u.Name] != nil {
+		writeError(w, http.StatusInternalServerError, "User '%s' already exists", u.Name)
+		return
+	}
+	users[u.Name] = &u
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request, v []string) {
+	users, ok := s.userService(w, v[0])
+	if !ok {
+		return
+	}
+	u := users[v[1]]
+	if u == nil {
+		writeError(w, http.StatusNotFound, "No such user: %s", v[1])
+		return
+	}
+	var body struct {
+		User security.User `json:"user"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	u.Enabled = body.User.Enabled
+	if body.User.Password != "" {
+		u.Password = body.User.Password
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, _ *http.Request, v []string) {
+	users, ok := s.userService(w, v[0])
+	if !ok {
+		return
+	}
+	if users[v[1]] == nil {
+		writeError(w, http.StatusNotFound, "No such user: %s", v[1])
+		return
+	}
+	delete(users, v[1])
+	for _, assigned := range s.cat.roles {
+		delete(assigned, v[1])
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) listGroups(w http.ResponseWriter, _ *http.Request, v []string) {
+	groups := s.cat.groups[v[0]]
+	if groups == nil {
+		writeError(w, http.StatusNotFound, "No such user/group service: %s", v[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"groups": slices.Sorted(maps.Keys(groups))})
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, _ *http.Request, v []string) {
+	groups := s.cat.groups[v[0]]
+	if groups == nil {
+		writeError(w, http.StatusNotFound, "No such user/group service: %s", v[0])
+		return
+	}
+	if groups[v[1]] {
+		writeError(w, http.StatusInternalServerError, "Group '%s' already exists", v[1])
+		return
+	}
+	groups[v[1]] = true
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) deleteGroup(w http.ResponseWriter, _ *http.Request, v []string) {
+	groups := s.cat.groups[v[0]]
+	if !groups[v[1]] {
+		writeError(w, http.StatusNotFound, "No such group: %s", v[1])
+		return
+	}
+	delete(groups, v[1])
+	w.WriteHeader(http.StatusOK)
+}
+
+// listRoles writes `{"roles":[…]}`, the GeoServer 2.28+ shape.
+func (s *Server) listRoles(w http.ResponseWriter, _ *http.Request, _ []string) {
+	writeJSON(w, http.StatusOK, map[string]any{"roles": slices.Sorted(maps.Keys(s.cat.roles))})
+}
+
+func (s *Server) createRole(w http.ResponseWriter, _ *http.Request, v []string) {
+	if s.cat.roles[v[0]] != nil {
+		writeError(w, http.StatusInternalServerError, "Role '%s' already exists", v[0])
+		return
+	}
+	s.cat.roles[v[0]] = map[string]bool{}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) deleteRole(w http.ResponseWriter, _ *http.Request, v []string) {
+	if s.cat.roles[v[0]] == nil {
+		writeError(w, http.StatusNotFound, "No such role: %s", v[0])
+		return
+	}
+	delete(s.cat.roles, v[0])
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) userRoles(w http.ResponseWriter, _ *http.Request, v []string) {
+	out := []string{}
+	for _, role := range slices.Sorted(maps.Keys(s.cat.roles)) {
+		if s.cat.roles[role][v[0]] {
+			out = append(out, role)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"roles": out})
+}
+
+func (s *Server) assignRole(w http.ResponseWriter, _ *http.Request, v []string) {
+	assigned := s.cat.roles[v[0]]
+	if assigned == nil {
+		writeError(w, http.StatusNotFound, "No such role: %s", v[0])
+		return
+	}
+	assigned[v[1]] = true
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) unassignRole(w http.ResponseWriter, _ *http.Request, v []string) {
+	assigned := s.cat.roles[v[0]]
+	if assigned == nil {
+		writeError(w, http.StatusNotFound, "No such role: %s", v[0])
+		return
+	}
+	delete(assigned, v[1])
+	w.WriteHeader(http.StatusOK)
+}
+
+// aclKinds are the rule sets under /rest/security/acl.
+var aclKinds = map[string]bool{"layers": true, "services": true, "rest": true}
+
+func (s *Server) aclRules(w http.ResponseWriter, kind string) (map[string]string, bool) {
+	if !aclKinds[kind] {
+		writeError(w, http.StatusNotFound, "No such ACL rule set: %s", kind)
+		return nil, false
+	}
+	return s.cat.acl[kind], true
+}
+
+// listACL writes the rule set as GeoServer does: one JSON object
+// mapping each rule to its comma-separated roles.
+func (s *Server) listACL(w http.ResponseWriter, _ *http.Request, v []string) {
+	rules, ok := s.aclRules(w, v[0])
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+func (s *Server) addACL(w http.ResponseWriter, r *http.Request, v []string) {
+	s.writeACL(w, r, v, false)
+}
+
+func (s *Server) updateACL(w http.ResponseWriter, r *http.Request, v []string) {
+	s.writeACL(w, r, v, true)
+}
+
+// writeACL applies a POST (all rules must be new) or PUT (all rules
+// must exist); GeoServer answers 409 and changes nothing otherwise.
+func (s *Server) writeACL(w http.ResponseWriter, r *http.Request, v []string, exists bool) {
+	rules, ok := s.aclRules(w, v[0])
+	if !ok {
+		return
+	}
+	var body map[string]string
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	for _, rule := range slices.Sorted(maps.Keys(body)) {
+		if _, found := rules[rule]; found != exists {
+			if exists {
+				writeError(w, http.StatusConflict, "Unexisting rules: %s", rule)
+			} else {
+				writeError(w, http.StatusConflict, "Already existing rules: %s", rule)
+			}
+			return
+		}
+	}
+	maps.Copy(rules, body)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteACL(w http.ResponseWriter, _ *http.Request, v []string) {
+	rules, ok := s.aclRules(w, v[0])
+	if !ok {
+		return
+	}
+	rule := v[1]
+	if v[0] == "rest" {
+		// The path form separates pattern and methods with ";".
+		rule = strings.Replace(rule, ";", ":", 1)
+	}
+	if _, found := rules[rule]; !found {
+		writeError(w, http.StatusNotFound, "No such rule: %s", rule)
+		return
+	}
+	delete(rules, rule)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,77 @@
+package geoservertest
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// Fixture describes a catalog for [Seed] and [Server.Seed] to create,
+// keyed by workspace name.
+//
+//	c := geoservertest.Seed(t, geoservertest.Fixture{
+//		"topp": {Datastores: map[string][]string{"pg": {"roads", "rivers"}}},
+//	})
+type Fixture map[string]FixtureWorkspace
+
+// FixtureWorkspace is the content of one workspace in a [Fixture].
+type FixtureWorkspace struct {
+	// Datastores maps PostGIS store names to the feature types
+	// published from them. Publishing creates each one's layer.
+	Datastores map[string][]string
+
+	// CoverageStores maps GeoTIFF store names to their coverages,
+	// which get layers too.
+	CoverageStores map[string][]string
+
+	// Styles are workspace styles, created without a body.
+	Styles []string
+}
+
+// Seed starts a [Server], creates f in it and returns a client for it.
+// opts are passed to [Server.Client].
+func Seed(tb testing.TB, f Fixture, opts ...geoserver.Option) *geoserver.Client {
+	tb.Helper()
+	s := New(tb, Options{})
+	s.Seed(f)
+	return s.Client(opts...)
+}
+
+// Seed creates f through the REST API, workspaces in name order, and
+// fails the test on the first error. Stores point at host "db" and
+// file "file:<store>.tif".
+func (s *Server) Seed(f Fixture) {
+	s.tb.Helper()
+	c, must := s.Client(), s.Must
+	ctx := context.Background()
+	for _, ws := range slices.Sorted(maps.Keys(f)) {
+		fw := f[ws]
+		must("workspace "+ws, c.Workspaces.Create(ctx, &workspaces.Workspace{Name: ws}))
+		for _, store := range slices.Sorted(maps.Keys(fw.Datastores)) {
+			must("datastore "+store, c.Datastores.InWorkspace(ws).Create(ctx, datastores.PostGIS{Name: store, Host: "db"}))
+			for _, ft := range fw.Datastores[store] {
+				must("feature type "+ft, c.FeatureTypes.InWorkspace(ws).InDatastore(store).Create(ctx, &featuretypes.FeatureType{Name: ft}))
+			}
+		}
+		for _, store := range slices.Sorted(maps.Keys(fw.CoverageStores)) {
+			must("coverage store "+store, c.CoverageStores.InWorkspace(ws).Create(ctx, &coveragestores.CoverageStore{
+				Name: store, Type: "GeoTIFF", URL: "file:" + store + ".tif",
+			}))
+			for _, cov := range fw.CoverageStores[store] {
+				must("coverage "+cov, c.Coverages.InWorkspace(ws).InCoverageStore(store).Create(ctx, &coverages.Coverage{Name: cov}))
+			}
+		}
+		for _, st := range fw.Styles {
+			must("style "+st, c.Styles.InWorkspace(ws).Create(ctx, &styles.Style{Name: st}))
+		}
+	}
+}
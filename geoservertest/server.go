@@ -0,0 +1,297 @@
+// Package geoservertest runs an in-memory fake GeoServer for unit
+// testing code built on [*geoserver.Client], without Docker.
+//
+//	srv := geoservertest.New(t, geoservertest.Options{})
+//	c := srv.Client()
+//	_ = c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"})
+//
+// The server keeps a catalog of workspaces, namespaces, datastores,
+// feature types, coverage stores, coverages, layers, layer groups,
+// styles, FreeMarker templates, GeoWebCache tile layers, users /
+// groups / roles, ACL rules and OWS service settings, and speaks the
+// same JSON wire shapes the rest/* sub-clients decode —
+// `{"entry":[{"@key":…,"$":…}]}` connection parameters,
+// `{"name":…,"href":…}` list entries, the bare-string
+// `{"dataStores":""}` / `{"styles":""}` empty lists, and the mixed
+// string/object layer-group style arrays.
+//
+// It answers the way GeoServer does where callers are likely to
+// branch on it: 404 for missing objects, 500 "… already exists" for
+// duplicates, 403 for deleting a non-empty workspace or store (or a
+// referenced resource or style) without recurse, 401 for bad
+// credentials. A few server-side effects are reproduced too: creating
+// a workspace creates its namespace and vice versa, publishing a
+// feature type or coverage creates its layer, datastore passwords
+// read back encrypted ("crypt1:…"), and file uploads create the store,
+// resource and layer.
+//
+// It does not render maps, read data, or validate that a published
+// table or file actually exists.
+package geoservertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+)
+
+// Default credentials accepted by a [Server] when [Options] leaves
+// them empty — the same as a stock GeoServer install.
+const (
+	DefaultUsername = "admin"
+	DefaultPassword = "geoserver"
+)
+
+// Options configures [New].
+type Options struct {
+	// Username and Password are the basic-auth credentials the
+	// server accepts. Default [DefaultUsername] / [DefaultPassword].
+	Username string
+	Password string
+}
+
+// Request is one request received by a [Server], as recorded by
+// [Server.Requests].
+type Request struct {
+	Method   string
+	Path     string
+	RawQuery string
+}
+
+// Server is a running fake GeoServer. It is safe for concurrent use;
+// requests are served one at a time.
+type Server struct {
+	// URL is the server root, suitable for [geoserver.New].
+	URL string
+
+	tb   testing.TB
+	opts Options
+	srv  *httptest.Server
+
+	mu   sync.Mutex
+	cat  *catalog
+	reqs []Request
+}
+
+// New starts a Server with a fresh catalog holding the stock global
+// styles (point, line, polygon, raster, generic) and the admin user.
+// The server is closed when tb's test ends.
+func New(tb testing.TB, opts Options) *Server {
+	tb.Helper()
+	if opts.Username == "" {
+		opts.Username = DefaultUsername
+	}
+	if opts.Password == "" {
+		opts.Password = DefaultPassword
+	}
+	s := &Server{tb: tb, opts: opts, cat: newCatalog(opts.Username)}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	tb.Cleanup(s.srv.Close)
+	return s
+}
+
+// Client returns a [*geoserver.Client] pointed at the server with the
+// configured credentials. opts are applied after the credentials, so
+// they may override them.
+func (s *Server) Client(opts ...geoserver.Option) *geoserver.Client {
+	s.tb.Helper()
+	all := append([]geoserver.Option{geoserver.WithBasicAuth(s.opts.Username, s.opts.Password)}, opts...)
+	c, err := geoserver.New(s.URL, all...)
+	if err != nil {
+		s.tb.Fatalf("geoservertest: geoserver.New: %v", err)
+	}
+	return c
+}
+
+// Must fails the server's test with what and err when err is non-nil.
+// It is the seeding helper for fixture objects [Server.Seed] doesn't
+// cover, built through [Server.Client]:
+//
+//	srv := geoservertest.New(t, geoservertest.Options{})
+//	c, must := srv.Client(), srv.Must
+//	must("workspace", c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"}))
+//
+// Like [testing.T.Fatalf] it must be called from the test goroutine.
+func (s *Server) Must(what string, err error) {
+	s.tb.Helper()
+	if err != nil {
+		s.tb.Fatalf("%s: %v", what, err)
+	}
+}
+
+// Close shuts the server down. It is called automatically at the end
+// of the test; calling it earlier simulates an unreachable server.
+func (s *Server) Close() { s.srv.Close() }
+
+// Requests returns every request received so far, in arrival order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.reqs)
+}
+
+// route is one REST endpoint. Segments of "*" in pattern match any
+// single path segment and are passed to handle in order; a final "**"
+// matches the rest of the path, passed as one "/"-joined var.
+type route struct {
+	method  string
+	pattern string
+	handle  func(s *Server, w http.ResponseWriter, r *http.Request, v []string)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reqs = append(s.reqs, Request{Method: r.Method, Path: r.URL.Path, RawQuery: r.URL.RawQuery})
+
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != s.opts.Username || pass != s.opts.Password {
+		w.Header().Set("WWW-Authenticate", `Basic realm="GeoServer Realm"`)
+		writeError(w, http.StatusUnauthorized, "HTTP Status 401 - Unauthorized")
+		return
+	}
+
+	rest, ok := strings.CutPrefix(r.URL.EscapedPath(), "/rest/")
+	if gwc, isGWC := strings.CutPrefix(r.URL.EscapedPath(), "/gwc/rest/"); isGWC {
+		// GeoWebCache routes are registered under "gwc/".
+		rest, ok = "gwc/"+gwc, true
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such resource: %s", r.URL.Path)
+		return
+	}
+	var segs []string
+	for _, raw := range strings.Split(strings.TrimSuffix(rest, "/"), "/") {
+		seg, err := url.PathUnescape(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "malformed path segment %q", raw)
+			return
+		}
+		segs = append(segs, seg)
+	}
+	if n := len(segs) - 1; r.Method == http.MethodGet {
+		segs[n] = strings.TrimSuffix(segs[n], ".json")
+	}
+
+	pathMatched := false
+	for _, rt := range routes {
+		vars, ok := matchPattern(rt.pattern, segs)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+		if rt.method == r.Method {
+			rt.handle(s, w, r, vars)
+			return
+		}
+	}
+	if pathMatched {
+		writeError(w, http.StatusMethodNotAllowed, "Request method '%s' not supported", r.Method)
+		return
+	}
+	writeError(w, http.StatusNotFound, "No such resource: %s", r.URL.Path)
+}
+
+func matchPattern(pattern string, segs []string) ([]string, bool) {
+	parts := strings.Split(pattern, "/")
+	rest := parts[len(parts)-1] == "**"
+	if rest {
+		parts = parts[:len(parts)-1]
+		if len(segs) <= len(parts) {
+			return nil, false
+		}
+	} else if len(parts) != len(segs) {
+		return nil, false
+	}
+	var vars []string
+	for i, p := range parts {
+		switch {
+		case p == "*":
+			vars = append(vars, segs[i])
+		case p != segs[i]:
+			return nil, false
+		}
+	}
+	if rest {
+		vars = append(vars, strings.Join(segs[len(parts):], "/"))
+	}
+	return vars, true
+}
+
+// href builds the absolute .json link GeoServer puts in list entries
+// and references.
+func (s *Server) href(parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, p := range parts {
+		escaped[i] = url.PathEscape(p)
+	}
+	return s.URL + "/rest/" + strings.Join(escaped, "/") + ".json"
+}
+
+// namedRef is the `{"name":…,"href":…}` shape of list entries.
+type namedRef struct {
+	Name string `json:"name"`
+	Href string `json:"href"`
+}
+
+// writeList writes GeoServer's `{"<outer>":{"<inner>":[…]}}`
+// collection envelope. With emptyAsString an empty collection is
+// written as `{"<outer>":""}`, the way GeoServer answers for the
+// collections whose sub-clients handle that form.
+func writeList[T any](w http.ResponseWriter, outer, inner string, entries []T, emptyAsString bool) {
+	if len(entries) == 0 && emptyAsString {
+		writeJSON(w, http.StatusOK, map[string]string{outer: ""})
+		return
+	}
+	if entries == nil {
+		entries = []T{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{outer: map[string]any{inner: entries}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a plain-text error body, which is what GeoServer's
+// REST layer sends for catalog failures.
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	w.Header().Set("Content-Type", "text/plain;charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, format, args...)
+}
+
+// created answers a successful POST the way GeoServer does: 201 with
+// the new object's name as the body and its URL in Location.
+func (s *Server) created(w http.ResponseWriter, name string, parts ...string) {
+	w.Header().Set("Location", strings.TrimSuffix(s.href(parts...), ".json"))
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte(name))
+}
+
+// decodeBody decodes a JSON request body into v, answering 400 on
+// failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Error parsing request body: %v", err)
+		return false
+	}
+	return true
+}
+
+// queryTrue reports whether query parameter key is "true".
+func queryTrue(r *http.Request, key string) bool {
+	return strings.EqualFold(r.URL.Query().Get(key), "true")
+}
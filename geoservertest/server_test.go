@@ -0,0 +1,518 @@
+package geoservertest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/gwc"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/namespaces"
+	"github.com/hishamkaram/geoserver/v2/rest/security"
+	"github.com/hishamkaram/geoserver/v2/rest/services"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// seed creates workspace "topp" with PostGIS store "pg" and feature
+// type "states" (and therefore layer "states").
+func seed(t *testing.T) (*geoservertest.Server, *geoserver.Client) {
+	t.Helper()
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"}); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	pg := datastores.PostGIS{Name: "pg", Host: "db", Port: 5432, Database: "gis", User: "u", Password: "secret"}
+	if err := c.Datastores.InWorkspace("topp").Create(ctx, pg); err != nil {
+		t.Fatalf("create datastore: %v", err)
+	}
+	ft := &featuretypes.FeatureType{Name: "states", Attributes: &featuretypes.Attributes{
+		Attribute: []featuretypes.Attribute{{Name: "the_geom", Binding: "org.locationtech.jts.geom.MultiPolygon"}},
+	}}
+	if err := c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Create(ctx, ft); err != nil {
+		t.Fatalf("create feature type: %v", err)
+	}
+	return srv, c
+}
+
+func TestWorkspaceAndNamespace(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+
+	if got, err := c.Workspaces.List(ctx, workspaces.ListOptions{}); err != nil || len(got) != 0 {
+		t.Fatalf("empty List = %v, %v", got, err)
+	}
+	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"})
+	if !errors.Is(err, geoserver.ErrAlreadyExists) {
+		t.Fatalf("duplicate Create = %v, want ErrAlreadyExists", err)
+	}
+	ns, err := c.Namespaces.Get(ctx, "topp")
+	if err != nil || ns.URI != "http://topp" {
+		t.Fatalf("namespace created with workspace = %+v, %v", ns, err)
+	}
+	isolated := true
+	if err := c.Workspaces.Update(ctx, "topp", &workspaces.WorkspacePatch{Isolated: &isolated}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if ws, err := c.Workspaces.Get(ctx, "topp"); err != nil || !ws.Isolated {
+		t.Fatalf("Get after Update = %+v, %v", ws, err)
+	}
+
+	if err := c.Namespaces.Create(ctx, &namespaces.Namespace{Prefix: "sf", URI: "http://sf.example"}); err != nil {
+		t.Fatalf("Namespaces.Create: %v", err)
+	}
+	got, err := c.Workspaces.List(ctx, workspaces.ListOptions{})
+	if err != nil || len(got) != 2 || got[0].Name != "sf" || got[1].Name != "topp" {
+		t.Fatalf("List = %+v, %v", got, err)
+	}
+
+	if _, err := c.Workspaces.Get(ctx, "nope"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("Get missing = %v, want ErrNotFound", err)
+	}
+	if err := c.Workspaces.Delete(ctx, "sf", workspaces.DeleteOptions{}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := c.Namespaces.Get(ctx, "sf"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("namespace after workspace Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDatastore_WireQuirks(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+	_ = c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"})
+	dc := c.Datastores.InWorkspace("topp")
+
+	// Empty datastore lists come back as {"dataStores":""}.
+	if got, err := dc.List(ctx, datastores.ListOptions{}); err != nil || len(got) != 0 {
+		t.Fatalf("empty List = %v, %v", got, err)
+	}
+	pg := datastores.PostGIS{Name: "pg", Host: "db", Port: 5432, Database: "gis", User: "u", Password: "secret"}
+	if err := dc.Create(ctx, pg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := dc.Create(ctx, pg); !errors.Is(err, geoserver.ErrAlreadyExists) {
+		t.Fatalf("duplicate Create = %v, want ErrAlreadyExists", err)
+	}
+	ds, err := dc.Get(ctx, "pg")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if ds.Type != "PostGIS" || !ds.Enabled || ds.Workspace == nil || ds.Workspace.Name != "topp" {
+		t.Fatalf("Get = %+v", ds)
+	}
+	params := map[string]string{}
+	for _, e := range ds.ConnectionParameters.Entry {
+		params[e.Key] = e.Value
+	}
+	ns, err := c.Namespaces.Get(ctx, "topp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["host"] != "db" || !strings.HasPrefix(params["passwd"], "crypt1:") || params["namespace"] != ns.URI {
+		t.Fatalf("connection params = %v", params)
+	}
+
+	disabled := false
+	if err := dc.Update(ctx, "pg", &datastores.Patch{Enabled: &disabled}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if ds, err := dc.Get(ctx, "pg"); err != nil || ds.Enabled || ds.Type != "PostGIS" {
+		t.Fatalf("Get after Update = %+v, %v", ds, err)
+	}
+}
+
+func TestPublishCreatesLayer(t *testing.T) {
+	_, c := seed(t)
+	ctx := context.Background()
+
+	ft, err := c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Get(ctx, "states")
+	if err != nil {
+		t.Fatalf("Get feature type: %v", err)
+	}
+	if ft.NativeName != "states" || ft.SRS != "EPSG:4326" || !ft.Enabled {
+		t.Fatalf("feature type defaults = %+v", ft)
+	}
+	lc := c.Layers.InWorkspace("topp")
+	l, err := lc.Get(ctx, "states")
+	if err != nil {
+		t.Fatalf("Get layer: %v", err)
+	}
+	if l.Type != "VECTOR" || l.DefaultStyle == nil || l.DefaultStyle.Name != "polygon" {
+		t.Fatalf("layer = %+v", l)
+	}
+	if l.Resource == nil || !strings.HasSuffix(l.Resource.Href, "/featuretypes/states.json") {
+		t.Fatalf("layer resource = %+v", l.Resource)
+	}
+
+	if err := lc.AddStyle(ctx, "states", "point", layers.AddStyleOptions{Default: true}); err != nil {
+		t.Fatalf("AddStyle: %v", err)
+	}
+	refs, err := lc.ListStyles(ctx, "states")
+	if err != nil || len(refs) != 1 || refs[0].Name != "point" {
+		t.Fatalf("ListStyles = %+v, %v", refs, err)
+	}
+	if l, _ := lc.Get(ctx, "states"); l.DefaultStyle.Name != "point" {
+		t.Fatalf("default style = %+v", l.DefaultStyle)
+	}
+}
+
+func TestUpdateLayer_Styles(t *testing.T) {
+	_, c := seed(t)
+	ctx := context.Background()
+	lc := c.Layers.InWorkspace("topp")
+
+	if err := lc.Update(ctx, "states", &layers.Layer{Styles: &layers.Styles{Style: []layers.Ref{{Name: "line"}, {Name: "point"}}}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	// A new list replaces the old one; an omitted default style stays.
+	if err := lc.Update(ctx, "states", &layers.Layer{Styles: &layers.Styles{Style: []layers.Ref{{Name: "raster"}}}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	l, err := lc.Get(ctx, "states")
+	if err != nil || len(l.Styles.Style) != 1 || l.Styles.Style[0].Name != "raster" || l.DefaultStyle.Name != "polygon" {
+		t.Fatalf("layer = %+v, %v", l, err)
+	}
+	// A rejected update leaves the layer as it was.
+	if err := lc.Update(ctx, "states", &layers.Layer{DefaultStyle: &layers.Ref{Name: "nope"}}); err == nil {
+		t.Fatal("missing default style accepted")
+	}
+	if l, _ := lc.Get(ctx, "states"); l.DefaultStyle.Name != "polygon" {
+		t.Fatalf("default style after rejected update = %+v", l.DefaultStyle)
+	}
+
+	// An omitted default style is not re-validated, even once dangling.
+	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "other"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Styles.InWorkspace("other").Create(ctx, &styles.Style{Name: "s"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lc.Update(ctx, "states", &layers.Layer{DefaultStyle: &layers.Ref{Name: "other:s"}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := c.Workspaces.Delete(ctx, "other", workspaces.DeleteOptions{Recurse: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lc.Update(ctx, "states", &layers.Layer{Styles: &layers.Styles{Style: []layers.Ref{{Name: "line"}}}}); err != nil {
+		t.Fatalf("Update with a dangling default style: %v", err)
+	}
+	l, err = lc.Get(ctx, "states")
+	if err != nil || len(l.Styles.Style) != 1 || l.Styles.Style[0].Name != "line" || l.DefaultStyle.Name != "other:s" {
+		t.Fatalf("layer = %+v, %v", l, err)
+	}
+}
+
+func TestDelete_RecurseRules(t *testing.T) {
+	_, c := seed(t)
+	ctx := context.Background()
+
+	err := c.Datastores.InWorkspace("topp").Delete(ctx, "pg", datastores.DeleteOptions{})
+	if !errors.Is(err, geoserver.ErrStillReferenced) || !errors.Is(err, geoserver.ErrForbidden) {
+		t.Fatalf("Delete non-empty store = %v, want ErrStillReferenced", err)
+	}
+	err = c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Delete(ctx, "states", featuretypes.DeleteOptions{})
+	if !errors.Is(err, geoserver.ErrStillReferenced) {
+		t.Fatalf("Delete published feature type = %v, want ErrStillReferenced", err)
+	}
+	err = c.Workspaces.Delete(ctx, "topp", workspaces.DeleteOptions{})
+	if !errors.Is(err, geoserver.ErrStillReferenced) {
+		t.Fatalf("Delete non-empty workspace = %v, want ErrStillReferenced", err)
+	}
+	err = c.Styles.Delete(ctx, "polygon", styles.DeleteOptions{})
+	if !errors.Is(err, geoserver.ErrStillReferenced) {
+		t.Fatalf("Delete style in use = %v, want ErrStillReferenced", err)
+	}
+
+	if err := c.Datastores.InWorkspace("topp").Delete(ctx, "pg", datastores.DeleteOptions{Recurse: true}); err != nil {
+		t.Fatalf("recursive Delete: %v", err)
+	}
+	if _, err := c.Layers.InWorkspace("topp").Get(ctx, "states"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("layer after recursive store Delete = %v, want ErrNotFound", err)
+	}
+	if err := c.Styles.Delete(ctx, "polygon", styles.DeleteOptions{}); err != nil {
+		t.Fatalf("Delete unreferenced style: %v", err)
+	}
+	if err := c.Workspaces.Delete(ctx, "topp", workspaces.DeleteOptions{}); err != nil {
+		t.Fatalf("Delete empty workspace: %v", err)
+	}
+}
+
+func TestLayerGroups(t *testing.T) {
+	_, c := seed(t)
+	ctx := context.Background()
+	gc := c.LayerGroups.InWorkspace("topp")
+
+	err := gc.Create(ctx, &layergroups.LayerGroup{
+		Name:         "base",
+		Publishables: layergroups.Publishables{Published: layergroups.Published{{Type: "layer", Name: "states"}}},
+		Styles:       layergroups.Styles{Style: []layergroups.Ref{{Name: ""}}},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	g, err := gc.Get(ctx, "base")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if g.Mode != "SINGLE" || len(g.Publishables.Published) != 1 || g.Publishables.Published[0].Name != "topp:states" {
+		t.Fatalf("Get = %+v", g)
+	}
+
+	err = gc.Create(ctx, &layergroups.LayerGroup{
+		Name:         "bad",
+		Publishables: layergroups.Publishables{Published: layergroups.Published{{Type: "layer", Name: "missing"}}},
+	})
+	if !errors.Is(err, geoserver.ErrBadRequest) {
+		t.Fatalf("Create with missing member = %v, want ErrBadRequest", err)
+	}
+	err = c.Layers.InWorkspace("topp").Delete(ctx, "states", layers.DeleteOptions{})
+	if !errors.Is(err, geoserver.ErrStillReferenced) {
+		t.Fatalf("Delete grouped layer = %v, want ErrStillReferenced", err)
+	}
+}
+
+func TestStyles(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+
+	got, err := c.Styles.List(ctx, styles.ListOptions{})
+	if err != nil || len(got) != 5 {
+		t.Fatalf("stock styles = %+v, %v", got, err)
+	}
+	_ = c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"})
+	sc := c.Styles.InWorkspace("topp")
+	if got, err := sc.List(ctx, styles.ListOptions{}); err != nil || len(got) != 0 {
+		t.Fatalf("empty workspace styles = %v, %v", got, err)
+	}
+	if err := sc.Create(ctx, &styles.Style{Name: "roads", Filename: "roads.sld"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	sld := `<StyledLayerDescriptor version="1.0.0"/>`
+	if err := sc.UploadSLD(ctx, "roads", strings.NewReader(sld), styles.UploadOptions{}); err != nil {
+		t.Fatalf("UploadSLD: %v", err)
+	}
+	st, err := sc.Get(ctx, "roads")
+	if err != nil || st.Format != "sld" {
+		t.Fatalf("Get = %+v, %v", st, err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/rest/workspaces/topp/styles/roads.sld", nil)
+	req.SetBasicAuth(geoservertest.DefaultUsername, geoservertest.DefaultPassword)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != sld {
+		t.Fatalf("SLD body = %q", body)
+	}
+}
+
+func TestUploads(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+	_ = c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"})
+
+	err := c.Datastores.InWorkspace("topp").UploadFile(ctx, "roads", strings.NewReader("zip"), datastores.UploadOptions{Extension: "shp"})
+	if err != nil {
+		t.Fatalf("datastore UploadFile: %v", err)
+	}
+	if ds, err := c.Datastores.InWorkspace("topp").Get(ctx, "roads"); err != nil || ds.Type != "Shapefile" {
+		t.Fatalf("uploaded store = %+v, %v", ds, err)
+	}
+	err = c.CoverageStores.InWorkspace("topp").UploadFile(ctx, "dem", strings.NewReader("tiff"), coveragestores.UploadOptions{Extension: "geotiff"})
+	if err != nil {
+		t.Fatalf("coverage store UploadFile: %v", err)
+	}
+	ls, err := c.Layers.InWorkspace("topp").List(ctx, layers.ListOptions{})
+	if err != nil || len(ls) != 2 || ls[0].Name != "dem" || ls[1].Name != "roads" {
+		t.Fatalf("layers = %+v, %v", ls, err)
+	}
+	if l, err := c.Layers.InWorkspace("topp").Get(ctx, "dem"); err != nil || l.Type != "RASTER" {
+		t.Fatalf("raster layer = %+v, %v", l, err)
+	}
+}
+
+func TestSecurity(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+
+	if err := c.Security.Users().Create(ctx, &security.User{Name: "alice", Password: "pw", Enabled: true}); err != nil {
+		t.Fatalf("Users.Create: %v", err)
+	}
+	users, err := c.Security.Users().List(ctx, security.ListOptions{})
+	if err != nil || len(users) != 2 || users[1].Name != "alice" || users[1].Password != "" {
+		t.Fatalf("Users.List = %+v, %v", users, err)
+	}
+	if err := c.Security.Roles.Create(ctx, "EDITOR"); err != nil {
+		t.Fatalf("Roles.Create: %v", err)
+	}
+	if err := c.Security.Roles.AssignToUser(ctx, "EDITOR", "alice"); err != nil {
+		t.Fatalf("AssignToUser: %v", err)
+	}
+	if roles, err := c.Security.Roles.ForUser(ctx, "alice"); err != nil || len(roles) != 1 || roles[0] != "EDITOR" {
+		t.Fatalf("ForUser = %v, %v", roles, err)
+	}
+	if err := c.Security.Groups().Create(ctx, "editors"); err != nil {
+		t.Fatalf("Groups.Create: %v", err)
+	}
+	if groups, err := c.Security.Groups().List(ctx, security.ListOptions{}); err != nil || len(groups) != 1 {
+		t.Fatalf("Groups.List = %v, %v", groups, err)
+	}
+	if err := c.Security.Users().Delete(ctx, "alice"); err != nil {
+		t.Fatalf("Users.Delete: %v", err)
+	}
+	if roles, err := c.Security.Roles.ForUser(ctx, "alice"); err != nil || len(roles) != 0 {
+		t.Fatalf("ForUser after Delete = %v, %v", roles, err)
+	}
+}
+
+func TestAuthAndRequests(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{Username: "ops", Password: "pw"})
+	ctx := context.Background()
+
+	bad, err := geoserver.New(srv.URL, geoserver.WithBasicAuth("admin", "geoserver"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bad.Workspaces.List(ctx, workspaces.ListOptions{}); !errors.Is(err, geoserver.ErrUnauthorized) {
+		t.Fatalf("bad credentials = %v, want ErrUnauthorized", err)
+	}
+	if _, err := srv.Client().Workspaces.List(ctx, workspaces.ListOptions{}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 2 || reqs[1].Method != http.MethodGet || reqs[1].Path != "/rest/workspaces" {
+		t.Fatalf("Requests = %+v", reqs)
+	}
+}
+
+func TestACLAndServices(t *testing.T) {
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	ctx := context.Background()
+
+	rule := acl.Rule{Workspace: "topp", Layer: "*", Operation: acl.OpRead, Roles: []string{"READER"}}
+	if err := c.ACL.Layers().Add(ctx, rule); err != nil {
+		t.Fatalf("Layers.Add: %v", err)
+	}
+	if err := c.ACL.Layers().Add(ctx, rule); !errors.Is(err, geoserver.ErrConflict) {
+		t.Fatalf("second Add = %v, want ErrConflict", err)
+	}
+	if rules, err := c.ACL.Layers().List(ctx, acl.ListOptions{}); err != nil || len(rules) != 3 {
+		t.Fatalf("Layers.List = %+v, %v", rules, err)
+	}
+	if err := c.ACL.Layers().Delete(ctx, rule); err != nil {
+		t.Fatalf("Layers.Delete: %v", err)
+	}
+	rest := acl.RESTRule{Pattern: "/rest/workspaces/**", Methods: []string{"GET"}, Roles: []string{"ADMIN"}}
+	if err := c.ACL.REST().Add(ctx, rest); err != nil {
+		t.Fatalf("REST.Add: %v", err)
+	}
+	if err := c.ACL.REST().Delete(ctx, rest); err != nil {
+		t.Fatalf("REST.Delete: %v", err)
+	}
+
+	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"}); err != nil {
+		t.Fatal(err)
+	}
+	wms := c.Services.WMS().InWorkspace("topp")
+	if _, err := wms.Get(ctx); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("workspace Get before override = %v, want ErrNotFound", err)
+	}
+	if err := wms.Update(ctx, &services.WMSSettings{ServiceInfo: services.ServiceInfo{Title: "Topp"}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if s, err := wms.Get(ctx); err != nil || s.Title != "Topp" || !s.Enabled {
+		t.Fatalf("workspace Get = %+v, %v", s, err)
+	}
+	if s, err := c.Services.WMS().Get(ctx); err != nil || s.Title != "" || s.Name != "WMS" {
+		t.Fatalf("global Get = %+v, %v", s, err)
+	}
+}
+
+func TestTemplatesAndTileLayers(t *testing.T) {
+	_, c := seed(t)
+	ctx := context.Background()
+
+	tc := c.Templates.InWorkspace("topp").InDatastore("pg").InFeatureType("states")
+	if refs, err := tc.List(ctx); err != nil || len(refs) != 0 {
+		t.Fatalf("empty List = %+v, %v", refs, err)
+	}
+	if err := tc.PutString(ctx, "title", "${name}"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if refs, err := tc.List(ctx); err != nil || len(refs) != 1 || refs[0].Name != "title.ftl" {
+		t.Fatalf("List = %+v, %v", refs, err)
+	}
+	if body, err := tc.Get(ctx, "title.ftl"); err != nil || body != "${name}" {
+		t.Fatalf("Get = %q, %v", body, err)
+	}
+	if err := c.Templates.InWorkspace("nope").PutString(ctx, "title", ""); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("Put in missing scope = %v, want ErrNotFound", err)
+	}
+
+	cfg := &gwc.LayerConfig{Name: "topp:states", Enabled: true}
+	if err := c.GWC.Layers().Put(ctx, "topp:states", cfg); err != nil {
+		t.Fatalf("GWC Put: %v", err)
+	}
+	if names, err := c.GWC.Layers().List(ctx); err != nil || len(names) != 1 || names[0] != "topp:states" {
+		t.Fatalf("GWC List = %v, %v", names, err)
+	}
+	if err := c.Workspaces.Delete(ctx, "topp", workspaces.DeleteOptions{Recurse: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GWC.Layers().Get(ctx, "topp:states"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("GWC Get after workspace Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	c := geoservertest.Seed(t, geoservertest.Fixture{
+		"topp": {
+			Datastores:     map[string][]string{"pg": {"roads", "rivers"}, "empty": nil},
+			CoverageStores: map[string][]string{"dem": {"elevation"}},
+			Styles:         []string{"blue"},
+		},
+		"other": {},
+	})
+	ctx := context.Background()
+	inv, err := c.Catalog().Inventory(ctx, geoserver.InventoryOptions{NamesOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Workspace("other") == nil {
+		t.Fatal("workspace other not created")
+	}
+	topp := inv.Workspace("topp")
+	if topp == nil || topp.Datastore("pg") == nil || len(topp.Datastore("pg").FeatureTypes) != 2 ||
+		topp.Datastore("empty") == nil || topp.CoverageStore("dem") == nil || topp.Style("blue") == nil {
+		t.Fatalf("topp = %+v", topp)
+	}
+	for _, l := range []string{"roads", "rivers", "elevation"} {
+		if _, err := c.Layers.InWorkspace("topp").Get(ctx, l); err != nil {
+			t.Fatalf("layer %s: %v", l, err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package geoservertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+)
+
+// serviceSlugs are the OWS services with settings under
+// /rest/services/{slug}/settings.
+var serviceSlugs = []string{"wms", "wfs", "wcs", "wmts"}
+
+// serviceScope resolves the service slug (v[0]) and optional workspace
+// (v[1]) of a settings request.
+func (s *Server) serviceScope(w http.ResponseWriter, v []string) (slug, ws string, ok bool) {
+	if !slices.Contains(serviceSlugs, v[0]) {
+		writeError(w, http.StatusNotFound, "No such service: %s", v[0])
+		return "", "", false
+	}
+	if len(v) > 1 {
+		if _, ok := s.workspace(w, v[1]); !ok {
+			return "", "", false
+		}
+		return v[0], v[1], true
+	}
+	return v[0], "", true
+}
+
+func (s *Server) getServiceSettings(w http.ResponseWriter, _ *http.Request, v []string) {
+	slug, ws, ok := s.serviceScope(w, v)
+	if !ok {
+		return
+	}
+	settings := s.cat.services[slug][ws]
+	if settings == nil {
+		writeError(w, http.StatusNotFound, "No %s settings for workspace %s", slug, ws)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{slug: settings})
+}
+
+// updateServiceSettings merges the body into the stored settings,
+// creating a workspace override if there is none.
+func (s *Server) updateServiceSettings(w http.ResponseWriter, r *http.Request, v []string) {
+	slug, ws, ok := s.serviceScope(w, v)
+	if !ok {
+		return
+	}
+	var body map[string]json.RawMessage
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	settings := s.cat.services[slug][ws]
+	if settings == nil {
+		settings = map[string]any{"enabled": true, "name": s.cat.services[slug][""]["name"]}
+	}
+	if raw, ok := body[slug]; ok {
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			writeError(w, http.StatusBadRequest, "Error parsing request body: %v", err)
+			return
+		}
+	}
+	s.cat.services[slug][ws] = settings
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteServiceSettings(w http.ResponseWriter, _ *http.Request, v []string) {
+	slug, ws, ok := s.serviceScope(w, v)
+	if !ok {
+		return
+	}
+	if s.cat.services[slug][ws] == nil {
+		writeError(w, http.StatusNotFound, "No %s settings for workspace %s", slug, ws)
+		return
+	}
+	delete(s.cat.services[slug], ws)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,113 @@
+package geoservertest
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// templateHandler adapts a template handler to a route at the scope
+// named by kinds ("workspaces", "datastores", …), whose names are the
+// leading route vars. A trailing var is the template name.
+func templateHandler(fn func(s *Server, w http.ResponseWriter, r *http.Request, scope, name string), kinds ...string) func(*Server, http.ResponseWriter, *http.Request, []string) {
+	return func(s *Server, w http.ResponseWriter, r *http.Request, v []string) {
+		var parts []string
+		for i, kind := range kinds {
+			parts = append(parts, kind, v[i])
+		}
+		if !s.cat.scopeExists(parts) {
+			writeError(w, http.StatusNotFound, "No such scope: %s", strings.Join(parts, "/"))
+			return
+		}
+		name := ""
+		if len(v) > len(kinds) {
+			name = v[len(kinds)]
+			if !strings.HasSuffix(name, ".ftl") {
+				name += ".ftl"
+			}
+		}
+		fn(s, w, r, strings.Join(parts, "/"), name)
+	}
+}
+
+// scopeExists reports whether the catalog object at a template scope
+// path exists.
+func (c *catalog) scopeExists(parts []string) bool {
+	if len(parts) == 0 {
+		return true
+	}
+	ws := c.workspaces[parts[1]]
+	if ws == nil {
+		return false
+	}
+	if len(parts) == 2 {
+		return true
+	}
+	switch parts[2] {
+	case "datastores":
+		ds := ws.datastores[parts[3]]
+		return ds != nil && (len(parts) == 4 || ds.featureTypes[parts[5]] != nil)
+	case "coveragestores":
+		cs := ws.coverageStores[parts[3]]
+		return cs != nil && (len(parts) == 4 || cs.coverages[parts[5]] != nil)
+	}
+	return false
+}
+
+func listTemplates(s *Server, w http.ResponseWriter, _ *http.Request, scope, _ string) {
+	type info struct {
+		Name string `json:"name"`
+		Href string `json:"href"`
+	}
+	var out []info
+	for _, name := range slices.Sorted(maps.Keys(s.cat.templates[scope])) {
+		href := fmt.Sprintf("%s/rest/%s/templates/%s.json", s.URL, scope, name)
+		if scope == "" {
+			href = s.href("templates", name)
+		}
+		out = append(out, info{Name: name, Href: href})
+	}
+	if len(out) == 0 {
+		// An empty listing collapses to an empty object.
+		writeJSON(w, http.StatusOK, map[string]any{"org.geoserver.rest.catalog.TemplateInfos": map[string]any{}})
+		return
+	}
+	writeList(w, "org.geoserver.rest.catalog.TemplateInfos", "org.geoserver.rest.catalog.TemplateInfo", out, false)
+}
+
+func getTemplate(s *Server, w http.ResponseWriter, _ *http.Request, scope, name string) {
+	body, ok := s.cat.templates[scope][name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Template not found: %s", name)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write(body)
+}
+
+func putTemplate(s *Server, w http.ResponseWriter, r *http.Request, scope, name string) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if s.cat.templates[scope] == nil {
+		s.cat.templates[scope] = map[string][]byte{}
+	}
+	status := http.StatusOK
+	if _, exists := s.cat.templates[scope][name]; !exists {
+		status = http.StatusCreated
+	}
+	s.cat.templates[scope][name] = body
+	w.WriteHeader(status)
+}
+
+func deleteTemplate(s *Server, w http.ResponseWriter, _ *http.Request, scope, name string) {
+	if _, ok := s.cat.templates[scope][name]; !ok {
+		writeError(w, http.StatusNotFound, "Template not found: %s", name)
+		return
+	}
+	delete(s.cat.templates[scope], name)
+	w.WriteHeader(http.StatusOK)
+}
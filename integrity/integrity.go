@@ -0,0 +1,165 @@
+// Package integrity checks a GeoServer catalog for dangling references
+// and repairs the ones that are safe to fix.
+//
+//	r, err := integrity.Validate(ctx, c)
+//	fmt.Print(r)                   // findings, worst first
+//	repaired, err := r.Repair(ctx) // the safe fixes only
+//
+// [Validate] crawls the catalog through the sub-clients and builds a
+// reference graph: layers to their resource and styles, layer groups
+// to their members and style overrides, stores to their feature types
+// and coverages, workspace GeoWebCache tile layers to their layer or
+// group, and layer ACL rules to their workspace and layer. Global
+// layer groups aren't crawled, so their tile layers are skipped. Every edge whose
+// target does not exist becomes a [Finding] with a severity and a
+// suggested fix.
+//
+// Only fixes that drop a reference the server already cannot resolve
+// are repaired automatically: a layer's missing alternate styles, a
+// group's missing members (while others remain) and missing style
+// overrides, and tile layers without a catalog layer. A missing
+// default style or resource needs a decision, and ACL rules are left
+// to an administrator; those findings carry a fix description only.
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+)
+
+// Severity ranks a finding.
+type Severity int
+
+// Severities, from least to most severe.
+const (
+	// Info marks catalog hygiene: an empty store, an unpublished
+	// resource.
+	Info Severity = iota
+	// Warning marks a dangling reference GeoServer tolerates: an
+	// alternate style, a tile layer, an ACL rule.
+	Warning
+	// Error marks a dangling reference that breaks rendering or
+	// publishing.
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Check identifies what a finding is about.
+type Check string
+
+// Checks.
+const (
+	CheckLayerResource     Check = "layer-resource"      // layer → missing feature type or coverage
+	CheckLayerDefaultStyle Check = "layer-default-style" // layer → missing default style
+	CheckLayerStyle        Check = "layer-style"         // layer → missing alternate style
+	CheckGroupMember       Check = "group-member"        // layer group → missing layer or group
+	CheckGroupStyle        Check = "group-style"         // layer group → missing style override
+	CheckEmptyStore        Check = "empty-store"         // store without feature types or coverages
+	CheckUnpublished       Check = "unpublished"         // feature type or coverage without a layer
+	CheckTileLayer         Check = "tile-layer"          // tile layer → missing layer or group
+	CheckACLWorkspace      Check = "acl-workspace"       // ACL rule → missing workspace
+	CheckACLLayer          Check = "acl-layer"           // ACL rule → missing layer or group
+)
+
+// Finding is one integrity problem.
+type Finding struct {
+	Check    Check
+	Severity Severity
+
+	// Ref is the object holding the reference; Target is what it
+	// points at. Target is zero for CheckEmptyStore and
+	// CheckUnpublished.
+	Ref    geoserver.Ref
+	Target geoserver.Ref
+
+	Message string
+
+	// Fix describes the suggested fix.
+	Fix string
+
+	repair func(ctx context.Context) error
+}
+
+// Repairable reports whether [Report.Repair] fixes the finding.
+func (f Finding) Repairable() bool { return f.repair != nil }
+
+// String renders f as "severity ref: message (fix: …)".
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s: %s (fix: %s)", f.Severity, f.Ref, f.Message, f.Fix)
+}
+
+// Edge is one reference in the catalog graph.
+type Edge struct {
+	From, To geoserver.Ref
+}
+
+// Report is the result of [Validate].
+type Report struct {
+	// Findings are sorted by severity, worst first, then by Ref.
+	Findings []Finding
+
+	// Edges is the reference graph, dangling edges included.
+	Edges []Edge
+}
+
+// Count returns the number of findings with severity s.
+func (r *Report) Count(s Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+// String renders one finding per line, "*" marking the repairable
+// ones, followed by a summary.
+func (r *Report) String() string {
+	var b strings.Builder
+	repairable := 0
+	for _, f := range r.Findings {
+		mark := " "
+		if f.Repairable() {
+			mark = "*"
+			repairable++
+		}
+		fmt.Fprintf(&b, "%s %s\n", mark, f)
+	}
+	fmt.Fprintf(&b, "%d errors, %d warnings, %d info; %d repairable\n",
+		r.Count(Error), r.Count(Warning), r.Count(Info), repairable)
+	return b.String()
+}
+
+// Repair applies the fixes of the repairable findings in order and
+// returns the findings it fixed. It stops at the first failure.
+// Findings on the same object share one fix, applied once it succeeds;
+// calling Repair again after a failure retries the fixes not yet
+// applied.
+func (r *Report) Repair(ctx context.Context) ([]Finding, error) {
+	var done []Finding
+	for _, f := range r.Findings {
+		if !f.Repairable() {
+			continue
+		}
+		if err := f.repair(ctx); err != nil {
+			return done, fmt.Errorf("integrity: repair %s: %w", f.Ref, err)
+		}
+		done = append(done, f)
+	}
+	return done, nil
+}
@@ -0,0 +1,194 @@
+package integrity_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/integrity"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/gwc"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// broken builds a catalog whose references into workspace "gone" were
+// left dangling when it was deleted, plus a stray tile layer, ACL
+// rules and an empty store. opts are passed to [geoservertest.Server.Client].
+func broken(t *testing.T, opts ...geoserver.Option) *geoserver.Client {
+	t.Helper()
+	srv := geoservertest.New(t, geoservertest.Options{})
+	srv.Seed(geoservertest.Fixture{
+		"gone": {Datastores: map[string][]string{"gs": {"gl"}}, Styles: []string{"s", "s2"}},
+		"topp": {Datastores: map[string][]string{"pg": {"roads", "parks"}, "empty": nil}},
+	})
+	c, must := srv.Client(opts...), srv.Must
+	ctx := context.Background()
+	lc := c.Layers.InWorkspace("topp")
+	must("layer", lc.Update(ctx, "roads", &layers.Layer{
+		DefaultStyle: &layers.Ref{Name: "gone:s"},
+		Styles:       &layers.Styles{Style: []layers.Ref{{Name: "gone:s2"}, {Name: "line"}}},
+	}))
+	must("layer", lc.Update(ctx, "parks", &layers.Layer{Styles: &layers.Styles{Style: []layers.Ref{{Name: "gone:s2"}}}}))
+	gc := c.LayerGroups.InWorkspace("topp")
+	must("group", gc.Create(ctx, &layergroups.LayerGroup{
+		Name: "g",
+		Publishables: layergroups.Publishables{Published: layergroups.Published{
+			{Type: "layer", Name: "topp:parks"}, {Type: "layer", Name: "gone:gl"},
+		}},
+		Styles: layergroups.Styles{Style: []layergroups.Ref{{Name: "gone:s"}, {Name: ""}}},
+	}))
+	must("group", gc.Create(ctx, &layergroups.LayerGroup{
+		Name:         "dead",
+		Publishables: layergroups.Publishables{Published: layergroups.Published{{Type: "layer", Name: "gone:gl"}}},
+	}))
+	must("acl", c.ACL.Layers().Add(ctx, acl.Rule{Workspace: "gone", Layer: "*", Operation: acl.OpRead, Roles: []string{"R"}}))
+	must("acl", c.ACL.Layers().Add(ctx, acl.Rule{Workspace: "topp", Layer: "ghost", Operation: acl.OpRead, Roles: []string{"R"}}))
+	must("acl", c.ACL.Layers().Add(ctx, acl.Rule{Workspace: "topp", Layer: "g", Operation: acl.OpRead, Roles: []string{"R"}}))
+	must("tile layer", c.GWC.Layers().Put(ctx, "topp:ghost", &gwc.LayerConfig{Name: "topp:ghost"}))
+	must("tile layer", c.GWC.Layers().Put(ctx, "topp:roads", &gwc.LayerConfig{Name: "topp:roads"}))
+
+	must("delete gone", c.Workspaces.Delete(ctx, "gone", workspaces.DeleteOptions{Recurse: true}))
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	c := broken(t)
+	r, err := integrity.Validate(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"  error layer topp:roads: default style gone:s does not exist (fix: set an existing default style)",
+		"  error layergroup topp:dead: member gone:gl does not exist (fix: delete the group: none of its members exist)",
+		"* error layergroup topp:g: member gone:gl does not exist (fix: drop it from the group)",
+		"* error layergroup topp:g: style gone:s does not exist (fix: reset the member to its layer's default style)",
+		"  warning aclrule gone.*.r: workspace gone does not exist (fix: delete the rule, or keep it to protect a workspace that will be recreated)",
+		"  warning aclrule topp.ghost.r: no layer or layer group topp:ghost (fix: delete the rule, or keep it to protect a layer that will be recreated)",
+		"* warning layer topp:parks: alternate style gone:s2 does not exist (fix: drop it from the layer's styles)",
+		"* warning layer topp:roads: alternate style gone:s2 does not exist (fix: drop it from the layer's styles)",
+		"* warning tilelayer topp:ghost: no layer or layer group topp:ghost (fix: delete the tile layer)",
+		"  info datastore topp:empty: store has no feature types or coverages (fix: publish a resource from it or delete the store)",
+		"4 errors, 5 warnings, 1 info; 5 repairable",
+	}
+	if got := strings.TrimRight(r.String(), "\n"); got != strings.Join(want, "\n") {
+		t.Fatalf("report:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+	for _, f := range r.Findings {
+		if f.Check == integrity.CheckGroupStyle && f.Target != (geoserver.Ref{Kind: geoserver.KindStyle, Workspace: "gone", Name: "s"}) {
+			t.Fatalf("group style target = %+v", f.Target)
+		}
+	}
+	edge := integrity.Edge{
+		From: geoserver.Ref{Kind: geoserver.KindDatastore, Workspace: "topp", Name: "pg"},
+		To:   geoserver.Ref{Kind: geoserver.KindFeatureType, Workspace: "topp", Store: "pg", Name: "roads"},
+	}
+	found := false
+	for _, e := range r.Edges {
+		found = found || e == edge
+	}
+	if !found {
+		t.Fatalf("graph lacks %v", edge)
+	}
+}
+
+func TestValidate_GlobalGroupTileLayer(t *testing.T) {
+	ctx := context.Background()
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	// A global layer group's tile layer carries no workspace prefix.
+	srv.Must("tile layer", c.GWC.Layers().Put(ctx, "basemap", &gwc.LayerConfig{Name: "basemap"}))
+	r, err := integrity.Validate(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Findings) != 0 {
+		t.Fatalf("findings = %v, want none", r.Findings)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	ctx := context.Background()
+	c := broken(t)
+	r, err := integrity.Validate(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repaired, err := r.Repair(ctx)
+	if err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	if len(repaired) != 5 {
+		t.Fatalf("repaired %d findings", len(repaired))
+	}
+
+	after, err := integrity.Validate(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Count(integrity.Error) != 2 || after.Count(integrity.Warning) != 2 {
+		t.Fatalf("after repair:\n%s", after)
+	}
+	for _, f := range after.Findings {
+		if f.Repairable() {
+			t.Fatalf("still repairable after repair: %s", f)
+		}
+	}
+	g, err := c.LayerGroups.InWorkspace("topp").Get(ctx, "g")
+	if err != nil || len(g.Publishables.Published) != 1 || g.Publishables.Published[0].Name != "topp:parks" {
+		t.Fatalf("group = %+v, %v", g, err)
+	}
+	l, err := c.Layers.InWorkspace("topp").Get(ctx, "roads")
+	if err != nil || len(l.Styles.Style) != 1 || l.Styles.Style[0].Name != "line" || l.DefaultStyle.Name != "gone:s" {
+		t.Fatalf("layer = %+v, %v", l, err)
+	}
+}
+
+// failFirstWrite fails the first non-GET request it sees.
+type failFirstWrite struct {
+	armed, failed bool
+}
+
+func (f *failFirstWrite) RoundTrip(req *http.Request) (*http.Response, error) {
+	if f.armed && !f.failed && req.Method != http.MethodGet {
+		f.failed = true
+		return nil, errors.New("connection reset")
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRepair_RetriesFailedFix(t *testing.T) {
+	ctx := context.Background()
+	rt := &failFirstWrite{}
+	c := broken(t, geoserver.WithTransport(rt))
+	r, err := integrity.Validate(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt.armed = true
+	if repaired, err := r.Repair(ctx); err == nil || len(repaired) != 0 {
+		t.Fatalf("first Repair = %d findings, %v; want the first fix to fail", len(repaired), err)
+	}
+	repaired, err := r.Repair(ctx)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if len(repaired) != 5 {
+		t.Fatalf("retry repaired %d findings", len(repaired))
+	}
+	after, err := integrity.Validate(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range after.Findings {
+		if f.Repairable() {
+			t.Fatalf("still repairable after retry: %s", f)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package integrity
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+)
+
+// Validate crawls the catalog of c and reports its dangling
+// references. It changes nothing; see [Report.Repair].
+func Validate(ctx context.Context, c *geoserver.Client) (*Report, error) {
+	if c == nil {
+		return nil, errors.New("integrity: nil client")
+	}
+	v := &validator{c: c, exists: map[geoserver.Ref]bool{}, resources: map[string]geoserver.Ref{}, fixes: map[geoserver.Ref]func(context.Context) error{}}
+	if err := v.crawl(ctx); err != nil {
+		return nil, fmt.Errorf("integrity: %w", err)
+	}
+	v.check()
+	slices.SortStableFunc(v.report.Findings, func(a, b Finding) int {
+		return cmp.Or(cmp.Compare(b.Severity, a.Severity), cmp.Compare(a.Ref.String(), b.Ref.String()))
+	})
+	return &v.report, nil
+}
+
+type validator struct {
+	c      *geoserver.Client
+	report Report
+
+	exists    map[geoserver.Ref]bool
+	resources map[string]geoserver.Ref // "ws:name" → feature type or coverage
+	stores    []storeInfo
+	layers    []layerInfo
+	groups    []groupInfo
+	tiles     []string
+	rules     []acl.Rule
+
+	// fixes holds one repair per object, shared by its findings.
+	fixes map[geoserver.Ref]func(context.Context) error
+}
+
+type storeInfo struct {
+	ref       geoserver.Ref
+	resources []geoserver.Ref
+}
+
+type layerInfo struct {
+	ref   geoserver.Ref
+	layer *layers.Layer
+}
+
+type groupInfo struct {
+	ref   geoserver.Ref
+	group *layergroups.LayerGroup
+}
+
+// crawl reads the catalog tree with [geoserver.Catalog.Inventory],
+// then the tile layers and layer ACL rules it doesn't cover.
+func (v *validator) crawl(ctx context.Context) error {
+	c := v.c
+	inv, err := c.Catalog().Inventory(ctx, geoserver.InventoryOptions{})
+	if err != nil {
+		return err
+	}
+	if err := inv.Err(); err != nil {
+		return err
+	}
+	for _, s := range inv.Styles {
+		v.exists[geoserver.Ref{Kind: geoserver.KindStyle, Name: s.Style.Name}] = true
+	}
+	for _, iw := range inv.Workspaces {
+		v.crawlWorkspace(iw)
+	}
+	v.tiles, err = c.GWC.Layers().List(ctx)
+	if err != nil && !errors.Is(err, geoserver.ErrNotFound) {
+		return err
+	}
+	v.rules, err = c.ACL.Layers().List(ctx, acl.ListOptions{})
+	return err
+}
+
+func (v *validator) crawlWorkspace(iw *geoserver.InventoryWorkspace) {
+	ws := iw.Workspace.Name
+	v.exists[geoserver.Ref{Kind: geoserver.KindWorkspace, Name: ws}] = true
+	for _, s := range iw.Styles {
+		v.exists[geoserver.Ref{Kind: geoserver.KindStyle, Workspace: ws, Name: s.Style.Name}] = true
+	}
+	for _, ds := range iw.Datastores {
+		store := storeInfo{ref: geoserver.Ref{Kind: geoserver.KindDatastore, Workspace: ws, Name: ds.Datastore.Name}}
+		for _, ft := range ds.FeatureTypes {
+			store.resources = append(store.resources, v.resource(geoserver.KindFeatureType, ws, ds.Datastore.Name, ft.FeatureType.Name))
+		}
+		v.stores = append(v.stores, store)
+	}
+	for _, cs := range iw.CoverageStores {
+		store := storeInfo{ref: geoserver.Ref{Kind: geoserver.KindCoverageStore, Workspace: ws, Name: cs.CoverageStore.Name}}
+		for _, cov := range cs.Coverages {
+			store.resources = append(store.resources, v.resource(geoserver.KindCoverage, ws, cs.CoverageStore.Name, cov.Coverage.Name))
+		}
+		v.stores = append(v.stores, store)
+	}
+	for _, l := range iw.Layers {
+		ref := geoserver.Ref{Kind: geoserver.KindLayer, Workspace: ws, Name: l.Layer.Name}
+		v.exists[ref] = true
+		v.layers = append(v.layers, layerInfo{ref: ref, layer: l.Layer})
+	}
+	for _, g := range iw.LayerGroups {
+		ref := geoserver.Ref{Kind: geoserver.KindLayerGroup, Workspace: ws, Name: g.LayerGroup.Name}
+		v.exists[ref] = true
+		v.groups = append(v.groups, groupInfo{ref: ref, group: g.LayerGroup})
+	}
+}
+
+func (v *validator) resource(kind, ws, store, name string) geoserver.Ref {
+	ref := geoserver.Ref{Kind: kind, Workspace: ws, Store: store, Name: name}
+	v.exists[ref] = true
+	v.resources[ws+":"+name] = ref
+	return ref
+}
+
+// edge records from → to and reports whether to exists.
+func (v *validator) edge(from, to geoserver.Ref) bool {
+	v.report.Edges = append(v.report.Edges, Edge{From: from, To: to})
+	return v.exists[to]
+}
+
+func (v *validator) find(f Finding) {
+	v.report.Findings = append(v.report.Findings, f)
+}
+
+// fix returns the repair of ref, running fn until it first succeeds
+// however many findings share it, so a failed repair is retried by the
+// next [Report.Repair].
+func (v *validator) fix(ref geoserver.Ref, fn func(context.Context) error) func(context.Context) error {
+	if f, ok := v.fixes[ref]; ok {
+		return f
+	}
+	done := false
+	f := func(ctx context.Context) error {
+		if done {
+			return nil
+		}
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		done = true
+		return nil
+	}
+	v.fixes[ref] = f
+	return f
+}
+
+// styleRef resolves a style name as GeoServer does: "ws:name" is a
+// workspace style; a bare name is the workspace's own style if it has
+// one, else a global style.
+func (v *validator) styleRef(ws, name string) geoserver.Ref {
+	if prefix, local, ok := strings.Cut(name, ":"); ok {
+		return geoserver.Ref{Kind: geoserver.KindStyle, Workspace: prefix, Name: local}
+	}
+	if ref := (geoserver.Ref{Kind: geoserver.KindStyle, Workspace: ws, Name: name}); v.exists[ref] {
+		return ref
+	}
+	return geoserver.Ref{Kind: geoserver.KindStyle, Name: name}
+}
+
+// memberRef resolves a layer-group member.
+func memberRef(ws string, m layergroups.PublishedItem) geoserver.Ref {
+	kind := geoserver.KindLayer
+	if m.Type == "layerGroup" {
+		kind = geoserver.KindLayerGroup
+	}
+	prefix, name, ok := strings.Cut(m.Name, ":")
+	if !ok {
+		prefix, name = ws, m.Name
+	}
+	return geoserver.Ref{Kind: kind, Workspace: prefix, Name: name}
+}
+
+func (v *validator) check() {
+	published := map[string]bool{}
+	for _, l := range v.layers {
+		published[v.checkLayer(l)] = true
+	}
+	for _, s := range v.stores {
+		if len(s.resources) == 0 {
+			v.find(Finding{
+				Check: CheckEmptyStore, Severity: Info, Ref: s.ref,
+				Message: "store has no feature types or coverages",
+				Fix:     "publish a resource from it or delete the store",
+			})
+		}
+		for _, r := range s.resources {
+			v.edge(s.ref, r)
+			if !published[r.Workspace+":"+r.Name] {
+				v.find(Finding{
+					Check: CheckUnpublished, Severity: Info, Ref: r,
+					Message: "no layer publishes it",
+					Fix:     "publish a layer for it or delete it",
+				})
+			}
+		}
+	}
+	for _, g := range v.groups {
+		v.checkGroup(g)
+	}
+	v.checkTiles()
+	v.checkRules()
+}
+
+// checkLayer checks one layer and returns the "ws:name" of its
+// resource.
+func (v *validator) checkLayer(l layerInfo) string {
+	ws := l.ref.Workspace
+	resource := ws + ":" + l.ref.Name
+	kind := geoserver.KindFeatureType
+	if r := l.layer.Resource; r != nil && r.Name != "" {
+		resource = r.Name
+		if !strings.Contains(resource, ":") {
+			resource = ws + ":" + resource
+		}
+		if r.Class == "coverage" {
+			kind = geoserver.KindCoverage
+		}
+	}
+	target, ok := v.resources[resource]
+	if !ok {
+		prefix, name, _ := strings.Cut(resource, ":")
+		target = geoserver.Ref{Kind: kind, Workspace: prefix, Name: name}
+	}
+	if !v.edge(l.ref, target) {
+		v.find(Finding{
+			Check: CheckLayerResource, Severity: Error, Ref: l.ref, Target: target,
+			Message: "resource " + resource + " does not exist",
+			Fix:     "delete the layer or recreate its resource",
+		})
+	}
+
+	if d := l.layer.DefaultStyle; d != nil && d.Name != "" {
+		if target := v.styleRef(ws, d.Name); !v.edge(l.ref, target) {
+			v.find(Finding{
+				Check: CheckLayerDefaultStyle, Severity: Error, Ref: l.ref, Target: target,
+				Message: "default style " + d.Name + " does not exist",
+				Fix:     "set an existing default style",
+			})
+		}
+	}
+	if l.layer.Styles == nil {
+		return resource
+	}
+	var kept []layers.Ref
+	var missing []Finding
+	for _, s := range l.layer.Styles.Style {
+		target := v.styleRef(ws, s.Name)
+		if v.edge(l.ref, target) {
+			kept = append(kept, layers.Ref{Name: s.Name})
+			continue
+		}
+		missing = append(missing, Finding{
+			Check: CheckLayerStyle, Severity: Warning, Ref: l.ref, Target: target,
+			Message: "alternate style " + s.Name + " does not exist",
+			Fix:     "drop it from the layer's styles",
+		})
+	}
+	if len(missing) > 0 {
+		lc, name := v.c.Layers.InWorkspace(ws), l.ref.Name
+		repair := v.fix(l.ref, func(ctx context.Context) error {
+			// Send only the style list: the default style may be
+			// dangling too and would fail validation.
+			return lc.Update(ctx, name, &layers.Layer{Styles: &layers.Styles{Style: kept}})
+		})
+		for _, f := range missing {
+			f.repair = repair
+			v.find(f)
+		}
+	}
+	return resource
+}
+
+func (v *validator) checkGroup(g groupInfo) {
+	ws := g.ref.Workspace
+	var findings []Finding
+	keep := make([]bool, len(g.group.Publishables.Published))
+	kept := 0
+	for i, m := range g.group.Publishables.Published {
+		target := memberRef(ws, m)
+		if keep[i] = v.edge(g.ref, target); keep[i] {
+			kept++
+			continue
+		}
+		findings = append(findings, Finding{
+			Check: CheckGroupMember, Severity: Error, Ref: g.ref, Target: target,
+			Message: "member " + m.Name + " does not exist",
+			Fix:     "drop it from the group",
+		})
+	}
+	styleOK := make([]bool, len(g.group.Styles.Style))
+	for i, s := range g.group.Styles.Style {
+		if s.Name == "" {
+			styleOK[i] = true
+			continue
+		}
+		target := v.styleRef(ws, s.Name)
+		if styleOK[i] = v.edge(g.ref, target); styleOK[i] {
+			continue
+		}
+		if i < len(keep) && !keep[i] {
+			continue // goes with its member
+		}
+		findings = append(findings, Finding{
+			Check: CheckGroupStyle, Severity: Error, Ref: g.ref, Target: target,
+			Message: "style " + s.Name + " does not exist",
+			Fix:     "reset the member to its layer's default style",
+		})
+	}
+	if len(findings) == 0 {
+		return
+	}
+	var repair func(context.Context) error
+	if kept > 0 {
+		gc, name := v.c.LayerGroups.InWorkspace(ws), g.ref.Name
+		group := *g.group
+		repair = v.fix(g.ref, func(ctx context.Context) error {
+			group.Publishables.Published, group.Styles.Style = nil, nil
+			for i, m := range g.group.Publishables.Published {
+				if !keep[i] {
+					continue
+				}
+				group.Publishables.Published = append(group.Publishables.Published, m)
+				if i < len(g.group.Styles.Style) {
+					s := g.group.Styles.Style[i]
+					if !styleOK[i] {
+						s = layergroups.Ref{}
+					}
+					group.Styles.Style = append(group.Styles.Style, s)
+				}
+			}
+			return gc.Update(ctx, name, &group)
+		})
+	}
+	for _, f := range findings {
+		if repair == nil && f.Check == CheckGroupMember {
+			f.Fix = "delete the group: none of its members exist"
+		}
+		f.repair = repair
+		v.find(f)
+	}
+}
+
+// publishable resolves "ws:name" to the layer or, failing that, the
+// layer group of that name. A name matching neither resolves to the
+// layer.
+func (v *validator) publishable(ws, name string) geoserver.Ref {
+	if group := (geoserver.Ref{Kind: geoserver.KindLayerGroup, Workspace: ws, Name: name}); v.exists[group] {
+		return group
+	}
+	return geoserver.Ref{Kind: geoserver.KindLayer, Workspace: ws, Name: name}
+}
+
+// checkTiles reports tile layers whose layer or layer group is gone.
+// An unprefixed name belongs to a global layer group, which the crawl
+// doesn't read, so those are skipped rather than reported.
+func (v *validator) checkTiles() {
+	for _, name := range v.tiles {
+		ws, local, ok := strings.Cut(name, ":")
+		if !ok {
+			continue
+		}
+		ref := geoserver.Ref{Kind: geoserver.KindTileLayer, Workspace: ws, Name: local}
+		if target := v.publishable(ws, local); !v.edge(ref, target) {
+			v.find(Finding{
+				Check: CheckTileLayer, Severity: Warning, Ref: ref, Target: target,
+				Message: "no layer or layer group " + name,
+				Fix:     "delete the tile layer",
+				repair: v.fix(ref, func(ctx context.Context) error {
+					return v.c.GWC.Layers().Delete(ctx, name)
+				}),
+			})
+		}
+	}
+}
+
+func (v *validator) checkRules() {
+	for _, r := range v.rules {
+		if r.Workspace == "" || r.Workspace == "*" {
+			continue
+		}
+		encoded, _ := r.Encode()
+		ref := geoserver.Ref{Kind: geoserver.KindACLRule, Name: encoded}
+		ws := geoserver.Ref{Kind: geoserver.KindWorkspace, Name: r.Workspace}
+		if !v.edge(ref, ws) {
+			v.find(Finding{
+				Check: CheckACLWorkspace, Severity: Warning, Ref: ref, Target: ws,
+				Message: "workspace " + r.Workspace + " does not exist",
+				Fix:     "delete the rule, or keep it to protect a workspace that will be recreated",
+			})
+			continue
+		}
+		if r.Layer == "" || r.Layer == "*" {
+			continue
+		}
+		if target := v.publishable(r.Workspace, r.Layer); !v.edge(ref, target) {
+			v.find(Finding{
+				Check: CheckACLLayer, Severity: Warning, Ref: ref, Target: target,
+				Message: "no layer or layer group " + r.Workspace + ":" + r.Layer,
+				Fix:     "delete the rule, or keep it to protect a layer that will be recreated",
+			})
+		}
+	}
+}
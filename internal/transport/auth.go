@@ -1,6 +1,8 @@
 package transport
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 )
 
@@ -14,7 +16,25 @@ import (
 // Vault-rotated creds, retry libs).
 type AuthRoundTripper struct {
 	Apply func(*http.Request)
-	Base  http.RoundTripper
+	// Source, when non-nil, takes precedence over Apply. Credentials
+	// are resolved per request and, on a 401 response, invalidated and
+	// re-resolved for a single retry of the request.
+	Source CredentialSource
+	Base   http.RoundTripper
+}
+
+// CredentialSource resolves auth headers for a request from a
+// (typically cached) dynamic credential provider.
+type CredentialSource interface {
+	// Apply sets the auth header(s) on req. It may block to fetch
+	// fresh credentials and must honour req.Context(). gen identifies
+	// the credentials applied, for Invalidate.
+	Apply(req *http.Request) (gen uint64, err error)
+	// Invalidate drops the cached credentials so the next Apply
+	// fetches fresh ones, unless they are newer than gen: when several
+	// requests are rejected with the same stale credentials, only the
+	// first 401 triggers a fetch and the rest reuse its result.
+	Invalidate(gen uint64)
 }
 
 // RoundTrip implements [http.RoundTripper].
@@ -23,6 +43,9 @@ type AuthRoundTripper struct {
 // or future request rewinds), the request is cloned before mutation —
 // the caller's copy stays untouched.
 func (rt *AuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.Source != nil {
+		return rt.roundTripSource(req)
+	}
 	if rt.Apply == nil {
 		base := rt.Base
 		if base == nil {
@@ -39,6 +62,45 @@ func (rt *AuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	return base.RoundTrip(clone)
 }
 
+// roundTripSource sends req with credentials from rt.Source. A 401 is
+// taken as a sign of rotated credentials: the cache is invalidated and
+// the request replayed once, provided its body can be rewound.
+func (rt *AuthRoundTripper) roundTripSource(req *http.Request) (*http.Response, error) {
+	base := rt.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	clone := req.Clone(req.Context())
+	gen, err := rt.Source.Apply(clone)
+	if err != nil {
+		return nil, fmt.Errorf("resolve credentials: %w", err)
+	}
+	resp, err := base.RoundTrip(clone)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if !rewindable {
+		return resp, nil
+	}
+
+	rt.Source.Invalidate(gen)
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	if _, applyErr := rt.Source.Apply(retry); applyErr != nil {
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, bodyReadCap))
+	_ = resp.Body.Close()
+	return base.RoundTrip(retry)
+}
+
 // HeaderRoundTripper wraps another [http.RoundTripper] and attaches a
 // fixed set of headers to every outgoing request. Used for User-Agent
 // and any headers configured via WithHeader.
@@ -0,0 +1,129 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket: tokens refill at Rate per second up
+// to Burst, and each request consumes one. Safe for concurrent use.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter allowing rps requests per second
+// with bursts of up to burst requests. The bucket starts full.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	return &RateLimiter{rate: rps, burst: float64(burst), tokens: float64(burst)}
+}
+
+// Wait blocks until a token is available or ctx is done. On
+// cancellation the reserved token is handed back so waiting callers
+// that give up don't starve the ones that stay.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// LimitRoundTripper wraps another [http.RoundTripper] and holds each
+// request until the optional rate limiter grants a token and a
+// concurrency slot is free. Either gate may be nil.
+//
+// A concurrency slot is held until the response body is closed, not
+// just until RoundTrip returns — the server is still busy streaming
+// the body until then.
+type LimitRoundTripper struct {
+	Limiter *RateLimiter
+	// Slots is a counting semaphore; its capacity is the maximum
+	// number of in-flight requests.
+	Slots  chan struct{}
+	Logger *slog.Logger
+	Base   http.RoundTripper
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (rt *LimitRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := rt.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx := req.Context()
+	start := time.Now()
+
+	if rt.Limiter != nil {
+		if err := rt.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if rt.Slots != nil {
+		select {
+		case rt.Slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if wait := time.Since(start); wait >= time.Millisecond {
+		logDebug(rt.Logger, "request queued", "op", OpFromContext(ctx), "method", req.Method, "url", req.URL.String(), "wait", wait)
+	}
+
+	resp, err := base.RoundTrip(req)
+	if rt.Slots == nil {
+		return resp, err
+	}
+	if err != nil || resp == nil || resp.Body == nil {
+		<-rt.Slots
+		return resp, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { <-rt.Slots }}
+	return resp, nil
+}
+
+// releaseBody frees a concurrency slot exactly once when closed.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
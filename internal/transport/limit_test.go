@@ -0,0 +1,94 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hishamkaram/geoserver/v2/internal/transport"
+)
+
+func TestRateLimiter_Paces(t *testing.T) {
+	l := transport.NewRateLimiter(50, 1)
+	ctx := context.Background()
+	start := time.Now()
+	for range 6 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	// First token is free (burst 1), the other five wait 20ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("6 waits at 50 rps took %v, want >= ~100ms", elapsed)
+	}
+}
+
+func TestRateLimiter_ContextCancel(t *testing.T) {
+	l := transport.NewRateLimiter(0.1, 1)
+	_ = l.Wait(context.Background()) // drain the burst
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestLimitRoundTripper_CapsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &transport.LimitRoundTripper{Slots: make(chan struct{}, 3)}}
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transport.DoJSON(context.Background(), client, nil, "About.Ping",
+				transport.Request{Method: http.MethodGet, URL: srv.URL}, nil)
+			if err != nil {
+				t.Errorf("DoJSON: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := peak.Load(); got > 3 {
+		t.Fatalf("peak in-flight = %d, want <= 3", got)
+	}
+}
+
+func TestLimitRoundTripper_QueuedRequestHonoursContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	slots := make(chan struct{}, 1)
+	slots <- struct{}{} // occupy the only slot
+	client := &http.Client{Transport: &transport.LimitRoundTripper{Slots: slots}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := transport.DoJSON(ctx, client, nil, "About.Ping",
+		transport.Request{Method: http.MethodGet, URL: srv.URL}, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
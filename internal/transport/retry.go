@@ -0,0 +1,155 @@
+package transport
+
+import (
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryRoundTripper wraps another [http.RoundTripper] and re-sends
+// requests that fail with a retryable status code or a transport
+// error, sleeping a jittered exponential backoff between attempts.
+//
+// Only idempotent methods (GET, HEAD, PUT, DELETE, OPTIONS) are
+// retried unless RetryOp reports true for the request's operation
+// name (see [WithOp]). Requests whose body cannot be rewound — a
+// non-nil Body with no GetBody, typically a streamed upload — are
+// never retried.
+type RetryRoundTripper struct {
+	// MaxAttempts is the total number of attempts, including the
+	// first. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the base delay before the second attempt;
+	// each further attempt doubles it up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RetryStatus reports whether a response status is retryable.
+	RetryStatus func(status int) bool
+	// RetryOp reports whether a non-idempotent request for the given
+	// operation may be retried. Nil means never.
+	RetryOp func(op string) bool
+	Logger  *slog.Logger
+	Base    http.RoundTripper
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (rt *RetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := rt.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if rt.MaxAttempts < 2 || !rt.retryable(req) {
+		return base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	op := OpFromContext(ctx)
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := base.RoundTrip(attemptReq)
+		retry := false
+		switch {
+		case err != nil:
+			retry = ctx.Err() == nil
+		case rt.RetryStatus != nil && rt.RetryStatus(resp.StatusCode):
+			retry = true
+		}
+		if !retry {
+			return resp, err
+		}
+		if attempt >= rt.MaxAttempts {
+			if rt.Logger != nil {
+				rt.Logger.Warn("retries exhausted", "op", op, "method", req.Method, "url", req.URL.String(), "attempts", attempt, "status", statusOf(resp), "err", err)
+			}
+			return resp, err
+		}
+
+		delay := rt.backoff(attempt)
+		if resp != nil {
+			if ra, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok && ra > delay {
+				delay = ra
+			}
+			// Drain a bounded amount so the connection can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, bodyReadCap))
+			_ = resp.Body.Close()
+		}
+		logDebug(rt.Logger, "retrying request", "op", op, "method", req.Method, "url", req.URL.String(), "attempt", attempt, "status", statusOf(resp), "delay", delay, "err", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (rt *RetryRoundTripper) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return rt.RetryOp != nil && rt.RetryOp(OpFromContext(req.Context()))
+}
+
+// backoff returns the jittered delay before attempt+1: a random
+// duration in [d/2, d) where d = InitialBackoff * 2^(attempt-1),
+// capped at MaxBackoff.
+func (rt *RetryRoundTripper) backoff(attempt int) time.Duration {
+	d := rt.InitialBackoff
+	for i := 1; i < attempt && d < rt.MaxBackoff; i++ {
+		d *= 2
+	}
+	if rt.MaxBackoff > 0 && d > rt.MaxBackoff {
+		d = rt.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half)
+}
+
+// retryAfter parses a Retry-After header value — either delta-seconds
+// or an HTTP-date — into a wait duration relative to now.
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
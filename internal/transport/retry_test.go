@@ -0,0 +1,168 @@
+package transport_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hishamkaram/geoserver/v2/internal/transport"
+)
+
+func newRetryClient(maxAttempts int, retryOp func(string) bool) *http.Client {
+	return &http.Client{Transport: &transport.RetryRoundTripper{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		RetryStatus: func(s int) bool {
+			return s == http.StatusServiceUnavailable || s == http.StatusTooManyRequests || s == http.StatusBadGateway
+		},
+		RetryOp: retryOp,
+	}}
+}
+
+func TestRetry_RecoversFromTransientStatus(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+
+	var out struct{ OK bool }
+	status, err := transport.DoJSON(context.Background(), newRetryClient(4, nil), nil, "Workspaces.List",
+		transport.Request{Method: http.MethodGet, URL: srv.URL}, &out)
+	if err != nil || status != http.StatusOK || !out.OK {
+		t.Fatalf("status=%d err=%v out=%+v", status, err, out)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("server saw %d calls, want 3", got)
+	}
+}
+
+func TestRetry_ExhaustedReturnsLastResponse(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, "restarting")
+	}))
+	defer srv.Close()
+
+	status, err := transport.DoJSON(context.Background(), newRetryClient(3, nil), nil, "Workspaces.List",
+		transport.Request{Method: http.MethodGet, URL: srv.URL}, nil)
+	if status != http.StatusServiceUnavailable || err == nil {
+		t.Fatalf("status=%d err=%v", status, err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("server saw %d calls, want 3", got)
+	}
+}
+
+func TestRetry_POSTNotRetriedByDefault(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	_, _ = transport.DoJSON(context.Background(), newRetryClient(4, nil), nil, "Workspaces.Create",
+		transport.Request{Method: http.MethodPost, URL: srv.URL, Body: map[string]string{"name": "x"}}, nil)
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("server saw %d calls, want 1", got)
+	}
+}
+
+func TestRetry_POSTOptInRewindsBody(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"x"}` {
+			t.Errorf("attempt %d body = %q", calls.Load()+1, body)
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	retryOp := func(op string) bool { return op == "Workspaces.Create" }
+	status, err := transport.DoJSON(context.Background(), newRetryClient(4, retryOp), nil, "Workspaces.Create",
+		transport.Request{Method: http.MethodPost, URL: srv.URL, Body: map[string]string{"name": "x"}}, nil)
+	if err != nil || status != http.StatusCreated {
+		t.Fatalf("status=%d err=%v", status, err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("server saw %d calls, want 2", got)
+	}
+}
+
+func TestRetry_UnrewindableBodyNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	// io.MultiReader hides the concrete type, so net/http can't set GetBody.
+	body := io.MultiReader(strings.NewReader("zipbytes"))
+	_, _ = transport.DoRaw(context.Background(), newRetryClient(4, nil), nil, "Datastores.UploadFile",
+		http.MethodPut, srv.URL, body, "application/zip", "", nil, nil)
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("server saw %d calls, want 1", got)
+	}
+}
+
+func TestRetry_HonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	_, err := transport.DoJSON(context.Background(), newRetryClient(2, nil), nil, "Workspaces.List",
+		transport.Request{Method: http.MethodGet, URL: srv.URL}, nil)
+	if err != nil {
+		t.Fatalf("DoJSON: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("retried after %v; Retry-After asked for 1s", elapsed)
+	}
+}
+
+func TestRetry_ContextCancelDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := transport.DoJSON(ctx, newRetryClient(4, nil), nil, "Workspaces.List",
+		transport.Request{Method: http.MethodGet, URL: srv.URL}, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("backoff ignored context cancellation (%v)", elapsed)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -44,6 +45,10 @@ type Request struct {
 	Query map[string]string
 	// Accept overrides the default "application/json".
 	Accept string
+	// MaxResponseBytes caps how much of a 2xx response body is read
+	// and decoded. Zero means [DefaultMaxResponseBytes]. Error bodies
+	// are always capped at [bodyReadCap] regardless of this value.
+	MaxResponseBytes int64
 }
 
 // JSON is a JSON-shaped target for [DoJSON]. nil disables decoding.
@@ -53,7 +58,9 @@ type JSON any
 // and translates non-2xx responses into a *transport-layer-Error.
 //
 // On success: status code is reported in the returned status; out (if
-// non-nil) is filled from the response body. Error is nil.
+// non-nil) is stream-decoded from the response body with a
+// [json.Decoder], reading at most req.MaxResponseBytes. A body larger
+// than that fails with an error wrapping [ErrResponseTooLarge].
 //
 // On non-2xx: returns a structured Error wrapping the response body
 // (capped) so the calling resource sub-client can rewrap as a
@@ -71,8 +78,9 @@ func DoJSON(ctx context.Context, doer Doer, logger *slog.Logger, op string, req
 // as RawBody — typical OWS calls are GET with no body.
 //
 // On success: out (if non-nil) is xml.Unmarshal'd from the response
-// body; the body cap is [xmlBodyReadCap] (32 MiB) since capabilities
-// documents are often well above the [bodyReadCap] used by [DoJSON].
+// body; the body cap is req.MaxResponseBytes, defaulting to
+// [DefaultMaxResponseBytes] (32 MiB) since capabilities documents are
+// often tens to hundreds of KiB on real installations.
 //
 // On non-2xx: returns a structured Error wrapping the response body
 // (truncated to [bodyReadCap]) so the caller can inspect what came back.
@@ -82,7 +90,7 @@ func DoXML(ctx context.Context, doer Doer, logger *slog.Logger, op string, req R
 	if req.Accept == "" {
 		req.Accept = "application/xml"
 	}
-	httpReq, err := buildHTTPRequest(ctx, req)
+	httpReq, err := buildHTTPRequest(WithOp(ctx, op), req)
 	if err != nil {
 		return 0, fmt.Errorf("%s: build request: %w", op, err)
 	}
@@ -106,7 +114,7 @@ func DoXML(ctx context.Context, doer Doer, logger *slog.Logger, op string, req R
 		}
 	}
 
-	body, readErr := io.ReadAll(io.LimitReader(resp.Body, xmlBodyReadCap))
+	body, readErr := io.ReadAll(newCappedReader(resp.Body, req.MaxResponseBytes))
 	if readErr != nil {
 		logDebug(logger, "body read failed", "op", op, "method", req.Method, "url", req.URL, "status", resp.StatusCode, "err", readErr)
 		return resp.StatusCode, fmt.Errorf("%s: read body: %w", op, readErr)
@@ -146,7 +154,7 @@ func DoRaw(ctx context.Context, doer Doer, logger *slog.Logger, op string, metho
 }
 
 func doRequest(ctx context.Context, doer Doer, logger *slog.Logger, op string, req Request, out JSON) (status int, err error) {
-	httpReq, err := buildHTTPRequest(ctx, req)
+	httpReq, err := buildHTTPRequest(WithOp(ctx, op), req)
 	if err != nil {
 		return 0, fmt.Errorf("%s: build request: %w", op, err)
 	}
@@ -158,15 +166,9 @@ func doRequest(ctx context.Context, doer Doer, logger *slog.Logger, op string, r
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	body, readErr := io.ReadAll(io.LimitReader(resp.Body, bodyReadCap))
-	if readErr != nil {
-		logDebug(logger, "body read failed", "op", op, "method", req.Method, "url", req.URL, "status", resp.StatusCode, "err", readErr)
-		return resp.StatusCode, fmt.Errorf("%s: read body: %w", op, readErr)
-	}
-
-	logDebug(logger, "request done", "op", op, "method", req.Method, "url", req.URL, "status", resp.StatusCode, "body_bytes", len(body))
-
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, bodyReadCap))
+		logDebug(logger, "request done", "op", op, "method", req.Method, "url", req.URL, "status", resp.StatusCode, "body_bytes", len(body))
 		return resp.StatusCode, &Error{
 			Op:         op,
 			Method:     req.Method,
@@ -176,12 +178,23 @@ func doRequest(ctx context.Context, doer Doer, logger *slog.Logger, op string, r
 		}
 	}
 
-	if out == nil || len(body) == 0 {
-		return resp.StatusCode, nil
-	}
-	if jsonErr := json.Unmarshal(body, out); jsonErr != nil {
-		return resp.StatusCode, fmt.Errorf("%s: decode response: %w", op, jsonErr)
+	body := newCappedReader(resp.Body, req.MaxResponseBytes)
+	if out != nil {
+		if decErr := json.NewDecoder(body).Decode(out); decErr != nil && !errors.Is(decErr, io.EOF) {
+			// io.EOF means an empty body (e.g., 201 Created with no
+			// payload) — not an error, out is left unchanged.
+			logDebug(logger, "decode failed", "op", op, "method", req.Method, "url", req.URL, "status", resp.StatusCode, "body_bytes", body.n, "err", decErr)
+			if errors.Is(decErr, ErrResponseTooLarge) {
+				return resp.StatusCode, fmt.Errorf("%s: read body: %w", op, decErr)
+			}
+			return resp.StatusCode, fmt.Errorf("%s: decode response: %w", op, decErr)
+		}
 	}
+	// Drain what's left (within the cap) so the connection can be
+	// reused; a body past the cap is simply closed.
+	_, _ = io.Copy(io.Discard, body)
+
+	logDebug(logger, "request done", "op", op, "method", req.Method, "url", req.URL, "status", resp.StatusCode, "body_bytes", body.n)
 	return resp.StatusCode, nil
 }
 
@@ -205,16 +218,57 @@ func (e *Error) Error() string {
 		e.Op, e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), preview)
 }
 
-// Read body cap matches the public *APIError.Body cap so the wrapper
-// doesn't have to re-truncate.
+// Read body cap for non-2xx responses. Matches the public
+// *APIError.Body cap so the wrapper doesn't have to re-truncate.
 const bodyReadCap = 8 << 10 // 8 KiB
 
-// xmlBodyReadCap is the larger cap used by [DoXML] on the success path.
-// WMS / WFS / WCS GetCapabilities documents are commonly tens to
-// hundreds of KiB on real installations; the JSON 8 KiB cap is too
-// small. The error path still uses [bodyReadCap] so an oversized error
-// body doesn't blow up.
-const xmlBodyReadCap = 32 << 20 // 32 MiB
+// DefaultMaxResponseBytes is the success-path body cap used when
+// [Request.MaxResponseBytes] is zero. Catalog documents (layer lists,
+// feature types with hundreds of attributes, capabilities documents)
+// are routinely well past the 8 KiB error-body cap; 32 MiB keeps a
+// runaway response from exhausting memory without truncating real
+// payloads.
+const DefaultMaxResponseBytes = 32 << 20 // 32 MiB
+
+// ErrResponseTooLarge is returned (wrapped) when a 2xx response body
+// exceeds [Request.MaxResponseBytes].
+var ErrResponseTooLarge = errors.New("response body exceeds configured maximum")
+
+// cappedReader reads at most max bytes from r, then fails with
+// [ErrResponseTooLarge] if the underlying body has more. Unlike
+// [io.LimitReader] it surfaces the overflow instead of reporting a
+// silent EOF — a truncated JSON document would otherwise show up as a
+// confusing "unexpected end of JSON input".
+type cappedReader struct {
+	r   io.Reader
+	max int64
+	n   int64
+}
+
+func newCappedReader(r io.Reader, limit int64) *cappedReader {
+	if limit <= 0 {
+		limit = DefaultMaxResponseBytes
+	}
+	return &cappedReader{r: r, max: limit}
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.n >= c.max {
+		// Probe for one more byte to tell "exactly max" from "over".
+		var probe [1]byte
+		n, err := c.r.Read(probe[:])
+		if n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, err
+	}
+	if remaining := c.max - c.n; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
 
 // buildHTTPRequest constructs the http.Request with body / query /
 // Accept set. Auth and User-Agent are applied by the transport
@@ -265,6 +319,23 @@ func buildHTTPRequest(ctx context.Context, req Request) (*http.Request, error) {
 	return httpReq, nil
 }
 
+type opKey struct{}
+
+// WithOp returns a copy of ctx carrying the public operation name
+// (e.g., "Workspaces.Create"). [DoJSON], [DoXML] and [DoRaw] attach it
+// to every outgoing request so RoundTripper layers — retry policy,
+// observers — can key behavior on the operation without parsing URLs.
+func WithOp(ctx context.Context, op string) context.Context {
+	return context.WithValue(ctx, opKey{}, op)
+}
+
+// OpFromContext returns the operation name attached by [WithOp], or ""
+// for requests that didn't originate from a sub-client.
+func OpFromContext(ctx context.Context) string {
+	op, _ := ctx.Value(opKey{}).(string)
+	return op
+}
+
 func logDebug(logger *slog.Logger, msg string, args ...any) {
 	if logger == nil {
 		return
@@ -0,0 +1,144 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hishamkaram/geoserver/v2/internal/transport"
+)
+
+// bigLayerList returns a `{"layers":{"layer":[…]}}` document with n
+// entries — well past the 8 KiB error-body cap for any realistic n.
+func bigLayerList(n int) string {
+	var b strings.Builder
+	b.WriteString(`{"layers":{"layer":[`)
+	for i := range n {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"name":"layer_%06d","href":"http://localhost:8080/geoserver/rest/workspaces/topp/layers/layer_%06d.json"}`, i, i)
+	}
+	b.WriteString(`]}}`)
+	return b.String()
+}
+
+type layerList struct {
+	Layers struct {
+		Layer []struct {
+			Name string `json:"name"`
+		} `json:"layer"`
+	} `json:"layers"`
+}
+
+func TestDoJSON_LargeBodyDecodes(t *testing.T) {
+	payload := bigLayerList(40000) // ~4 MiB
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, payload)
+	}))
+	defer srv.Close()
+
+	var out layerList
+	status, err := transport.DoJSON(context.Background(), srv.Client(), nil, "Layers.List",
+		transport.Request{Method: http.MethodGet, URL: srv.URL}, &out)
+	if err != nil {
+		t.Fatalf("DoJSON: %v", err)
+	}
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if got := len(out.Layers.Layer); got != 40000 {
+		t.Fatalf("decoded %d layers, want 40000", got)
+	}
+	if out.Layers.Layer[39999].Name != "layer_039999" {
+		t.Fatalf("last layer = %q", out.Layers.Layer[39999].Name)
+	}
+}
+
+func TestDoJSON_MaxResponseBytesExceeded(t *testing.T) {
+	payload := bigLayerList(1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, payload)
+	}))
+	defer srv.Close()
+
+	var out layerList
+	_, err := transport.DoJSON(context.Background(), srv.Client(), nil, "Layers.List",
+		transport.Request{Method: http.MethodGet, URL: srv.URL, MaxResponseBytes: 1024}, &out)
+	if !errors.Is(err, transport.ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestDoJSON_MaxResponseBytesExactFit(t *testing.T) {
+	payload := `{"layers":{"layer":[{"name":"a"}]}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, payload)
+	}))
+	defer srv.Close()
+
+	var out layerList
+	_, err := transport.DoJSON(context.Background(), srv.Client(), nil, "Layers.List",
+		transport.Request{Method: http.MethodGet, URL: srv.URL, MaxResponseBytes: int64(len(payload))}, &out)
+	if err != nil {
+		t.Fatalf("DoJSON: %v", err)
+	}
+	if len(out.Layers.Layer) != 1 {
+		t.Fatalf("decoded %+v", out)
+	}
+}
+
+func TestDoJSON_EmptySuccessBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	var out layerList
+	status, err := transport.DoJSON(context.Background(), srv.Client(), nil, "Workspaces.Create",
+		transport.Request{Method: http.MethodPost, URL: srv.URL, Body: map[string]string{"name": "x"}}, &out)
+	if err != nil {
+		t.Fatalf("DoJSON: %v", err)
+	}
+	if status != http.StatusCreated {
+		t.Fatalf("status = %d", status)
+	}
+}
+
+func TestDoJSON_ErrorBodyStillCapped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, strings.Repeat("x", 64<<10))
+	}))
+	defer srv.Close()
+
+	_, err := transport.DoJSON(context.Background(), srv.Client(), nil, "Layers.List",
+		transport.Request{Method: http.MethodGet, URL: srv.URL}, nil)
+	var tErr *transport.Error
+	if !errors.As(err, &tErr) {
+		t.Fatalf("expected *transport.Error, got %T: %v", err, err)
+	}
+	if len(tErr.Body) != 8<<10 {
+		t.Fatalf("error body len = %d, want %d", len(tErr.Body), 8<<10)
+	}
+}
+
+func TestDoXML_MaxResponseBytesExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "<Capabilities>"+strings.Repeat("<Layer/>", 1000)+"</Capabilities>")
+	}))
+	defer srv.Close()
+
+	var out struct{}
+	_, err := transport.DoXML(context.Background(), srv.Client(), nil, "WMS.GetCapabilities",
+		transport.Request{Method: http.MethodGet, URL: srv.URL, MaxResponseBytes: 512}, &out)
+	if !errors.Is(err, transport.ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Diff returns the JSON field paths set in desired whose value differs
+// in live, e.g. "srs" or "attributes.attribute[0].binding". Fields
+// absent or null in desired are not compared, which is what keeps
+// server-populated fields out of the diff. href and date fields are
+// ignored, and so is a live value that is [Encrypted].
+func Diff(desired, live any) ([]string, error) {
+	d, err := JSONValue(desired)
+	if err != nil {
+		return nil, err
+	}
+	l, err := JSONValue(live)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	subsetDiff("", d, l, &out)
+	slices.Sort(out)
+	return out, nil
+}
+
+// JSONValue round-trips v through JSON into maps, slices and scalars.
+func JSONValue(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode for diff: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("decode for diff: %w", err)
+	}
+	return out, nil
+}
+
+func subsetDiff(path string, desired, live any, out *[]string) {
+	switch d := desired.(type) {
+	case nil:
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !ok {
+			*out = append(*out, path)
+			return
+		}
+		for k, dv := range d {
+			if serverPopulated[k] {
+				continue
+			}
+			subsetDiff(joinPath(path, k), dv, l[k], out)
+		}
+	case []any:
+		l, ok := live.([]any)
+		if !ok || len(l) != len(d) {
+			*out = append(*out, path)
+			return
+		}
+		for i := range d {
+			subsetDiff(fmt.Sprintf("%s[%d]", path, i), d[i], l[i], out)
+		}
+	default:
+		if live != Encrypted && !reflect.DeepEqual(desired, live) {
+			*out = append(*out, path)
+		}
+	}
+}
+
+// serverPopulated holds the keys whose values the server sets.
+var serverPopulated = map[string]bool{"href": true, "dateCreated": true, "dateModified": true}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,32 @@
+package wire_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
+)
+
+func TestDiff(t *testing.T) {
+	type ref struct {
+		Name string `json:"name,omitempty"`
+		Href string `json:"href,omitempty"`
+	}
+	type doc struct {
+		Name   string   `json:"name,omitempty"`
+		Title  string   `json:"title,omitempty"`
+		Style  *ref     `json:"style,omitempty"`
+		Tags   []string `json:"tags"`
+		Filter *string  `json:"filter"`
+	}
+	desired := doc{Name: "roads", Style: &ref{Name: "line"}, Tags: []string{"a"}}
+	live := doc{Name: "roads", Title: "Roads", Style: &ref{Name: "line", Href: "http://x/line.json"}, Tags: []string{"a", "b"}}
+	got, err := wire.Diff(desired, live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Title is unset and Filter null in desired; hrefs never count.
+	if want := []string{"tags"}; !slices.Equal(got, want) {
+		t.Fatalf("Diff = %v, want %v", got, want)
+	}
+}
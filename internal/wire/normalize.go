@@ -0,0 +1,120 @@
+package wire
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Encrypted stands in for every "crypt1:…" value in a [Normalize]d
+// document: the ciphertext differs per server even for the same
+// password.
+const Encrypted = "(encrypted)"
+
+// NormalizeDoc marshals v to JSON and returns its [Normalize]d form as
+// an object, empty when nothing is left.
+func NormalizeDoc(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	m, _ := Normalize(doc).(map[string]any)
+	if m == nil {
+		m = map[string]any{}
+	}
+	return m, nil
+}
+
+// Normalize rewrites a decoded JSON value into a canonical form in
+// which GeoServer's wire quirks no longer tell two encodings of the
+// same object apart:
+//
+//   - "href" links and "@class" markers are dropped;
+//   - {"$": v} wrappers collapse to v;
+//   - {"@key": k, "$": v} entry lists, or a single such entry, become
+//     a {k: v} map;
+//   - a one-element list collapses to its element, since GeoServer
+//     writes a single-member list as a bare object;
+//   - empty strings, lists and objects are dropped;
+//   - "crypt1:…" values become [Encrypted].
+//
+// It returns nil for values that carry no information.
+func Normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		if m := entries([]any{v}); m != nil {
+			return Normalize(m)
+		}
+		out := map[string]any{}
+		for k, e := range v {
+			if k == "href" || k == "@class" {
+				continue
+			}
+			if e = Normalize(e); e != nil {
+				out[k] = e
+			}
+		}
+		if s, ok := out["$"]; ok && len(out) == 1 {
+			return s
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []any:
+		if m := entries(v); m != nil {
+			return Normalize(m)
+		}
+		var out []any
+		for _, e := range v {
+			if e = Normalize(e); e != nil {
+				out = append(out, e)
+			}
+		}
+		switch len(out) {
+		case 0:
+			return nil
+		case 1:
+			return out[0]
+		}
+		return out
+	case string:
+		if v == "" {
+			return nil
+		}
+		if strings.HasPrefix(v, "crypt1:") {
+			return Encrypted
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// entries turns a list of {"@key": k, "$": v} entries into a map, or
+// returns nil when xs is anything else.
+func entries(xs []any) map[string]any {
+	if len(xs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(xs))
+	for _, x := range xs {
+		e, ok := x.(map[string]any)
+		if !ok || len(e) != 2 {
+			return nil
+		}
+		k, ok := e["@key"].(string)
+		if !ok {
+			return nil
+		}
+		v, ok := e["$"]
+		if !ok {
+			return nil
+		}
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,42 @@
+package wire_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
+)
+
+func TestNormalize(t *testing.T) {
+	decode := func(s string) any {
+		t.Helper()
+		var v any
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	for _, tc := range []struct{ a, b string }{
+		// A single-member list is written as a bare object.
+		{`{"styles":{"style":[{"name":"roads"}]}}`, `{"styles":{"style":{"name":"roads"}}}`},
+		{`{"keywords":{"string":["roads"]}}`, `{"keywords":{"string":"roads"}}`},
+		// Entry lists, single or not, become maps.
+		{`{"entry":[{"@key":"host","$":"db"}]}`, `{"entry":{"@key":"host","$":"db"}}`},
+		{`{"entry":[{"@key":"host","$":"db"},{"@key":"port","$":"5432"}]}`, `{"entry":{"host":"db","port":"5432"}}`},
+		// Links, class markers, wrappers, empties and ciphertext.
+		{`{"name":"pg","href":"http://a/pg.json","srs":{"@class":"projected","$":"EPSG:4326"},"title":""}`, `{"name":"pg","srs":"EPSG:4326"}`},
+		{`{"passwd":"crypt1:abc"}`, `{"passwd":"crypt1:xyz"}`},
+	} {
+		a, b := wire.Normalize(decode(tc.a)), wire.Normalize(decode(tc.b))
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("Normalize(%s) = %v\nNormalize(%s) = %v", tc.a, a, tc.b, b)
+		}
+	}
+	if got := wire.Normalize(decode(`{"style":[{"name":"a"},{"name":"b"}]}`)); !reflect.DeepEqual(got, decode(`{"style":[{"name":"a"},{"name":"b"}]}`)) {
+		t.Errorf("two-element list = %v", got)
+	}
+	if got := wire.Normalize(decode(`{"a":"","b":[],"c":{"href":"x"}}`)); got != nil {
+		t.Errorf("empty document = %v", got)
+	}
+}
@@ -0,0 +1,95 @@
+package wire
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Outcome says what an Ensure or Upsert call did to an object.
+type Outcome int
+
+// Outcomes.
+const (
+	// Unchanged means the object already matched.
+	Unchanged Outcome = iota
+	// Created means the object was missing and has been created.
+	Created
+	// Updated means the object differed and has been updated.
+	Updated
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Created:
+		return "created"
+	case Updated:
+		return "updated"
+	}
+	return "unchanged"
+}
+
+// httpStatusErr is satisfied by the root package's *APIError, which
+// this package can't import.
+type httpStatusErr interface {
+	error
+	HTTPStatusCode() int
+}
+
+func isNotFound(err error) bool {
+	var s httpStatusErr
+	return errors.As(err, &s) && s.HTTPStatusCode() == http.StatusNotFound
+}
+
+// Ensure calls create when get answers 404, and otherwise leaves the
+// object alone.
+func Ensure[T any](get func() (T, error), create func() error) (Outcome, error) {
+	_, err := get()
+	if isNotFound(err) {
+		if err := create(); err != nil {
+			return Unchanged, err
+		}
+		return Created, nil
+	}
+	return Unchanged, err
+}
+
+// Upsert calls create when get answers 404, and update when the live
+// object differs from desired in a field desired sets (see [Same]).
+func Upsert[T any](desired any, get func() (T, error), create func() error, update func(live T) error) (Outcome, error) {
+	live, err := get()
+	if isNotFound(err) {
+		if err := create(); err != nil {
+			return Unchanged, err
+		}
+		return Created, nil
+	}
+	if err != nil {
+		return Unchanged, err
+	}
+	same, err := Same(desired, live)
+	if err != nil || same {
+		return Unchanged, err
+	}
+	if err := update(live); err != nil {
+		return Unchanged, err
+	}
+	return Updated, nil
+}
+
+// Same reports whether live matches desired in every field desired
+// sets, comparing the [Normalize]d documents so that wire shapes
+// don't count. Server-populated fields are ignored: hrefs, dates, and
+// values the server has encrypted.
+func Same(desired, live any) (bool, error) {
+	d, err := NormalizeDoc(desired)
+	if err != nil {
+		return false, err
+	}
+	l, err := NormalizeDoc(live)
+	if err != nil {
+		return false, err
+	}
+	var out []string
+	subsetDiff("", d, l, &out)
+	return len(out) == 0, nil
+}
@@ -0,0 +1,82 @@
+package wire_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
+)
+
+type statusErr int
+
+func (e statusErr) Error() string       { return http.StatusText(int(e)) }
+func (e statusErr) HTTPStatusCode() int { return int(e) }
+
+type entry struct {
+	Key   string `json:"@key"`
+	Value string `json:"$"`
+}
+
+type store struct {
+	Name         string  `json:"name,omitempty"`
+	Enabled      bool    `json:"enabled,omitempty"`
+	DateModified string  `json:"dateModified,omitempty"`
+	Params       []entry `json:"entry,omitempty"`
+}
+
+func TestSame(t *testing.T) {
+	desired := store{Name: "pg", Params: []entry{{"host", "db"}, {"passwd", "secret"}}}
+	live := store{Name: "pg", Enabled: true, DateModified: "2026-01-02", Params: []entry{{"passwd", "crypt1:abc"}, {"port", "5432"}, {"host", "db"}}}
+	if same, err := wire.Same(desired, live); err != nil || !same {
+		t.Fatalf("Same = %v, %v; want true", same, err)
+	}
+	desired.Params[0].Value = "db2"
+	if same, _ := wire.Same(desired, live); same {
+		t.Fatal("Same ignored a changed parameter")
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	live := &store{Name: "pg"}
+	var created, updated int
+	get := func() (*store, error) {
+		if live == nil {
+			return nil, errors.Join(errors.New("get"), statusErr(http.StatusNotFound))
+		}
+		return live, nil
+	}
+	create := func() error { created++; return nil }
+	update := func(*store) error { updated++; return nil }
+
+	for _, tc := range []struct {
+		live    *store
+		desired store
+		want    wire.Outcome
+	}{
+		{live: nil, desired: store{Name: "pg"}, want: wire.Created},
+		{live: &store{Name: "pg", Enabled: true}, desired: store{Name: "pg"}, want: wire.Unchanged},
+		{live: &store{Name: "pg"}, desired: store{Name: "pg", Enabled: true}, want: wire.Updated},
+	} {
+		live = tc.live
+		got, err := wire.Upsert(tc.desired, get, create, update)
+		if err != nil || got != tc.want {
+			t.Fatalf("Upsert(%+v) = %v, %v; want %v", tc.desired, got, err, tc.want)
+		}
+	}
+	if created != 1 || updated != 1 {
+		t.Fatalf("created %d, updated %d", created, updated)
+	}
+
+	live = nil
+	if got, err := wire.Ensure(get, create); err != nil || got != wire.Created {
+		t.Fatalf("Ensure = %v, %v", got, err)
+	}
+	failed := func() (*store, error) { return nil, statusErr(http.StatusInternalServerError) }
+	if _, err := wire.Ensure(failed, create); err == nil || created != 2 {
+		t.Fatalf("Ensure on a failed get = %v (created %d)", err, created)
+	}
+	if got := wire.Updated.String(); got != "updated" {
+		t.Fatalf("String = %q", got)
+	}
+}
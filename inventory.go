@@ -0,0 +1,554 @@
+package geoserver
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// defaultInventoryWorkers is the request concurrency of
+// [Catalog.Inventory] when [InventoryOptions.Workers] is unset.
+const defaultInventoryWorkers = 8
+
+// InventoryOptions configures [Catalog.Inventory].
+type InventoryOptions struct {
+	// Workers bounds the number of requests in flight. Default 8.
+	Workers int
+
+	// Workspaces and ExcludeWorkspaces filter workspaces by name
+	// pattern ([path.Match] syntax, e.g. "prod_*"). An empty Workspaces
+	// takes every workspace; ExcludeWorkspaces wins over Workspaces.
+	// Workspace names are XML prefixes and can't hold pattern
+	// metacharacters, so a plain name matches only itself.
+	Workspaces        []string
+	ExcludeWorkspaces []string
+
+	// Kinds and ExcludeKinds filter the object kinds crawled:
+	// KindStyle, KindDatastore, KindFeatureType, KindCoverageStore,
+	// KindCoverage, KindLayer and KindLayerGroup. An empty Kinds takes
+	// them all. Feature types and coverages are reached through their
+	// stores, so excluding a store kind excludes its resources too.
+	Kinds        []string
+	ExcludeKinds []string
+
+	// NamesOnly skips the request for each object: every document
+	// holds only the name from its list entry, so a layer is linked to
+	// the resource of the same name and to no style. Use it for a
+	// cheap census of what exists.
+	NamesOnly bool
+
+	// Progress, if set, is called after every request with the
+	// running totals. Calls are serialized.
+	Progress func(InventoryProgress)
+}
+
+// InventoryProgress reports how far [Catalog.Inventory] has got.
+// Total grows as the crawl discovers more objects.
+type InventoryProgress struct {
+	Ref   Ref   // the object just fetched or listed
+	Err   error // its failure, if any
+	Done  int
+	Total int
+}
+
+// InventoryError is the failure to fetch one object. Its children,
+// if any, are missing from the inventory.
+type InventoryError struct {
+	Ref Ref
+	Err error
+}
+
+func (e *InventoryError) Error() string { return e.Ref.String() + ": " + e.Err.Error() }
+
+func (e *InventoryError) Unwrap() error { return e.Err }
+
+// Inventory is the catalog tree read by [Catalog.Inventory]. Objects
+// are linked both ways: children point at their parent, layers at
+// their resource and default style, resources back at their layer,
+// and layer groups at their members. Every list is sorted by name.
+type Inventory struct {
+	Workspaces []*InventoryWorkspace
+
+	// Styles are the global styles.
+	Styles []*InventoryStyle
+
+	// Errors lists the objects that could not be fetched.
+	Errors []*InventoryError
+}
+
+// InventoryWorkspace is one workspace and everything in it.
+type InventoryWorkspace struct {
+	Workspace      *workspaces.Workspace
+	Datastores     []*InventoryDatastore
+	CoverageStores []*InventoryCoverageStore
+	Layers         []*InventoryLayer
+	LayerGroups    []*InventoryLayerGroup
+	Styles         []*InventoryStyle
+}
+
+// InventoryDatastore is a datastore and its feature types.
+type InventoryDatastore struct {
+	Workspace    *InventoryWorkspace
+	Datastore    *datastores.Datastore
+	FeatureTypes []*InventoryFeatureType
+}
+
+// InventoryFeatureType is a feature type and the layer publishing it.
+type InventoryFeatureType struct {
+	Datastore   *InventoryDatastore
+	FeatureType *featuretypes.FeatureType
+	Layer       *InventoryLayer
+}
+
+// InventoryCoverageStore is a coverage store and its coverages.
+type InventoryCoverageStore struct {
+	Workspace     *InventoryWorkspace
+	CoverageStore *coveragestores.CoverageStore
+	Coverages     []*InventoryCoverage
+}
+
+// InventoryCoverage is a coverage and the layer publishing it.
+type InventoryCoverage struct {
+	CoverageStore *InventoryCoverageStore
+	Coverage      *coverages.Coverage
+	Layer         *InventoryLayer
+}
+
+// InventoryLayer is a layer. FeatureType or Coverage is its resource,
+// DefaultStyle its default style; each is nil when it was not crawled
+// or does not exist.
+type InventoryLayer struct {
+	Workspace    *InventoryWorkspace
+	Layer        *layers.Layer
+	FeatureType  *InventoryFeatureType
+	Coverage     *InventoryCoverage
+	DefaultStyle *InventoryStyle
+}
+
+// InventoryLayerGroup is a layer group. Layers and LayerGroups are its
+// members that were crawled, in member order.
+type InventoryLayerGroup struct {
+	Workspace   *InventoryWorkspace
+	LayerGroup  *layergroups.LayerGroup
+	Layers      []*InventoryLayer
+	LayerGroups []*InventoryLayerGroup
+}
+
+// InventoryStyle is a style. Workspace is nil for a global style.
+type InventoryStyle struct {
+	Workspace *InventoryWorkspace
+	Style     *styles.Style
+}
+
+// Inventory reads the catalog tree with up to opts.Workers requests in
+// flight. A failure to list workspaces, or a cancelled ctx, fails the
+// call; any other failure is recorded in [Inventory.Errors] and the
+// crawl goes on without that object's children.
+//
+//	inv, err := c.Catalog().Inventory(ctx, geoserver.InventoryOptions{
+//		Workspaces: []string{"prod_*"},
+//		Kinds:      []string{geoserver.KindDatastore, geoserver.KindFeatureType, geoserver.KindLayer},
+//	})
+func (cat *Catalog) Inventory(ctx context.Context, opts InventoryOptions) (*Inventory, error) {
+	const op = "Catalog.Inventory"
+	c := cat.c
+	list, err := c.Workspaces.List(ctx, workspaces.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	cr := &crawler{ctx: ctx, opts: opts, inv: &Inventory{}}
+	cr.sem = make(chan struct{}, cmp.Or(max(opts.Workers, 0), defaultInventoryWorkers))
+	if cr.wants(KindStyle) {
+		cr.styles(c.Styles, nil, func(s *InventoryStyle) { cr.inv.Styles = append(cr.inv.Styles, s) })
+	}
+	for _, ws := range list {
+		if !cr.wantsWorkspace(ws.Name) {
+			continue
+		}
+		iw := &InventoryWorkspace{}
+		cr.inv.Workspaces = append(cr.inv.Workspaces, iw)
+		cr.workspace(c, iw, ws.Name)
+	}
+	cr.wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// A workspace whose Get failed has no document; drop it.
+	cr.inv.Workspaces = slices.DeleteFunc(cr.inv.Workspaces, func(iw *InventoryWorkspace) bool { return iw.Workspace == nil })
+	cr.inv.link()
+	return cr.inv, nil
+}
+
+// crawler runs one inventory: every fetch is a goroutine that holds a
+// slot of sem for the duration of its request.
+type crawler struct {
+	ctx  context.Context
+	opts InventoryOptions
+	sem  chan struct{}
+	wg   sync.WaitGroup
+
+	total atomic.Int64 // fetches started
+
+	mu   sync.Mutex // guards inv and done
+	inv  *Inventory
+	done int
+}
+
+func (cr *crawler) wants(kind string) bool {
+	return (len(cr.opts.Kinds) == 0 || slices.Contains(cr.opts.Kinds, kind)) && !slices.Contains(cr.opts.ExcludeKinds, kind)
+}
+
+func (cr *crawler) wantsWorkspace(name string) bool {
+	match := func(patterns []string) bool {
+		return slices.ContainsFunc(patterns, func(p string) bool {
+			ok, _ := path.Match(p, name)
+			return ok
+		})
+	}
+	return (len(cr.opts.Workspaces) == 0 || match(cr.opts.Workspaces)) && !match(cr.opts.ExcludeWorkspaces)
+}
+
+// fetch runs fn for ref in the pool. fn's result is applied by then,
+// under the crawler's lock, so it may touch the inventory and spawn
+// more fetches.
+func (cr *crawler) fetch(ref Ref, fn func(ctx context.Context) (then func(), err error)) {
+	cr.total.Add(1)
+	cr.wg.Add(1)
+	go func() {
+		defer cr.wg.Done()
+		var then func()
+		err := cr.ctx.Err()
+		if err == nil {
+			select {
+			case cr.sem <- struct{}{}:
+				then, err = fn(cr.ctx)
+				<-cr.sem
+			case <-cr.ctx.Done():
+				err = cr.ctx.Err()
+			}
+		}
+		cr.mu.Lock()
+		defer cr.mu.Unlock()
+		cr.done++
+		if err != nil && cr.ctx.Err() == nil {
+			cr.inv.Errors = append(cr.inv.Errors, &InventoryError{Ref: ref, Err: err})
+		} else if err == nil && then != nil {
+			then()
+		}
+		if cr.opts.Progress != nil {
+			cr.opts.Progress(InventoryProgress{Ref: ref, Err: err, Done: cr.done, Total: int(cr.total.Load())})
+		}
+	}()
+}
+
+// each lists names with list and fetches each one with get, handing
+// the results to add. All three run under the crawler's lock except
+// the requests themselves. With [InventoryOptions.NamesOnly], add gets
+// the document named builds from the name instead.
+func each[T any](cr *crawler, listRef Ref, list func(context.Context) ([]string, error), ref func(name string) Ref, get func(context.Context, string) (*T, error), named func(string) *T, add func(*T)) {
+	cr.fetch(listRef, func(ctx context.Context) (func(), error) {
+		names, err := list(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, name := range names {
+				if cr.opts.NamesOnly {
+					add(named(name))
+					continue
+				}
+				cr.fetch(ref(name), func(ctx context.Context) (func(), error) {
+					v, err := get(ctx, name)
+					if err != nil {
+						return nil, err
+					}
+					return func() { add(v) }, nil
+				})
+			}
+		}, nil
+	})
+}
+
+func names[T any](items []T, name func(T) string) []string {
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = name(it)
+	}
+	return out
+}
+
+func (cr *crawler) workspace(c *Client, iw *InventoryWorkspace, ws string) {
+	if cr.opts.NamesOnly {
+		iw.Workspace = &workspaces.Workspace{Name: ws}
+	} else {
+		cr.fetch(Ref{Kind: KindWorkspace, Name: ws}, func(ctx context.Context) (func(), error) {
+			w, err := c.Workspaces.Get(ctx, ws)
+			return func() { iw.Workspace = w }, err
+		})
+	}
+	if cr.wants(KindStyle) {
+		cr.styles(c.Styles.InWorkspace(ws), iw, func(s *InventoryStyle) { iw.Styles = append(iw.Styles, s) })
+	}
+	if cr.wants(KindDatastore) {
+		dc := c.Datastores.InWorkspace(ws)
+		each(cr, Ref{Kind: KindDatastore, Workspace: ws, Name: "*"},
+			func(ctx context.Context) ([]string, error) {
+				l, err := dc.List(ctx, datastores.ListOptions{})
+				return names(l, func(d datastores.Datastore) string { return d.Name }), err
+			},
+			func(name string) Ref { return Ref{Kind: KindDatastore, Workspace: ws, Name: name} },
+			dc.Get,
+			func(name string) *datastores.Datastore { return &datastores.Datastore{Name: name} },
+			func(d *datastores.Datastore) {
+				ids := &InventoryDatastore{Workspace: iw, Datastore: d}
+				iw.Datastores = append(iw.Datastores, ids)
+				if cr.wants(KindFeatureType) {
+					fc := c.FeatureTypes.InWorkspace(ws).InDatastore(d.Name)
+					each(cr, Ref{Kind: KindFeatureType, Workspace: ws, Store: d.Name, Name: "*"},
+						func(ctx context.Context) ([]string, error) {
+							l, err := fc.List(ctx, featuretypes.ListOptions{})
+							return names(l, func(f featuretypes.FeatureType) string { return f.Name }), err
+						},
+						func(name string) Ref { return Ref{Kind: KindFeatureType, Workspace: ws, Store: d.Name, Name: name} },
+						fc.Get,
+						func(name string) *featuretypes.FeatureType { return &featuretypes.FeatureType{Name: name} },
+						func(ft *featuretypes.FeatureType) {
+							ids.FeatureTypes = append(ids.FeatureTypes, &InventoryFeatureType{Datastore: ids, FeatureType: ft})
+						})
+				}
+			})
+	}
+	if cr.wants(KindCoverageStore) {
+		cc := c.CoverageStores.InWorkspace(ws)
+		each(cr, Ref{Kind: KindCoverageStore, Workspace: ws, Name: "*"},
+			func(ctx context.Context) ([]string, error) {
+				l, err := cc.List(ctx, coveragestores.ListOptions{})
+				return names(l, func(s coveragestores.CoverageStore) string { return s.Name }), err
+			},
+			func(name string) Ref { return Ref{Kind: KindCoverageStore, Workspace: ws, Name: name} },
+			cc.Get,
+			func(name string) *coveragestores.CoverageStore { return &coveragestores.CoverageStore{Name: name} },
+			func(s *coveragestores.CoverageStore) {
+				ics := &InventoryCoverageStore{Workspace: iw, CoverageStore: s}
+				iw.CoverageStores = append(iw.CoverageStores, ics)
+				if cr.wants(KindCoverage) {
+					vc := c.Coverages.InWorkspace(ws).InCoverageStore(s.Name)
+					each(cr, Ref{Kind: KindCoverage, Workspace: ws, Store: s.Name, Name: "*"},
+						func(ctx context.Context) ([]string, error) {
+							l, err := vc.List(ctx, coverages.ListOptions{})
+							return names(l, func(v coverages.Coverage) string { return v.Name }), err
+						},
+						func(name string) Ref { return Ref{Kind: KindCoverage, Workspace: ws, Store: s.Name, Name: name} },
+						vc.Get,
+						func(name string) *coverages.Coverage { return &coverages.Coverage{Name: name} },
+						func(cov *coverages.Coverage) {
+							ics.Coverages = append(ics.Coverages, &InventoryCoverage{CoverageStore: ics, Coverage: cov})
+						})
+				}
+			})
+	}
+	if cr.wants(KindLayer) {
+		lc := c.Layers.InWorkspace(ws)
+		each(cr, Ref{Kind: KindLayer, Workspace: ws, Name: "*"},
+			func(ctx context.Context) ([]string, error) {
+				l, err := lc.List(ctx, layers.ListOptions{})
+				return names(l, func(l layers.Layer) string { return l.Name }), err
+			},
+			func(name string) Ref { return Ref{Kind: KindLayer, Workspace: ws, Name: name} },
+			lc.Get,
+			func(name string) *layers.Layer { return &layers.Layer{Name: name} },
+			func(l *layers.Layer) { iw.Layers = append(iw.Layers, &InventoryLayer{Workspace: iw, Layer: l}) })
+	}
+	if cr.wants(KindLayerGroup) {
+		gc := c.LayerGroups.InWorkspace(ws)
+		each(cr, Ref{Kind: KindLayerGroup, Workspace: ws, Name: "*"},
+			func(ctx context.Context) ([]string, error) {
+				l, err := gc.List(ctx, layergroups.ListOptions{})
+				return names(l, func(g layergroups.LayerGroup) string { return g.Name }), err
+			},
+			func(name string) Ref { return Ref{Kind: KindLayerGroup, Workspace: ws, Name: name} },
+			gc.Get,
+			func(name string) *layergroups.LayerGroup { return &layergroups.LayerGroup{Name: name} },
+			func(g *layergroups.LayerGroup) {
+				iw.LayerGroups = append(iw.LayerGroups, &InventoryLayerGroup{Workspace: iw, LayerGroup: g})
+			})
+	}
+}
+
+func (cr *crawler) styles(sc *styles.Client, iw *InventoryWorkspace, add func(*InventoryStyle)) {
+	ws := sc.Workspace()
+	each(cr, Ref{Kind: KindStyle, Workspace: ws, Name: "*"},
+		func(ctx context.Context) ([]string, error) {
+			l, err := sc.List(ctx, styles.ListOptions{})
+			return names(l, func(s styles.Style) string { return s.Name }), err
+		},
+		func(name string) Ref { return Ref{Kind: KindStyle, Workspace: ws, Name: name} },
+		sc.Get,
+		func(name string) *styles.Style { return &styles.Style{Name: name} },
+		func(s *styles.Style) { add(&InventoryStyle{Workspace: iw, Style: s}) })
+}
+
+// link sorts the tree and resolves its cross references.
+func (inv *Inventory) link() {
+	fts := map[string]*InventoryFeatureType{}
+	covs := map[string]*InventoryCoverage{}
+	lyrs := map[string]*InventoryLayer{}
+	groups := map[string]*InventoryLayerGroup{}
+	stys := map[string]*InventoryStyle{}
+	for _, s := range inv.Styles {
+		stys[s.Style.Name] = s
+	}
+	slices.SortFunc(inv.Styles, func(a, b *InventoryStyle) int { return cmp.Compare(a.Style.Name, b.Style.Name) })
+	slices.SortFunc(inv.Errors, func(a, b *InventoryError) int { return cmp.Compare(a.Ref.String(), b.Ref.String()) })
+	slices.SortFunc(inv.Workspaces, func(a, b *InventoryWorkspace) int { return cmp.Compare(a.Workspace.Name, b.Workspace.Name) })
+	for _, iw := range inv.Workspaces {
+		ws := iw.Workspace.Name
+		slices.SortFunc(iw.Datastores, func(a, b *InventoryDatastore) int { return cmp.Compare(a.Datastore.Name, b.Datastore.Name) })
+		for _, ds := range iw.Datastores {
+			slices.SortFunc(ds.FeatureTypes, func(a, b *InventoryFeatureType) int {
+				return cmp.Compare(a.FeatureType.Name, b.FeatureType.Name)
+			})
+			for _, ft := range ds.FeatureTypes {
+				fts[ws+":"+ft.FeatureType.Name] = ft
+			}
+		}
+		slices.SortFunc(iw.CoverageStores, func(a, b *InventoryCoverageStore) int {
+			return cmp.Compare(a.CoverageStore.Name, b.CoverageStore.Name)
+		})
+		for _, cs := range iw.CoverageStores {
+			slices.SortFunc(cs.Coverages, func(a, b *InventoryCoverage) int { return cmp.Compare(a.Coverage.Name, b.Coverage.Name) })
+			for _, cov := range cs.Coverages {
+				covs[ws+":"+cov.Coverage.Name] = cov
+			}
+		}
+		slices.SortFunc(iw.Layers, func(a, b *InventoryLayer) int { return cmp.Compare(a.Layer.Name, b.Layer.Name) })
+		for _, l := range iw.Layers {
+			lyrs[ws+":"+l.Layer.Name] = l
+		}
+		slices.SortFunc(iw.LayerGroups, func(a, b *InventoryLayerGroup) int {
+			return cmp.Compare(a.LayerGroup.Name, b.LayerGroup.Name)
+		})
+		for _, g := range iw.LayerGroups {
+			groups[ws+":"+g.LayerGroup.Name] = g
+		}
+		slices.SortFunc(iw.Styles, func(a, b *InventoryStyle) int { return cmp.Compare(a.Style.Name, b.Style.Name) })
+		for _, s := range iw.Styles {
+			stys[ws+":"+s.Style.Name] = s
+		}
+	}
+
+	qualify := func(ws, name string) string {
+		if strings.Contains(name, ":") {
+			return name
+		}
+		return ws + ":" + name
+	}
+	for _, iw := range inv.Workspaces {
+		ws := iw.Workspace.Name
+		for _, l := range iw.Layers {
+			resource := ws + ":" + l.Layer.Name
+			if r := l.Layer.Resource; r != nil && r.Name != "" {
+				resource = qualify(ws, r.Name)
+			}
+			if ft := fts[resource]; ft != nil {
+				l.FeatureType, ft.Layer = ft, l
+			} else if cov := covs[resource]; cov != nil {
+				l.Coverage, cov.Layer = cov, l
+			}
+			if d := l.Layer.DefaultStyle; d != nil && d.Name != "" {
+				// A bare name is the workspace's style if it has one.
+				l.DefaultStyle = cmp.Or(stys[qualify(ws, d.Name)], stys[d.Name])
+			}
+		}
+		for _, g := range iw.LayerGroups {
+			for _, m := range g.LayerGroup.Publishables.Published {
+				if m.Type == "layerGroup" {
+					if member := groups[qualify(ws, m.Name)]; member != nil {
+						g.LayerGroups = append(g.LayerGroups, member)
+					}
+				} else if member := lyrs[qualify(ws, m.Name)]; member != nil {
+					g.Layers = append(g.Layers, member)
+				}
+			}
+		}
+	}
+}
+
+// Err joins the inventory's partial failures, or returns nil if there
+// were none.
+func (inv *Inventory) Err() error {
+	errs := make([]error, len(inv.Errors))
+	for i, e := range inv.Errors {
+		errs[i] = e
+	}
+	return errors.Join(errs...)
+}
+
+// lookup finds the item named name in a list sorted by name.
+func lookup[T any](items []*T, name string, nameOf func(*T) string) *T {
+	i, ok := slices.BinarySearchFunc(items, name, func(it *T, name string) int { return cmp.Compare(nameOf(it), name) })
+	if !ok {
+		return nil
+	}
+	return items[i]
+}
+
+// Workspace returns the workspace named name, or nil.
+func (inv *Inventory) Workspace(name string) *InventoryWorkspace {
+	return lookup(inv.Workspaces, name, func(iw *InventoryWorkspace) string { return iw.Workspace.Name })
+}
+
+// Style returns the global style named name, or nil.
+func (inv *Inventory) Style(name string) *InventoryStyle {
+	return lookup(inv.Styles, name, func(s *InventoryStyle) string { return s.Style.Name })
+}
+
+// Datastore returns the datastore named name, or nil.
+func (iw *InventoryWorkspace) Datastore(name string) *InventoryDatastore {
+	return lookup(iw.Datastores, name, func(d *InventoryDatastore) string { return d.Datastore.Name })
+}
+
+// CoverageStore returns the coverage store named name, or nil.
+func (iw *InventoryWorkspace) CoverageStore(name string) *InventoryCoverageStore {
+	return lookup(iw.CoverageStores, name, func(s *InventoryCoverageStore) string { return s.CoverageStore.Name })
+}
+
+// Layer returns the layer named name, or nil.
+func (iw *InventoryWorkspace) Layer(name string) *InventoryLayer {
+	return lookup(iw.Layers, name, func(l *InventoryLayer) string { return l.Layer.Name })
+}
+
+// LayerGroup returns the layer group named name, or nil.
+func (iw *InventoryWorkspace) LayerGroup(name string) *InventoryLayerGroup {
+	return lookup(iw.LayerGroups, name, func(g *InventoryLayerGroup) string { return g.LayerGroup.Name })
+}
+
+// Style returns the workspace style named name, or nil.
+func (iw *InventoryWorkspace) Style(name string) *InventoryStyle {
+	return lookup(iw.Styles, name, func(s *InventoryStyle) string { return s.Style.Name })
+}
+
+// FeatureType returns the feature type named name, or nil.
+func (ids *InventoryDatastore) FeatureType(name string) *InventoryFeatureType {
+	return lookup(ids.FeatureTypes, name, func(ft *InventoryFeatureType) string { return ft.FeatureType.Name })
+}
+
+// Coverage returns the coverage named name, or nil.
+func (ics *InventoryCoverageStore) Coverage(name string) *InventoryCoverage {
+	return lookup(ics.Coverages, name, func(c *InventoryCoverage) string { return c.Coverage.Name })
+}
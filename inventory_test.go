@@ -0,0 +1,184 @@
+package geoserver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/layergroups"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+)
+
+// failPath answers 500 to every request whose path contains fail and,
+// when method is set, that uses it.
+type failPath struct {
+	fail   string
+	method string // fail only this method; "" fails every method
+	next   http.RoundTripper
+}
+
+func (f failPath) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.Path, f.fail) && (f.method == "" || f.method == req.Method) {
+		return &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "500 Internal Server Error",
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+	return f.next.RoundTrip(req)
+}
+
+// inventoryCatalog builds workspaces "topp" and "prod_a", each with
+// datastore "pg" (roads) and "broken" (rivers), a coverage store "dem"
+// (elevation), a style "roads" and group "base" of roads and elevation.
+func inventoryCatalog(t *testing.T) *geoservertest.Server {
+	t.Helper()
+	srv := geoservertest.New(t, geoservertest.Options{})
+	content := geoservertest.FixtureWorkspace{
+		Datastores:     map[string][]string{"pg": {"roads"}, "broken": {"rivers"}},
+		CoverageStores: map[string][]string{"dem": {"elevation"}},
+		Styles:         []string{"roads"},
+	}
+	srv.Seed(geoservertest.Fixture{"topp": content, "prod_a": content})
+	c, must := srv.Client(), srv.Must
+	ctx := context.Background()
+	for _, ws := range []string{"topp", "prod_a"} {
+		must("layer", c.Layers.InWorkspace(ws).Update(ctx, "roads", &layers.Layer{DefaultStyle: &layers.Ref{Name: ws + ":roads"}}))
+		must("group", c.LayerGroups.InWorkspace(ws).Create(ctx, &layergroups.LayerGroup{
+			Name: "base",
+			Publishables: layergroups.Publishables{Published: layergroups.Published{
+				{Type: "layer", Name: ws + ":roads"}, {Type: "layer", Name: ws + ":elevation"},
+			}},
+		}))
+	}
+	return srv
+}
+
+func TestCatalog_Inventory(t *testing.T) {
+	ctx := context.Background()
+	srv := inventoryCatalog(t)
+	c := srv.Client(geoserver.WithTransport(failPath{fail: "/datastores/broken/featuretypes", next: http.DefaultTransport}))
+	var calls, last atomic.Int64
+	inv, err := c.Catalog().Inventory(ctx, geoserver.InventoryOptions{
+		Workers:           3,
+		ExcludeWorkspaces: []string{"prod_*"},
+		Progress: func(p geoserver.InventoryProgress) {
+			calls.Add(1)
+			last.Store(int64(p.Total - p.Done))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Workspaces) != 1 || inv.Workspaces[0].Workspace.Name != "topp" {
+		t.Fatalf("workspaces = %+v", inv.Workspaces)
+	}
+	if calls.Load() == 0 || last.Load() != 0 {
+		t.Fatalf("progress: %d calls, %d pending at the end", calls.Load(), last.Load())
+	}
+
+	ws := inv.Workspaces[0]
+	if len(ws.Datastores) != 2 || ws.Datastores[0].Datastore.Name != "broken" || len(ws.Datastores[0].FeatureTypes) != 0 {
+		t.Fatalf("datastores = %+v", ws.Datastores)
+	}
+	if len(inv.Errors) != 1 || inv.Errors[0].Ref != (geoserver.Ref{Kind: geoserver.KindFeatureType, Workspace: "topp", Store: "broken", Name: "*"}) {
+		t.Fatalf("errors = %v", inv.Errors)
+	}
+	var apiErr *geoserver.APIError
+	if !errors.As(inv.Err(), &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Err() = %v", inv.Err())
+	}
+
+	pg := ws.Datastores[1]
+	if pg.Workspace != ws || len(pg.FeatureTypes) != 1 {
+		t.Fatalf("pg = %+v", pg)
+	}
+	roads := pg.FeatureTypes[0]
+	if roads.Datastore != pg || roads.Layer == nil || roads.Layer.FeatureType != roads {
+		t.Fatalf("roads = %+v", roads)
+	}
+	if s := roads.Layer.DefaultStyle; s == nil || s.Workspace != ws || s.Style.Name != "roads" {
+		t.Fatalf("roads default style = %+v", s)
+	}
+	elevation := ws.CoverageStores[0].Coverages[0]
+	if elevation.Layer == nil || elevation.Layer.Coverage != elevation {
+		t.Fatalf("elevation = %+v", elevation)
+	}
+	// rivers' feature type was not crawled, so its layer is unlinked.
+	if len(ws.Layers) != 3 || ws.Layers[1].Layer.Name != "rivers" || ws.Layers[1].FeatureType != nil {
+		t.Fatalf("layers = %+v", ws.Layers)
+	}
+	g := ws.LayerGroups[0]
+	if len(g.Layers) != 2 || g.Layers[0] != roads.Layer || g.Layers[1] != elevation.Layer {
+		t.Fatalf("group members = %+v", g.Layers)
+	}
+}
+
+func TestCatalog_InventoryFilters(t *testing.T) {
+	ctx := context.Background()
+	c := inventoryCatalog(t).Client()
+	inv, err := c.Catalog().Inventory(ctx, geoserver.InventoryOptions{
+		Workspaces:   []string{"prod_*", "topp"},
+		ExcludeKinds: []string{geoserver.KindDatastore, geoserver.KindStyle, geoserver.KindLayerGroup},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Workspaces) != 2 || inv.Workspaces[0].Workspace.Name != "prod_a" || len(inv.Styles) != 0 {
+		t.Fatalf("inventory = %+v", inv)
+	}
+	for _, ws := range inv.Workspaces {
+		if len(ws.Datastores) != 0 || len(ws.Styles) != 0 || len(ws.LayerGroups) != 0 || len(ws.CoverageStores) != 1 {
+			t.Fatalf("workspace %s = %+v", ws.Workspace.Name, ws)
+		}
+		for _, l := range ws.Layers {
+			if l.FeatureType != nil || l.DefaultStyle != nil || (l.Layer.Name == "elevation") != (l.Coverage != nil) {
+				t.Fatalf("layer %s = %+v", l.Layer.Name, l)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.Catalog().Inventory(ctx, geoserver.InventoryOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled Inventory = %v", err)
+	}
+}
+
+func TestCatalog_InventoryNamesOnly(t *testing.T) {
+	ctx := context.Background()
+	srv := inventoryCatalog(t)
+	inv, err := srv.Client().Catalog().Inventory(ctx, geoserver.InventoryOptions{Workspaces: []string{"topp"}, NamesOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range srv.Requests() {
+		if strings.HasSuffix(r.Path, "/roads.json") || strings.HasSuffix(r.Path, "/workspaces/topp.json") {
+			t.Fatalf("NamesOnly fetched %s", r.Path)
+		}
+	}
+	ws := inv.Workspace("topp")
+	if ws == nil || inv.Workspace("prod_a") != nil || inv.Style("line") == nil {
+		t.Fatalf("inventory = %+v", inv)
+	}
+	roads := ws.Datastore("pg").FeatureType("roads")
+	if roads == nil || ws.Datastore("nope") != nil || ws.Layer("roads") != roads.Layer || roads.Layer.DefaultStyle != nil {
+		t.Fatalf("roads = %+v", roads)
+	}
+	if ws.CoverageStore("dem").Coverage("elevation") == nil || ws.LayerGroup("base") == nil || ws.Style("roads") == nil {
+		t.Fatalf("workspace = %+v", ws)
+	}
+}
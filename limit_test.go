@@ -0,0 +1,84 @@
+package geoserver_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+)
+
+// syncBuffer is a goroutine-safe log sink.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWithRateLimit_PacesAndLogsQueueWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"about":{"resource":[]}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var logs syncBuffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c, err := geoserver.New(srv.URL,
+		geoserver.WithRateLimit(40, 1),
+		geoserver.WithMaxConcurrentRequests(2),
+		geoserver.WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.About.Ping(context.Background()); err != nil {
+				t.Errorf("Ping: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("5 requests at 40 rps took %v, want >= ~100ms", elapsed)
+	}
+	out := logs.String()
+	if !strings.Contains(out, "request queued") || !strings.Contains(out, "op=About.Ping") {
+		t.Fatalf("expected queue-wait debug log, got:\n%s", out)
+	}
+}
+
+func TestWithRateLimit_InvalidArgs(t *testing.T) {
+	for _, opt := range []geoserver.Option{
+		geoserver.WithRateLimit(0, 1),
+		geoserver.WithRateLimit(10, 0),
+		geoserver.WithMaxConcurrentRequests(0),
+	} {
+		if _, err := geoserver.New("http://localhost:8080/geoserver", opt); err == nil {
+			t.Fatal("expected option error")
+		}
+	}
+}
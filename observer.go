@@ -0,0 +1,128 @@
+package geoserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hishamkaram/geoserver/v2/internal/transport"
+)
+
+// Observer receives a callback pair around every operation the client
+// issues — one pair per public sub-client call, not per HTTP attempt
+// (retries configured with [WithRetry] happen inside the pair).
+//
+// op is the stable operation name also reported on [*APIError.Op]
+// (e.g., "Workspaces.Create"), so implementations can name spans and
+// label metrics without parsing URLs. The interface is stdlib-only;
+// adapters for OpenTelemetry or Prometheus live in caller code.
+//
+// Implementations must be safe for concurrent use.
+type Observer interface {
+	// OnRequestStart is called before the request is sent. The
+	// returned context is used for the request and handed back to
+	// OnRequestEnd, so a tracing span started here reaches both the
+	// transport's propagators and the matching end call, even for
+	// concurrent calls of the same op. Return ctx unchanged if
+	// there's nothing to attach.
+	OnRequestStart(ctx context.Context, op, method, url string) context.Context
+
+	// OnRequestEnd is called once the operation completes. status is
+	// 0 when no response was received. bytes counts response-body
+	// bytes read by the client. err is the error returned to the
+	// caller — an [*APIError] for non-2xx responses. For streamed
+	// responses (downloads returning an [io.ReadCloser]) the call is
+	// deferred until the caller closes the stream.
+	OnRequestEnd(ctx context.Context, op string, status int, bytes int64, duration time.Duration, err error)
+}
+
+// WithObserver registers an [Observer] for every operation issued by
+// the client. Multiple calls accumulate; observers are notified in
+// registration order on start and in reverse order on end, so nested
+// spans close cleanly.
+func WithObserver(o Observer) Option {
+	return func(cfg *clientConfig) error {
+		if o == nil {
+			return errors.New("geoserver: WithObserver: observer is nil")
+		}
+		cfg.observers = append(cfg.observers, o)
+		return nil
+	}
+}
+
+// multiObserver fans a callback pair out to several observers.
+type multiObserver []Observer
+
+func (m multiObserver) OnRequestStart(ctx context.Context, op, method, url string) context.Context {
+	for _, o := range m {
+		ctx = o.OnRequestStart(ctx, op, method, url)
+	}
+	return ctx
+}
+
+func (m multiObserver) OnRequestEnd(ctx context.Context, op string, status int, bytes int64, d time.Duration, err error) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i].OnRequestEnd(ctx, op, status, bytes, d, err)
+	}
+}
+
+// observe starts an observed operation. It returns the context and
+// [transport.Doer] to issue the request with, and a func to call once
+// the outcome is known. Without an observer configured it returns the
+// inputs unchanged and a no-op.
+func (c *clientCore) observe(ctx context.Context, op, method, requestURL string) (context.Context, transport.Doer, func(status int, err error)) {
+	if c.observer == nil {
+		return ctx, c.httpClient, func(int, error) {}
+	}
+	start := time.Now()
+	ctx = c.observer.OnRequestStart(ctx, op, method, requestURL)
+	doer := &countingDoer{doer: c.httpClient}
+	end := func(status int, err error) {
+		c.observer.OnRequestEnd(ctx, op, status, doer.n.Load(), time.Since(start), err)
+	}
+	return ctx, doer, end
+}
+
+// countingDoer tallies response-body bytes read through it.
+type countingDoer struct {
+	doer transport.Doer
+	n    atomic.Int64
+}
+
+func (d *countingDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.doer.Do(req)
+	if resp != nil && resp.Body != nil {
+		resp.Body = &countingBody{ReadCloser: resp.Body, n: &d.n}
+	}
+	return resp, err
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+// endOnClose defers an observer's OnRequestEnd until a streamed body
+// is closed.
+type endOnClose struct {
+	io.ReadCloser
+	once   sync.Once
+	status int
+	end    func(status int, err error)
+}
+
+func (b *endOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.end(b.status, nil) })
+	return err
+}
@@ -0,0 +1,124 @@
+package geoserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+)
+
+type ctxKey struct{}
+
+type observed struct {
+	op, method, url string
+	status          int
+	bytes           int64
+	err             error
+	sawStartCtx     bool
+}
+
+// recordingObserver captures every callback pair for inspection.
+type recordingObserver struct {
+	mu     sync.Mutex
+	starts int
+	ends   []observed
+}
+
+func (o *recordingObserver) OnRequestStart(ctx context.Context, op, method, url string) context.Context {
+	o.mu.Lock()
+	o.starts++
+	o.mu.Unlock()
+	return context.WithValue(ctx, ctxKey{}, op+" "+method+" "+url)
+}
+
+func (o *recordingObserver) OnRequestEnd(ctx context.Context, op string, status int, bytes int64, _ time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	v, _ := ctx.Value(ctxKey{}).(string)
+	o.ends = append(o.ends, observed{op: op, status: status, bytes: bytes, err: err, sawStartCtx: v != ""})
+}
+
+func TestWithObserver_DoAndError(t *testing.T) {
+	const body = `{"workspace":{"name":"topp"}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/workspaces/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	obs := &recordingObserver{}
+	c, err := geoserver.New(srv.URL, geoserver.WithObserver(obs))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.Workspaces.Get(ctx, "topp"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	_, missErr := c.Workspaces.Get(ctx, "missing")
+
+	if obs.starts != 2 || len(obs.ends) != 2 {
+		t.Fatalf("starts=%d ends=%d", obs.starts, len(obs.ends))
+	}
+	ok := obs.ends[0]
+	if ok.op != "Workspaces.Get" || ok.status != http.StatusOK || ok.bytes != int64(len(body)) || ok.err != nil || !ok.sawStartCtx {
+		t.Fatalf("success end = %+v", ok)
+	}
+	miss := obs.ends[1]
+	if miss.status != http.StatusNotFound || !errors.Is(miss.err, geoserver.ErrNotFound) || miss.err != missErr {
+		t.Fatalf("error end = %+v", miss)
+	}
+}
+
+func TestWithObserver_StreamEndsOnClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "hello world")
+	}))
+	t.Cleanup(srv.Close)
+
+	obs := &recordingObserver{}
+	c, err := geoserver.New(srv.URL, geoserver.WithObserver(obs))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	rc, err := c.Resources.Get(context.Background(), "styles/readme.txt")
+	if err != nil {
+		t.Fatalf("Resources.Get: %v", err)
+	}
+	if _, err := io.ReadAll(rc); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(obs.ends) != 0 {
+		t.Fatalf("OnRequestEnd fired before Close: %+v", obs.ends)
+	}
+	_ = rc.Close()
+	_ = rc.Close()
+	if len(obs.ends) != 1 || obs.ends[0].bytes != int64(len("hello world")) || obs.ends[0].status != http.StatusOK {
+		t.Fatalf("ends = %+v", obs.ends)
+	}
+}
+
+func TestWithObserver_TransportError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	srv.Close() // connection refused
+
+	obs := &recordingObserver{}
+	c, err := geoserver.New(srv.URL, geoserver.WithObserver(obs))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_ = c.About.Ping(context.Background())
+	if len(obs.ends) != 1 || obs.ends[0].status != 0 || obs.ends[0].err == nil {
+		t.Fatalf("ends = %+v", obs.ends)
+	}
+}
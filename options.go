@@ -2,8 +2,11 @@ package geoserver
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -21,15 +24,29 @@ type clientConfig struct {
 	userAgent     string
 	auth          authCredentials
 	defaultHeader http.Header
+
+	maxResponseBytes int64
+	retry            *RetryPolicy
+
+	rateLimit     float64
+	rateBurst     int
+	maxConcurrent int
+
+	observers []Observer
+
+	owsBaseURL string
+	gwcBaseURL string
 }
 
 // authCredentials holds the resolved auth strategy. Mutually exclusive:
-// at most one of basic / bearer is set. Empty == no auth header attached.
+// at most one of basic / bearer / provider is set. Empty == no auth
+// header attached.
 type authCredentials struct {
 	kind     authKind
 	username string
 	password string
 	bearer   string
+	provider CredentialsProvider
 }
 
 type authKind int
@@ -38,6 +55,7 @@ const (
 	authNone authKind = iota
 	authBasic
 	authBearer
+	authProvider
 )
 
 // WithHTTPClient supplies a custom *http.Client. The client's Transport
@@ -103,7 +121,8 @@ func WithUserAgent(ua string) Option {
 }
 
 // WithBasicAuth attaches HTTP basic-auth headers to every request.
-// Mutually exclusive with [WithBearerToken]; later option wins.
+// Mutually exclusive with [WithBearerToken] and
+// [WithCredentialsProvider]; later option wins.
 func WithBasicAuth(username, password string) Option {
 	return func(cfg *clientConfig) error {
 		if username == "" {
@@ -115,7 +134,8 @@ func WithBasicAuth(username, password string) Option {
 }
 
 // WithBearerToken attaches a bearer token to every request.
-// Mutually exclusive with [WithBasicAuth]; later option wins.
+// Mutually exclusive with [WithBasicAuth] and
+// [WithCredentialsProvider]; later option wins.
 func WithBearerToken(token string) Option {
 	return func(cfg *clientConfig) error {
 		if token == "" {
@@ -141,3 +161,105 @@ func WithHeader(key, value string) Option {
 		return nil
 	}
 }
+
+// WithMaxResponseBytes caps how many bytes of a successful (2xx)
+// response body the client reads and decodes. Applies to JSON catalog
+// documents and XML capabilities documents alike; a larger body fails
+// with an error wrapping [ErrResponseTooLarge] rather than being
+// silently truncated. Error bodies surfaced through [*APIError] are
+// capped separately at 8 KiB. Default: 32 MiB.
+func WithMaxResponseBytes(n int64) Option {
+	return func(cfg *clientConfig) error {
+		if n <= 0 {
+			return errors.New("geoserver: WithMaxResponseBytes: limit must be positive")
+		}
+		cfg.maxResponseBytes = n
+		return nil
+	}
+}
+
+// WithRateLimit caps the client's request rate at rps requests per
+// second, allowing bursts of up to burst requests. Requests over the
+// limit wait (honouring context cancellation) rather than fail. Use
+// it to keep bulk catalog migrations from overwhelming GeoServer's
+// catalog lock. Retried attempts (see [WithRetry]) count against the
+// limit too.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(cfg *clientConfig) error {
+		if rps <= 0 {
+			return errors.New("geoserver: WithRateLimit: rps must be positive")
+		}
+		if burst < 1 {
+			return errors.New("geoserver: WithRateLimit: burst must be at least 1")
+		}
+		cfg.rateLimit = rps
+		cfg.rateBurst = burst
+		return nil
+	}
+}
+
+// WithMaxConcurrentRequests caps the number of requests in flight at
+// once across every goroutine sharing the client. Further requests
+// queue (honouring context cancellation) until a slot frees up. A
+// slot is held until the response body has been read and closed.
+func WithMaxConcurrentRequests(n int) Option {
+	return func(cfg *clientConfig) error {
+		if n < 1 {
+			return errors.New("geoserver: WithMaxConcurrentRequests: n must be at least 1")
+		}
+		cfg.maxConcurrent = n
+		return nil
+	}
+}
+
+// WithOWSBaseURL routes the OWS sub-clients ([Client.WMS], [Client.WFS],
+// [Client.WCS]) to a different GeoServer root than the REST API — for
+// example a CDN hostname fronting public map traffic while
+// /geoserver/rest stays on an internal address. The URL plays the
+// same role as New's serverURL: service paths ("wms",
+// "{workspace}/wfs", …) are appended to it. Default: serverURL.
+func WithOWSBaseURL(u string) Option {
+	return func(cfg *clientConfig) error {
+		base, err := normalizeBaseURL(u)
+		if err != nil {
+			return fmt.Errorf("geoserver: WithOWSBaseURL: %w", err)
+		}
+		cfg.owsBaseURL = base
+		return nil
+	}
+}
+
+// WithGWCBaseURL routes the GeoWebCache sub-client ([Client.GWC]) to a
+// different GeoServer root than the REST API — for example the
+// separate gwc service of a GeoServer Cloud deployment. "gwc/rest/…"
+// is appended to it, as it is to New's serverURL. Default: serverURL.
+func WithGWCBaseURL(u string) Option {
+	return func(cfg *clientConfig) error {
+		base, err := normalizeBaseURL(u)
+		if err != nil {
+			return fmt.Errorf("geoserver: WithGWCBaseURL: %w", err)
+		}
+		cfg.gwcBaseURL = base
+		return nil
+	}
+}
+
+// normalizeBaseURL validates an http(s) base URL and returns it with a
+// trailing slash, the form [clientCore] stores. [New] and the base URL
+// overrides share it so they accept the same URLs.
+func normalizeBaseURL(raw string) (string, error) {
+	if raw == "" {
+		return "", errors.New("empty URL")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("parse URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("URL scheme must be http or https, got %q", parsed.Scheme)
+	}
+	if !strings.HasSuffix(raw, "/") {
+		raw += "/"
+	}
+	return raw, nil
+}
@@ -0,0 +1,168 @@
+// Package ows holds what the OWS service clients ([wms], [wfs], [wcs])
+// share: the service exception report an OGC service can answer with
+// in place of the content that was asked for, and the GeoJSON types
+// feature responses decode into.
+//
+// [wms]: https://pkg.go.dev/github.com/hishamkaram/geoserver/v2/ows/wms
+// [wfs]: https://pkg.go.dev/github.com/hishamkaram/geoserver/v2/ows/wfs
+// [wcs]: https://pkg.go.dev/github.com/hishamkaram/geoserver/v2/ows/wcs
+package ows
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// ExceptionReport is a service exception report: a WMS
+// `<ServiceExceptionReport>` or an OWS `<ows:ExceptionReport>`. OGC
+// services deliver these with 200 OK, so the OWS clients check every
+// response for one and return it as an error instead of the content.
+//
+//	var report *ows.ExceptionReport
+//	if errors.As(err, &report) && report.Code() == "LayerNotDefined" {
+//		// …
+//	}
+type ExceptionReport struct {
+	// Op is the operation name, e.g. "WMS.GetMap".
+	Op string
+
+	// URL is the request URL, without its query.
+	URL string
+
+	// Version is the report's version attribute.
+	Version string
+
+	Exceptions []Exception
+}
+
+// Exception is one exception in an [ExceptionReport].
+type Exception struct {
+	// Code is the exception code, e.g. "LayerNotDefined" or
+	// "InvalidParameterValue". GeoServer leaves it empty for some
+	// internal errors.
+	Code string
+
+	// Locator names the offending parameter, when the server says.
+	Locator string
+
+	Text string
+}
+
+func (e *ExceptionReport) Error() string {
+	var b strings.Builder
+	b.WriteString("ows: " + e.Op + ": service exception")
+	for i, ex := range e.Exceptions {
+		if i > 0 {
+			b.WriteString(";")
+		}
+		if ex.Code != "" {
+			b.WriteString(" " + ex.Code)
+		}
+		if ex.Locator != "" {
+			b.WriteString(" (" + ex.Locator + ")")
+		}
+		if ex.Text != "" {
+			b.WriteString(": " + ex.Text)
+		}
+	}
+	return b.String()
+}
+
+// Code returns the first exception's code, or "" when there is none.
+func (e *ExceptionReport) Code() string {
+	if len(e.Exceptions) == 0 {
+		return ""
+	}
+	return e.Exceptions[0].Code
+}
+
+// maxReport caps how much of an exception report is read.
+const maxReport = 64 << 10
+
+// CheckResponse returns body unless it holds an exception report, in
+// which case it reads and closes body and returns the report as an
+// [*ExceptionReport]. A response is checked when its contentType is
+// `application/vnd.ogc.se_xml` or any XML type; XML that turns out to
+// be content (GML, SVG) is returned intact.
+//
+// The OWS clients call it on every response; it is exported for
+// callers fetching OWS URLs through their own transport.
+func CheckResponse(op, url, contentType string, body io.ReadCloser) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !strings.HasSuffix(mediaType, "xml") && mediaType != "application/vnd.ogc.se_xml" {
+		return body, nil
+	}
+	br := bufio.NewReader(body)
+	head, _ := br.Peek(1024)
+	if !isReport(head) {
+		return struct {
+			io.Reader
+			io.Closer
+		}{br, body}, nil
+	}
+	defer func() { _ = body.Close() }()
+	report, err := ParseExceptionReport(io.LimitReader(br, maxReport))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	report.Op, report.URL = op, url
+	return nil, report
+}
+
+// isReport reports whether the XML document starting with head has an
+// exception report as its root element.
+func isReport(head []byte) bool {
+	d := xml.NewDecoder(bytes.NewReader(head))
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return false
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local == "ServiceExceptionReport" || se.Name.Local == "ExceptionReport"
+		}
+	}
+}
+
+// reportXML decodes both report shapes: WMS puts the code and locator
+// on <ServiceException> with the text as its content, OWS puts them on
+// <Exception> with <ExceptionText> children.
+type reportXML struct {
+	Version string `xml:"version,attr"`
+	Service []struct {
+		Code    string `xml:"code,attr"`
+		Locator string `xml:"locator,attr"`
+		Text    string `xml:",chardata"`
+	} `xml:"ServiceException"`
+	OWS []struct {
+		Code    string   `xml:"exceptionCode,attr"`
+		Locator string   `xml:"locator,attr"`
+		Text    []string `xml:"ExceptionText"`
+	} `xml:"Exception"`
+}
+
+// ParseExceptionReport decodes a service exception report from r. Op
+// and URL are left empty.
+func ParseExceptionReport(r io.Reader) (*ExceptionReport, error) {
+	if r == nil {
+		return nil, errors.New("ows: ParseExceptionReport: nil reader")
+	}
+	var doc reportXML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ows: parse exception report: %w", err)
+	}
+	report := &ExceptionReport{Version: doc.Version}
+	for _, ex := range doc.Service {
+		report.Exceptions = append(report.Exceptions, Exception{Code: ex.Code, Locator: ex.Locator, Text: strings.TrimSpace(ex.Text)})
+	}
+	for _, ex := range doc.OWS {
+		report.Exceptions = append(report.Exceptions, Exception{Code: ex.Code, Locator: ex.Locator, Text: strings.TrimSpace(strings.Join(ex.Text, "\n"))})
+	}
+	return report, nil
+}
@@ -0,0 +1,40 @@
+package ows_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hishamkaram/geoserver/v2/ows"
+)
+
+func TestCheckResponse(t *testing.T) {
+	const owsReport = `<?xml version="1.0" encoding="UTF-8"?>
+<ows:ExceptionReport xmlns:ows="http://www.opengis.net/ows/1.1" version="2.0.0">
+  <ows:Exception exceptionCode="InvalidParameterValue" locator="typeName">
+    <ows:ExceptionText>Feature type topp:nope unknown</ows:ExceptionText>
+  </ows:Exception>
+</ows:ExceptionReport>`
+	_, err := ows.CheckResponse("WFS.GetFeature", "http://x/wfs", "text/xml", io.NopCloser(strings.NewReader(owsReport)))
+	var report *ows.ExceptionReport
+	if !errors.As(err, &report) {
+		t.Fatalf("err = %v", err)
+	}
+	want := "ows: WFS.GetFeature: service exception InvalidParameterValue (typeName): Feature type topp:nope unknown"
+	if report.Version != "2.0.0" || err.Error() != want {
+		t.Fatalf("report = %+v\nerror = %s", report, err)
+	}
+
+	// XML content passes through with the peeked bytes intact.
+	const gml = `<?xml version="1.0"?><wfs:FeatureCollection xmlns:wfs="http://www.opengis.net/wfs"/>`
+	for _, ct := range []string{"text/xml; subtype=gml/3.1.1", "image/png"} {
+		body, err := ows.CheckResponse("op", "u", ct, io.NopCloser(strings.NewReader(gml)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := io.ReadAll(body); string(data) != gml {
+			t.Fatalf("%s body = %q", ct, data)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package ows
+
+import "encoding/json"
+
+// FeatureCollection is a GeoJSON feature collection as GeoServer
+// writes it for WFS GetFeature and WMS GetFeatureInfo.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+
+	// NumberMatched and NumberReturned are GeoServer's WFS 2.0 paging
+	// counts. NumberMatched is nil when the server didn't count.
+	NumberMatched  *int `json:"numberMatched,omitempty"`
+	NumberReturned int  `json:"numberReturned,omitempty"`
+
+	TimeStamp string          `json:"timeStamp,omitempty"`
+	CRS       json.RawMessage `json:"crs,omitempty"`
+	BBox      []float64       `json:"bbox,omitempty"`
+}
+
+// Feature is a GeoJSON feature. Property values decode as
+// encoding/json does into any: numbers become float64.
+type Feature struct {
+	Type string `json:"type"`
+
+	// ID is the feature ID, "layer.fid" on GeoServer.
+	ID string `json:"id,omitempty"`
+
+	// Geometry is nil for a feature without one.
+	Geometry *Geometry `json:"geometry"`
+
+	// GeometryName is the name of the geometry attribute, a GeoServer
+	// extension.
+	GeometryName string `json:"geometry_name,omitempty"`
+
+	Properties map[string]any `json:"properties"`
+	BBox       []float64      `json:"bbox,omitempty"`
+}
+
+// Geometry is a GeoJSON geometry. Coordinates is left undecoded since
+// its nesting depends on Type; a GeometryCollection has Geometries
+// instead.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometries  []Geometry      `json:"geometries,omitempty"`
+}
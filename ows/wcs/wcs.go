@@ -48,6 +48,15 @@ func (c *Client) Workspace() string { return c.workspace }
 // `/wcs` endpoint (true) or a workspace-scoped one (false).
 func (c *Client) IsGlobal() bool { return c.workspace == "" }
 
+// Endpoint returns the URL this client sends requests to: the global
+// `/wcs`, or `/{workspace}/wcs` when scoped.
+func (c *Client) Endpoint() (string, error) {
+	if c.workspace != "" {
+		return c.core.URL(c.workspace, "wcs")
+	}
+	return c.core.URL("wcs")
+}
+
 // GetCapabilitiesOptions controls a [Client.GetCapabilities] call.
 // All fields are optional.
 type GetCapabilitiesOptions struct {
@@ -67,12 +76,7 @@ type GetCapabilitiesOptions struct {
 func (c *Client) GetCapabilities(ctx context.Context, opts GetCapabilitiesOptions) (*Capabilities, error) {
 	const op = "WCS.GetCapabilities"
 
-	parts := []string{}
-	if c.workspace != "" {
-		parts = append(parts, c.workspace)
-	}
-	parts = append(parts, "wcs")
-	u, err := c.core.URL(parts...)
+	u, err := c.Endpoint()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -141,12 +145,7 @@ func (c *Client) DescribeCoverage(ctx context.Context, opts DescribeCoverageOpti
 		return nil, errors.New(op + ": empty CoverageIDs (WCS DescribeCoverage requires at least one)")
 	}
 
-	parts := []string{}
-	if c.workspace != "" {
-		parts = append(parts, c.workspace)
-	}
-	parts = append(parts, "wcs")
-	u, err := c.core.URL(parts...)
+	u, err := c.Endpoint()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -354,3 +354,40 @@ func TestClient_IsGlobal(t *testing.T) {
 		t.Errorf("InWorkspace mutated original")
 	}
 }
+
+func TestOWSBaseURL(t *testing.T) {
+	rest := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("OWS request hit the REST base: %s", r.URL.Path)
+	}))
+	defer rest.Close()
+
+	var paths []string
+	ows := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Query().Get("request") == "DescribeCoverage" {
+			_, _ = io.WriteString(w, minimalCoverageDescriptionsXML)
+			return
+		}
+		_, _ = io.WriteString(w, minimalCapsXML)
+	}))
+	defer ows.Close()
+
+	c, err := geoserver.New(rest.URL+"/geoserver", geoserver.WithOWSBaseURL(ows.URL+"/cdn/geoserver"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.WCS.GetCapabilities(ctx, wcs.GetCapabilitiesOptions{}); err != nil {
+		t.Fatalf("GetCapabilities: %v", err)
+	}
+	if _, err := c.WCS.InWorkspace("nurc").GetCapabilities(ctx, wcs.GetCapabilitiesOptions{}); err != nil {
+		t.Fatalf("InWorkspace.GetCapabilities: %v", err)
+	}
+	if _, err := c.WCS.DescribeCoverage(ctx, wcs.DescribeCoverageOptions{CoverageIDs: []string{"nurc__Arc_Sample"}}); err != nil {
+		t.Fatalf("DescribeCoverage: %v", err)
+	}
+	want := []string{"/cdn/geoserver/wcs", "/cdn/geoserver/nurc/wcs", "/cdn/geoserver/wcs"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+}
@@ -51,6 +51,25 @@ func ExampleClient_DescribeFeatureType() {
 	}
 }
 
+// ExampleClient_IterFeatures pages through a layer 500 features at
+// a time, in a stable order.
+func ExampleClient_IterFeatures() {
+	c, _ := geoserver.New("http://localhost:8080/geoserver",
+		geoserver.WithBasicAuth("admin", "geoserver"))
+
+	for f, err := range c.WFS.IterFeatures(context.Background(), wfs.GetFeatureRequest{
+		TypeNames: []string{"topp:states"},
+		CQLFilter: "PERSONS > 1000000",
+		SortBy:    []wfs.SortBy{{Property: "STATE_FIPS"}},
+		Count:     500,
+	}) {
+		if err != nil {
+			return
+		}
+		fmt.Println(f.ID, f.Properties["STATE_NAME"])
+	}
+}
+
 // ExampleParseCapabilities decodes a capabilities document fetched
 // out-of-band — useful for parsing a saved fixture or a body from a
 // custom transport.
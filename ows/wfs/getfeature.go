@@ -0,0 +1,362 @@
+package wfs
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hishamkaram/geoserver/v2/ows"
+)
+
+// GeoJSON types shared with the other OWS clients. These are aliases
+// for the definitions in [ows], so values flow between packages
+// without conversion.
+type (
+	// FeatureCollection — see [ows.FeatureCollection].
+	FeatureCollection = ows.FeatureCollection
+	// Feature — see [ows.Feature].
+	Feature = ows.Feature
+	// Geometry — see [ows.Geometry].
+	Geometry = ows.Geometry
+)
+
+// defaultPageSize is the page size [Client.IterFeatures] asks for
+// when the request sets no Count.
+const defaultPageSize = 1000
+
+// errStopped aborts decoding when an iteration's consumer stops early.
+var errStopped = errors.New("wfs: iteration stopped")
+
+// BBox is a bounding-box filter. GeoServer reads the coordinates in
+// the axis order of CRS: "EPSG:4326" is longitude first, while the
+// "urn:ogc:def:crs:EPSG::4326" form is latitude first. Empty CRS is
+// the feature type's native CRS.
+type BBox struct {
+	MinX, MinY, MaxX, MaxY float64
+	CRS                    string
+}
+
+// SortBy orders features by one property.
+type SortBy struct {
+	Property   string
+	Descending bool
+}
+
+// GetFeatureRequest describes a [Client.GetFeature] call. TypeNames
+// is required; at most one of CQLFilter, Filter and BBox may be set.
+type GetFeatureRequest struct {
+	// Version is the WFS version requested. Default "2.0.0". Under
+	// 1.x, Count is sent as maxFeatures.
+	Version string
+
+	// TypeNames are the prefixed feature types to query, e.g.
+	// "topp:states".
+	TypeNames []string
+
+	// CQLFilter is a GeoServer CQL or ECQL filter, e.g.
+	// "PERSONS > 1000000 AND BBOX(the_geom, -100, 30, -90, 40)".
+	CQLFilter string
+
+	// Filter is an OGC Filter Encoding XML document.
+	Filter string
+
+	BBox *BBox
+
+	// PropertyNames limits the properties returned. Empty returns all
+	// of them.
+	PropertyNames []string
+
+	// SortBy orders the features. Paging with StartIndex needs a
+	// stable order, so set it for [Client.IterFeatures] over data
+	// without a natural one.
+	SortBy []SortBy
+
+	// SRSName reprojects the output, e.g. "EPSG:3857".
+	SRSName string
+
+	// Count caps the features returned; [Client.IterFeatures] uses it
+	// as the page size (default 1000). StartIndex skips that many
+	// features first.
+	Count, StartIndex int
+
+	// OutputFormat is the response format for [Client.GetFeatureRaw],
+	// e.g. "SHAPE-ZIP", "csv" or "application/gml+xml; version=3.2".
+	// Empty takes the server default, GML. GetFeature and IterFeatures
+	// always ask for "application/json".
+	OutputFormat string
+}
+
+// GetFeature fetches features as GeoJSON and decodes them. The
+// response is decoded as it streams, one feature at a time; use
+// [Client.IterFeatures] to avoid holding a large result in memory.
+// Service exceptions come back as [*ows.ExceptionReport] errors.
+//
+//	fc, err := c.WFS.GetFeature(ctx, wfs.GetFeatureRequest{
+//		TypeNames: []string{"topp:states"},
+//		CQLFilter: "PERSONS > 1000000",
+//	})
+func (c *Client) GetFeature(ctx context.Context, req GetFeatureRequest) (*FeatureCollection, error) {
+	const op = "WFS.GetFeature"
+	req.OutputFormat = "application/json"
+	body, _, err := c.getFeature(ctx, op, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+	var features []Feature
+	fc, err := decodeFeatures(body, func(f Feature) error {
+		features = append(features, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode: %w", op, err)
+	}
+	fc.Features = features
+	return fc, nil
+}
+
+// IterFeatures pages through every feature matching req, starting at
+// req.StartIndex, with one GetFeature request per req.Count features.
+// Each page is decoded as it streams. Paging ends once the server's
+// numberMatched is reached or, when it doesn't count, on a short page.
+// An error ends the sequence after it is yielded.
+//
+//	for f, err := range c.WFS.IterFeatures(ctx, wfs.GetFeatureRequest{
+//		TypeNames: []string{"topp:states"},
+//		SortBy:    []wfs.SortBy{{Property: "STATE_FIPS"}},
+//	}) {
+//		if err != nil {
+//			return err
+//		}
+//		load(f)
+//	}
+func (c *Client) IterFeatures(ctx context.Context, req GetFeatureRequest) iter.Seq2[Feature, error] {
+	const op = "WFS.IterFeatures"
+	return func(yield func(Feature, error) bool) {
+		req := req
+		req.OutputFormat = "application/json"
+		req.Count = cmp.Or(req.Count, defaultPageSize)
+		for {
+			body, _, err := c.getFeature(ctx, op, req)
+			if err != nil {
+				yield(Feature{}, err)
+				return
+			}
+			n, stopped := 0, false
+			fc, err := decodeFeatures(body, func(f Feature) error {
+				n++
+				if !yield(f, nil) {
+					stopped = true
+					return errStopped
+				}
+				return nil
+			})
+			_ = body.Close()
+			if stopped {
+				return
+			}
+			if err != nil {
+				yield(Feature{}, fmt.Errorf("%s: decode: %w", op, err))
+				return
+			}
+			req.StartIndex += n
+			switch {
+			case n == 0:
+				return
+			case fc.NumberMatched != nil:
+				if req.StartIndex >= *fc.NumberMatched {
+					return
+				}
+			case n < req.Count:
+				return
+			}
+		}
+	}
+}
+
+// GetFeatureRaw is [Client.GetFeature] for any OutputFormat. It
+// returns the response stream and its Content-Type; the caller must
+// close the stream.
+//
+//	body, _, err := c.WFS.GetFeatureRaw(ctx, wfs.GetFeatureRequest{
+//		TypeNames:    []string{"topp:states"},
+//		OutputFormat: "SHAPE-ZIP",
+//	})
+func (c *Client) GetFeatureRaw(ctx context.Context, req GetFeatureRequest) (io.ReadCloser, string, error) {
+	return c.getFeature(ctx, "WFS.GetFeatureRaw", req)
+}
+
+func (c *Client) getFeature(ctx context.Context, op string, req GetFeatureRequest) (io.ReadCloser, string, error) {
+	query, err := req.query(op)
+	if err != nil {
+		return nil, "", err
+	}
+	u, err := c.Endpoint()
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	body, header, err := c.core.DoStreamHeader(ctx, op, http.MethodGet, u, query)
+	if err != nil {
+		return nil, "", err
+	}
+	contentType := header.Get("Content-Type")
+	body, err = ows.CheckResponse(op, u, contentType, body)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, contentType, nil
+}
+
+// query builds the GetFeature KVP parameters, naming them for the
+// requested version.
+func (req *GetFeatureRequest) query(op string) (map[string]string, error) {
+	filters := 0
+	for _, set := range []bool{req.CQLFilter != "", req.Filter != "", req.BBox != nil} {
+		if set {
+			filters++
+		}
+	}
+	switch {
+	case len(req.TypeNames) == 0:
+		return nil, fmt.Errorf("%s: no type names", op)
+	case req.Count < 0 || req.StartIndex < 0:
+		return nil, fmt.Errorf("%s: negative Count or StartIndex", op)
+	case filters > 1:
+		return nil, fmt.Errorf("%s: CQLFilter, Filter and BBox are mutually exclusive", op)
+	}
+
+	version := cmp.Or(req.Version, "2.0.0")
+	v2 := strings.HasPrefix(version, "2.")
+	query := map[string]string{
+		"service": "wfs",
+		"version": version,
+		"request": "GetFeature",
+	}
+	typeNames, count, asc, desc := "typeNames", "count", "ASC", "DESC"
+	if !v2 {
+		typeNames, count, asc, desc = "typeName", "maxFeatures", "A", "D"
+	}
+	query[typeNames] = strings.Join(req.TypeNames, ",")
+	if req.Count > 0 {
+		query[count] = strconv.Itoa(req.Count)
+	}
+	if req.StartIndex > 0 {
+		query["startIndex"] = strconv.Itoa(req.StartIndex)
+	}
+	if len(req.SortBy) > 0 {
+		parts := make([]string, len(req.SortBy))
+		for i, s := range req.SortBy {
+			parts[i] = s.Property + " " + asc
+			if s.Descending {
+				parts[i] = s.Property + " " + desc
+			}
+		}
+		query["sortBy"] = strings.Join(parts, ",")
+	}
+	if len(req.PropertyNames) > 0 {
+		query["propertyName"] = strings.Join(req.PropertyNames, ",")
+	}
+	if req.CQLFilter != "" {
+		query["cql_filter"] = req.CQLFilter
+	}
+	if req.Filter != "" {
+		query["filter"] = req.Filter
+	}
+	if b := req.BBox; b != nil {
+		parts := make([]string, 0, 5)
+		for _, v := range []float64{b.MinX, b.MinY, b.MaxX, b.MaxY} {
+			parts = append(parts, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		if b.CRS != "" {
+			parts = append(parts, b.CRS)
+		}
+		query["bbox"] = strings.Join(parts, ",")
+	}
+	if req.SRSName != "" {
+		query["srsName"] = req.SRSName
+	}
+	if req.OutputFormat != "" {
+		query["outputFormat"] = req.OutputFormat
+	}
+	return query, nil
+}
+
+// decodeFeatures reads a GeoJSON feature collection from r, handing
+// each feature to fn as soon as it is decoded rather than buffering
+// the array. It returns the collection's other members; Features is
+// left empty. An error from fn stops decoding and is returned.
+func decodeFeatures(r io.Reader, fn func(Feature) error) (*FeatureCollection, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	var fc FeatureCollection
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok {
+		case "features":
+			if err := expectDelim(dec, '['); err != nil {
+				return nil, err
+			}
+			for dec.More() {
+				var f Feature
+				if err := dec.Decode(&f); err != nil {
+					return nil, err
+				}
+				if err := fn(f); err != nil {
+					return nil, err
+				}
+			}
+			err = expectDelim(dec, ']')
+		case "numberMatched":
+			// GeoServer writes "unknown" when it skipped the count.
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err == nil {
+				if n, convErr := strconv.Atoi(string(raw)); convErr == nil {
+					fc.NumberMatched = &n
+				}
+			}
+		case "type":
+			err = dec.Decode(&fc.Type)
+		case "numberReturned":
+			err = dec.Decode(&fc.NumberReturned)
+		case "timeStamp":
+			err = dec.Decode(&fc.TimeStamp)
+		case "crs":
+			err = dec.Decode(&fc.CRS)
+		case "bbox":
+			err = dec.Decode(&fc.BBox)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	return &fc, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("expected %v, have %v", want, tok)
+	}
+	return nil
+}
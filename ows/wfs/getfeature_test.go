@@ -0,0 +1,226 @@
+package wfs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/ows"
+	"github.com/hishamkaram/geoserver/v2/ows/wfs"
+)
+
+// featureServer serves GetFeature requests through handle, which
+// returns the response Content-Type and body for the request's query.
+func featureServer(t *testing.T, handle func(q url.Values) (string, string)) *geoserver.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType, body := handle(r.URL.Query())
+		w.Header().Set("Content-Type", contentType)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := geoserver.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+// statesPage renders features [start, start+count) of total as
+// GeoServer's GeoJSON output, with the counts after the features as
+// GeoServer writes them. matched=false leaves numberMatched out.
+func statesPage(start, count, total int, matched bool) string {
+	var features []string
+	for i := start; i < min(start+count, total); i++ {
+		features = append(features, fmt.Sprintf(
+			`{"type":"Feature","id":"states.%d","geometry":{"type":"Point","coordinates":[%d,0]},"geometry_name":"the_geom","properties":{"n":%d}}`, i, i, i))
+	}
+	counts := fmt.Sprintf(`"totalFeatures":%d,"numberMatched":%d,`, total, total)
+	if !matched {
+		counts = `"totalFeatures":"unknown",`
+	}
+	return fmt.Sprintf(`{"type":"FeatureCollection","features":[%s],%s"numberReturned":%d,"timeStamp":"2026-10-18T10:00:00Z","crs":{"type":"name","properties":{"name":"urn:ogc:def:crs:EPSG::4326"}}}`,
+		strings.Join(features, ","), counts, len(features))
+}
+
+func TestGetFeature(t *testing.T) {
+	c := featureServer(t, func(q url.Values) (string, string) {
+		want := map[string]string{
+			"service": "wfs", "version": "2.0.0", "request": "GetFeature",
+			"typeNames": "topp:states", "cql_filter": "PERSONS > 1000000",
+			"propertyName": "STATE_NAME,the_geom", "sortBy": "STATE_NAME ASC,PERSONS DESC",
+			"srsName": "EPSG:3857", "count": "2", "startIndex": "1", "outputFormat": "application/json",
+		}
+		for k, v := range want {
+			if got := q.Get(k); got != v {
+				t.Errorf("%s = %q, want %q", k, got, v)
+			}
+		}
+		return "application/json;charset=UTF-8", statesPage(1, 2, 5, true)
+	})
+	fc, err := c.WFS.GetFeature(context.Background(), wfs.GetFeatureRequest{
+		TypeNames:     []string{"topp:states"},
+		CQLFilter:     "PERSONS > 1000000",
+		PropertyNames: []string{"STATE_NAME", "the_geom"},
+		SortBy:        []wfs.SortBy{{Property: "STATE_NAME"}, {Property: "PERSONS", Descending: true}},
+		SRSName:       "EPSG:3857",
+		Count:         2,
+		StartIndex:    1,
+		OutputFormat:  "csv",
+	})
+	if err != nil {
+		t.Fatalf("GetFeature: %v", err)
+	}
+	if len(fc.Features) != 2 || fc.Features[0].ID != "states.1" || fc.Features[1].Properties["n"] != float64(2) {
+		t.Fatalf("features = %+v", fc.Features)
+	}
+	if f := fc.Features[0]; f.Geometry == nil || f.Geometry.Type != "Point" || f.GeometryName != "the_geom" {
+		t.Errorf("feature = %+v", f)
+	}
+	if fc.Type != "FeatureCollection" || fc.NumberMatched == nil || *fc.NumberMatched != 5 || fc.NumberReturned != 2 || len(fc.CRS) == 0 {
+		t.Errorf("collection = %+v", fc)
+	}
+}
+
+func TestGetFeature_Validation(t *testing.T) {
+	c := featureServer(t, func(url.Values) (string, string) {
+		t.Error("request sent")
+		return "", ""
+	})
+	for _, req := range []wfs.GetFeatureRequest{
+		{},
+		{TypeNames: []string{"topp:states"}, Count: -1},
+		{TypeNames: []string{"topp:states"}, CQLFilter: "a = 1", BBox: &wfs.BBox{MaxX: 1, MaxY: 1}},
+	} {
+		if _, err := c.WFS.GetFeature(context.Background(), req); err == nil {
+			t.Errorf("%+v: no error", req)
+		}
+	}
+}
+
+func TestGetFeature_Exception(t *testing.T) {
+	c := featureServer(t, func(url.Values) (string, string) {
+		return "application/xml", `<?xml version="1.0" encoding="UTF-8"?>
+<ows:ExceptionReport xmlns:ows="http://www.opengis.net/ows/1.1" version="2.0.0">
+  <ows:Exception exceptionCode="InvalidParameterValue" locator="typeName">
+    <ows:ExceptionText>Feature type topp:nope unknown</ows:ExceptionText>
+  </ows:Exception>
+</ows:ExceptionReport>`
+	})
+	_, err := c.WFS.GetFeature(context.Background(), wfs.GetFeatureRequest{TypeNames: []string{"topp:nope"}})
+	var report *ows.ExceptionReport
+	if !errors.As(err, &report) || report.Code() != "InvalidParameterValue" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestIterFeatures(t *testing.T) {
+	for _, matched := range []bool{true, false} {
+		t.Run(fmt.Sprintf("numberMatched=%t", matched), func(t *testing.T) {
+			var starts []string
+			c := featureServer(t, func(q url.Values) (string, string) {
+				starts = append(starts, q.Get("startIndex"))
+				start, _ := strconv.Atoi(q.Get("startIndex"))
+				count, _ := strconv.Atoi(q.Get("count"))
+				return "application/json", statesPage(start, count, 5, matched)
+			})
+			var ids []string
+			for f, err := range c.WFS.IterFeatures(context.Background(), wfs.GetFeatureRequest{
+				TypeNames: []string{"topp:states"},
+				Count:     2,
+			}) {
+				if err != nil {
+					t.Fatalf("IterFeatures: %v", err)
+				}
+				ids = append(ids, f.ID)
+			}
+			if got := strings.Join(ids, " "); got != "states.0 states.1 states.2 states.3 states.4" {
+				t.Errorf("ids = %s", got)
+			}
+			if got := strings.Join(starts, ","); got != ",2,4" {
+				t.Errorf("startIndex sequence = %q", got)
+			}
+		})
+	}
+}
+
+func TestIterFeatures_Break(t *testing.T) {
+	requests := 0
+	c := featureServer(t, func(q url.Values) (string, string) {
+		requests++
+		start, _ := strconv.Atoi(q.Get("startIndex"))
+		return "application/json", statesPage(start, 2, 100, true)
+	})
+	n := 0
+	for _, err := range c.WFS.IterFeatures(context.Background(), wfs.GetFeatureRequest{TypeNames: []string{"topp:states"}, Count: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 3 {
+			break
+		}
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+func TestIterFeatures_Error(t *testing.T) {
+	c := featureServer(t, func(url.Values) (string, string) {
+		return "application/json", `{"type":"FeatureCollection","features":[{"type":"Feature","id":"states.0"},`
+	})
+	var errs int
+	for _, err := range c.WFS.IterFeatures(context.Background(), wfs.GetFeatureRequest{TypeNames: []string{"topp:states"}}) {
+		if err != nil {
+			errs++
+			if !strings.Contains(err.Error(), "WFS.IterFeatures: decode") {
+				t.Errorf("err = %v", err)
+			}
+		}
+	}
+	if errs != 1 {
+		t.Errorf("errors = %d, want 1", errs)
+	}
+}
+
+func TestGetFeatureRaw(t *testing.T) {
+	c := featureServer(t, func(q url.Values) (string, string) {
+		want := map[string]string{
+			"version": "1.1.0", "typeName": "topp:states", "maxFeatures": "10",
+			"sortBy": "STATE_NAME D", "bbox": "-100,30.5,-90,40,EPSG:4326", "outputFormat": "csv",
+		}
+		for k, v := range want {
+			if got := q.Get(k); got != v {
+				t.Errorf("%s = %q, want %q", k, got, v)
+			}
+		}
+		if q.Has("typeNames") || q.Has("count") {
+			t.Errorf("2.0 parameters sent under 1.1.0: %v", q)
+		}
+		return "text/csv", "FID,STATE_NAME\nstates.1,Illinois\n"
+	})
+	body, contentType, err := c.WFS.GetFeatureRaw(context.Background(), wfs.GetFeatureRequest{
+		Version:      "1.1.0",
+		TypeNames:    []string{"topp:states"},
+		BBox:         &wfs.BBox{MinX: -100, MinY: 30.5, MaxX: -90, MaxY: 40, CRS: "EPSG:4326"},
+		SortBy:       []wfs.SortBy{{Property: "STATE_NAME", Descending: true}},
+		Count:        10,
+		OutputFormat: "csv",
+	})
+	if err != nil {
+		t.Fatalf("GetFeatureRaw: %v", err)
+	}
+	defer func() { _ = body.Close() }()
+	data, _ := io.ReadAll(body)
+	if contentType != "text/csv" || !strings.HasPrefix(string(data), "FID,STATE_NAME") {
+		t.Errorf("got %q %q", contentType, data)
+	}
+}
@@ -2,6 +2,11 @@
 // Covers the GetCapabilities endpoint — fetching the XML capabilities
 // document and parsing it into Go types.
 //
+// [Client.GetFeature] fetches features as GeoJSON, [Client.IterFeatures]
+// pages through large layers with startIndex/count, and
+// [Client.GetFeatureRaw] streams any other output format (shape-zip,
+// CSV, GML).
+//
 // The GeoServer WFS GetCapabilities response uses both `wfs:` and
 // `ows:` XML namespaces; the type definitions in this package match
 // on local name only, so values flow through Go's encoding/xml
@@ -14,10 +14,13 @@ import (
 type Core interface {
 	URL(parts ...string) (string, error)
 	DoXML(ctx context.Context, op, method, requestURL string, query map[string]string, out any) error
+	DoStreamHeader(ctx context.Context, op, method, requestURL string, query map[string]string) (io.ReadCloser, http.Header, error)
 }
 
 // Client is the v2 WFS sub-client. The current surface covers
-// [Client.GetCapabilities]; [Client.InWorkspace] returns a
+// [Client.GetCapabilities], [Client.DescribeFeatureType] and
+// [Client.GetFeature] with its paging [Client.IterFeatures] and
+// [Client.GetFeatureRaw]; [Client.InWorkspace] returns a
 // workspace-scoped view that issues `/{workspace}/wfs` rather than
 // the global `/wfs`.
 //
@@ -48,6 +51,15 @@ func (c *Client) Workspace() string { return c.workspace }
 // `/wfs` endpoint (true) or a workspace-scoped one (false).
 func (c *Client) IsGlobal() bool { return c.workspace == "" }
 
+// Endpoint returns the URL this client sends requests to: the global
+// `/wfs`, or `/{workspace}/wfs` when scoped.
+func (c *Client) Endpoint() (string, error) {
+	if c.workspace != "" {
+		return c.core.URL(c.workspace, "wfs")
+	}
+	return c.core.URL("wfs")
+}
+
 // GetCapabilitiesOptions controls a [Client.GetCapabilities] call.
 // All fields are optional.
 type GetCapabilitiesOptions struct {
@@ -67,12 +79,7 @@ type GetCapabilitiesOptions struct {
 func (c *Client) GetCapabilities(ctx context.Context, opts GetCapabilitiesOptions) (*Capabilities, error) {
 	const op = "WFS.GetCapabilities"
 
-	parts := []string{}
-	if c.workspace != "" {
-		parts = append(parts, c.workspace)
-	}
-	parts = append(parts, "wfs")
-	u, err := c.core.URL(parts...)
+	u, err := c.Endpoint()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -136,12 +143,7 @@ type DescribeFeatureTypeOptions struct {
 func (c *Client) DescribeFeatureType(ctx context.Context, opts DescribeFeatureTypeOptions) (*FeatureSchema, error) {
 	const op = "WFS.DescribeFeatureType"
 
-	parts := []string{}
-	if c.workspace != "" {
-		parts = append(parts, c.workspace)
-	}
-	parts = append(parts, "wfs")
-	u, err := c.core.URL(parts...)
+	u, err := c.Endpoint()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -391,3 +391,40 @@ func TestClient_IsGlobal(t *testing.T) {
 		t.Errorf("InWorkspace mutated original")
 	}
 }
+
+func TestOWSBaseURL(t *testing.T) {
+	rest := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("OWS request hit the REST base: %s", r.URL.Path)
+	}))
+	defer rest.Close()
+
+	var paths []string
+	ows := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Query().Get("request") == "DescribeFeatureType" {
+			_, _ = io.WriteString(w, minimalSchemaXML)
+			return
+		}
+		_, _ = io.WriteString(w, minimalCapsXML)
+	}))
+	defer ows.Close()
+
+	c, err := geoserver.New(rest.URL+"/geoserver", geoserver.WithOWSBaseURL(ows.URL+"/cdn/geoserver/"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.WFS.GetCapabilities(ctx, wfs.GetCapabilitiesOptions{}); err != nil {
+		t.Fatalf("GetCapabilities: %v", err)
+	}
+	if _, err := c.WFS.InWorkspace("topp").GetCapabilities(ctx, wfs.GetCapabilitiesOptions{}); err != nil {
+		t.Fatalf("InWorkspace.GetCapabilities: %v", err)
+	}
+	if _, err := c.WFS.DescribeFeatureType(ctx, wfs.DescribeFeatureTypeOptions{TypeNames: []string{"topp:states"}}); err != nil {
+		t.Fatalf("DescribeFeatureType: %v", err)
+	}
+	want := []string{"/cdn/geoserver/wfs", "/cdn/geoserver/topp/wfs", "/cdn/geoserver/wfs"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+}
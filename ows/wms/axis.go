@@ -0,0 +1,131 @@
+package wms
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AxisOrder overrides the axis order a WMS 1.3.0 request assumes for
+// its CRS. WMS 1.1.1 is always easting first.
+type AxisOrder int
+
+const (
+	// AxisAuto looks the CRS up in a built-in table of northing-first
+	// EPSG codes: the common geographic CRSs, EPSG:4326 among them,
+	// and projected CRSs such as EPSG:3035, EPSG:2180 and the German
+	// Gauss-Krüger zones. Any other CRS, CRS:84 included, is taken to
+	// be easting first.
+	AxisAuto AxisOrder = iota
+
+	// AxisEastingFirst sends the bounding box as given.
+	AxisEastingFirst
+
+	// AxisNorthingFirst swaps the bounding box on the wire.
+	AxisNorthingFirst
+)
+
+// northingFirst reports whether o puts crs's northing first.
+func (o AxisOrder) northingFirst(crs string) bool {
+	switch o {
+	case AxisEastingFirst:
+		return false
+	case AxisNorthingFirst:
+		return true
+	}
+	return northingFirst(crs)
+}
+
+// northingFirst reports whether WMS 1.3.0 reads crs northing first,
+// in any of the usual spellings of an EPSG code. The table is not the
+// EPSG registry: a CRS missing from it needs an explicit [AxisOrder].
+func northingFirst(crs string) bool {
+	var code string
+	switch upper := strings.ToUpper(crs); {
+	case strings.HasPrefix(upper, "EPSG:"):
+		code = upper[len("EPSG:"):]
+	case strings.HasPrefix(upper, "URN:OGC:DEF:CRS:EPSG:"):
+		code = upper[strings.LastIndex(upper, ":")+1:]
+	case strings.HasPrefix(upper, "HTTP://WWW.OPENGIS.NET/DEF/CRS/EPSG/"):
+		code = upper[strings.LastIndex(upper, "/")+1:]
+	}
+	n, err := strconv.Atoi(code)
+	return err == nil && northingFirstCodes[n]
+}
+
+// northingFirstCodes are the EPSG codes whose first axis is latitude
+// or northing. Projected CRSs in the geographic range, such as
+// EPSG:4087 and EPSG:4088, are easting first and left out.
+var northingFirstCodes = map[int]bool{
+	// Geographic 2D.
+	4019: true, // GRS 1980
+	4124: true, // RT90
+	4148: true, // Hartebeesthoek94
+	4149: true, // CH1903
+	4150: true, // CH1903+
+	4151: true, // CHTRF95
+	4152: true, // NAD83(HARN)
+	4167: true, // NZGD2000
+	4170: true, // SIRGAS
+	4171: true, // RGF93
+	4178: true, // Pulkovo 1942(83)
+	4179: true, // Pulkovo 1942(58)
+	4202: true, // AGD66
+	4203: true, // AGD84
+	4204: true, // Ain el Abd
+	4214: true, // Beijing 1954
+	4230: true, // ED50
+	4231: true, // ED87
+	4236: true, // Hu Tzu Shan 1950
+	4240: true, // Indian 1975
+	4258: true, // ETRS89
+	4267: true, // NAD27
+	4269: true, // NAD83
+	4272: true, // NZGD49
+	4275: true, // NTF
+	4277: true, // OSGB36
+	4283: true, // GDA94
+	4284: true, // Pulkovo 1942
+	4299: true, // TM65
+	4300: true, // TM75
+	4301: true, // Tokyo
+	4312: true, // MGI
+	4314: true, // DHDN
+	4322: true, // WGS 72
+	4324: true, // WGS 72BE
+	4326: true, // WGS 84
+	4490: true, // CGCS2000
+	4610: true, // Xian 1980
+	4612: true, // JGD2000
+	4617: true, // NAD83(CSRS)
+	4619: true, // SWEREF99
+	4674: true, // SIRGAS 2000
+	4755: true, // DGN95
+	4759: true, // NAD83(NSRS2007)
+	4807: true, // NTF (Paris)
+	6318: true, // NAD83(2011)
+	6668: true, // JGD2011
+	7844: true, // GDA2020
+
+	// Geographic 3D.
+	4937: true, // ETRS89
+	4979: true, // WGS 84
+
+	// Projected, northing first.
+	2176:  true, // ETRS89 / Poland CS2000 zone 5
+	2177:  true, // ETRS89 / Poland CS2000 zone 6
+	2178:  true, // ETRS89 / Poland CS2000 zone 7
+	2179:  true, // ETRS89 / Poland CS2000 zone 8
+	2180:  true, // ETRS89 / Poland CS92
+	2393:  true, // KKJ / Finland Uniform Coordinate System
+	3006:  true, // SWEREF99 TM
+	3034:  true, // ETRS89-extended / LCC Europe
+	3035:  true, // ETRS89-extended / LAEA Europe
+	3059:  true, // LKS92 / Latvia TM
+	3301:  true, // Estonian Coordinate System of 1997
+	3346:  true, // LKS94 / Lithuania TM
+	3844:  true, // Pulkovo 1942(58) / Stereo70
+	31466: true, // DHDN / 3-degree Gauss-Kruger zone 2
+	31467: true, // DHDN / 3-degree Gauss-Kruger zone 3
+	31468: true, // DHDN / 3-degree Gauss-Kruger zone 4
+	31469: true, // DHDN / 3-degree Gauss-Kruger zone 5
+}
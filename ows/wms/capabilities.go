@@ -0,0 +1,120 @@
+package wms
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// pixelDiagonal is the ground size, per unit of scale denominator, of
+// the diagonal of a standard 0.28 mm rendering pixel. WMS 1.1.1
+// ScaleHints are scale denominators multiplied by it.
+const pixelDiagonal = 0.00028 * math.Sqrt2
+
+// UnmarshalXML decodes a WMS 1.1.1 or 1.3.0 capabilities document and
+// maps the version-specific elements onto the shared [Layer] tree.
+func (c *Capabilities) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if n := start.Name.Local; n != "WMT_MS_Capabilities" && n != "WMS_Capabilities" {
+		return fmt.Errorf("expected element type <WMT_MS_Capabilities> or <WMS_Capabilities> but have <%s>", n)
+	}
+	// Base drops the method; it is exported because encoding/xml
+	// cannot fill an unexported embedded struct.
+	type Base Capabilities
+	var aux struct {
+		XMLName xml.Name
+		Base
+	}
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+	*c = Capabilities(aux.Base)
+	c.XMLName = aux.XMLName
+	c.Capability.Layer.normalize(strings.HasPrefix(c.Version, "1.3"))
+	return nil
+}
+
+// UnmarshalXML decodes a Layer, collecting every Dimension (and, for
+// WMS 1.1.1, its matching Extent) into Dimensions.
+func (l *Layer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type Base Layer
+	var aux struct {
+		Base
+		Dimension []*Dimension `xml:"Dimension"`
+		Extent    []*Extent    `xml:"Extent"`
+	}
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+	*l = Layer(aux.Base)
+	for _, dim := range aux.Dimension {
+		dim.Values = strings.TrimSpace(dim.Values)
+		for _, ext := range aux.Extent {
+			if ext.Name != dim.Name {
+				continue
+			}
+			if dim.Default == "" {
+				dim.Default = ext.Default
+			}
+			if dim.Values == "" {
+				dim.Values = strings.TrimSpace(ext.Values)
+			}
+			dim.NearestValue = dim.NearestValue || ext.NearestValue
+		}
+	}
+	l.Dimensions = aux.Dimension
+	if len(aux.Dimension) > 0 {
+		l.Dimension = *aux.Dimension[0]
+	}
+	switch {
+	case len(aux.Extent) > 0:
+		l.Extent = *aux.Extent[0]
+		l.Extent.Values = strings.TrimSpace(l.Extent.Values)
+	case len(aux.Dimension) > 0:
+		first := aux.Dimension[0]
+		l.Extent = Extent{Name: first.Name, Default: first.Default, NearestValue: first.NearestValue, Values: first.Values}
+	}
+	return nil
+}
+
+// normalize fills each version's fields from the other's throughout
+// the tree under l. v130 reports a WMS 1.3.0 document, whose bounding
+// boxes follow the CRS axis order; those in a CRS the northing-first
+// table lists are swapped to easting first.
+func (l *Layer) normalize(v130 bool) {
+	if len(l.SRS) == 0 {
+		l.SRS = l.CRS
+	} else if len(l.CRS) == 0 {
+		l.CRS = l.SRS
+	}
+
+	if ex := l.EXGeographicBoundingBox; ex != nil && l.LatLonBoundingBox == (LatLonBoundingBox{}) {
+		l.LatLonBoundingBox = LatLonBoundingBox{MinX: ex.West, MinY: ex.South, MaxX: ex.East, MaxY: ex.North}
+	} else if ll := l.LatLonBoundingBox; ex == nil && ll != (LatLonBoundingBox{}) {
+		l.EXGeographicBoundingBox = &EXGeographicBoundingBox{West: ll.MinX, East: ll.MaxX, South: ll.MinY, North: ll.MaxY}
+	}
+
+	for _, bb := range l.BoundingBox {
+		if bb.SRS == "" {
+			bb.SRS = bb.CRS
+		} else if bb.CRS == "" {
+			bb.CRS = bb.SRS
+		}
+		if v130 && northingFirst(bb.CRS) {
+			bb.MinX, bb.MinY, bb.MaxX, bb.MaxY = bb.MinY, bb.MinX, bb.MaxY, bb.MaxX
+		}
+	}
+
+	if h := l.ScaleHint; h != nil {
+		if l.MinScaleDenominator == 0 && h.Min > 0 && !math.IsInf(h.Min, 0) {
+			l.MinScaleDenominator = h.Min / pixelDiagonal
+		}
+		if l.MaxScaleDenominator == 0 && h.Max > 0 && !math.IsInf(h.Max, 0) {
+			l.MaxScaleDenominator = h.Max / pixelDiagonal
+		}
+	}
+
+	for _, child := range l.Layer {
+		child.normalize(v130)
+	}
+}
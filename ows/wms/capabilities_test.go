@@ -0,0 +1,197 @@
+package wms_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hishamkaram/geoserver/v2/ows/wms"
+)
+
+const caps130XML = `<?xml version="1.0" encoding="UTF-8"?>
+<WMS_Capabilities version="1.3.0" updateSequence="7"
+    xmlns="http://www.opengis.net/wms" xmlns:xlink="http://www.w3.org/1999/xlink"
+    xmlns:inspire_common="http://inspire.ec.europa.eu/schemas/common/1.0"
+    xmlns:inspire_vs="http://inspire.ec.europa.eu/schemas/inspire_vs/1.0">
+  <Service>
+    <Name>WMS</Name>
+    <Title>Test GeoServer Web Map Service</Title>
+  </Service>
+  <Capability>
+    <Request>
+      <GetMap>
+        <Format>image/png</Format>
+        <DCPType><HTTP><Get><OnlineResource xlink:type="simple" xlink:href="http://example.com/geoserver/wms?"/></Get></HTTP></DCPType>
+      </GetMap>
+    </Request>
+    <Exception><Format>XML</Format></Exception>
+    <inspire_vs:ExtendedCapabilities>
+      <inspire_common:MetadataUrl>
+        <inspire_common:URL>http://example.com/csw?id=1</inspire_common:URL>
+        <inspire_common:MediaType>application/vnd.ogc.csw.GetRecordByIdResponse_xml</inspire_common:MediaType>
+      </inspire_common:MetadataUrl>
+      <inspire_common:SupportedLanguages>
+        <inspire_common:DefaultLanguage><inspire_common:Language>eng</inspire_common:Language></inspire_common:DefaultLanguage>
+        <inspire_common:SupportedLanguage><inspire_common:Language>ger</inspire_common:Language></inspire_common:SupportedLanguage>
+      </inspire_common:SupportedLanguages>
+      <inspire_common:ResponseLanguage><inspire_common:Language>eng</inspire_common:Language></inspire_common:ResponseLanguage>
+    </inspire_vs:ExtendedCapabilities>
+    <Layer>
+      <Title>Root layer</Title>
+      <CRS>EPSG:4326</CRS>
+      <CRS>EPSG:3857</CRS>
+      <Layer queryable="1">
+        <Name>topp:states</Name>
+        <Title>states</Title>
+        <CRS>EPSG:4326</CRS>
+        <EX_GeographicBoundingBox>
+          <westBoundLongitude>-130</westBoundLongitude>
+          <eastBoundLongitude>-65</eastBoundLongitude>
+          <southBoundLatitude>20</southBoundLatitude>
+          <northBoundLatitude>50</northBoundLatitude>
+        </EX_GeographicBoundingBox>
+        <BoundingBox CRS="EPSG:4326" minx="20" miny="-130" maxx="50" maxy="-65"/>
+        <BoundingBox CRS="EPSG:3857" minx="-14471533" miny="2273030" maxx="-7235766" maxy="6446275"/>
+        <BoundingBox CRS="EPSG:3035" minx="1000" miny="2000" maxx="3000" maxy="4000"/>
+        <BoundingBox CRS="EPSG:4087" minx="-14471533" miny="2273030" maxx="-7235766" maxy="6446275"/>
+        <Dimension name="time" units="ISO8601" default="2026-01-02" nearestValue="1">
+          2026-01-01,2026-01-02
+        </Dimension>
+        <Dimension name="elevation" units="EPSG:5030" unitSymbol="m" default="0">0,100</Dimension>
+        <MinScaleDenominator>5000</MinScaleDenominator>
+        <MaxScaleDenominator>1000000</MaxScaleDenominator>
+      </Layer>
+    </Layer>
+  </Capability>
+</WMS_Capabilities>`
+
+const caps111XML = `<?xml version="1.0" encoding="UTF-8"?>
+<WMT_MS_Capabilities version="1.1.1" updateSequence="7">
+  <Service>
+    <Name>OGC:WMS</Name>
+    <Title>Test GeoServer Web Map Service</Title>
+  </Service>
+  <Capability>
+    <Layer>
+      <Title>Root layer</Title>
+      <SRS>EPSG:4326</SRS>
+      <SRS>EPSG:3857</SRS>
+      <Layer queryable="1">
+        <Name>topp:states</Name>
+        <Title>states</Title>
+        <SRS>EPSG:4326</SRS>
+        <LatLonBoundingBox minx="-130" miny="20" maxx="-65" maxy="50"/>
+        <BoundingBox SRS="EPSG:4326" minx="-130" miny="20" maxx="-65" maxy="50"/>
+        <BoundingBox SRS="EPSG:3857" minx="-14471533" miny="2273030" maxx="-7235766" maxy="6446275"/>
+        <BoundingBox SRS="EPSG:3035" minx="2000" miny="1000" maxx="4000" maxy="3000"/>
+        <BoundingBox SRS="EPSG:4087" minx="-14471533" miny="2273030" maxx="-7235766" maxy="6446275"/>
+        <Dimension name="time" units="ISO8601"/>
+        <Dimension name="elevation" units="EPSG:5030" unitSymbol="m"/>
+        <Extent name="time" default="2026-01-02" nearestValue="1">2026-01-01,2026-01-02</Extent>
+        <Extent name="elevation" default="0">0,100</Extent>
+        <ScaleHint min="1.979898987322333" max="395.9797974644666"/>
+      </Layer>
+    </Layer>
+  </Capability>
+</WMT_MS_Capabilities>`
+
+// describe renders the version-neutral parts of a layer tree.
+func describe(l *wms.Layer) string {
+	var b strings.Builder
+	var walk func(l *wms.Layer, depth int)
+	walk = func(l *wms.Layer, depth int) {
+		fmt.Fprintf(&b, "%*s%s %q q=%d", depth*2, "", l.Name, l.Title, l.Queryable)
+		for _, s := range l.SRS {
+			fmt.Fprintf(&b, " srs=%s", *s)
+		}
+		for _, s := range l.CRS {
+			fmt.Fprintf(&b, " crs=%s", *s)
+		}
+		ll := l.LatLonBoundingBox
+		fmt.Fprintf(&b, " ll=%g,%g,%g,%g", ll.MinX, ll.MinY, ll.MaxX, ll.MaxY)
+		if ex := l.EXGeographicBoundingBox; ex != nil {
+			fmt.Fprintf(&b, " ex=%g,%g,%g,%g", ex.West, ex.South, ex.East, ex.North)
+		}
+		for _, bb := range l.BoundingBox {
+			fmt.Fprintf(&b, " bbox[%s/%s]=%g,%g,%g,%g", bb.SRS, bb.CRS, bb.MinX, bb.MinY, bb.MaxX, bb.MaxY)
+		}
+		for _, d := range l.Dimensions {
+			fmt.Fprintf(&b, " dim[%s %s %s default=%s nearest=%t]=%s", d.Name, d.Units, d.UnitSymbol, d.Default, d.NearestValue, d.Values)
+		}
+		fmt.Fprintf(&b, " extent=%s:%s scale=%.0f-%.0f\n", l.Extent.Name, l.Extent.Default, l.MinScaleDenominator, l.MaxScaleDenominator)
+		for _, child := range l.Layer {
+			walk(child, depth+1)
+		}
+	}
+	walk(l, 0)
+	return b.String()
+}
+
+func TestParseCapabilities_130(t *testing.T) {
+	caps, err := wms.ParseCapabilities(strings.NewReader(caps130XML))
+	if err != nil {
+		t.Fatalf("ParseCapabilities: %v", err)
+	}
+	if caps.Version != "1.3.0" || caps.UpdateSequence != "7" || caps.Service.Title != "Test GeoServer Web Map Service" {
+		t.Errorf("caps = %s %s %+v", caps.Version, caps.UpdateSequence, caps.Service)
+	}
+	if got := caps.Capability.Request.GetMap.DCPType.HTTP.Get.OnlineResource.Href; got != "http://example.com/geoserver/wms?" {
+		t.Errorf("GetMap href = %q", got)
+	}
+	ext := caps.Capability.ExtendedCapabilities
+	if ext == nil || ext.MetadataURL == nil || ext.MetadataURL.URL != "http://example.com/csw?id=1" {
+		t.Fatalf("ExtendedCapabilities = %+v", ext)
+	}
+	if ext.DefaultLanguage != "eng" || len(ext.SupportedLanguages) != 1 || ext.SupportedLanguages[0] != "ger" || ext.ResponseLanguage != "eng" {
+		t.Errorf("languages = %+v", ext)
+	}
+
+	states := caps.Capability.Layer.Layer[0]
+	want := `topp:states "states" q=1 srs=EPSG:4326 crs=EPSG:4326 ll=-130,20,-65,50 ex=-130,20,-65,50` +
+		` bbox[EPSG:4326/EPSG:4326]=-130,20,-65,50 bbox[EPSG:3857/EPSG:3857]=-1.4471533e+07,2.27303e+06,-7.235766e+06,6.446275e+06` +
+		` bbox[EPSG:3035/EPSG:3035]=2000,1000,4000,3000 bbox[EPSG:4087/EPSG:4087]=-1.4471533e+07,2.27303e+06,-7.235766e+06,6.446275e+06` +
+		` dim[time ISO8601  default=2026-01-02 nearest=true]=2026-01-01,2026-01-02` +
+		` dim[elevation EPSG:5030 m default=0 nearest=false]=0,100` +
+		" extent=time:2026-01-02 scale=5000-1000000\n"
+	if got := describe(states); got != want {
+		t.Errorf("layer:\n%s\nwant:\n%s", got, want)
+	}
+	if states.Dimension.Name != "time" {
+		t.Errorf("Dimension = %+v", states.Dimension)
+	}
+}
+
+func TestParseCapabilities_SameTreeForBothVersions(t *testing.T) {
+	v111, err := wms.ParseCapabilities(strings.NewReader(caps111XML))
+	if err != nil {
+		t.Fatalf("1.1.1: %v", err)
+	}
+	v130, err := wms.ParseCapabilities(strings.NewReader(caps130XML))
+	if err != nil {
+		t.Fatalf("1.3.0: %v", err)
+	}
+	a, b := describe(&v111.Capability.Layer), describe(&v130.Capability.Layer)
+	if a != b {
+		t.Errorf("layer trees differ:\n1.1.1:\n%s1.3.0:\n%s", a, b)
+	}
+	if v111.Capability.ExtendedCapabilities != nil {
+		t.Errorf("1.1.1 ExtendedCapabilities = %+v", v111.Capability.ExtendedCapabilities)
+	}
+}
+
+func TestGetCapabilities_130(t *testing.T) {
+	c := owsServer(t, "text/xml", caps130XML, func(q url.Values) {
+		if q.Get("version") != "1.3.0" {
+			t.Errorf("version = %q", q.Get("version"))
+		}
+	})
+	caps, err := c.WMS.GetCapabilities(context.Background(), wms.GetCapabilitiesOptions{Version: "1.3.0"})
+	if err != nil {
+		t.Fatalf("GetCapabilities: %v", err)
+	}
+	if got := caps.Capability.Layer.Layer[0].Name; got != "topp:states" {
+		t.Errorf("layer Name = %q", got)
+	}
+}
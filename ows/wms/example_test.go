@@ -3,6 +3,8 @@ package wms_test
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	geoserver "github.com/hishamkaram/geoserver/v2"
@@ -35,6 +37,31 @@ func ExampleClient_InWorkspace() {
 		wms.GetCapabilitiesOptions{Version: "1.3.0"})
 }
 
+// ExampleClient_GetMap renders a 256×256 PNG thumbnail of a layer
+// and saves it.
+func ExampleClient_GetMap() {
+	c, _ := geoserver.New("http://localhost:8080/geoserver")
+
+	img, contentType, err := c.WMS.GetMap(context.Background(), wms.GetMapRequest{
+		Layers: []string{"topp:states"},
+		BBox:   wms.BBox{MinX: -125, MinY: 24, MaxX: -66, MaxY: 50},
+		CRS:    "EPSG:4326",
+		Width:  256,
+		Height: 256,
+	})
+	if err != nil {
+		return
+	}
+	defer func() { _ = img.Close() }()
+	f, err := os.Create("states.png")
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	_, _ = io.Copy(f, img)
+	fmt.Println(contentType)
+}
+
 // ExampleParseCapabilities decodes a capabilities document fetched
 // out-of-band — useful for parsing a saved fixture or a body from a
 // custom transport.
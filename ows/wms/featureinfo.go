@@ -0,0 +1,121 @@
+package wms
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hishamkaram/geoserver/v2/ows"
+)
+
+// GeoJSON types shared with the other OWS clients. These are aliases
+// for the definitions in [ows], so values flow between packages
+// without conversion.
+type (
+	// FeatureCollection — see [ows.FeatureCollection].
+	FeatureCollection = ows.FeatureCollection
+	// Feature — see [ows.Feature].
+	Feature = ows.Feature
+	// Geometry — see [ows.Geometry].
+	Geometry = ows.Geometry
+)
+
+// GetFeatureInfoRequest describes a [Client.GetFeatureInfo] call: the
+// map the user clicked on, and the pixel they clicked.
+type GetFeatureInfoRequest struct {
+	// GetMapRequest is the map context. Its Layers, BBox, CRS, Width
+	// and Height are required; its Time, Elevation, CQLFilter,
+	// ViewParams and Env filters apply to the query too.
+	GetMapRequest
+
+	// QueryLayers are the layers to query. Default Layers.
+	QueryLayers []string
+
+	// I and J are the pixel column and row, from the top left of the
+	// map. They are sent as X and Y under WMS 1.1.1.
+	I, J int
+
+	// InfoFormat is the response MIME type for
+	// [Client.GetFeatureInfoRaw], e.g. "text/html", "text/plain" or
+	// "application/vnd.ogc.gml"; default "text/html". GetFeatureInfo
+	// always asks for "application/json".
+	InfoFormat string
+
+	// FeatureCount caps the features returned per layer. The server
+	// default is 1.
+	FeatureCount int
+
+	// Buffer is GeoServer's search radius around the pixel, in pixels.
+	Buffer int
+}
+
+// GetFeatureInfo queries the features under a map pixel and decodes
+// the GeoJSON answer. Service exceptions come back as
+// [*ows.ExceptionReport] errors, as for [Client.GetMap].
+//
+//	fc, err := c.WMS.GetFeatureInfo(ctx, wms.GetFeatureInfoRequest{
+//		GetMapRequest: mapReq,
+//		I:             clickX,
+//		J:             clickY,
+//		FeatureCount:  10,
+//	})
+func (c *Client) GetFeatureInfo(ctx context.Context, req GetFeatureInfoRequest) (*FeatureCollection, error) {
+	const op = "WMS.GetFeatureInfo"
+	req.InfoFormat = "application/json"
+	body, _, err := c.featureInfo(ctx, op, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+	var fc FeatureCollection
+	if err := json.NewDecoder(body).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("%s: decode: %w", op, err)
+	}
+	return &fc, nil
+}
+
+// GetFeatureInfoRaw is [Client.GetFeatureInfo] for any InfoFormat. It
+// returns the response stream and its Content-Type; the caller must
+// close the stream. text/html answers are rendered through the
+// layer's FreeMarker templates (see the templates sub-client), so
+// this is also how to check their output.
+func (c *Client) GetFeatureInfoRaw(ctx context.Context, req GetFeatureInfoRequest) (io.ReadCloser, string, error) {
+	return c.featureInfo(ctx, "WMS.GetFeatureInfoRaw", req)
+}
+
+func (c *Client) featureInfo(ctx context.Context, op string, req GetFeatureInfoRequest) (io.ReadCloser, string, error) {
+	if len(req.Layers) == 0 {
+		return nil, "", fmt.Errorf("%s: no layers", op)
+	}
+	query, err := req.query(op)
+	if err != nil {
+		return nil, "", err
+	}
+	if req.I < 0 || req.I >= req.Width || req.J < 0 || req.J >= req.Height {
+		return nil, "", fmt.Errorf("%s: pixel (%d, %d) outside the %dx%d map", op, req.I, req.J, req.Width, req.Height)
+	}
+	query["request"] = "GetFeatureInfo"
+	queryLayers := req.QueryLayers
+	if len(queryLayers) == 0 {
+		queryLayers = req.Layers
+	}
+	query["query_layers"] = strings.Join(queryLayers, ",")
+	query["info_format"] = cmp.Or(req.InfoFormat, "text/html")
+	x, y := "x", "y"
+	if query["version"] == "1.3.0" {
+		x, y = "i", "j"
+	}
+	query[x], query[y] = strconv.Itoa(req.I), strconv.Itoa(req.J)
+	if req.FeatureCount > 0 {
+		query["feature_count"] = strconv.Itoa(req.FeatureCount)
+	}
+	if req.Buffer > 0 {
+		query["buffer"] = strconv.Itoa(req.Buffer)
+	}
+	req.filters(query)
+	return c.fetch(ctx, op, query)
+}
@@ -0,0 +1,95 @@
+package wms_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/hishamkaram/geoserver/v2/ows"
+	"github.com/hishamkaram/geoserver/v2/ows/wms"
+)
+
+const featureInfoJSON = `{
+  "type": "FeatureCollection",
+  "features": [{
+    "type": "Feature",
+    "id": "roads.7",
+    "geometry": {"type": "LineString", "coordinates": [[1, 2], [3, 4]]},
+    "geometry_name": "the_geom",
+    "properties": {"name": "Main St", "lanes": 2}
+  }],
+  "totalFeatures": "unknown",
+  "numberReturned": 1,
+  "timeStamp": "2026-10-18T10:00:00.000Z"
+}`
+
+var clickMap = wms.GetMapRequest{
+	Layers: []string{"topp:roads", "topp:rivers"},
+	BBox:   wms.BBox{MinX: -10, MinY: 40, MaxX: 5, MaxY: 52},
+	CRS:    "EPSG:4326",
+	Width:  300,
+	Height: 200,
+	Time:   "2026-01-01",
+}
+
+func TestGetFeatureInfo(t *testing.T) {
+	c := owsServer(t, "application/json;charset=UTF-8", featureInfoJSON, func(q url.Values) {
+		want := map[string]string{
+			"request": "GetFeatureInfo", "version": "1.3.0", "bbox": "40,-10,52,5",
+			"layers": "topp:roads,topp:rivers", "query_layers": "topp:roads,topp:rivers",
+			"info_format": "application/json", "i": "12", "j": "34",
+			"feature_count": "5", "buffer": "3", "time": "2026-01-01",
+		}
+		for k, v := range want {
+			if got := q.Get(k); got != v {
+				t.Errorf("%s = %q, want %q", k, got, v)
+			}
+		}
+	})
+	req := wms.GetFeatureInfoRequest{GetMapRequest: clickMap, I: 12, J: 34, FeatureCount: 5, Buffer: 3}
+	req.Version = "1.3.0"
+	fc, err := c.WMS.GetFeatureInfo(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GetFeatureInfo: %v", err)
+	}
+	if len(fc.Features) != 1 || fc.NumberReturned != 1 {
+		t.Fatalf("collection = %+v", fc)
+	}
+	f := fc.Features[0]
+	if f.ID != "roads.7" || f.Geometry == nil || f.Geometry.Type != "LineString" || f.GeometryName != "the_geom" ||
+		f.Properties["name"] != "Main St" || f.Properties["lanes"] != 2.0 {
+		t.Fatalf("feature = %+v", f)
+	}
+}
+
+func TestGetFeatureInfoRaw(t *testing.T) {
+	c := owsServer(t, "text/plain", "Results for FeatureType 'roads':\nname = Main St\n", func(q url.Values) {
+		if q.Get("x") != "1" || q.Get("y") != "2" || q.Get("query_layers") != "topp:rivers" || q.Get("info_format") != "text/plain" {
+			t.Errorf("query = %v", q)
+		}
+	})
+	body, contentType, err := c.WMS.GetFeatureInfoRaw(context.Background(), wms.GetFeatureInfoRequest{
+		GetMapRequest: clickMap, QueryLayers: []string{"topp:rivers"}, I: 1, J: 2, InfoFormat: "text/plain",
+	})
+	if err != nil {
+		t.Fatalf("GetFeatureInfoRaw: %v", err)
+	}
+	defer func() { _ = body.Close() }()
+	if data, _ := io.ReadAll(body); contentType != "text/plain" || len(data) == 0 {
+		t.Fatalf("GetFeatureInfoRaw = %q, %q", data, contentType)
+	}
+}
+
+func TestGetFeatureInfo_Errors(t *testing.T) {
+	c := owsServer(t, "application/vnd.ogc.se_xml", exceptionXML, nil)
+	_, err := c.WMS.GetFeatureInfo(context.Background(), wms.GetFeatureInfoRequest{GetMapRequest: clickMap})
+	var report *ows.ExceptionReport
+	if !errors.As(err, &report) || report.Op != "WMS.GetFeatureInfo" {
+		t.Fatalf("GetFeatureInfo error = %v, want *ows.ExceptionReport", err)
+	}
+	if _, err := c.WMS.GetFeatureInfo(context.Background(), wms.GetFeatureInfoRequest{GetMapRequest: clickMap, I: 300}); err == nil {
+		t.Fatal("pixel outside the map: no error")
+	}
+}
@@ -0,0 +1,146 @@
+package wms_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/ows"
+	"github.com/hishamkaram/geoserver/v2/ows/wms"
+)
+
+const exceptionXML = `<?xml version="1.0" encoding="UTF-8"?>
+<ServiceExceptionReport version="1.1.1">
+  <ServiceException code="LayerNotDefined" locator="layers">
+    Could not find layer topp:nope
+  </ServiceException>
+</ServiceExceptionReport>`
+
+// owsServer answers every request with contentType and body, and
+// hands the query to check.
+func owsServer(t *testing.T, contentType, body string, check func(url.Values)) *geoserver.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r.URL.Query())
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := geoserver.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestGetMap(t *testing.T) {
+	c := owsServer(t, "image/png", "PNG", func(q url.Values) {
+		want := map[string]string{
+			"service": "wms", "version": "1.1.1", "request": "GetMap",
+			"layers": "topp:roads,topp:rivers", "styles": ",blue",
+			"bbox": "-10,40.5,5,52", "srs": "EPSG:4326",
+			"width": "256", "height": "128", "format": "image/png", "transparent": "true",
+			"time": "2026-01-01", "cql_filter": "type='A';INCLUDE",
+			"viewparams": `a:1\,2;b:x\;y`, "env": "color:ff0000", "format_options": "dpi:180",
+			"exceptions": "application/vnd.ogc.se_xml",
+		}
+		for k, v := range want {
+			if got := q.Get(k); got != v {
+				t.Errorf("%s = %q, want %q", k, got, v)
+			}
+		}
+	})
+	body, contentType, err := c.WMS.GetMap(context.Background(), wms.GetMapRequest{
+		Layers:        []string{"topp:roads", "topp:rivers"},
+		Styles:        []string{"", "blue"},
+		BBox:          wms.BBox{MinX: -10, MinY: 40.5, MaxX: 5, MaxY: 52},
+		CRS:           "EPSG:4326",
+		Width:         256,
+		Height:        128,
+		Transparent:   true,
+		Time:          "2026-01-01",
+		CQLFilter:     []string{"type='A'", "INCLUDE"},
+		ViewParams:    map[string]string{"b": "x;y", "a": "1,2"},
+		Env:           map[string]string{"color": "ff0000"},
+		FormatOptions: map[string]string{"dpi": "180"},
+	})
+	if err != nil {
+		t.Fatalf("GetMap: %v", err)
+	}
+	defer func() { _ = body.Close() }()
+	if data, _ := io.ReadAll(body); string(data) != "PNG" || contentType != "image/png" {
+		t.Fatalf("GetMap = %q, %q", data, contentType)
+	}
+}
+
+func TestGetMap_AxisOrder(t *testing.T) {
+	for _, tc := range []struct {
+		version, crs, key, bbox string
+		axis                    wms.AxisOrder
+	}{
+		{"1.1.1", "EPSG:4326", "srs", "-10,40,5,52", wms.AxisAuto},
+		{"1.3.0", "EPSG:4326", "crs", "40,-10,52,5", wms.AxisAuto},
+		{"1.3.0", "urn:ogc:def:crs:EPSG::4258", "crs", "40,-10,52,5", wms.AxisAuto},
+		{"1.3.0", "EPSG:3035", "crs", "40,-10,52,5", wms.AxisAuto},
+		{"1.3.0", "CRS:84", "crs", "-10,40,5,52", wms.AxisAuto},
+		{"1.3.0", "EPSG:3857", "crs", "-10,40,5,52", wms.AxisAuto},
+		{"1.3.0", "EPSG:4087", "crs", "-10,40,5,52", wms.AxisAuto},
+		{"1.3.0", "EPSG:4326", "crs", "-10,40,5,52", wms.AxisEastingFirst},
+		{"1.3.0", "EPSG:5514", "crs", "40,-10,52,5", wms.AxisNorthingFirst},
+		{"1.1.1", "EPSG:5514", "srs", "-10,40,5,52", wms.AxisNorthingFirst},
+	} {
+		c := owsServer(t, "image/png", "", func(q url.Values) {
+			if q.Get(tc.key) != tc.crs || q.Get("bbox") != tc.bbox {
+				t.Errorf("%s %s: %s=%q bbox=%q, want %q", tc.version, tc.crs, tc.key, q.Get(tc.key), q.Get("bbox"), tc.bbox)
+			}
+		})
+		body, _, err := c.WMS.GetMap(context.Background(), wms.GetMapRequest{
+			Version: tc.version, AxisOrder: tc.axis, Layers: []string{"l"}, CRS: tc.crs,
+			BBox: wms.BBox{MinX: -10, MinY: 40, MaxX: 5, MaxY: 52}, Width: 1, Height: 1,
+		})
+		if err != nil {
+			t.Fatalf("GetMap: %v", err)
+		}
+		_ = body.Close()
+	}
+}
+
+func TestGetMap_ServiceException(t *testing.T) {
+	c := owsServer(t, "application/vnd.ogc.se_xml;charset=UTF-8", exceptionXML, nil)
+	_, _, err := c.WMS.InWorkspace("topp").GetMap(context.Background(), wms.GetMapRequest{
+		Layers: []string{"nope"}, CRS: "EPSG:3857", BBox: wms.BBox{MaxX: 1, MaxY: 1}, Width: 1, Height: 1,
+	})
+	var report *ows.ExceptionReport
+	if !errors.As(err, &report) {
+		t.Fatalf("GetMap error = %v, want *ows.ExceptionReport", err)
+	}
+	if report.Op != "WMS.GetMap" || report.Code() != "LayerNotDefined" || report.Exceptions[0].Locator != "layers" ||
+		report.Exceptions[0].Text != "Could not find layer topp:nope" {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestGetMap_Validation(t *testing.T) {
+	c, _ := geoserver.New("http://localhost:8080/geoserver")
+	ok := wms.GetMapRequest{Layers: []string{"l"}, CRS: "EPSG:4326", BBox: wms.BBox{MaxX: 1, MaxY: 1}, Width: 1, Height: 1}
+	for _, mutate := range []func(*wms.GetMapRequest){
+		func(r *wms.GetMapRequest) { r.Layers = nil },
+		func(r *wms.GetMapRequest) { r.CRS = "" },
+		func(r *wms.GetMapRequest) { r.Width = 0 },
+		func(r *wms.GetMapRequest) { r.BBox = wms.BBox{} },
+		func(r *wms.GetMapRequest) { r.Version = "1.0.0" },
+	} {
+		req := ok
+		mutate(&req)
+		if _, _, err := c.WMS.GetMap(context.Background(), req); err == nil {
+			t.Fatalf("GetMap(%+v): no error", req)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package wms
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// LegendRequest describes a [Client.GetLegendGraphic] call. Layer is
+// required.
+type LegendRequest struct {
+	Layer string
+
+	// Style selects a style other than the layer's default.
+	Style string
+
+	// Rule limits the legend to the rule with this name.
+	Rule string
+
+	// Scale, when set, limits the legend to the rules that apply at
+	// this scale denominator.
+	Scale float64
+
+	// Width and Height size each legend symbol, in pixels. The server
+	// default is 20×20.
+	Width, Height int
+
+	// Format is the legend MIME type: "image/png" (the default),
+	// "image/svg+xml" or another image type. [Client.GetLegendJSON]
+	// sets "application/json".
+	Format string
+
+	// LegendOptions is GeoServer's legend_options vendor parameter,
+	// e.g. {"fontName": "Arial", "forceLabels": "on", "dpi": "180"}.
+	LegendOptions map[string]string
+}
+
+// GetLegendGraphic renders the legend of a layer's style and returns
+// the image stream and its Content-Type. The caller must close the
+// stream. Service exceptions come back as [*ows.ExceptionReport]
+// errors, as for [Client.GetMap].
+func (c *Client) GetLegendGraphic(ctx context.Context, req LegendRequest) (io.ReadCloser, string, error) {
+	return c.legend(ctx, "WMS.GetLegendGraphic", req)
+}
+
+// GetLegendJSON fetches a layer's legend as GeoServer's JSON legend
+// document: the style's rules with their filters, scale ranges,
+// symbolizers and labels, enough to draw an HTML legend.
+func (c *Client) GetLegendJSON(ctx context.Context, req LegendRequest) (*Legend, error) {
+	const op = "WMS.GetLegendJSON"
+	req.Format = "application/json"
+	body, _, err := c.legend(ctx, op, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+	var legend Legend
+	if err := json.NewDecoder(body).Decode(&legend); err != nil {
+		return nil, fmt.Errorf("%s: decode: %w", op, err)
+	}
+	return &legend, nil
+}
+
+func (c *Client) legend(ctx context.Context, op string, req LegendRequest) (io.ReadCloser, string, error) {
+	if req.Layer == "" {
+		return nil, "", errors.New(op + ": empty Layer")
+	}
+	query := map[string]string{
+		"service":    "wms",
+		"version":    "1.0.0",
+		"request":    "GetLegendGraphic",
+		"layer":      req.Layer,
+		"format":     cmp.Or(req.Format, "image/png"),
+		"exceptions": "application/vnd.ogc.se_xml",
+	}
+	set := func(key, value string) {
+		if value != "" {
+			query[key] = value
+		}
+	}
+	set("style", req.Style)
+	set("rule", req.Rule)
+	set("legend_options", vendorList(req.LegendOptions))
+	if req.Scale > 0 {
+		query["scale"] = strconv.FormatFloat(req.Scale, 'f', -1, 64)
+	}
+	if req.Width > 0 {
+		query["width"] = strconv.Itoa(req.Width)
+	}
+	if req.Height > 0 {
+		query["height"] = strconv.Itoa(req.Height)
+	}
+	return c.fetch(ctx, op, query)
+}
+
+// Legend is GeoServer's JSON legend document.
+type Legend struct {
+	Layers []LegendLayer `json:"Legend"`
+}
+
+// LegendLayer is the legend of one layer.
+type LegendLayer struct {
+	LayerName string       `json:"layerName"`
+	Title     string       `json:"title,omitempty"`
+	Rules     []LegendRule `json:"rules"`
+}
+
+// LegendRule is one style rule. Filter is the rule's filter in CQL;
+// ElseFilter is set on a rule that catches what no other rule does.
+type LegendRule struct {
+	Name        string       `json:"name,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Abstract    string       `json:"abstract,omitempty"`
+	Filter      string       `json:"filter,omitempty"`
+	ElseFilter  LegendValue  `json:"ElseFilter,omitempty"`
+	MinScale    float64      `json:"MinScaleDenominator,omitempty"`
+	MaxScale    float64      `json:"MaxScaleDenominator,omitempty"`
+	Symbolizers []Symbolizer `json:"symbolizers"`
+}
+
+// Symbolizer is one symbolizer of a [LegendRule]. Exactly one field
+// is set.
+type Symbolizer struct {
+	Point   *PointSymbolizer   `json:"Point,omitempty"`
+	Line    *LineSymbolizer    `json:"Line,omitempty"`
+	Polygon *PolygonSymbolizer `json:"Polygon,omitempty"`
+	Text    *TextSymbolizer    `json:"Text,omitempty"`
+	Raster  *RasterSymbolizer  `json:"Raster,omitempty"`
+}
+
+// LegendValue is a style value. GeoServer writes literals as JSON
+// numbers, strings or booleans and expressions as "${…}" strings;
+// LegendValue holds the text of any of them.
+type LegendValue string
+
+// UnmarshalJSON accepts a JSON string, number or boolean.
+func (v *LegendValue) UnmarshalJSON(data []byte) error {
+	var scalar any
+	if err := json.Unmarshal(data, &scalar); err != nil {
+		return err
+	}
+	switch x := scalar.(type) {
+	case string:
+		*v = LegendValue(x)
+	case float64, bool:
+		*v = LegendValue(data)
+	case nil:
+		*v = ""
+	default:
+		return fmt.Errorf("wms: legend value %s is not a scalar", data)
+	}
+	return nil
+}
+
+// Stroke is the stroke of a line, polygon outline or mark.
+type Stroke struct {
+	Stroke          LegendValue `json:"stroke,omitempty"`
+	StrokeWidth     LegendValue `json:"stroke-width,omitempty"`
+	StrokeOpacity   LegendValue `json:"stroke-opacity,omitempty"`
+	StrokeLineCap   LegendValue `json:"stroke-linecap,omitempty"`
+	StrokeLineJoin  LegendValue `json:"stroke-linejoin,omitempty"`
+	StrokeDashArray LegendValue `json:"stroke-dasharray,omitempty"`
+}
+
+// Fill is the fill of a polygon, mark or label.
+type Fill struct {
+	Fill        LegendValue `json:"fill,omitempty"`
+	FillOpacity LegendValue `json:"fill-opacity,omitempty"`
+}
+
+// Graphic is a mark or external graphic of a point symbolizer.
+type Graphic struct {
+	// Mark is a well-known mark name such as "circle" or "square".
+	Mark string `json:"mark,omitempty"`
+	Fill
+	Stroke
+
+	ExternalGraphicURL  string `json:"external-graphic-url,omitempty"`
+	ExternalGraphicType string `json:"external-graphic-type,omitempty"`
+}
+
+// PointSymbolizer draws a graphic at each point.
+type PointSymbolizer struct {
+	Title    string      `json:"title,omitempty"`
+	Size     LegendValue `json:"size,omitempty"`
+	Rotation LegendValue `json:"rotation,omitempty"`
+	Opacity  LegendValue `json:"opacity,omitempty"`
+	Graphics []Graphic   `json:"graphics,omitempty"`
+}
+
+// LineSymbolizer strokes lines.
+type LineSymbolizer struct {
+	Stroke
+	PerpendicularOffset LegendValue `json:"perpendicular-offset,omitempty"`
+}
+
+// PolygonSymbolizer fills and outlines polygons.
+type PolygonSymbolizer struct {
+	Fill
+	Stroke
+}
+
+// TextSymbolizer draws labels. Label is the label expression, e.g.
+// "${name}".
+type TextSymbolizer struct {
+	Label LegendValue `json:"label,omitempty"`
+	Fonts []Font      `json:"fonts,omitempty"`
+	Halo  *Halo       `json:"halo,omitempty"`
+	Fill
+}
+
+// Font is one font choice of a [TextSymbolizer].
+type Font struct {
+	Family []string    `json:"font-family,omitempty"`
+	Style  LegendValue `json:"font-style,omitempty"`
+	Weight LegendValue `json:"font-weight,omitempty"`
+	Size   LegendValue `json:"font-size,omitempty"`
+}
+
+// Halo is the halo around a label.
+type Halo struct {
+	Radius LegendValue `json:"radius,omitempty"`
+	Fill
+}
+
+// RasterSymbolizer renders coverages, usually through a color map.
+type RasterSymbolizer struct {
+	Opacity  LegendValue `json:"opacity,omitempty"`
+	ColorMap *ColorMap   `json:"colormap,omitempty"`
+}
+
+// ColorMap maps raster values to colors. Type is "ramp", "intervals"
+// or "values".
+type ColorMap struct {
+	Type    string          `json:"type,omitempty"`
+	Entries []ColorMapEntry `json:"entries,omitempty"`
+}
+
+// ColorMapEntry is one color map stop.
+type ColorMapEntry struct {
+	Label    string      `json:"label,omitempty"`
+	Quantity LegendValue `json:"quantity,omitempty"`
+	Color    LegendValue `json:"color,omitempty"`
+	Opacity  LegendValue `json:"opacity,omitempty"`
+}
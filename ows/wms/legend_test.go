@@ -0,0 +1,97 @@
+package wms_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/hishamkaram/geoserver/v2/ows"
+	"github.com/hishamkaram/geoserver/v2/ows/wms"
+)
+
+const legendJSON = `{"Legend": [{
+  "layerName": "topp:states",
+  "title": "USA Population",
+  "rules": [
+    {
+      "title": "< 2M",
+      "filter": "[PERSONS < 2000000]",
+      "MaxScaleDenominator": 5.0E7,
+      "symbolizers": [
+        {"Polygon": {"fill": "#4DFF4D", "fill-opacity": "0.7", "stroke": "#000000", "stroke-width": 1}},
+        {"Text": {"label": "${STATE_ABBR}", "fonts": [{"font-family": ["Arial"], "font-size": 12}], "fill": "#000000", "halo": {"radius": 2, "fill": "#FFFFFF"}}}
+      ]
+    },
+    {
+      "name": "capitals",
+      "ElseFilter": "true",
+      "symbolizers": [{"Point": {"size": 6, "graphics": [{"mark": "circle", "fill": "#FF0000", "stroke-width": 0.5}]}}]
+    }
+  ]
+}]}`
+
+func TestGetLegendJSON(t *testing.T) {
+	c := owsServer(t, "application/json", legendJSON, func(q url.Values) {
+		want := map[string]string{
+			"request": "GetLegendGraphic", "layer": "topp:states", "style": "population",
+			"format": "application/json", "scale": "25000000", "legend_options": "forceLabels:on",
+		}
+		for k, v := range want {
+			if got := q.Get(k); got != v {
+				t.Errorf("%s = %q, want %q", k, got, v)
+			}
+		}
+	})
+	legend, err := c.WMS.GetLegendJSON(context.Background(), wms.LegendRequest{
+		Layer: "topp:states", Style: "population", Scale: 25e6, LegendOptions: map[string]string{"forceLabels": "on"},
+	})
+	if err != nil {
+		t.Fatalf("GetLegendJSON: %v", err)
+	}
+	if len(legend.Layers) != 1 || len(legend.Layers[0].Rules) != 2 {
+		t.Fatalf("legend = %+v", legend)
+	}
+	first := legend.Layers[0].Rules[0]
+	if first.Filter != "[PERSONS < 2000000]" || first.MaxScale != 5e7 || len(first.Symbolizers) != 2 {
+		t.Fatalf("first rule = %+v", first)
+	}
+	poly, text := first.Symbolizers[0].Polygon, first.Symbolizers[1].Text
+	if poly == nil || poly.Fill.Fill != "#4DFF4D" || poly.StrokeWidth != "1" {
+		t.Fatalf("polygon = %+v", poly)
+	}
+	if text == nil || text.Label != "${STATE_ABBR}" || text.Fonts[0].Size != "12" || text.Halo.Radius != "2" {
+		t.Fatalf("text = %+v", text)
+	}
+	second := legend.Layers[0].Rules[1]
+	point := second.Symbolizers[0].Point
+	if second.ElseFilter != "true" || point == nil || point.Graphics[0].Mark != "circle" || point.Graphics[0].StrokeWidth != "0.5" {
+		t.Fatalf("second rule = %+v", second)
+	}
+}
+
+func TestGetLegendGraphic(t *testing.T) {
+	c := owsServer(t, "image/svg+xml", "<svg/>", func(q url.Values) {
+		if q.Get("format") != "image/svg+xml" || q.Get("width") != "16" || q.Get("rule") != "capitals" {
+			t.Errorf("query = %v", q)
+		}
+	})
+	body, contentType, err := c.WMS.GetLegendGraphic(context.Background(), wms.LegendRequest{
+		Layer: "topp:states", Rule: "capitals", Width: 16, Format: "image/svg+xml",
+	})
+	if err != nil {
+		t.Fatalf("GetLegendGraphic: %v", err)
+	}
+	defer func() { _ = body.Close() }()
+	if data, _ := io.ReadAll(body); string(data) != "<svg/>" || contentType != "image/svg+xml" {
+		t.Fatalf("GetLegendGraphic = %q, %q", data, contentType)
+	}
+
+	c = owsServer(t, "application/vnd.ogc.se_xml", exceptionXML, nil)
+	_, _, err = c.WMS.GetLegendGraphic(context.Background(), wms.LegendRequest{Layer: "topp:nope"})
+	var report *ows.ExceptionReport
+	if !errors.As(err, &report) {
+		t.Fatalf("GetLegendGraphic error = %v, want *ows.ExceptionReport", err)
+	}
+}
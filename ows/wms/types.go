@@ -3,11 +3,14 @@
 // document and parsing it into Go types. The exported XML types mirror
 // v1's wms package one-for-one so callers can move with no shape
 // changes; the parser accepts io.Reader (v2 idiom) instead of []byte.
+// WMS 1.1.1 and 1.3.0 documents decode into the same tree.
 //
-// WMS request endpoints (GetMap, GetFeatureInfo, GetLegendGraphic) are
-// HTTP-content endpoints rather than catalog operations and stay on
-// the application layer. WFS / WCS GetCapabilities can land alongside
-// in this package's siblings (ows/wfs, ows/wcs) using the same shape.
+// [Client.GetMap] renders map images from a typed request,
+// [Client.GetFeatureInfo] queries the features under a pixel of one,
+// and [Client.GetLegendGraphic] draws a layer's legend, or decodes it
+// into typed rules with [Client.GetLegendJSON].
+// Service exceptions, which WMS delivers with 200 OK, come back as an
+// [*ows.ExceptionReport] error rather than as content.
 package wms
 
 import "encoding/xml"
@@ -106,7 +109,23 @@ type LatLonBoundingBox struct {
 	MaxY    float64  `xml:"maxy,attr"`
 }
 
-// BoundingBox is a layer's CRS-specific extent.
+// EXGeographicBoundingBox is the WMS 1.3.0 geographic extent of a
+// Layer, replacing 1.1.1's [LatLonBoundingBox]. Decoding either
+// version fills both.
+type EXGeographicBoundingBox struct {
+	XMLName xml.Name `xml:"EX_GeographicBoundingBox"`
+	West    float64  `xml:"westBoundLongitude"`
+	East    float64  `xml:"eastBoundLongitude"`
+	South   float64  `xml:"southBoundLatitude"`
+	North   float64  `xml:"northBoundLatitude"`
+}
+
+// BoundingBox is a layer's CRS-specific extent. WMS 1.1.1 names the
+// CRS in the SRS attribute and 1.3.0 in CRS; decoding either version
+// fills both. A 1.3.0 box in a CRS that [AxisAuto] knows to be
+// northing first, such as EPSG:4326, is swapped on decode so MinX/MinY
+// come easting first. A box in a northing-first CRS missing from that
+// table keeps the document's order.
 type BoundingBox struct {
 	XMLName xml.Name `xml:"BoundingBox"`
 	MinX    float64  `xml:"minx,attr"`
@@ -114,6 +133,16 @@ type BoundingBox struct {
 	MaxX    float64  `xml:"maxx,attr"`
 	MaxY    float64  `xml:"maxy,attr"`
 	SRS     string   `xml:"SRS,attr,omitempty"`
+	CRS     string   `xml:"CRS,attr,omitempty"`
+}
+
+// ScaleHint is the WMS 1.1.1 scale range of a Layer, given as the
+// ground size of a pixel's diagonal. Decoding converts it into
+// [Layer.MinScaleDenominator] and [Layer.MaxScaleDenominator].
+type ScaleHint struct {
+	XMLName xml.Name `xml:"ScaleHint"`
+	Min     float64  `xml:"min,attr"`
+	Max     float64  `xml:"max,attr"`
 }
 
 // LegendURL is a renderable legend for a Style.
@@ -151,18 +180,29 @@ type LogoURL struct {
 }
 
 // Dimension declares a non-spatial dimension axis on a Layer
-// (typically time, elevation, custom).
+// (typically time, elevation, custom). WMS 1.3.0 carries the default
+// and the available values on the Dimension itself; for 1.1.1 they
+// are merged in from the matching [Extent] on decode.
 type Dimension struct {
-	XMLName xml.Name `xml:"Dimension"`
-	Name    string   `xml:"name,attr,omitempty"`
-	Units   string   `xml:"units,attr,omitempty"`
+	XMLName        xml.Name `xml:"Dimension"`
+	Name           string   `xml:"name,attr,omitempty"`
+	Units          string   `xml:"units,attr,omitempty"`
+	UnitSymbol     string   `xml:"unitSymbol,attr,omitempty"`
+	Default        string   `xml:"default,attr,omitempty"`
+	MultipleValues bool     `xml:"multipleValues,attr,omitempty"`
+	NearestValue   bool     `xml:"nearestValue,attr,omitempty"`
+	Current        bool     `xml:"current,attr,omitempty"`
+	Values         string   `xml:",chardata"`
 }
 
-// Extent declares the active value range on a [Dimension].
+// Extent declares the active value range on a [Dimension] (WMS
+// 1.1.1 only).
 type Extent struct {
-	XMLName xml.Name `xml:"Extent"`
-	Name    string   `xml:"name,attr,omitempty"`
-	Default string   `xml:"default,attr,omitempty"`
+	XMLName      xml.Name `xml:"Extent"`
+	Name         string   `xml:"name,attr,omitempty"`
+	Default      string   `xml:"default,attr,omitempty"`
+	NearestValue bool     `xml:"nearestValue,attr,omitempty"`
+	Values       string   `xml:",chardata"`
 }
 
 // Attribution is the per-Layer attribution block (title, online
@@ -176,21 +216,53 @@ type Attribution struct {
 
 // Layer is one published WMS layer. Layers nest — `Layer.Layer` is
 // the child list when this is a layer group / category.
+//
+// The tree is the same whichever version was decoded: SRS and CRS
+// both list the layer's reference systems, LatLonBoundingBox and
+// EXGeographicBoundingBox both hold its geographic extent, and
+// Dimensions lists every dimension with its default and values.
+// Dimension and Extent keep the first one for v1 compatibility.
 type Layer struct {
-	XMLName           xml.Name          `xml:"Layer"`
-	Title             string            `xml:"Title"`
-	Abstract          string            `xml:"Abstract"`
-	Queryable         int8              `xml:"queryable,attr,omitempty"`
-	SRS               []*string         `xml:"SRS,omitempty"`
-	LatLonBoundingBox LatLonBoundingBox `xml:"LatLonBoundingBox,omitempty"`
-	BoundingBox       []*BoundingBox    `xml:"BoundingBox,omitempty"`
-	AuthorityURL      AuthorityURL      `xml:"AuthorityURL,omitempty"`
-	Style             []*Style          `xml:"Style,omitempty"`
-	Layer             []*Layer          `xml:"Layer,omitempty"`
-	MetadataURL       []*MetadataURL    `xml:"MetadataURL,omitempty"`
-	Dimension         Dimension         `xml:"Dimension,omitempty"`
-	Extent            Extent            `xml:"Extent,omitempty"`
-	Attribution       Attribution       `xml:"Attribution,omitempty"`
+	XMLName                 xml.Name                 `xml:"Layer"`
+	Name                    string                   `xml:"Name,omitempty"`
+	Title                   string                   `xml:"Title"`
+	Abstract                string                   `xml:"Abstract"`
+	Queryable               int8                     `xml:"queryable,attr,omitempty"`
+	SRS                     []*string                `xml:"SRS,omitempty"`
+	CRS                     []*string                `xml:"CRS,omitempty"`
+	LatLonBoundingBox       LatLonBoundingBox        `xml:"LatLonBoundingBox,omitempty"`
+	EXGeographicBoundingBox *EXGeographicBoundingBox `xml:"EX_GeographicBoundingBox,omitempty"`
+	BoundingBox             []*BoundingBox           `xml:"BoundingBox,omitempty"`
+	AuthorityURL            AuthorityURL             `xml:"AuthorityURL,omitempty"`
+	Style                   []*Style                 `xml:"Style,omitempty"`
+	Layer                   []*Layer                 `xml:"Layer,omitempty"`
+	MetadataURL             []*MetadataURL           `xml:"MetadataURL,omitempty"`
+	Dimension               Dimension                `xml:"Dimension,omitempty"`
+	Extent                  Extent                   `xml:"Extent,omitempty"`
+	Dimensions              []*Dimension             `xml:"-"`
+	Attribution             Attribution              `xml:"Attribution,omitempty"`
+	ScaleHint               *ScaleHint               `xml:"ScaleHint,omitempty"`
+	MinScaleDenominator     float64                  `xml:"MinScaleDenominator,omitempty"`
+	MaxScaleDenominator     float64                  `xml:"MaxScaleDenominator,omitempty"`
+}
+
+// ExtendedCapabilities is the INSPIRE View Service extension
+// (`inspire_vs:ExtendedCapabilities`) GeoServer's INSPIRE module adds
+// to the Capability block.
+type ExtendedCapabilities struct {
+	XMLName            xml.Name            `xml:"ExtendedCapabilities"`
+	MetadataURL        *InspireMetadataURL `xml:"MetadataUrl,omitempty"`
+	DefaultLanguage    string              `xml:"SupportedLanguages>DefaultLanguage>Language,omitempty"`
+	SupportedLanguages []string            `xml:"SupportedLanguages>SupportedLanguage>Language,omitempty"`
+	ResponseLanguage   string              `xml:"ResponseLanguage>Language,omitempty"`
+}
+
+// InspireMetadataURL links the service to its INSPIRE metadata
+// record.
+type InspireMetadataURL struct {
+	XMLName   xml.Name `xml:"MetadataUrl"`
+	URL       string   `xml:"URL"`
+	MediaType string   `xml:"MediaType,omitempty"`
 }
 
 // Capability is the operations + advertised layer tree.
@@ -200,6 +272,7 @@ type Capability struct {
 	Exception                Exception                `xml:"Exception"`
 	UserDefinedSymbolization UserDefinedSymbolization `xml:"UserDefinedSymbolization"`
 	Layer                    Layer                    `xml:"Layer"`
+	ExtendedCapabilities     *ExtendedCapabilities    `xml:"ExtendedCapabilities,omitempty"`
 }
 
 // Service is the service-level metadata block (title, keywords, fees,
@@ -214,8 +287,9 @@ type Service struct {
 	AccessConstraints string         `xml:"AccessConstraints"`
 }
 
-// Capabilities is the root of the WMS GetCapabilities document
-// (`<WMT_MS_Capabilities>` for WMS 1.1.1).
+// Capabilities is the root of the WMS GetCapabilities document:
+// `<WMT_MS_Capabilities>` for WMS 1.1.1, `<WMS_Capabilities>` for
+// 1.3.0. Both decode into the same tree; Version tells them apart.
 type Capabilities struct {
 	XMLName        xml.Name   `xml:"WMT_MS_Capabilities"`
 	Version        string     `xml:"version,attr,omitempty"`
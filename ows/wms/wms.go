@@ -16,10 +16,12 @@ import (
 type Core interface {
 	URL(parts ...string) (string, error)
 	DoXML(ctx context.Context, op, method, requestURL string, query map[string]string, out any) error
+	DoStreamHeader(ctx context.Context, op, method, requestURL string, query map[string]string) (io.ReadCloser, http.Header, error)
 }
 
 // Client is the v2 WMS sub-client. The current surface covers
-// [Client.GetCapabilities]; [Client.InWorkspace] returns a
+// [Client.GetCapabilities], [Client.GetMap], [Client.GetFeatureInfo]
+// and [Client.GetLegendGraphic]; [Client.InWorkspace] returns a
 // workspace-scoped view that issues `/ {workspace}/wms` rather than
 // the global `/wms`.
 //
@@ -55,12 +57,21 @@ func (c *Client) Workspace() string { return c.workspace }
 // `/wms` endpoint (true) or a workspace-scoped one (false).
 func (c *Client) IsGlobal() bool { return c.workspace == "" }
 
+// Endpoint returns the URL this client sends requests to: the global
+// `/wms`, or `/{workspace}/wms` when scoped.
+func (c *Client) Endpoint() (string, error) {
+	if c.workspace != "" {
+		return c.core.URL(c.workspace, "wms")
+	}
+	return c.core.URL("wms")
+}
+
 // GetCapabilitiesOptions controls a [Client.GetCapabilities] call.
 // All fields are optional.
 type GetCapabilitiesOptions struct {
-	// Version is the WMS protocol version requested. Default
-	// "1.1.1" — matches v1's GetCapabilities and is the version
-	// this package's [Capabilities] type tree decodes.
+	// Version is the WMS protocol version requested: "1.1.1" (the
+	// default, matching v1's GetCapabilities) or "1.3.0". Both decode
+	// into the same [Capabilities] tree.
 	Version string
 
 	// UpdateSequence is an optional cache-coordination token.
@@ -76,12 +87,7 @@ type GetCapabilitiesOptions struct {
 func (c *Client) GetCapabilities(ctx context.Context, opts GetCapabilitiesOptions) (*Capabilities, error) {
 	const op = "WMS.GetCapabilities"
 
-	parts := []string{}
-	if c.workspace != "" {
-		parts = append(parts, c.workspace)
-	}
-	parts = append(parts, "wms")
-	u, err := c.core.URL(parts...)
+	u, err := c.Endpoint()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -242,3 +242,51 @@ func TestClient_IsGlobal(t *testing.T) {
 		t.Errorf("InWorkspace mutated original")
 	}
 }
+
+func TestGetCapabilities_OWSBaseURL(t *testing.T) {
+	rest := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("OWS request hit the REST base: %s", r.URL.Path)
+	}))
+	defer rest.Close()
+
+	var paths []string
+	ows := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_, _ = io.WriteString(w, minimalCapsXML)
+	}))
+	defer ows.Close()
+
+	c, err := geoserver.New(rest.URL+"/geoserver", geoserver.WithOWSBaseURL(ows.URL+"/cdn/geoserver"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.WMS.GetCapabilities(ctx, wms.GetCapabilitiesOptions{}); err != nil {
+		t.Fatalf("GetCapabilities: %v", err)
+	}
+	if _, err := c.WMS.InWorkspace("topp").GetCapabilities(ctx, wms.GetCapabilitiesOptions{}); err != nil {
+		t.Fatalf("InWorkspace.GetCapabilities: %v", err)
+	}
+	want := []string{"/cdn/geoserver/wms", "/cdn/geoserver/topp/wms"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+}
+
+func TestClient_Endpoint(t *testing.T) {
+	c, err := geoserver.New("http://rest.example.com/geoserver", geoserver.WithOWSBaseURL("http://cdn.example.com/geoserver"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, tc := range []struct {
+		c    *wms.Client
+		want string
+	}{
+		{c.WMS, "http://cdn.example.com/geoserver/wms"},
+		{c.WMS.InWorkspace("topp"), "http://cdn.example.com/geoserver/topp/wms"},
+	} {
+		if got, err := tc.c.Endpoint(); err != nil || got != tc.want {
+			t.Fatalf("Endpoint = %q, %v; want %q", got, err, tc.want)
+		}
+	}
+}
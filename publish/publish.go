@@ -0,0 +1,328 @@
+// Package publish puts data on a GeoServer in one call: workspace,
+// store, resource, layer style and tile layer, created or brought in
+// line as needed.
+//
+//	res, err := publish.PostGISTable(ctx, c, publish.PublishPostGISRequest{
+//		Workspace: "topp",
+//		Store:     "pg",
+//		PostGIS:   datastores.PostGIS{Host: "db", Port: 5432, Database: "gis", User: "u", Password: "p"},
+//		Table:     "roads",
+//		Title:     "Roads",
+//		SRS:       "EPSG:4326",
+//		Style:     "line",
+//	})
+//	fmt.Println(res.WMS, res.WFS)
+//
+// Every call is idempotent. Each object is read first; a missing one
+// is created, one that differs from the request is updated, and one
+// that matches is left alone. Only fields the request sets are
+// compared, so values the server fills in don't count as differences.
+// [Result.Steps] records what happened to each object, and calling
+// again with the same request reports every step [Unchanged].
+//
+// [GeoTIFF] and [Shapefile] publish an uploaded file instead of a
+// database table. The upload runs only when the store doesn't exist
+// yet, or when the request asks to replace it.
+package publish
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/gwc"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// Action is what a publish call did to one catalog object.
+type Action string
+
+// Actions.
+const (
+	Created   Action = "created"
+	Updated   Action = "updated"
+	Unchanged Action = "unchanged"
+)
+
+// Step is the action taken on one object.
+type Step struct {
+	Ref    geoserver.Ref
+	Action Action
+
+	// Fields lists the fields that differed, for an [Updated] object.
+	Fields []string
+}
+
+// String renders s as "created datastore topp:pg".
+func (s Step) String() string {
+	out := string(s.Action) + " " + s.Ref.String()
+	if len(s.Fields) > 0 {
+		out += " (" + strings.Join(s.Fields, ", ") + ")"
+	}
+	return out
+}
+
+// Result is the outcome of a publish call.
+type Result struct {
+	// Layer is the published layer as the server now has it.
+	Layer *layers.Layer
+
+	// WMS is the workspace WMS endpoint serving Layer. WFS is set for
+	// vector layers and WCS for raster ones.
+	WMS, WFS, WCS string
+
+	// Steps lists the objects visited, parents first.
+	Steps []Step
+}
+
+// Changed reports whether any object was created or updated.
+func (r *Result) Changed() bool {
+	return slices.ContainsFunc(r.Steps, func(s Step) bool { return s.Action != Unchanged })
+}
+
+// PublishPostGISRequest describes a PostGIS table to publish.
+type PublishPostGISRequest struct {
+	// Workspace is created when missing.
+	Workspace string
+
+	// Store names the datastore. Empty takes PostGIS.Name.
+	Store string
+
+	// PostGIS is the store's connection. Parameters that differ from
+	// the live store's are updated; encrypted passwords can't be
+	// compared and are left alone.
+	datastores.PostGIS
+
+	// Table is the native table name.
+	Table string
+
+	// Layer names the feature type and layer. Empty takes Table.
+	Layer string
+
+	// Title and SRS, when set, are applied to the feature type.
+	Title string
+	SRS   string
+
+	// Style, when set, becomes the layer's default style: "name" for a
+	// global style or "ws:name" for a workspace one. It must exist.
+	Style string
+
+	// GWC, when set, is the layer's tile layer configuration. Its Name
+	// defaults to "workspace:layer". Nil leaves tile caching alone.
+	GWC *gwc.LayerConfig
+}
+
+// PostGISTable publishes a PostGIS table: it ensures the workspace,
+// the datastore, the feature type and its layer, the layer's default
+// style and its tile layer.
+func PostGISTable(ctx context.Context, c *geoserver.Client, req PublishPostGISRequest) (*Result, error) {
+	if c == nil {
+		return nil, errors.New("publish: nil client")
+	}
+	store := cmp.Or(req.Store, req.PostGIS.Name)
+	name := cmp.Or(req.Layer, req.Table)
+	switch {
+	case req.Workspace == "":
+		return nil, errors.New("publish: PostGISTable: empty workspace")
+	case store == "":
+		return nil, errors.New("publish: PostGISTable: empty store")
+	case req.Table == "":
+		return nil, errors.New("publish: PostGISTable: empty table")
+	}
+	p := &publisher{c: c, ws: req.Workspace}
+	conn := req.PostGIS
+	conn.Name = store
+	steps := []func(context.Context) error{
+		p.workspace,
+		func(ctx context.Context) error { return p.datastore(ctx, conn) },
+		func(ctx context.Context) error {
+			want := &featuretypes.FeatureType{Name: name, NativeName: req.Table, Title: req.Title, SRS: req.SRS, Enabled: true}
+			return p.featureType(ctx, store, want, true)
+		},
+		func(ctx context.Context) error { return p.layer(ctx, name, req.Style, req.GWC) },
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return nil, fmt.Errorf("publish: %w", err)
+		}
+	}
+	return p.finish(true)
+}
+
+// publisher carries one publish call.
+type publisher struct {
+	c   *geoserver.Client
+	ws  string
+	res Result
+
+	// created is set once the resource under the layer was created,
+	// which creates the layer with it.
+	created bool
+}
+
+func (p *publisher) step(ref geoserver.Ref, action Action, fields []string) {
+	p.res.Steps = append(p.res.Steps, Step{Ref: ref, Action: action, Fields: fields})
+}
+
+func (p *publisher) workspace(ctx context.Context) error {
+	ref := geoserver.Ref{Kind: geoserver.KindWorkspace, Name: p.ws}
+	_, err := p.c.Workspaces.Get(ctx, p.ws)
+	if errors.Is(err, geoserver.ErrNotFound) {
+		if err := p.c.Workspaces.Create(ctx, &workspaces.Workspace{Name: p.ws}); err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+		p.step(ref, Created, nil)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+	p.step(ref, Unchanged, nil)
+	return nil
+}
+
+func (p *publisher) datastore(ctx context.Context, conn datastores.PostGIS) error {
+	dc := p.c.Datastores.InWorkspace(p.ws)
+	ref := geoserver.Ref{Kind: geoserver.KindDatastore, Workspace: p.ws, Name: conn.Name}
+	got, err := dc.Get(ctx, conn.Name)
+	if errors.Is(err, geoserver.ErrNotFound) {
+		if err := dc.Create(ctx, conn); err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+		p.step(ref, Created, nil)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+	merged, changed := got.ConnectionParameters.Merge(conn.Datastore().ConnectionParameters)
+	if len(changed) == 0 {
+		p.step(ref, Unchanged, nil)
+		return nil
+	}
+	fields := make([]string, len(changed))
+	for i, k := range changed {
+		fields[i] = "connectionParameters." + k
+	}
+	patch := &datastores.Patch{ConnectionParameters: &merged}
+	if err := dc.Update(ctx, conn.Name, patch); err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+	p.step(ref, Updated, fields)
+	return nil
+}
+
+// featureType ensures want in store. create reports whether a missing
+// feature type may be created; uploads configure theirs.
+func (p *publisher) featureType(ctx context.Context, store string, want *featuretypes.FeatureType, create bool) error {
+	fc := p.c.FeatureTypes.InWorkspace(p.ws).InDatastore(store)
+	ref := geoserver.Ref{Kind: geoserver.KindFeatureType, Workspace: p.ws, Store: store, Name: want.Name}
+	got, err := fc.Get(ctx, want.Name)
+	if create && errors.Is(err, geoserver.ErrNotFound) {
+		if err := fc.Create(ctx, want); err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+		p.created = true
+		p.step(ref, Created, nil)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+	return p.update(ref, want, got, func() error { return fc.Update(ctx, want.Name, want) })
+}
+
+// update sends an update when the fields set in want differ from got.
+func (p *publisher) update(ref geoserver.Ref, want, got any, send func() error) error {
+	fields, err := wire.Diff(want, got)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+	fields = slices.DeleteFunc(fields, func(f string) bool { return f == "name" })
+	if len(fields) == 0 {
+		p.step(ref, Unchanged, nil)
+		return nil
+	}
+	if err := send(); err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+	p.step(ref, Updated, fields)
+	return nil
+}
+
+// layer sets the default style of the layer the resource created, and
+// its tile layer.
+func (p *publisher) layer(ctx context.Context, name, style string, tile *gwc.LayerConfig) error {
+	lc := p.c.Layers.InWorkspace(p.ws)
+	ref := geoserver.Ref{Kind: geoserver.KindLayer, Workspace: p.ws, Name: name}
+	l, err := lc.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+	action, fields := Unchanged, []string(nil)
+	if style != "" && (l.DefaultStyle == nil || l.DefaultStyle.Name != style) {
+		if err := lc.Update(ctx, name, &layers.Layer{DefaultStyle: &layers.Ref{Name: style}}); err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+		if l, err = lc.Get(ctx, name); err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+		action, fields = Updated, []string{"defaultStyle"}
+	}
+	if p.created {
+		action, fields = Created, nil
+	}
+	p.step(ref, action, fields)
+	p.res.Layer = l
+	if tile == nil {
+		return nil
+	}
+	return p.tileLayer(ctx, name, *tile)
+}
+
+func (p *publisher) tileLayer(ctx context.Context, name string, want gwc.LayerConfig) error {
+	tc := p.c.GWC.Layers()
+	if want.Name == "" {
+		want.Name = p.ws + ":" + name
+	}
+	want.ID = "" // assigned by the server
+	ref := geoserver.Ref{Kind: geoserver.KindTileLayer, Name: want.Name}
+	got, err := tc.Get(ctx, want.Name)
+	if errors.Is(err, geoserver.ErrNotFound) {
+		if err := tc.Put(ctx, want.Name, &want); err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+		p.step(ref, Created, nil)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+	got.ID, want.XMLName = "", got.XMLName
+	return p.update(ref, want, got, func() error { return tc.Put(ctx, want.Name, &want) })
+}
+
+// finish fills in the service endpoints.
+func (p *publisher) finish(vector bool) (*Result, error) {
+	var err error
+	if p.res.WMS, err = p.c.WMS.InWorkspace(p.ws).Endpoint(); err != nil {
+		return nil, fmt.Errorf("publish: WMS endpoint: %w", err)
+	}
+	if vector {
+		p.res.WFS, err = p.c.WFS.InWorkspace(p.ws).Endpoint()
+	} else {
+		p.res.WCS, err = p.c.WCS.InWorkspace(p.ws).Endpoint()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("publish: service endpoint: %w", err)
+	}
+	return &p.res, nil
+}
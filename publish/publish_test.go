@@ -0,0 +1,162 @@
+package publish_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/publish"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/gwc"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+)
+
+func steps(res *publish.Result) string {
+	var out []string
+	for _, s := range res.Steps {
+		out = append(out, s.String())
+	}
+	return strings.Join(out, "\n")
+}
+
+func TestPostGISTable(t *testing.T) {
+	ctx := context.Background()
+	srv := geoservertest.New(t, geoservertest.Options{})
+	c := srv.Client()
+	srv.Must("style", c.Styles.Create(ctx, &styles.Style{Name: "roads"}))
+	req := publish.PublishPostGISRequest{
+		Workspace: "topp",
+		Store:     "pg",
+		PostGIS:   datastores.PostGIS{Host: "db", Port: 5432, Database: "gis", User: "u", Password: "secret"},
+		Table:     "roads",
+		Title:     "Roads",
+		SRS:       "EPSG:4326",
+		Style:     "roads",
+		GWC:       &gwc.LayerConfig{Enabled: true, MimeFormats: &gwc.MimeFormats{String: []string{"image/png"}}},
+	}
+
+	res, err := publish.PostGISTable(ctx, c, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"created workspace topp",
+		"created datastore topp:pg",
+		"created featuretype topp:pg:roads",
+		"created layer topp:roads",
+		"created tilelayer topp:roads",
+	}, "\n")
+	if got := steps(res); got != want {
+		t.Fatalf("steps:\n%s\nwant:\n%s", got, want)
+	}
+	if res.Layer == nil || res.Layer.Name != "roads" || res.Layer.DefaultStyle == nil || res.Layer.DefaultStyle.Name != "roads" {
+		t.Fatalf("layer = %+v", res.Layer)
+	}
+	wmsURL, _ := c.WMS.InWorkspace("topp").Endpoint()
+	wfsURL, _ := c.WFS.InWorkspace("topp").Endpoint()
+	if res.WMS != wmsURL || res.WFS != wfsURL || res.WCS != "" || !strings.HasSuffix(res.WMS, "/topp/wms") {
+		t.Fatalf("endpoints = %q %q %q", res.WMS, res.WFS, res.WCS)
+	}
+
+	res, err = publish.PostGISTable(ctx, c, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Changed() {
+		t.Fatalf("second call changed the catalog:\n%s", steps(res))
+	}
+
+	req.Host, req.Title = "db2", "Main roads"
+	res, err = publish.PostGISTable(ctx, c, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = strings.Join([]string{
+		"unchanged workspace topp",
+		"updated datastore topp:pg (connectionParameters.host)",
+		"updated featuretype topp:pg:roads (title)",
+		"unchanged layer topp:roads",
+		"unchanged tilelayer topp:roads",
+	}, "\n")
+	if got := steps(res); got != want {
+		t.Fatalf("steps:\n%s\nwant:\n%s", got, want)
+	}
+	ft, err := c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Get(ctx, "roads")
+	if err != nil || ft.Title != "Main roads" {
+		t.Fatalf("feature type = %+v, %v", ft, err)
+	}
+}
+
+func TestShapefile(t *testing.T) {
+	ctx := context.Background()
+	c := geoservertest.New(t, geoservertest.Options{}).Client()
+	req := publish.PublishShapefileRequest{Workspace: "topp", Store: "rivers", Zip: strings.NewReader("zip"), Title: "Rivers"}
+
+	res, err := publish.Shapefile(ctx, c, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"created workspace topp",
+		"created datastore topp:rivers",
+		"created featuretype topp:rivers:rivers",
+		"created layer topp:rivers",
+	}, "\n")
+	if got := steps(res); got != want {
+		t.Fatalf("steps:\n%s\nwant:\n%s", got, want)
+	}
+	if res.WFS == "" || res.WCS != "" {
+		t.Fatalf("endpoints = %q %q", res.WFS, res.WCS)
+	}
+
+	req.Zip = strings.NewReader("zip")
+	if res, err = publish.Shapefile(ctx, c, req); err != nil || res.Changed() {
+		t.Fatalf("second call = %v:\n%s", err, steps(res))
+	}
+	req.Replace = true
+	if res, err = publish.Shapefile(ctx, c, req); err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Steps[1].String(); got != "updated datastore topp:rivers (file)" {
+		t.Fatalf("replace step = %s", got)
+	}
+}
+
+func TestGeoTIFF(t *testing.T) {
+	ctx := context.Background()
+	c := geoservertest.New(t, geoservertest.Options{}).Client()
+	req := publish.PublishGeoTIFFRequest{Workspace: "nurc", Store: "dem", File: strings.NewReader("tiff"), SRS: "EPSG:4326"}
+
+	res, err := publish.GeoTIFF(ctx, c, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := steps(res); !strings.Contains(got, "created coveragestore nurc:dem\ncreated coverage nurc:dem:dem\ncreated layer nurc:dem") {
+		t.Fatalf("steps:\n%s", got)
+	}
+	wcsURL, _ := c.WCS.InWorkspace("nurc").Endpoint()
+	if res.WCS != wcsURL || res.WFS != "" {
+		t.Fatalf("endpoints = %q %q", res.WFS, res.WCS)
+	}
+	if res, err = publish.GeoTIFF(ctx, c, req); err != nil || res.Changed() {
+		t.Fatalf("second call = %v:\n%s", err, steps(res))
+	}
+}
+
+func TestPostGISTable_Validation(t *testing.T) {
+	c := geoservertest.New(t, geoservertest.Options{}).Client()
+	for _, req := range []publish.PublishPostGISRequest{
+		{Store: "pg", Table: "roads"},
+		{Workspace: "topp", Table: "roads"},
+		{Workspace: "topp", Store: "pg"},
+	} {
+		if _, err := publish.PostGISTable(context.Background(), c, req); err == nil {
+			t.Fatalf("%+v: no error", req)
+		}
+	}
+	if _, err := publish.PostGISTable(context.Background(), (*geoserver.Client)(nil), publish.PublishPostGISRequest{}); err == nil {
+		t.Fatal("nil client: no error")
+	}
+}
@@ -0,0 +1,242 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/rest/coverages"
+	"github.com/hishamkaram/geoserver/v2/rest/coveragestores"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/gwc"
+)
+
+// PublishGeoTIFFRequest describes a GeoTIFF to upload and publish.
+type PublishGeoTIFFRequest struct {
+	// Workspace is created when missing.
+	Workspace string
+
+	// Store names the coverage store.
+	Store string
+
+	// File is the GeoTIFF. It is read only when the store is missing
+	// or Replace is set.
+	File io.Reader
+
+	// Replace uploads File into an existing store with
+	// update=overwrite. Without it an existing store is kept as is.
+	Replace bool
+
+	// Layer names the coverage the upload configures. Empty takes the
+	// store's only coverage.
+	Layer string
+
+	// Title and SRS, when set, are applied to the coverage.
+	Title string
+	SRS   string
+
+	// Style, when set, becomes the layer's default style.
+	Style string
+
+	// GWC, when set, is the layer's tile layer configuration. Its Name
+	// defaults to "workspace:layer".
+	GWC *gwc.LayerConfig
+}
+
+// GeoTIFF publishes a GeoTIFF through [coveragestores.WorkspaceClient.UploadFile]:
+// it ensures the workspace, uploads the file when the coverage store
+// is missing (or Replace is set), then brings the coverage, the
+// layer's default style and its tile layer in line.
+func GeoTIFF(ctx context.Context, c *geoserver.Client, req PublishGeoTIFFRequest) (*Result, error) {
+	if c == nil {
+		return nil, errors.New("publish: nil client")
+	}
+	if err := checkUpload("GeoTIFF", req.Workspace, req.Store, req.File); err != nil {
+		return nil, err
+	}
+	p := &publisher{c: c, ws: req.Workspace}
+	if err := p.workspace(ctx); err != nil {
+		return nil, fmt.Errorf("publish: %w", err)
+	}
+
+	sc := c.CoverageStores.InWorkspace(req.Workspace)
+	ref := geoserver.Ref{Kind: geoserver.KindCoverageStore, Workspace: req.Workspace, Name: req.Store}
+	_, err := sc.Get(ctx, req.Store)
+	exists := err == nil
+	if err != nil && !errors.Is(err, geoserver.ErrNotFound) {
+		return nil, fmt.Errorf("publish: %s: %w", ref, err)
+	}
+	if !exists || req.Replace {
+		opts := coveragestores.UploadOptions{Extension: "geotiff", ContentType: "image/tiff"}
+		if exists {
+			opts.Update = "overwrite"
+		}
+		if err := sc.UploadFile(ctx, req.Store, req.File, opts); err != nil {
+			return nil, fmt.Errorf("publish: %s: %w", ref, err)
+		}
+	}
+	p.stored(ref, exists, req.Replace)
+
+	cc := c.Coverages.InWorkspace(req.Workspace).InCoverageStore(req.Store)
+	name := req.Layer
+	if name == "" {
+		list, err := cc.List(ctx, coverages.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("publish: %s: %w", ref, err)
+		}
+		if name, err = only(ref, len(list), "coverage", func() string { return list[0].Name }); err != nil {
+			return nil, err
+		}
+	}
+	covRef := geoserver.Ref{Kind: geoserver.KindCoverage, Workspace: req.Workspace, Store: req.Store, Name: name}
+	got, err := cc.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("publish: %s: %w", covRef, err)
+	}
+	want := &coverages.Coverage{Name: name, Title: req.Title, SRS: req.SRS}
+	if err := p.update(covRef, want, got, func() error { return cc.Update(ctx, name, want) }); err != nil {
+		return nil, fmt.Errorf("publish: %w", err)
+	}
+	p.uploaded()
+	if err := p.layer(ctx, name, req.Style, req.GWC); err != nil {
+		return nil, fmt.Errorf("publish: %w", err)
+	}
+	return p.finish(false)
+}
+
+// PublishShapefileRequest describes a zipped Shapefile to upload and
+// publish.
+type PublishShapefileRequest struct {
+	// Workspace is created when missing.
+	Workspace string
+
+	// Store names the datastore.
+	Store string
+
+	// Zip is the zipped Shapefile with its sidecar files. It is read
+	// only when the store is missing or Replace is set.
+	Zip io.Reader
+
+	// Replace uploads Zip into an existing store with
+	// update=overwrite. Without it an existing store is kept as is.
+	Replace bool
+
+	// Layer names the feature type the upload configures; GeoServer
+	// names it after the .shp file in the archive. Empty takes the
+	// store's only feature type.
+	Layer string
+
+	// Title and SRS, when set, are applied to the feature type.
+	Title string
+	SRS   string
+
+	// Style, when set, becomes the layer's default style.
+	Style string
+
+	// GWC, when set, is the layer's tile layer configuration. Its Name
+	// defaults to "workspace:layer".
+	GWC *gwc.LayerConfig
+}
+
+// Shapefile publishes a zipped Shapefile through
+// [datastores.WorkspaceClient.UploadFile]: it ensures the workspace,
+// uploads the archive when the datastore is missing (or Replace is
+// set), then brings the feature type, the layer's default style and
+// its tile layer in line.
+func Shapefile(ctx context.Context, c *geoserver.Client, req PublishShapefileRequest) (*Result, error) {
+	if c == nil {
+		return nil, errors.New("publish: nil client")
+	}
+	if err := checkUpload("Shapefile", req.Workspace, req.Store, req.Zip); err != nil {
+		return nil, err
+	}
+	p := &publisher{c: c, ws: req.Workspace}
+	if err := p.workspace(ctx); err != nil {
+		return nil, fmt.Errorf("publish: %w", err)
+	}
+
+	dc := c.Datastores.InWorkspace(req.Workspace)
+	ref := geoserver.Ref{Kind: geoserver.KindDatastore, Workspace: req.Workspace, Name: req.Store}
+	_, err := dc.Get(ctx, req.Store)
+	exists := err == nil
+	if err != nil && !errors.Is(err, geoserver.ErrNotFound) {
+		return nil, fmt.Errorf("publish: %s: %w", ref, err)
+	}
+	if !exists || req.Replace {
+		opts := datastores.UploadOptions{Extension: "shp"}
+		if exists {
+			opts.Update = "overwrite"
+		}
+		if err := dc.UploadFile(ctx, req.Store, req.Zip, opts); err != nil {
+			return nil, fmt.Errorf("publish: %s: %w", ref, err)
+		}
+	}
+	p.stored(ref, exists, req.Replace)
+
+	fc := c.FeatureTypes.InWorkspace(req.Workspace).InDatastore(req.Store)
+	name := req.Layer
+	if name == "" {
+		list, err := fc.List(ctx, featuretypes.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("publish: %s: %w", ref, err)
+		}
+		if name, err = only(ref, len(list), "feature type", func() string { return list[0].Name }); err != nil {
+			return nil, err
+		}
+	}
+	want := &featuretypes.FeatureType{Name: name, Title: req.Title, SRS: req.SRS}
+	if err := p.featureType(ctx, req.Store, want, false); err != nil {
+		return nil, fmt.Errorf("publish: %w", err)
+	}
+	p.uploaded()
+	if err := p.layer(ctx, name, req.Style, req.GWC); err != nil {
+		return nil, fmt.Errorf("publish: %w", err)
+	}
+	return p.finish(true)
+}
+
+func checkUpload(op, workspace, store string, body io.Reader) error {
+	switch {
+	case workspace == "":
+		return fmt.Errorf("publish: %s: empty workspace", op)
+	case store == "":
+		return fmt.Errorf("publish: %s: empty store", op)
+	case body == nil:
+		return fmt.Errorf("publish: %s: nil body", op)
+	}
+	return nil
+}
+
+// stored records the step for an uploaded store. An upload into a new
+// store also creates the resource and layer under it.
+func (p *publisher) stored(ref geoserver.Ref, existed, replaced bool) {
+	switch {
+	case !existed:
+		p.created = true
+		p.step(ref, Created, nil)
+	case replaced:
+		p.step(ref, Updated, []string{"file"})
+	default:
+		p.step(ref, Unchanged, nil)
+	}
+}
+
+// uploaded reports the resource step as created when the upload
+// created its store.
+func (p *publisher) uploaded() {
+	if p.created {
+		last := &p.res.Steps[len(p.res.Steps)-1]
+		last.Action, last.Fields = Created, nil
+	}
+}
+
+// only returns the name of a store's single resource.
+func only(store geoserver.Ref, n int, kind string, first func() string) (string, error) {
+	if n != 1 {
+		return "", fmt.Errorf("publish: %s has %d %ss; set Layer to pick one", store, n, kind)
+	}
+	return first(), nil
+}
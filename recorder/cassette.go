@@ -0,0 +1,214 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"unicode/utf8"
+)
+
+// cassetteVersion is written to every cassette and checked on load.
+const cassetteVersion = 1
+
+// Cassette is the on-disk form of a recording: a version stamp and
+// the interactions in the order they were recorded.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	// Op is the client operation that issued the request (e.g.,
+	// "Workspaces.Create"). Informational — replay does not match on
+	// it.
+	Op       string   `json:"op,omitempty"`
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded side of an outgoing request. Only the
+// Content-Type header is kept — Authorization and other credentials
+// never reach the cassette.
+type Request struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Query       string `json:"query,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body
+}
+
+// Response is the recorded server answer.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body
+}
+
+// Body holds a payload as text when it is valid UTF-8, and as base64
+// otherwise (zip uploads, PNG tiles).
+type Body struct {
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+}
+
+func newBody(b []byte) Body {
+	if len(b) == 0 {
+		return Body{}
+	}
+	if utf8.Valid(b) {
+		return Body{Body: string(b)}
+	}
+	return Body{BodyBase64: base64.StdEncoding.EncodeToString(b)}
+}
+
+// Bytes returns the decoded payload.
+func (b Body) Bytes() ([]byte, error) {
+	if b.BodyBase64 != "" {
+		return base64.StdEncoding.DecodeString(b.BodyBase64)
+	}
+	return []byte(b.Body), nil
+}
+
+// droppedResponseHeaders are volatile or session-bound and would only
+// add noise to a golden file.
+var droppedResponseHeaders = []string{"Date", "Set-Cookie", "Content-Length", "Connection", "Keep-Alive"}
+
+// Load reads a cassette written by [Recorder.Save].
+func Load(path string) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+	return decodeCassette(path, raw)
+}
+
+// LoadWith is [Load] for a cassette in another format: unmarshal (for
+// example yaml.Unmarshal) decodes the file into an any, whose keys are
+// the cassette's JSON field names. unmarshal must produce
+// JSON-compatible values — maps keyed by string, slices, strings,
+// numbers, booleans and nil.
+func LoadWith(path string, unmarshal func([]byte, any) error) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+	var doc any
+	if err := unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("recorder: decode %s: %w", path, err)
+	}
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("recorder: decode %s: %w", path, err)
+	}
+	return decodeCassette(path, js)
+}
+
+func decodeCassette(path string, raw []byte) (*Cassette, error) {
+	var c Cassette
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("recorder: decode %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("recorder: %s: unsupported cassette version %d", path, c.Version)
+	}
+	return &c, nil
+}
+
+// save writes c, creating parent directories. A nil marshal writes
+// indented JSON; otherwise marshal encodes c as a generic document.
+func (c *Cassette) save(path string, marshal func(any) ([]byte, error)) error {
+	raw, err := c.encode(marshal)
+	if err != nil {
+		return fmt.Errorf("recorder: encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("recorder: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("recorder: %w", err)
+	}
+	return nil
+}
+
+func (c *Cassette) encode(marshal func(any) ([]byte, error)) ([]byte, error) {
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if marshal == nil {
+		return append(raw, '\n'), nil
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return marshal(doc)
+}
+
+// matchKey is what replay compares: method, path, canonical query and
+// normalized body.
+type matchKey struct {
+	method, path, query, body string
+}
+
+func (k matchKey) String() string {
+	s := k.method + " " + k.path
+	if k.query != "" {
+		s += "?" + k.query
+	}
+	return s
+}
+
+func keyOf(method, path, rawQuery string, body []byte) matchKey {
+	return matchKey{method: method, path: path, query: canonicalQuery(rawQuery), body: normalizeBody(body)}
+}
+
+// canonicalQuery re-encodes a query string with sorted keys so
+// parameter order doesn't matter.
+func canonicalQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	v, err := url.ParseQuery(raw)
+	if err != nil {
+		return raw
+	}
+	return v.Encode()
+}
+
+var xmlGap = regexp.MustCompile(`>\s+<`)
+
+// normalizeBody makes semantically equal bodies compare equal: JSON is
+// re-encoded (sorted keys, no insignificant whitespace), XML has the
+// whitespace between tags collapsed, and anything else is compared
+// with surrounding whitespace trimmed.
+func normalizeBody(b []byte) string {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return ""
+	}
+	if b[0] == '{' || b[0] == '[' {
+		var v any
+		if err := json.Unmarshal(b, &v); err == nil {
+			if out, err := json.Marshal(v); err == nil {
+				return string(out)
+			}
+		}
+	}
+	if b[0] == '<' {
+		return string(xmlGap.ReplaceAll(b, []byte("><")))
+	}
+	return string(b)
+}
+
+// ErrNoMatch is wrapped by the error a replaying [Recorder] returns
+// for a request with no unused recorded interaction.
+var ErrNoMatch = errors.New("recorder: no matching interaction")
@@ -0,0 +1,283 @@
+// Package recorder is an [http.RoundTripper] that records the
+// client's HTTP traffic to a golden cassette file and replays it
+// later, so code built on [*geoserver.Client] can be tested
+// deterministically without a running GeoServer.
+//
+// Record once against a real server, commit the cassette, and replay
+// in CI:
+//
+//	rec, err := recorder.New("testdata/publish.json", recorder.Options{Mode: recorder.ModeRecord})
+//	c, _ := geoserver.New(url, geoserver.WithBasicAuth(u, p), geoserver.WithTransport(rec))
+//	// … exercise c …
+//	err = rec.Save()
+//
+//	rec, err := recorder.New("testdata/publish.json", recorder.Options{}) // ModeReplay
+//	c, _ := geoserver.New("http://geoserver.invalid/geoserver", geoserver.WithTransport(rec))
+//
+// The recorder sits below the client's auth layer, but credentials are
+// never written: only a request's method, path, query, Content-Type
+// and body are kept. Cassettes are JSON unless [Options] supplies
+// another format's Marshal and Unmarshal, so a YAML cassette needs
+// only a YAML library on the caller's side:
+//
+//	rec, err := recorder.New("testdata/publish.yaml", recorder.Options{
+//		Marshal:   yaml.Marshal,
+//		Unmarshal: yaml.Unmarshal,
+//	})
+//
+// Replay matches on method, path, query (parameter order ignored) and
+// normalized body (JSON key order and whitespace ignored, XML
+// inter-tag whitespace ignored). Each recorded interaction is served
+// at most once, in recorded order among equal matches, so a GET
+// recorded before and after a Create replays both states. A request
+// with no unused match fails with [ErrNoMatch]; the error lists the
+// recorded requests for the same method and path to help spot what
+// changed.
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hishamkaram/geoserver/v2/internal/transport"
+)
+
+// Mode selects whether a [Recorder] talks to the network.
+type Mode int
+
+const (
+	// ModeReplay serves responses from an existing cassette and never
+	// touches the network. The zero value.
+	ModeReplay Mode = iota
+	// ModeRecord forwards every request to Options.Base and records
+	// the exchange; call [Recorder.Save] to write the cassette.
+	ModeRecord
+)
+
+// Options configures [New].
+type Options struct {
+	Mode Mode
+
+	// Base is the transport requests are forwarded to in ModeRecord.
+	// Default: [http.DefaultTransport]. Ignored in ModeReplay.
+	Base http.RoundTripper
+
+	// Marshal and Unmarshal read and write the cassette in another
+	// format, e.g. yaml.Marshal and yaml.Unmarshal. They see a generic
+	// document keyed by the cassette's JSON field names; see
+	// [LoadWith]. Default: indented JSON.
+	Marshal   func(any) ([]byte, error)
+	Unmarshal func([]byte, any) error
+}
+
+// Recorder records or replays HTTP exchanges. Plug it into a client
+// with [geoserver.WithTransport]. Safe for concurrent use, though
+// replay order between concurrent requests for the same key is
+// whatever order they arrive in.
+type Recorder struct {
+	path    string
+	mode    Mode
+	base    http.RoundTripper
+	marshal func(any) ([]byte, error)
+
+	mu       sync.Mutex
+	cassette Cassette
+	keys     []matchKey // replay: precomputed per interaction
+	used     []bool
+}
+
+// New returns a Recorder for the cassette at path. In ModeReplay the
+// cassette is loaded immediately and must exist; in ModeRecord it is
+// written by [Recorder.Save].
+func New(path string, opts Options) (*Recorder, error) {
+	if path == "" {
+		return nil, errors.New("recorder: empty cassette path")
+	}
+	r := &Recorder{path: path, mode: opts.Mode, base: opts.Base, marshal: opts.Marshal, cassette: Cassette{Version: cassetteVersion}}
+	switch opts.Mode {
+	case ModeRecord:
+		if r.base == nil {
+			r.base = http.DefaultTransport
+		}
+	case ModeReplay:
+		load := Load
+		if opts.Unmarshal != nil {
+			load = func(path string) (*Cassette, error) { return LoadWith(path, opts.Unmarshal) }
+		}
+		c, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = *c
+		r.keys = make([]matchKey, len(c.Interactions))
+		for i, in := range c.Interactions {
+			body, err := in.Request.Bytes()
+			if err != nil {
+				return nil, fmt.Errorf("recorder: %s: interaction %d: %w", path, i, err)
+			}
+			r.keys[i] = keyOf(in.Request.Method, in.Request.Path, in.Request.Query, body)
+		}
+		r.used = make([]bool, len(c.Interactions))
+	default:
+		return nil, fmt.Errorf("recorder: unknown mode %d", opts.Mode)
+	}
+	return r, nil
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req
+	if body != nil {
+		out = req.Clone(req.Context())
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+	resp, err := r.base.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("recorder: read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	for _, h := range droppedResponseHeaders {
+		header.Del(h)
+	}
+	in := Interaction{
+		Op: transport.OpFromContext(req.Context()),
+		Request: Request{
+			Method:      req.Method,
+			Path:        req.URL.Path,
+			Query:       req.URL.RawQuery,
+			ContentType: req.Header.Get("Content-Type"),
+			Body:        newBody(body),
+		},
+		Response: Response{Status: resp.StatusCode, Header: header, Body: newBody(respBody)},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	key := keyOf(req.Method, req.URL.Path, req.URL.RawQuery, body)
+
+	r.mu.Lock()
+	idx := -1
+	for i, k := range r.keys {
+		if !r.used[i] && k == key {
+			idx = i
+			r.used[i] = true
+			break
+		}
+	}
+	r.mu.Unlock()
+	if idx < 0 {
+		return nil, r.noMatch(req, key)
+	}
+
+	rec := r.cassette.Interactions[idx].Response
+	respBody, err := rec.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("recorder: interaction %d: %w", idx, err)
+	}
+	header := rec.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// noMatch builds the replay miss error, listing recorded interactions
+// for the same method and path.
+func (r *Recorder) noMatch(req *http.Request, key matchKey) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (op %q) in %s", key, transport.OpFromContext(req.Context()), r.path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, k := range r.keys {
+		if k.method != key.method || k.path != key.path {
+			continue
+		}
+		state := "unused"
+		if r.used[i] {
+			state = "already served"
+		}
+		fmt.Fprintf(&b, "\n\tcandidate #%d (%s): %s", i, state, k)
+		if k.body != key.body {
+			b.WriteString(" — body differs")
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNoMatch, b.String())
+}
+
+// Save writes the recorded interactions to the cassette path. Only
+// valid in ModeRecord.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return errors.New("recorder: Save called in replay mode")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.save(r.path, r.marshal)
+}
+
+// Unused returns the interactions a replaying Recorder has not served
+// yet — assert it is empty at the end of a test to catch code paths
+// that stopped issuing a request.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Interaction
+	for i, used := range r.used {
+		if !used {
+			out = append(out, r.cassette.Interactions[i])
+		}
+	}
+	return out
+}
+
+// readRequestBody reads and closes the request body, as any
+// transport would.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("recorder: read request body: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,320 @@
+package recorder_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/recorder"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// catalogServer is a tiny stateful server: one workspace list that
+// POST /rest/workspaces appends to.
+func catalogServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	names := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/workspaces":
+			w.Header().Set("Content-Type", "application/json")
+			if len(names) == 0 {
+				_, _ = io.WriteString(w, `{"workspaces":{"workspace":[]}}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"workspaces":{"workspace":[{"name":"`+strings.Join(names, `"},{"name":"`)+`"}]}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/workspaces":
+			body, _ := io.ReadAll(r.Body)
+			name := strings.Split(strings.Split(string(body), `"name":"`)[1], `"`)[0]
+			names = append(names, name)
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/rest/workspaces/png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte{0x89, 'P', 'N', 'G', 0xff, 0x00})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "No such workspace")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func record(t *testing.T, path string, fn func(c *geoserver.Client)) {
+	t.Helper()
+	srv := catalogServer(t)
+	rec, err := recorder.New(path, recorder.Options{Mode: recorder.ModeRecord})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c, err := geoserver.New(srv.URL, geoserver.WithBasicAuth("admin", "s3cret"), geoserver.WithTransport(rec))
+	if err != nil {
+		t.Fatalf("geoserver.New: %v", err)
+	}
+	fn(c)
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+func replayClient(t *testing.T, path string) (*geoserver.Client, *recorder.Recorder) {
+	t.Helper()
+	rec, err := recorder.New(path, recorder.Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c, err := geoserver.New("http://geoserver.invalid", geoserver.WithTransport(rec))
+	if err != nil {
+		t.Fatalf("geoserver.New: %v", err)
+	}
+	return c, rec
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "workspaces.json")
+	ctx := context.Background()
+	record(t, path, func(c *geoserver.Client) {
+		if ws, err := c.Workspaces.List(ctx, workspaces.ListOptions{}); err != nil || len(ws) != 0 {
+			t.Fatalf("List before = %v, %v", ws, err)
+		}
+		if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if ws, err := c.Workspaces.List(ctx, workspaces.ListOptions{}); err != nil || len(ws) != 1 {
+			t.Fatalf("List after = %v, %v", ws, err)
+		}
+		if _, err := c.Workspaces.Get(ctx, "nope"); !errors.Is(err, geoserver.ErrNotFound) {
+			t.Fatalf("Get = %v", err)
+		}
+	})
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(raw), "s3cret") || strings.Contains(string(raw), "Authorization") {
+		t.Fatalf("cassette leaks credentials:\n%s", raw)
+	}
+	cas, err := recorder.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var ops []string
+	for _, in := range cas.Interactions {
+		ops = append(ops, in.Op)
+	}
+	if got := strings.Join(ops, ","); got != "Workspaces.List,Workspaces.Create,Workspaces.List,Workspaces.Get" {
+		t.Fatalf("ops = %s", got)
+	}
+
+	// Replay serves the recorded states in order, with no network.
+	c, rec := replayClient(t, path)
+	if ws, err := c.Workspaces.List(ctx, workspaces.ListOptions{}); err != nil || len(ws) != 0 {
+		t.Fatalf("replay List before = %v, %v", ws, err)
+	}
+	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"}); err != nil {
+		t.Fatalf("replay Create: %v", err)
+	}
+	if ws, err := c.Workspaces.List(ctx, workspaces.ListOptions{}); err != nil || len(ws) != 1 || ws[0].Name != "topp" {
+		t.Fatalf("replay List after = %v, %v", ws, err)
+	}
+	if len(rec.Unused()) != 1 {
+		t.Fatalf("Unused = %d, want 1", len(rec.Unused()))
+	}
+	if _, err := c.Workspaces.Get(ctx, "nope"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("replay Get = %v, want ErrNotFound", err)
+	}
+	if len(rec.Unused()) != 0 {
+		t.Fatalf("Unused = %+v", rec.Unused())
+	}
+}
+
+func TestCodec(t *testing.T) {
+	// A stand-in for a YAML library: JSON behind a comment line.
+	const header = "# cassette\n"
+	opts := recorder.Options{
+		Marshal: func(v any) ([]byte, error) {
+			raw, err := json.Marshal(v)
+			return append([]byte(header), raw...), err
+		},
+		Unmarshal: func(data []byte, v any) error {
+			return json.Unmarshal(bytes.TrimPrefix(data, []byte(header)), v)
+		},
+	}
+	path := filepath.Join(t.TempDir(), "workspaces.txt")
+	ctx := context.Background()
+
+	srv := catalogServer(t)
+	rec, err := recorder.New(path, recorder.Options{Mode: recorder.ModeRecord, Marshal: opts.Marshal})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c, _ := geoserver.New(srv.URL, geoserver.WithBasicAuth("admin", "s3cret"), geoserver.WithTransport(rec))
+	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if raw, _ := os.ReadFile(path); !strings.HasPrefix(string(raw), header) {
+		t.Fatalf("cassette not written by Marshal:\n%s", raw)
+	}
+	if _, err := recorder.Load(path); err == nil {
+		t.Fatal("Load read a cassette in another format")
+	}
+
+	rec, err = recorder.New(path, recorder.Options{Unmarshal: opts.Unmarshal})
+	if err != nil {
+		t.Fatalf("New replay: %v", err)
+	}
+	c, _ = geoserver.New("http://geoserver.invalid", geoserver.WithTransport(rec))
+	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"}); err != nil {
+		t.Fatalf("replay Create: %v", err)
+	}
+	if len(rec.Unused()) != 0 {
+		t.Fatalf("Unused = %+v", rec.Unused())
+	}
+}
+
+func TestReplay_Unmatched(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	ctx := context.Background()
+	record(t, path, func(c *geoserver.Client) {
+		_ = c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"})
+	})
+
+	c, _ := replayClient(t, path)
+	err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "other"})
+	if !errors.Is(err, recorder.ErrNoMatch) {
+		t.Fatalf("err = %v, want ErrNoMatch", err)
+	}
+	for _, want := range []string{"POST /rest/workspaces", `op "Workspaces.Create"`, "body differs"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+
+	// An interaction is served once only.
+	if err := c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	err = c.Workspaces.Create(ctx, &workspaces.Workspace{Name: "topp"})
+	if !errors.Is(err, recorder.ErrNoMatch) || !strings.Contains(err.Error(), "already served") {
+		t.Fatalf("second Create = %v", err)
+	}
+}
+
+func TestReplay_NormalizesQueryAndBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	cassette := `{
+  "version": 1,
+  "interactions": [
+    {
+      "request": {"method": "PUT", "path": "/rest/x", "query": "b=2&a=1", "body": "{\"b\": [1, 2], \"a\": {\"y\": 1, \"x\": 2}}"},
+      "response": {"status": 200, "body": "ok"}
+    },
+    {
+      "request": {"method": "POST", "path": "/ows", "body": "<a>\n  <b>1</b>\n</a>"},
+      "response": {"status": 200, "bodyBase64": "iVBORw=="}
+    }
+  ]
+}`
+	if err := os.WriteFile(path, []byte(cassette), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := recorder.New(path, recorder.Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, "http://h/rest/x?a=1&b=2", strings.NewReader(`{"a":{"x":2,"y":1},"b":[1,2]}`))
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("PUT: %v", err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+		t.Fatalf("body = %q", body)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, "http://h/ows", strings.NewReader(`<a><b>1</b></a>`))
+	resp, err = rec.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "\x89PNG" {
+		t.Fatalf("body = %q", body)
+	}
+}
+
+func TestBinaryBodyRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	srv := catalogServer(t)
+	rec, err := recorder.New(path, recorder.Options{Mode: recorder.ModeRecord})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/rest/workspaces/png", nil)
+	req.SetBasicAuth("u", "p")
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, _ := io.ReadAll(resp.Body)
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := recorder.New(path, recorder.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ = http.NewRequest(http.MethodGet, "http://other/rest/workspaces/png", nil)
+	resp, err = replay.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	if string(got) != string(live) || resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("replayed %q (%s), want %q", got, resp.Header.Get("Content-Type"), live)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := recorder.New("", recorder.Options{}); err == nil {
+		t.Error("empty path: want error")
+	}
+	if _, err := recorder.New(filepath.Join(dir, "missing.json"), recorder.Options{}); err == nil {
+		t.Error("missing cassette: want error")
+	}
+	bad := filepath.Join(dir, "v9.json")
+	_ = os.WriteFile(bad, []byte(`{"version":9,"interactions":[]}`), 0o644)
+	if _, err := recorder.New(bad, recorder.Options{}); err == nil || !strings.Contains(err.Error(), "version 9") {
+		t.Errorf("bad version: err = %v", err)
+	}
+	if _, err := recorder.New(filepath.Join(dir, "new.json"), recorder.Options{Mode: recorder.ModeRecord}); err != nil {
+		t.Errorf("record mode must not require an existing cassette: %v", err)
+	}
+	empty := filepath.Join(dir, "empty.json")
+	_ = os.WriteFile(empty, []byte(`{"version":1,"interactions":[]}`), 0o644)
+	replay, err := recorder.New(empty, recorder.Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := replay.Save(); err == nil {
+		t.Error("Save in replay mode: want error")
+	}
+}
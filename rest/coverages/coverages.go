@@ -8,6 +8,8 @@ import (
 	"iter"
 	"net/http"
 	"strconv"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // Core is the plumbing the sub-client needs from the parent [*Client].
@@ -184,6 +186,32 @@ func (c *CoverageStoreClient) Update(ctx context.Context, name string, cov *Cove
 	return c.core.Do(ctx, op, http.MethodPut, u, body, nil, nil)
 }
 
+// Ensure creates cov when no coverage by its name exists in the scoped
+// coverage store, and otherwise leaves the existing one alone.
+func (c *CoverageStoreClient) Ensure(ctx context.Context, cov *Coverage) (Outcome, error) {
+	if cov == nil {
+		return Unchanged, errors.New("Coverages.Ensure: nil coverage")
+	}
+	return wire.Ensure(
+		func() (*Coverage, error) { return c.Get(ctx, cov.Name) },
+		func() error { return c.Create(ctx, cov) },
+	)
+}
+
+// Upsert creates cov when it is missing, and otherwise updates the
+// existing coverage if it differs. Only the fields cov sets are
+// compared; hrefs, dates and other server-populated values are ignored.
+func (c *CoverageStoreClient) Upsert(ctx context.Context, cov *Coverage) (Outcome, error) {
+	if cov == nil {
+		return Unchanged, errors.New("Coverages.Upsert: nil coverage")
+	}
+	return wire.Upsert(cov,
+		func() (*Coverage, error) { return c.Get(ctx, cov.Name) },
+		func() error { return c.Create(ctx, cov) },
+		func(*Coverage) error { return c.Update(ctx, cov.Name, cov) },
+	)
+}
+
 // Delete removes a coverage. With opts.Recurse=true, also removes the
 // layer that exposes it.
 func (c *CoverageStoreClient) Delete(ctx context.Context, name string, opts DeleteOptions) error {
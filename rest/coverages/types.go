@@ -121,3 +121,13 @@ type discoverResponse struct {
 		String []string `json:"string"`
 	} `json:"list"`
 }
+
+// Outcome reports what Ensure or Upsert did — see [wire.Outcome].
+type Outcome = wire.Outcome
+
+// Outcomes of Ensure and Upsert.
+const (
+	Unchanged = wire.Unchanged
+	Created   = wire.Created
+	Updated   = wire.Updated
+)
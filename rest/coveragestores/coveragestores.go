@@ -8,6 +8,8 @@ import (
 	"iter"
 	"net/http"
 	"strconv"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // Core is the plumbing the sub-client needs from the parent [*Client].
@@ -255,6 +257,33 @@ func (c *WorkspaceClient) putFile(ctx context.Context, op, httpMethod, name stri
 	return c.core.DoRaw(ctx, op, httpMethod, u, body, contentType, "*/*", query)
 }
 
+// Ensure creates store when no coverage store by its name exists in the
+// scoped workspace, and otherwise leaves the existing one alone.
+func (c *WorkspaceClient) Ensure(ctx context.Context, store *CoverageStore) (Outcome, error) {
+	if store == nil {
+		return Unchanged, errors.New("CoverageStores.Ensure: nil coverage store")
+	}
+	return wire.Ensure(
+		func() (*CoverageStore, error) { return c.Get(ctx, store.Name) },
+		func() error { return c.Create(ctx, store) },
+	)
+}
+
+// Upsert creates store when it is missing, and otherwise updates the
+// existing coverage store if it differs. Only the fields store sets
+// are compared and patched; hrefs and other server-populated values
+// are ignored.
+func (c *WorkspaceClient) Upsert(ctx context.Context, store *CoverageStore) (Outcome, error) {
+	if store == nil {
+		return Unchanged, errors.New("CoverageStores.Upsert: nil coverage store")
+	}
+	return wire.Upsert(store,
+		func() (*CoverageStore, error) { return c.Get(ctx, store.Name) },
+		func() error { return c.Create(ctx, store) },
+		func(*CoverageStore) error { return c.Update(ctx, store.Name, store.patch()) },
+	)
+}
+
 // Delete removes a coverage store. With opts.Recurse=true, also removes
 // all configured coverages and the layers that expose them.
 func (c *WorkspaceClient) Delete(ctx context.Context, name string, opts DeleteOptions) error {
@@ -5,6 +5,8 @@
 // and individual coverages live inside the store.
 package coveragestores
 
+import "github.com/hishamkaram/geoserver/v2/internal/wire"
+
 // CoverageStore is the GeoServer coverage-store document. The same
 // shape is used for read and write paths.
 //
@@ -39,6 +41,24 @@ type Patch struct {
 	Enabled     *bool   `json:"enabled,omitempty"`
 }
 
+// patch returns the fields s sets as a [Patch].
+func (s *CoverageStore) patch() *Patch {
+	p := &Patch{}
+	if s.URL != "" {
+		p.URL = &s.URL
+	}
+	if s.Description != "" {
+		p.Description = &s.Description
+	}
+	if s.Type != "" {
+		p.Type = &s.Type
+	}
+	if s.Enabled {
+		p.Enabled = &s.Enabled
+	}
+	return p
+}
+
 // ListOptions controls listing behavior. Currently empty; the
 // underlying endpoint does not paginate. Reserved for future fields.
 type ListOptions struct{}
@@ -111,3 +131,13 @@ type detailResponse struct {
 type createRequest struct {
 	CoverageStore CoverageStore `json:"coverageStore"`
 }
+
+// Outcome reports what Ensure or Upsert did — see [wire.Outcome].
+type Outcome = wire.Outcome
+
+// Outcomes of Ensure and Upsert.
+const (
+	Unchanged = wire.Unchanged
+	Created   = wire.Created
+	Updated   = wire.Updated
+)
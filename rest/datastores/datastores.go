@@ -9,6 +9,8 @@ import (
 	"iter"
 	"net/http"
 	"strconv"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // Core is the plumbing the sub-client needs from the parent [*Client].
@@ -277,6 +279,44 @@ func (c *WorkspaceClient) UploadFile(ctx context.Context, name string, body io.R
 	return c.core.DoRaw(ctx, op, http.MethodPut, u, body, contentType, "*/*", query)
 }
 
+// Ensure creates the datastore conn describes when no datastore by its
+// name exists in the scoped workspace, and otherwise leaves the
+// existing one alone.
+func (c *WorkspaceClient) Ensure(ctx context.Context, conn Connector) (Outcome, error) {
+	if conn == nil {
+		return Unchanged, errors.New("Datastores.Ensure: nil connector")
+	}
+	return wire.Ensure(
+		func() (*Datastore, error) { return c.Get(ctx, conn.Datastore().Name) },
+		func() error { return c.Create(ctx, conn) },
+	)
+}
+
+// Upsert creates the datastore conn describes when it is missing, and
+// otherwise updates the existing one if it differs. Only the fields
+// and connection parameters conn sets are compared; parameters the
+// server stores encrypted (such as passwords) can't be compared and
+// count as equal. An update sends conn's parameters merged into the
+// live ones with [ConnectionParameters.Merge].
+func (c *WorkspaceClient) Upsert(ctx context.Context, conn Connector) (Outcome, error) {
+	if conn == nil {
+		return Unchanged, errors.New("Datastores.Upsert: nil connector")
+	}
+	want := conn.Datastore()
+	return wire.Upsert(want,
+		func() (*Datastore, error) { return c.Get(ctx, want.Name) },
+		func() error { return c.Create(ctx, conn) },
+		func(live *Datastore) error {
+			params, _ := live.ConnectionParameters.Merge(want.ConnectionParameters)
+			patch := &Patch{ConnectionParameters: &params}
+			if want.Enabled {
+				patch.Enabled = &want.Enabled
+			}
+			return c.Update(ctx, want.Name, patch)
+		},
+	)
+}
+
 // Delete removes a datastore. With opts.Recurse=true, also removes all
 // contained feature types and layers (a non-empty datastore is rejected
 // without Recurse).
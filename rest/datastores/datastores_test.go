@@ -134,6 +134,33 @@ func TestRaw_Datastore(t *testing.T) {
 	}
 }
 
+func TestConnectionParameters_Merge(t *testing.T) {
+	live := datastores.ConnectionParameters{Entry: []datastores.ConnectionEntry{
+		{Key: "host", Value: "db"},
+		{Key: "passwd", Value: "crypt1:abc"},
+		{Key: "schema", Value: "public"},
+	}}
+	merged, changed := live.Merge(datastores.ConnectionParameters{Entry: []datastores.ConnectionEntry{
+		{Key: "host", Value: "db"},
+		{Key: "passwd", Value: "secret"},
+		{Key: "schema", Value: "roads"},
+		{Key: "port", Value: "5432"},
+	}})
+	if got := strings.Join(changed, ","); got != "schema,port" {
+		t.Fatalf("changed = %s", got)
+	}
+	var got []string
+	for _, e := range merged.Entry {
+		got = append(got, e.Key+"="+e.Value)
+	}
+	if s := strings.Join(got, " "); s != "host=db passwd=secret schema=roads port=5432" {
+		t.Fatalf("merged = %s", s)
+	}
+	if live.Entry[2].Value != "public" {
+		t.Fatalf("Merge modified its receiver: %+v", live.Entry)
+	}
+}
+
 // ---- HTTP CRUD tests ----
 
 func TestList_OK(t *testing.T) {
@@ -4,7 +4,13 @@
 // returned by [Client.InWorkspace].
 package datastores
 
-import "strconv"
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
+)
 
 // Datastore is the GeoServer datastore document. The same shape is used
 // for list items (where only Name is populated by the server) and for
@@ -45,6 +51,30 @@ type ConnectionEntry struct {
 	Value string `json:"$"`
 }
 
+// Merge returns p with the entries of want laid over it: a key p
+// already has takes want's value, and the others are appended.
+// GeoServer replaces the whole block on PUT, so changing some
+// parameters means sending all of them. changed lists the keys of want
+// whose value differs from p's; a value p holds encrypted ("crypt1:…")
+// can't be compared and counts as equal.
+func (p ConnectionParameters) Merge(want ConnectionParameters) (merged ConnectionParameters, changed []string) {
+	out := slices.Clone(p.Entry)
+	for _, e := range want.Entry {
+		i := slices.IndexFunc(out, func(m ConnectionEntry) bool { return m.Key == e.Key })
+		switch {
+		case i < 0:
+			out = append(out, e)
+			changed = append(changed, e.Key)
+		case out[i].Value != e.Value && !strings.HasPrefix(out[i].Value, "crypt1:"):
+			changed = append(changed, e.Key)
+			fallthrough
+		default:
+			out[i].Value = e.Value
+		}
+	}
+	return ConnectionParameters{Entry: out}, changed
+}
+
 // Patch is a partial-update payload for [WorkspaceClient.Update].
 // Pointer fields let callers distinguish "field absent" from "field
 // set to false / empty string". GeoServer treats PUT as a merge-patch.
@@ -52,7 +82,8 @@ type ConnectionEntry struct {
 // Note on ConnectionParameters: GeoServer replaces the entire
 // `connectionParameters` block on PUT — it does not merge entries. To
 // change a single parameter, fetch the full document with [WorkspaceClient.Get],
-// mutate the entries you need, and PUT the whole block back.
+// lay the entries you need over it with [ConnectionParameters.Merge],
+// and PUT the whole block back.
 type Patch struct {
 	Enabled              *bool                 `json:"enabled,omitempty"`
 	ConnectionParameters *ConnectionParameters `json:"connectionParameters,omitempty"`
@@ -211,3 +242,13 @@ type detailResponse struct {
 type createRequest struct {
 	DataStore Datastore `json:"dataStore"`
 }
+
+// Outcome reports what Ensure or Upsert did — see [wire.Outcome].
+type Outcome = wire.Outcome
+
+// Outcomes of Ensure and Upsert.
+const (
+	Unchanged = wire.Unchanged
+	Created   = wire.Created
+	Updated   = wire.Updated
+)
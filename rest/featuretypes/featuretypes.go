@@ -8,6 +8,8 @@ import (
 	"iter"
 	"net/http"
 	"strconv"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // Core is the plumbing the sub-client needs from the parent [*Client].
@@ -189,6 +191,32 @@ func (c *DatastoreClient) Update(ctx context.Context, name string, ft *FeatureTy
 	return c.core.Do(ctx, op, http.MethodPut, u, body, nil, nil)
 }
 
+// Ensure creates ft when no feature type by its name exists in the scoped
+// datastore, and otherwise leaves the existing one alone.
+func (c *DatastoreClient) Ensure(ctx context.Context, ft *FeatureType) (Outcome, error) {
+	if ft == nil {
+		return Unchanged, errors.New("FeatureTypes.Ensure: nil feature type")
+	}
+	return wire.Ensure(
+		func() (*FeatureType, error) { return c.Get(ctx, ft.Name) },
+		func() error { return c.Create(ctx, ft) },
+	)
+}
+
+// Upsert creates ft when it is missing, and otherwise updates the
+// existing feature type if it differs. Only the fields ft sets are
+// compared; hrefs, dates and other server-populated values are ignored.
+func (c *DatastoreClient) Upsert(ctx context.Context, ft *FeatureType) (Outcome, error) {
+	if ft == nil {
+		return Unchanged, errors.New("FeatureTypes.Upsert: nil feature type")
+	}
+	return wire.Upsert(ft,
+		func() (*FeatureType, error) { return c.Get(ctx, ft.Name) },
+		func() error { return c.Create(ctx, ft) },
+		func(*FeatureType) error { return c.Update(ctx, ft.Name, ft) },
+	)
+}
+
 // Delete removes a feature type. With opts.Recurse=true, also removes
 // the layer that exposes it; without Recurse a feature type with a
 // referencing layer is rejected.
@@ -424,3 +424,49 @@ func TestErrorMapping(t *testing.T) {
 		t.Fatalf("err = %v, want ErrNotFound", err)
 	}
 }
+
+// ===== Base URL routing =====
+
+func TestGWCBaseURL(t *testing.T) {
+	rest := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("GWC request hit the REST base: %s", r.URL.Path)
+	}))
+	defer rest.Close()
+
+	var paths []string
+	gwcSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/layers.json"):
+			_, _ = io.WriteString(w, `["topp:states"]`)
+		case strings.HasSuffix(r.URL.Path, "/gridsets.json"):
+			_, _ = io.WriteString(w, `["EPSG:4326"]`)
+		default:
+			_, _ = io.WriteString(w, `{"global":{}}`)
+		}
+	}))
+	defer gwcSrv.Close()
+
+	c, err := geoserver.New(rest.URL+"/geoserver", geoserver.WithGWCBaseURL(gwcSrv.URL+"/geoserver-cloud"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.GWC.Layers().List(ctx); err != nil {
+		t.Fatalf("Layers.List: %v", err)
+	}
+	if _, err := c.GWC.Gridsets().List(ctx); err != nil {
+		t.Fatalf("Gridsets.List: %v", err)
+	}
+	if _, err := c.GWC.Global().Get(ctx); err != nil {
+		t.Fatalf("Global.Get: %v", err)
+	}
+	want := []string{
+		"/geoserver-cloud/gwc/rest/layers.json",
+		"/geoserver-cloud/gwc/rest/gridsets.json",
+		"/geoserver-cloud/gwc/rest/global.json",
+	}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+}
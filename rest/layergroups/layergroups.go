@@ -7,6 +7,8 @@ import (
 	"io"
 	"iter"
 	"net/http"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // Core is the plumbing the sub-client needs from the parent [*Client].
@@ -139,6 +141,32 @@ func (c *WorkspaceClient) Update(ctx context.Context, name string, group *LayerG
 	return c.core.Do(ctx, op, http.MethodPut, u, body, nil, nil)
 }
 
+// Ensure creates group when no layer group by its name exists in the scoped
+// workspace, and otherwise leaves the existing one alone.
+func (c *WorkspaceClient) Ensure(ctx context.Context, group *LayerGroup) (Outcome, error) {
+	if group == nil {
+		return Unchanged, errors.New("LayerGroups.Ensure: nil layer group")
+	}
+	return wire.Ensure(
+		func() (*LayerGroup, error) { return c.Get(ctx, group.Name) },
+		func() error { return c.Create(ctx, group) },
+	)
+}
+
+// Upsert creates group when it is missing, and otherwise updates the
+// existing layer group if it differs. Only the fields group sets are
+// compared; hrefs, dates and other server-populated values are ignored.
+func (c *WorkspaceClient) Upsert(ctx context.Context, group *LayerGroup) (Outcome, error) {
+	if group == nil {
+		return Unchanged, errors.New("LayerGroups.Upsert: nil layer group")
+	}
+	return wire.Upsert(group,
+		func() (*LayerGroup, error) { return c.Get(ctx, group.Name) },
+		func() error { return c.Create(ctx, group) },
+		func(*LayerGroup) error { return c.Update(ctx, group.Name, group) },
+	)
+}
+
 // Delete removes a layer group. Note: GeoServer does not support
 // `?recurse=` on layer-group delete — the underlying layers are not
 // affected; only the group reference is removed.
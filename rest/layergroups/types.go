@@ -8,6 +8,8 @@ package layergroups
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // LayerGroup is the GeoServer layer-group document.
@@ -217,3 +219,13 @@ type detailResponse struct {
 type createRequest struct {
 	LayerGroup LayerGroup `json:"layerGroup"`
 }
+
+// Outcome reports what Ensure or Upsert did — see [wire.Outcome].
+type Outcome = wire.Outcome
+
+// Outcomes of Ensure and Upsert.
+const (
+	Unchanged = wire.Unchanged
+	Created   = wire.Created
+	Updated   = wire.Updated
+)
@@ -144,6 +144,30 @@ func (c *WorkspaceClient) Update(ctx context.Context, name string, layer *Layer)
 	return c.core.Do(ctx, op, http.MethodPut, u, body, nil, nil)
 }
 
+// Patch modifies a layer by sending exactly fields as its document,
+// keyed by wire name: {"queryable": false, "enabled": false}. Unlike
+// [WorkspaceClient.Update], false, zero and empty values reach the
+// server, so it is the way to clear a boolean [Layer] field. Omitted
+// fields keep their current values.
+func (c *WorkspaceClient) Patch(ctx context.Context, name string, fields map[string]any) error {
+	const op = "Layers.Patch"
+	if c.workspace == "" {
+		return errors.New(op + ": empty workspace name")
+	}
+	if name == "" {
+		return errors.New(op + ": empty name")
+	}
+	if len(fields) == 0 {
+		return errors.New(op + ": no fields")
+	}
+	u, err := c.core.URL("rest", "workspaces", c.workspace, "layers", name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	body := map[string]any{"layer": fields}
+	return c.core.Do(ctx, op, http.MethodPut, u, body, nil, nil)
+}
+
 // ListStyles returns the layer's alternative-style list — the styles
 // callable through WMS `?styles=<name>` beyond the layer's default
 // style. The default style is exposed separately on
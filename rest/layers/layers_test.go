@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -172,6 +173,29 @@ func TestUpdate_OK(t *testing.T) {
 	}
 }
 
+func TestPatch_SendsFalse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPut || r.URL.Path != "/rest/workspaces/topp/layers/states" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		if got := strings.TrimSpace(string(body)); got != `{"layer":{"opaque":false,"queryable":false}}` {
+			t.Errorf("body = %s", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv)
+	lc := c.Layers.InWorkspace("topp")
+	if err := lc.Patch(context.Background(), "states", map[string]any{"queryable": false, "opaque": false}); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	if err := lc.Patch(context.Background(), "states", nil); err == nil {
+		t.Fatal("empty patch accepted")
+	}
+}
+
 func TestUpdate_NilLayer(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
 		t.Errorf("server should not be hit; got %s %s", r.Method, r.URL.Path)
@@ -379,3 +403,54 @@ func TestGet_URLEscaping(t *testing.T) {
 		t.Fatalf("URL is double-encoded: %q", capturedURI)
 	}
 }
+
+// TestList_MultiMegabyte is a regression guard for the former 8 KiB
+// success-body cap: a production-sized layer list must decode in full.
+func TestList_MultiMegabyte(t *testing.T) {
+	const n = 30000
+	var b strings.Builder
+	b.WriteString(`{"layers":{"layer":[`)
+	for i := range n {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"name":"layer_%05d","href":"http://localhost:8080/geoserver/rest/workspaces/topp/layers/layer_%05d.json"}`, i, i)
+	}
+	b.WriteString(`]}}`)
+	payload := b.String()
+	if len(payload) < 2<<20 {
+		t.Fatalf("fixture only %d bytes; want multi-megabyte", len(payload))
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, payload)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv)
+	got, err := c.Layers.InWorkspace("topp").List(context.Background(), layers.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != n || got[n-1].Name != fmt.Sprintf("layer_%05d", n-1) {
+		t.Fatalf("List returned %d layers (last %+v)", len(got), got[len(got)-1])
+	}
+}
+
+func TestList_MaxResponseBytesExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"layers":{"layer":[`+strings.Repeat(`{"name":"states"},`, 200)+`{"name":"last"}]}}`)
+	}))
+	defer srv.Close()
+
+	c, err := geoserver.New(srv.URL, geoserver.WithMaxResponseBytes(1024))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_, err = c.Layers.InWorkspace("topp").List(context.Background(), layers.ListOptions{})
+	if !errors.Is(err, geoserver.ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
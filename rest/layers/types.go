@@ -20,6 +20,8 @@ package layers
 // Resource is a reference back to the underlying feature type or
 // coverage; DefaultStyle is the WMS rendering style; Styles is the set
 // of alternative styles GeoServer will offer through `?styles=`.
+// Enabled, like the other booleans, is omitted when false, so clearing
+// it takes [WorkspaceClient.Patch].
 type Layer struct {
 	Name         string       `json:"name,omitempty"`
 	Path         string       `json:"path,omitempty"`
@@ -29,6 +31,7 @@ type Layer struct {
 	Resource     *Ref         `json:"resource,omitempty"`
 	Queryable    bool         `json:"queryable,omitempty"`
 	Opaque       bool         `json:"opaque,omitempty"`
+	Enabled      bool         `json:"enabled,omitempty"`
 	Attribution  *Attribution `json:"attribution,omitempty"`
 }
 
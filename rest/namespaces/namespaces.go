@@ -7,6 +7,8 @@ import (
 	"io"
 	"iter"
 	"net/http"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // Core is the plumbing the sub-client needs from the parent [*Client].
@@ -126,6 +128,42 @@ func (c *Client) Update(ctx context.Context, prefix string, patch *Patch) error
 	return c.core.Do(ctx, op, http.MethodPut, u, body, nil, nil)
 }
 
+// Ensure creates ns when no namespace by its prefix exists, and
+// otherwise leaves the existing one alone.
+func (c *Client) Ensure(ctx context.Context, ns *Namespace) (Outcome, error) {
+	if ns == nil {
+		return Unchanged, errors.New("Namespaces.Ensure: nil namespace")
+	}
+	return wire.Ensure(
+		func() (*Namespace, error) { return c.Get(ctx, ns.Prefix) },
+		func() error { return c.Create(ctx, ns) },
+	)
+}
+
+// Upsert creates ns when it is missing, and otherwise updates the
+// existing namespace if its URI or Isolated flag differs. Only the
+// fields ns sets are compared, so Isolated=false never clears the
+// flag; use [Client.Update] for that.
+func (c *Client) Upsert(ctx context.Context, ns *Namespace) (Outcome, error) {
+	if ns == nil {
+		return Unchanged, errors.New("Namespaces.Upsert: nil namespace")
+	}
+	return wire.Upsert(ns,
+		func() (*Namespace, error) { return c.Get(ctx, ns.Prefix) },
+		func() error { return c.Create(ctx, ns) },
+		func(*Namespace) error {
+			patch := &Patch{}
+			if ns.URI != "" {
+				patch.URI = &ns.URI
+			}
+			if ns.Isolated {
+				patch.Isolated = &ns.Isolated
+			}
+			return c.Update(ctx, ns.Prefix, patch)
+		},
+	)
+}
+
 // Delete removes a namespace. The associated workspace is also
 // deleted by GeoServer.
 func (c *Client) Delete(ctx context.Context, prefix string) error {
@@ -5,7 +5,11 @@
 // in WFS / GML output.
 package namespaces
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
+)
 
 // Namespace is the GeoServer namespace document.
 //
@@ -71,3 +75,13 @@ type detailResponse struct {
 type createRequest struct {
 	Namespace Namespace `json:"namespace"`
 }
+
+// Outcome reports what Ensure or Upsert did — see [wire.Outcome].
+type Outcome = wire.Outcome
+
+// Outcomes of Ensure and Upsert.
+const (
+	Unchanged = wire.Unchanged
+	Created   = wire.Created
+	Updated   = wire.Updated
+)
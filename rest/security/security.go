@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 )
 
 // Core is the plumbing the sub-client needs from the parent [*Client].
@@ -135,9 +136,8 @@ func (c *UsersClient) List(ctx context.Context, _ ListOptions) ([]User, error) {
 }
 
 // Create registers a new user in the scoped service. user.Name and
-// user.Password are required; user.Enabled defaults to true if the
-// caller doesn't set it (use a deliberately-disabled user via the
-// modify path once GeoServer supports an Update method).
+// user.Password are required. Enabled is always sent, so set it to
+// true for a user who should be able to log in.
 func (c *UsersClient) Create(ctx context.Context, user *User) error {
 	const op = "Security.Users.Create"
 	if user == nil {
@@ -157,6 +157,84 @@ func (c *UsersClient) Create(ctx context.Context, user *User) error {
 	return c.core.Do(ctx, op, http.MethodPost, u, body, nil, nil)
 }
 
+// Update modifies the named user. Enabled is always sent; Password is
+// changed only when non-empty.
+func (c *UsersClient) Update(ctx context.Context, name string, user *User) error {
+	const op = "Security.Users.Update"
+	if name == "" {
+		return errors.New(op + ": empty name")
+	}
+	if user == nil {
+		return errors.New(op + ": nil user")
+	}
+	u, err := c.core.URL("rest", "security", "usergroup", "service", c.service, "user", name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	body := userCreateRequest{User: *user}
+	body.User.Name = name
+	return c.core.Do(ctx, op, http.MethodPost, u, body, nil, nil)
+}
+
+// Ensure creates user when the scoped service has no user by that
+// name, and otherwise leaves the existing one alone.
+func (c *UsersClient) Ensure(ctx context.Context, user *User) (Outcome, error) {
+	if user == nil {
+		return Unchanged, errors.New("Security.Users.Ensure: nil user")
+	}
+	live, err := c.find(ctx, user.Name)
+	if err != nil || live != nil {
+		return Unchanged, err
+	}
+	if err := c.Create(ctx, user); err != nil {
+		return Unchanged, err
+	}
+	return Created, nil
+}
+
+// Upsert creates user when it is missing and updates an existing user
+// whose Enabled flag differs. GeoServer never returns passwords, so
+// Password is sent on create but not compared: an existing user keeps
+// theirs. Rotate it with [UsersClient.Update].
+func (c *UsersClient) Upsert(ctx context.Context, user *User) (Outcome, error) {
+	if user == nil {
+		return Unchanged, errors.New("Security.Users.Upsert: nil user")
+	}
+	live, err := c.find(ctx, user.Name)
+	if err != nil {
+		return Unchanged, err
+	}
+	if live == nil {
+		if err := c.Create(ctx, user); err != nil {
+			return Unchanged, err
+		}
+		return Created, nil
+	}
+	if live.Enabled == user.Enabled {
+		return Unchanged, nil
+	}
+	if err := c.Update(ctx, user.Name, &User{Enabled: user.Enabled}); err != nil {
+		return Unchanged, err
+	}
+	return Updated, nil
+}
+
+// find returns the named user, or nil when the service has none. The
+// REST API has no single-user GET, so it searches the list.
+func (c *UsersClient) find(ctx context.Context, name string) (*User, error) {
+	if name == "" {
+		return nil, errors.New("Security.Users: empty user Name")
+	}
+	users, err := c.List(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if i := slices.IndexFunc(users, func(u User) bool { return u.Name == name }); i >= 0 {
+		return &users[i], nil
+	}
+	return nil, nil
+}
+
 // Delete removes a user from the scoped service.
 func (c *UsersClient) Delete(ctx context.Context, name string) error {
 	const op = "Security.Users.Delete"
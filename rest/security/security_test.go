@@ -154,6 +154,25 @@ func TestUsers_Create_EmptyPassword(t *testing.T) {
 	}
 }
 
+func TestUsers_Update_OK(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/security/usergroup/service/default/user/alice" {
+			t.Errorf("got %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if s := string(body); !strings.Contains(s, `"userName":"alice"`) || !strings.Contains(s, `"enabled":false`) || strings.Contains(s, "password") {
+			t.Errorf("body = %s", s)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv)
+	if err := c.Security.Users().Update(context.Background(), "alice", &security.User{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestUsers_Delete_OK(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete || r.URL.Path != "/rest/security/usergroup/service/default/user/alice" {
@@ -9,6 +9,8 @@
 // [Client.Roles].
 package security
 
+import "github.com/hishamkaram/geoserver/v2/internal/wire"
+
 // User represents a GeoServer user record.
 //
 // Password is write-only — GeoServer does not return the password
@@ -80,3 +82,13 @@ func (r *nameListResponse) names() []string {
 		return nil
 	}
 }
+
+// Outcome reports what Ensure or Upsert did — see [wire.Outcome].
+type Outcome = wire.Outcome
+
+// Outcomes of Ensure and Upsert.
+const (
+	Unchanged = wire.Unchanged
+	Created   = wire.Created
+	Updated   = wire.Updated
+)
@@ -10,6 +10,8 @@ import (
 	"iter"
 	"net/http"
 	"strconv"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // Core is the plumbing the sub-client needs from the parent [*Client].
@@ -202,6 +204,25 @@ func (c *Client) UploadSLD(ctx context.Context, name string, body io.Reader, opt
 	return c.core.DoRaw(ctx, op, http.MethodPut, u, body, contentType, "", nil)
 }
 
+// GetSLD streams the style body in SLD form — the document stored by
+// [Client.UploadSLD] for SLD styles, or GeoServer's SLD translation
+// for other formats. The caller must close the returned reader.
+func (c *Client) GetSLD(ctx context.Context, name string) (io.ReadCloser, error) {
+	const op = "Styles.GetSLD"
+	if name == "" {
+		return nil, errors.New(op + ": empty name")
+	}
+	u, err := c.core.URL(c.urlParts(name)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	body, _, err := c.core.DoStream(ctx, op, http.MethodGet, u+".sld", nil)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
 // Update modifies the style metadata via PUT-as-merge-patch with a
 // JSON body. Use this to rename, change Format / LanguageVersion, or
 // adjust Filename. To replace the SLD content, use [Client.UploadSLD].
@@ -221,6 +242,33 @@ func (c *Client) Update(ctx context.Context, name string, style *Style) error {
 	return c.core.Do(ctx, op, http.MethodPut, u, body, nil, nil)
 }
 
+// Ensure creates style when no style by its name exists in the
+// client's scope, and otherwise leaves the existing one alone.
+func (c *Client) Ensure(ctx context.Context, style *Style) (Outcome, error) {
+	if style == nil {
+		return Unchanged, errors.New("Styles.Ensure: nil style")
+	}
+	return wire.Ensure(
+		func() (*Style, error) { return c.Get(ctx, style.Name) },
+		func() error { return c.Create(ctx, style) },
+	)
+}
+
+// Upsert creates style when it is missing, and otherwise updates the
+// existing style if it differs. Only the fields style sets are
+// compared; hrefs, dates and other server-populated values are ignored.
+// The SLD body is not compared; replace it with [Client.UploadSLD].
+func (c *Client) Upsert(ctx context.Context, style *Style) (Outcome, error) {
+	if style == nil {
+		return Unchanged, errors.New("Styles.Upsert: nil style")
+	}
+	return wire.Upsert(style,
+		func() (*Style, error) { return c.Get(ctx, style.Name) },
+		func() error { return c.Create(ctx, style) },
+		func(*Style) error { return c.Update(ctx, style.Name, style) },
+	)
+}
+
 // Delete removes a style. With opts.Purge=true also removes the
 // on-disk SLD file from the GeoServer data directory.
 func (c *Client) Delete(ctx context.Context, name string, opts DeleteOptions) error {
@@ -322,6 +322,34 @@ func TestUploadSLD_CustomFormat(t *testing.T) {
 	}
 }
 
+func TestGetSLD(t *testing.T) {
+	const sldBody = `<StyledLayerDescriptor version="1.0.0"/>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectBasicAuth(t, r)
+		switch r.URL.Path {
+		case "/rest/workspaces/topp/styles/roads.sld":
+			w.Header().Set("Content-Type", "application/vnd.ogc.sld+xml")
+			_, _ = io.WriteString(w, sldBody)
+		default:
+			http.Error(w, "No such style", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv)
+	body, err := c.Styles.InWorkspace("topp").GetSLD(context.Background(), "roads")
+	if err != nil {
+		t.Fatalf("GetSLD: %v", err)
+	}
+	defer body.Close()
+	if got, _ := io.ReadAll(body); string(got) != sldBody {
+		t.Fatalf("body = %q", got)
+	}
+	if _, err := c.Styles.GetSLD(context.Background(), "roads"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("global GetSLD = %v, want ErrNotFound", err)
+	}
+}
+
 func TestUploadSLD_NilBody(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
 		t.Errorf("server should not be hit; got %s %s", r.Method, r.URL.Path)
@@ -13,6 +13,8 @@
 // follow with UploadSLD to attach the SLD content.
 package styles
 
+import "github.com/hishamkaram/geoserver/v2/internal/wire"
+
 // Style is the GeoServer style metadata document. The SLD body itself
 // is content-typed differently (application/vnd.ogc.sld+xml) and lives
 // outside this struct — fetch via [Client.DownloadSLD] (deferred to a
@@ -59,3 +61,13 @@ type createRequest struct {
 type detailResponse struct {
 	Style Style `json:"style"`
 }
+
+// Outcome reports what Ensure or Upsert did — see [wire.Outcome].
+type Outcome = wire.Outcome
+
+// Outcomes of Ensure and Upsert.
+const (
+	Unchanged = wire.Unchanged
+	Created   = wire.Created
+	Updated   = wire.Updated
+)
@@ -12,6 +12,8 @@ import (
 	"iter"
 	"net/http"
 	"strconv"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // WorkspaceRef is the workspace pointer GeoServer carries back on a
@@ -86,6 +88,16 @@ type listWire struct {
 	WMTSStores json.RawMessage `json:"wmtsStores"`
 }
 
+// Outcome reports what Ensure or Upsert did — see [wire.Outcome].
+type Outcome = wire.Outcome
+
+// Outcomes of Ensure and Upsert.
+const (
+	Unchanged = wire.Unchanged
+	Created   = wire.Created
+	Updated   = wire.Updated
+)
+
 // Client is the v2 cascaded WMTS stores sub-client.
 type Client struct {
 	core Core
@@ -209,6 +221,32 @@ func (c *WorkspaceClient) Update(ctx context.Context, name string, store *WMTSSt
 	return c.core.Do(ctx, op, http.MethodPut, u, store, nil, nil)
 }
 
+// Ensure creates store when no store by its name exists in the scoped
+// workspace, and otherwise leaves the existing one alone.
+func (c *WorkspaceClient) Ensure(ctx context.Context, store *WMTSStore) (Outcome, error) {
+	if store == nil {
+		return Unchanged, errors.New("WMTSStores.Ensure: nil store")
+	}
+	return wire.Ensure(
+		func() (*WMTSStore, error) { return c.Get(ctx, store.Name) },
+		func() error { return c.Create(ctx, store) },
+	)
+}
+
+// Upsert creates store when it is missing, and otherwise updates the
+// existing store if it differs. Only the fields store sets are
+// compared; hrefs, dates and other server-populated values are ignored.
+func (c *WorkspaceClient) Upsert(ctx context.Context, store *WMTSStore) (Outcome, error) {
+	if store == nil {
+		return Unchanged, errors.New("WMTSStores.Upsert: nil store")
+	}
+	return wire.Upsert(store,
+		func() (*WMTSStore, error) { return c.Get(ctx, store.Name) },
+		func() error { return c.Create(ctx, store) },
+		func(*WMTSStore) error { return c.Update(ctx, store.Name, store) },
+	)
+}
+
 // Delete removes a store. Set DeleteOptions.Recurse to also remove
 // any cascaded layers under it.
 func (c *WorkspaceClient) Delete(ctx context.Context, name string, opts DeleteOptions) error {
@@ -4,6 +4,8 @@
 // pattern once.
 package workspaces
 
+import "github.com/hishamkaram/geoserver/v2/internal/wire"
+
 // Workspace is the GeoServer workspace document. Fields are the subset
 // callers and the GeoServer JSON wire format both use.
 type Workspace struct {
@@ -47,3 +49,13 @@ type detailResponse struct {
 type createRequest struct {
 	Workspace Workspace `json:"workspace"`
 }
+
+// Outcome reports what Ensure or Upsert did — see [wire.Outcome].
+type Outcome = wire.Outcome
+
+// Outcomes of Ensure and Upsert.
+const (
+	Unchanged = wire.Unchanged
+	Created   = wire.Created
+	Updated   = wire.Updated
+)
@@ -8,6 +8,8 @@ import (
 	"iter"
 	"net/http"
 	"strconv"
+
+	"github.com/hishamkaram/geoserver/v2/internal/wire"
 )
 
 // Core is the plumbing the sub-client needs from the parent [*Client].
@@ -139,6 +141,33 @@ func (c *Client) Update(ctx context.Context, name string, patch *WorkspacePatch)
 	return c.core.Do(ctx, op, http.MethodPut, u, body, nil, nil)
 }
 
+// Ensure creates ws when no workspace by its name exists, and otherwise
+// leaves the existing one alone.
+func (c *Client) Ensure(ctx context.Context, ws *Workspace) (Outcome, error) {
+	if ws == nil {
+		return Unchanged, errors.New("Workspaces.Ensure: nil workspace")
+	}
+	return wire.Ensure(
+		func() (*Workspace, error) { return c.Get(ctx, ws.Name) },
+		func() error { return c.Create(ctx, ws) },
+	)
+}
+
+// Upsert creates ws when it is missing, and otherwise updates the
+// existing workspace if it differs. Only the fields ws sets are
+// compared, so Isolated=false never clears the flag; use
+// [Client.Update] for that.
+func (c *Client) Upsert(ctx context.Context, ws *Workspace) (Outcome, error) {
+	if ws == nil {
+		return Unchanged, errors.New("Workspaces.Upsert: nil workspace")
+	}
+	return wire.Upsert(ws,
+		func() (*Workspace, error) { return c.Get(ctx, ws.Name) },
+		func() error { return c.Create(ctx, ws) },
+		func(*Workspace) error { return c.Update(ctx, ws.Name, &WorkspacePatch{Isolated: &ws.Isolated}) },
+	)
+}
+
 // Delete removes a workspace. With opts.Recurse=true, also removes all
 // contained datastores, coverage stores, layer groups, and
 // feature/coverage definitions.
@@ -0,0 +1,113 @@
+package geoserver
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/hishamkaram/geoserver/v2/internal/transport"
+)
+
+// Retry defaults applied by [WithRetry] to zero-valued [RetryPolicy]
+// fields.
+const (
+	defaultRetryMaxAttempts    = 4
+	defaultRetryInitialBackoff = 250 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+)
+
+// defaultRetryStatuses are the statuses a load balancer or a
+// restarting GeoServer typically answers with transiently.
+var defaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures the retry layer installed by [WithRetry].
+// The zero value is usable: every zero field takes the documented
+// default.
+//
+// Only idempotent methods (GET, HEAD, PUT, DELETE) are retried by
+// default. A POST is retried only when its operation name — the Op
+// reported on [*APIError], e.g. "Imports.Create" — is listed in
+// RetryNonIdempotentOps. Requests with a streamed body that cannot be
+// rewound (file uploads from an [io.Reader]) are never retried.
+//
+// Responses carrying a Retry-After header (typically 429
+// [ErrRateLimited] or 503 [ErrServiceUnavailable]) wait at least that
+// long before the next attempt. When attempts run out, the last
+// response is returned and surfaces as the usual [*APIError].
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the
+	// first. Default 4. Set to 1 to disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the base delay before the second attempt.
+	// Each further attempt doubles it, with random jitter in the
+	// upper half of the interval. Default 250ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the computed exponential backoff. A longer
+	// Retry-After from the server still wins. Default 10s.
+	MaxBackoff time.Duration
+
+	// RetryableStatuses lists the HTTP statuses that trigger a retry.
+	// Default 429, 502, 503, 504. Connection-level failures are
+	// always retried (unless the context is done).
+	RetryableStatuses []int
+
+	// RetryNonIdempotentOps lists operation names whose POST
+	// requests may be retried. Only opt in for operations that are
+	// safe to repeat — a retried Create after a lost response
+	// typically fails with "already exists".
+	RetryNonIdempotentOps []string
+}
+
+// WithRetry installs a retrying [http.RoundTripper] on the client's
+// transport stack. See [RetryPolicy] for what is retried and how.
+func WithRetry(p RetryPolicy) Option {
+	return func(cfg *clientConfig) error {
+		if p.MaxAttempts < 0 {
+			return errors.New("geoserver: WithRetry: negative MaxAttempts")
+		}
+		if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+			return errors.New("geoserver: WithRetry: negative backoff")
+		}
+		if p.MaxAttempts == 0 {
+			p.MaxAttempts = defaultRetryMaxAttempts
+		}
+		if p.InitialBackoff == 0 {
+			p.InitialBackoff = defaultRetryInitialBackoff
+		}
+		if p.MaxBackoff == 0 {
+			p.MaxBackoff = defaultRetryMaxBackoff
+		}
+		if p.RetryableStatuses == nil {
+			p.RetryableStatuses = defaultRetryStatuses
+		}
+		p.RetryableStatuses = slices.Clone(p.RetryableStatuses)
+		p.RetryNonIdempotentOps = slices.Clone(p.RetryNonIdempotentOps)
+		cfg.retry = &p
+		return nil
+	}
+}
+
+// newRetryRoundTripper resolves a defaulted [RetryPolicy] into the
+// transport-layer round-tripper.
+func newRetryRoundTripper(p *RetryPolicy, logger *slog.Logger, base http.RoundTripper) *transport.RetryRoundTripper {
+	statuses := p.RetryableStatuses
+	ops := p.RetryNonIdempotentOps
+	return &transport.RetryRoundTripper{
+		MaxAttempts:    p.MaxAttempts,
+		InitialBackoff: p.InitialBackoff,
+		MaxBackoff:     p.MaxBackoff,
+		RetryStatus:    func(status int) bool { return slices.Contains(statuses, status) },
+		RetryOp:        func(op string) bool { return op != "" && slices.Contains(ops, op) },
+		Logger:         logger,
+		Base:           base,
+	}
+}
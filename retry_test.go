@@ -0,0 +1,95 @@
+package geoserver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+func TestWithRetry_GetRecovers(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); !ok {
+			t.Errorf("attempt %d missing basic auth", calls.Load()+1)
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"workspace":{"name":"topp"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := geoserver.New(srv.URL,
+		geoserver.WithBasicAuth("admin", "geoserver"),
+		geoserver.WithRetry(geoserver.RetryPolicy{InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ws, err := c.Workspaces.Get(context.Background(), "topp")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if ws.Name != "topp" || calls.Load() != 2 {
+		t.Fatalf("ws=%+v calls=%d", ws, calls.Load())
+	}
+}
+
+func TestWithRetry_ExhaustedSurfacesSentinel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := geoserver.New(srv.URL,
+		geoserver.WithRetry(geoserver.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_, err = c.Workspaces.Get(context.Background(), "topp")
+	if !errors.Is(err, geoserver.ErrServiceUnavailable) {
+		t.Fatalf("expected ErrServiceUnavailable, got %v", err)
+	}
+}
+
+func TestWithRetry_POSTOptInPerOp(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := geoserver.New(srv.URL, geoserver.WithRetry(geoserver.RetryPolicy{
+		InitialBackoff:        time.Millisecond,
+		RetryNonIdempotentOps: []string{"Workspaces.Create"},
+	}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.Workspaces.Create(context.Background(), &workspaces.Workspace{Name: "topp"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("server saw %d calls, want 2", calls.Load())
+	}
+}
+
+func TestWithRetry_InvalidPolicy(t *testing.T) {
+	if _, err := geoserver.New("http://localhost:8080/geoserver", geoserver.WithRetry(geoserver.RetryPolicy{MaxAttempts: -1})); err == nil {
+		t.Fatal("expected error for negative MaxAttempts")
+	}
+}
@@ -0,0 +1,407 @@
+package geoserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ErrTxClosed is returned by a [Tx] — and by writes through its client
+// — once it has been committed or rolled back.
+var ErrTxClosed = errors.New("geoserver: transaction already committed or rolled back")
+
+// Tx groups catalog writes so a failed multi-step change can be
+// compensated. GeoServer's REST API has no transactions; Tx records
+// each successful write made through [Tx.Client] together with the
+// request that undoes it, and [Tx.Rollback] replays those in reverse
+// order:
+//
+//   - a POST answered with 201 and a Location is undone by deleting
+//     that object (recurse=true, plus purge=true for styles);
+//   - a POST adding a style to a layer is undone by restoring the
+//     layer document read before it;
+//   - a PUT is undone by restoring the document read from the same URL
+//     before it, or by deleting the object when the PUT created it
+//     (GeoWebCache tile layers, style bodies);
+//   - a file, url or external upload is undone by deleting the store
+//     when the upload created it;
+//   - a DELETE is undone by posting the document read before it back to
+//     the parent collection, or by putting it back for GeoWebCache.
+//
+// Anything else is recorded as a step that cannot be undone, and
+// Rollback reports it: an upload into an existing store, a POST that
+// answers without a Location, a DELETE of an object that can't be read
+// back (ACL rules, security users), and a layer DELETE, since REST can
+// only create a layer together with its resource. Restores are merge updates, so a
+// field that an update added where the document had none stays. A
+// deleted style comes back without its body, and objects removed by a
+// recursive DELETE are not recreated.
+//
+//	tx := c.Begin()
+//	tc := tx.Client()
+//	if err := publish(ctx, tc); err != nil {
+//		return errors.Join(err, tx.Rollback(ctx))
+//	}
+//	tx.Commit()
+//
+// [Client.InTx] wraps that pattern. The sub-clients of [Tx.Client] are
+// the ordinary ones; only the transport underneath them differs. Reads
+// pass through untouched. A Tx is safe for concurrent use, but steps
+// are undone in the order their responses arrived.
+type Tx struct {
+	c    *Client
+	tc   *Client
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	steps  []txStep
+	closed bool
+}
+
+// TxStep is one write recorded by a [Tx].
+type TxStep struct {
+	Method string
+	URL    string
+
+	// Undo describes the compensating request, e.g. "DELETE <url>",
+	// or is "" when the step cannot be undone.
+	Undo string
+}
+
+type txStep struct {
+	TxStep
+	undo *txUndo
+}
+
+// txUndo is a compensating request.
+type txUndo struct {
+	method      string
+	url         string
+	body        []byte
+	contentType string
+}
+
+func (u *txUndo) String() string { return u.method + " " + u.url }
+
+// Begin starts a [Tx] on c.
+func (c *Client) Begin() *Tx {
+	tx := &Tx{c: c, base: c.core.httpClient.Transport}
+	if tx.base == nil {
+		tx.base = http.DefaultTransport
+	}
+	hc := *c.core.httpClient
+	hc.Transport = &txTransport{tx: tx}
+	core := *c.core
+	core.httpClient = &hc
+	tx.tc = newClient(&core)
+	return tx
+}
+
+// InTx runs fn with the client of a new [Tx]. It commits when fn
+// returns nil; otherwise it rolls back and returns fn's error joined
+// with any rollback error.
+func (c *Client) InTx(ctx context.Context, fn func(ctx context.Context, tc *Client) error) error {
+	tx := c.Begin()
+	if err := fn(ctx, tx.Client()); err != nil {
+		return errors.Join(err, tx.Rollback(ctx))
+	}
+	return tx.Commit()
+}
+
+// Client returns the client whose writes tx records.
+func (tx *Tx) Client() *Client { return tx.tc }
+
+// Steps returns the writes recorded so far, oldest first.
+func (tx *Tx) Steps() []TxStep {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	out := make([]TxStep, len(tx.steps))
+	for i, s := range tx.steps {
+		out[i] = s.TxStep
+	}
+	return out
+}
+
+// Commit keeps the recorded writes and closes tx.
+func (tx *Tx) Commit() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.closed {
+		return ErrTxClosed
+	}
+	tx.closed, tx.steps = true, nil
+	return nil
+}
+
+// Rollback undoes the recorded writes, newest first, and closes tx.
+// A failed compensation is logged and rollback carries on with the
+// rest; the failures are joined in the returned error.
+func (tx *Tx) Rollback(ctx context.Context) error {
+	tx.mu.Lock()
+	if tx.closed {
+		tx.mu.Unlock()
+		return ErrTxClosed
+	}
+	steps := tx.steps
+	tx.closed, tx.steps = true, nil
+	tx.mu.Unlock()
+
+	logger := tx.c.core.logger
+	var errs []error
+	for i := len(steps) - 1; i >= 0; i-- {
+		s := steps[i]
+		var err error
+		if s.undo == nil {
+			err = errors.New("cannot be undone")
+		} else {
+			err = tx.apply(ctx, s.undo)
+		}
+		if err != nil {
+			logger.Warn("tx undo failed", "method", s.Method, "url", s.URL, "undo", s.Undo, "err", err)
+			errs = append(errs, fmt.Errorf("geoserver: Tx.Rollback: undo %s %s: %w", s.Method, s.URL, err))
+			continue
+		}
+		logger.Debug("tx undo", "method", s.Method, "url", s.URL, "undo", s.Undo)
+	}
+	return errors.Join(errs...)
+}
+
+// apply sends a compensating request. Deleting what is already gone
+// counts as success.
+func (tx *Tx) apply(ctx context.Context, u *txUndo) error {
+	var body io.Reader = http.NoBody
+	if u.body != nil {
+		body = bytes.NewReader(u.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, u.method, u.url, body)
+	if err != nil {
+		return err
+	}
+	if u.contentType != "" {
+		req.Header.Set("Content-Type", u.contentType)
+	}
+	resp, err := tx.c.core.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 == 2 || u.method == http.MethodDelete && resp.StatusCode == http.StatusNotFound {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 8<<10))
+	return newAPIError("Tx.Rollback", u.method, u.url, resp.StatusCode, raw)
+}
+
+// record appends a step.
+func (tx *Tx) record(req *http.Request, undo *txUndo) {
+	s := txStep{TxStep: TxStep{Method: req.Method, URL: req.URL.String()}, undo: undo}
+	if undo != nil {
+		s.Undo = undo.String()
+	} else {
+		tx.c.core.logger.Warn("tx step cannot be undone", "method", req.Method, "url", s.URL)
+	}
+	tx.mu.Lock()
+	tx.steps = append(tx.steps, s)
+	tx.mu.Unlock()
+}
+
+func (tx *Tx) isClosed() bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	return tx.closed
+}
+
+// snapshot reads the document at u. It returns nil, nil for 404.
+func (tx *Tx) snapshot(req *http.Request, u *url.URL, accept string) (*txUndo, error) {
+	get, err := http.NewRequestWithContext(req.Context(), http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	get.Header.Set("Accept", accept)
+	resp, err := tx.base.RoundTrip(get)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, newAPIError("Tx.snapshot", http.MethodGet, u.String(), resp.StatusCode, raw)
+	}
+	ct := resp.Header.Get("Content-Type")
+	if ct == "" {
+		ct = accept
+	}
+	return &txUndo{url: u.String(), body: raw, contentType: ct}, nil
+}
+
+// txTransport records the writes of a [Tx].
+type txTransport struct {
+	tx *Tx
+}
+
+func (t *txTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tx := t.tx
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return tx.base.RoundTrip(req)
+	}
+	if tx.isClosed() {
+		return nil, ErrTxClosed
+	}
+	undo, known, err := tx.prepare(req)
+	if err != nil {
+		return nil, fmt.Errorf("geoserver: Tx: snapshot before %s %s: %w", req.Method, req.URL, err)
+	}
+	resp, err := tx.base.RoundTrip(req)
+	if err != nil || resp.StatusCode/100 != 2 {
+		return resp, err
+	}
+	if !known && req.Method == http.MethodPost && resp.StatusCode == http.StatusCreated {
+		undo = createdUndo(req.URL, resp.Header.Get("Location"))
+	}
+	tx.record(req, undo)
+	return resp, nil
+}
+
+// prepare works out how to undo req before it is sent. known reports
+// whether undo is final; otherwise a POST's undo is taken from its
+// response.
+func (tx *Tx) prepare(req *http.Request) (undo *txUndo, known bool, err error) {
+	u := *req.URL
+	u.RawQuery, u.Fragment = "", ""
+	dir, last := path.Split(u.Path)
+	dir = strings.TrimSuffix(dir, "/")
+
+	switch req.Method {
+	case http.MethodPost:
+		// layers.Client.AddStyle: POST …/layers/{layer}/styles.
+		if last == "styles" && path.Base(path.Dir(dir)) == "layers" {
+			layer := u
+			layer.Path = dir
+			snap, err := tx.snapshot(req, &layer, "application/json")
+			if err != nil || snap == nil {
+				return nil, true, err
+			}
+			snap.method = http.MethodPut
+			return snap, true, clearAbsentStyles(snap)
+		}
+		return nil, false, nil
+
+	case http.MethodPut:
+		if isUpload(last) {
+			store := u
+			store.Path = dir
+			snap, err := tx.snapshot(req, &store, "application/json")
+			if err != nil || snap != nil {
+				return nil, true, err // an upload into an existing store stays
+			}
+			return deleteUndo(&store), true, nil
+		}
+		accept := req.Header.Get("Content-Type")
+		if accept == "" {
+			accept = "application/json"
+		}
+		snap, err := tx.snapshot(req, &u, accept)
+		if err != nil {
+			return nil, true, err
+		}
+		if snap == nil {
+			return deleteUndo(&u), true, nil
+		}
+		snap.method = http.MethodPut
+		return snap, true, nil
+
+	case http.MethodDelete:
+		accept := "application/json"
+		if isGWC(u.Path) {
+			accept = "application/xml"
+		} else if path.Base(dir) == "layers" {
+			return nil, true, nil // layers can't be created through REST
+		}
+		snap, err := tx.snapshot(req, &u, accept)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return nil, true, nil // e.g. ACL rules and users answer GET with 405
+		}
+		if err != nil || snap == nil {
+			return nil, true, err
+		}
+		if isGWC(u.Path) {
+			snap.method = http.MethodPut
+			return snap, true, nil
+		}
+		parent := u
+		parent.Path = dir
+		snap.method, snap.url = http.MethodPost, parent.String()
+		return snap, true, nil
+	}
+	return nil, true, nil
+}
+
+// clearAbsentStyles gives a layer snapshot without alternate styles an
+// empty list, so restoring it drops the style that was added.
+func clearAbsentStyles(snap *txUndo) error {
+	var doc map[string]map[string]any
+	if err := json.Unmarshal(snap.body, &doc); err != nil {
+		return err
+	}
+	layer := doc["layer"]
+	if layer == nil || layer["styles"] != nil {
+		return nil
+	}
+	layer["styles"] = map[string]any{"style": []any{}}
+	raw, err := json.Marshal(doc)
+	snap.body = raw
+	return err
+}
+
+// createdUndo deletes the object a POST created, or returns nil when
+// the response didn't say where it is.
+func createdUndo(reqURL *url.URL, location string) *txUndo {
+	if location == "" {
+		return nil
+	}
+	u, err := reqURL.Parse(location)
+	if err != nil {
+		return nil
+	}
+	return deleteUndo(u)
+}
+
+func deleteUndo(u *url.URL) *txUndo {
+	d := *u
+	q := url.Values{"recurse": {"true"}}
+	if strings.Contains(d.Path, "/styles/") {
+		q.Set("purge", "true")
+	}
+	d.RawQuery = q.Encode()
+	return &txUndo{method: http.MethodDelete, url: d.String()}
+}
+
+// isUpload reports whether the last path segment is a store upload
+// endpoint such as "file.shp" or "external.geotiff".
+func isUpload(last string) bool {
+	for _, p := range []string{"file", "url", "external"} {
+		if last == p || strings.HasPrefix(last, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func isGWC(p string) bool { return strings.Contains(p, "/gwc/rest/") }
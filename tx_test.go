@@ -0,0 +1,234 @@
+package geoserver_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	geoserver "github.com/hishamkaram/geoserver/v2"
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/acl"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/gwc"
+	"github.com/hishamkaram/geoserver/v2/rest/layers"
+	"github.com/hishamkaram/geoserver/v2/rest/security"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+const txSLD = `<StyledLayerDescriptor version="1.0.0"><NamedLayer><Name>roads</Name></NamedLayer></StyledLayerDescriptor>`
+
+// txCatalog starts a fake server holding workspace "topp" with store
+// "pg", feature type "roads" titled "Roads", and global style "old".
+func txCatalog(t *testing.T, opts ...geoserver.Option) *geoserver.Client {
+	t.Helper()
+	srv := geoservertest.New(t, geoservertest.Options{})
+	srv.Seed(geoservertest.Fixture{"topp": {Datastores: map[string][]string{"pg": {"roads"}}}})
+	c, must := srv.Client(opts...), srv.Must
+	ctx := context.Background()
+	must("title", c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Update(ctx, "roads", &featuretypes.FeatureType{Title: "Roads"}))
+	must("style", c.Styles.Create(ctx, &styles.Style{Name: "old"}))
+	return c
+}
+
+func TestTx_Rollback(t *testing.T) {
+	ctx := context.Background()
+	c := txCatalog(t)
+	tx := c.Begin()
+	tc := tx.Client()
+
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		{"update feature type", func() error {
+			return tc.FeatureTypes.InWorkspace("topp").InDatastore("pg").Update(ctx, "roads", &featuretypes.FeatureType{Title: "Highways"})
+		}},
+		{"delete style", func() error { return tc.Styles.Delete(ctx, "old", styles.DeleteOptions{}) }},
+		{"workspace", func() error { return tc.Workspaces.Create(ctx, &workspaces.Workspace{Name: "new"}) }},
+		{"datastore", func() error {
+			return tc.Datastores.InWorkspace("new").Create(ctx, datastores.PostGIS{Name: "pg", Host: "db"})
+		}},
+		{"feature type", func() error {
+			return tc.FeatureTypes.InWorkspace("new").InDatastore("pg").Create(ctx, &featuretypes.FeatureType{Name: "rivers"})
+		}},
+		{"style", func() error { return tc.Styles.InWorkspace("new").Create(ctx, &styles.Style{Name: "blue"}) }},
+		{"style body", func() error {
+			return tc.Styles.InWorkspace("new").UploadSLD(ctx, "blue", strings.NewReader(txSLD), styles.UploadOptions{})
+		}},
+		{"add style", func() error {
+			return tc.Layers.InWorkspace("new").AddStyle(ctx, "rivers", "new:blue", layers.AddStyleOptions{Default: true})
+		}},
+		{"tile layer", func() error { return tc.GWC.Layers().Put(ctx, "new:rivers", &gwc.LayerConfig{Name: "new:rivers"}) }},
+		{"upload", func() error {
+			return tc.Datastores.InWorkspace("topp").UploadFile(ctx, "lakes", strings.NewReader("zip"), datastores.UploadOptions{Extension: "shp"})
+		}},
+	}
+	for _, s := range steps {
+		if err := s.fn(); err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+	}
+	recorded := tx.Steps()
+	if len(recorded) != len(steps) {
+		t.Fatalf("recorded %d steps, want %d: %+v", len(recorded), len(steps), recorded)
+	}
+	for _, s := range recorded {
+		if s.Undo == "" {
+			t.Fatalf("step %s %s cannot be undone", s.Method, s.URL)
+		}
+	}
+
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if _, err := c.Workspaces.Get(ctx, "new"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("workspace new after rollback: %v", err)
+	}
+	if _, err := c.GWC.Layers().Get(ctx, "new:rivers"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("tile layer after rollback: %v", err)
+	}
+	if _, err := c.Datastores.InWorkspace("topp").Get(ctx, "lakes"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("uploaded store after rollback: %v", err)
+	}
+	ft, err := c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Get(ctx, "roads")
+	if err != nil || ft.Title != "Roads" {
+		t.Fatalf("feature type after rollback = %+v, %v", ft, err)
+	}
+	if _, err := c.Styles.Get(ctx, "old"); err != nil {
+		t.Fatalf("deleted style not restored: %v", err)
+	}
+
+	if err := tx.Commit(); !errors.Is(err, geoserver.ErrTxClosed) {
+		t.Fatalf("Commit after Rollback = %v", err)
+	}
+	if err := tc.Workspaces.Create(ctx, &workspaces.Workspace{Name: "late"}); !errors.Is(err, geoserver.ErrTxClosed) {
+		t.Fatalf("write after Rollback = %v", err)
+	}
+	if _, err := tc.Workspaces.Get(ctx, "topp"); err != nil {
+		t.Fatalf("read after Rollback: %v", err)
+	}
+}
+
+func TestTx_RollbackRestoresStyleBody(t *testing.T) {
+	ctx := context.Background()
+	c := txCatalog(t)
+	if err := c.Styles.UploadSLD(ctx, "old", strings.NewReader(txSLD), styles.UploadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tx := c.Begin()
+	changed := strings.Replace(txSLD, "roads", "rivers", 1)
+	if err := tx.Client().Styles.UploadSLD(ctx, "old", strings.NewReader(changed), styles.UploadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Styles.GetSLD(ctx, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	if body, _ := io.ReadAll(r); string(body) != txSLD {
+		t.Fatalf("style body after rollback = %s", body)
+	}
+}
+
+func TestTx_RollbackReportsFailedUndo(t *testing.T) {
+	ctx := context.Background()
+	var logs bytes.Buffer
+	c := txCatalog(t,
+		geoserver.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		geoserver.WithTransport(failPath{fail: "/workspaces/held", next: http.DefaultTransport}),
+	)
+	err := c.InTx(ctx, func(ctx context.Context, tc *geoserver.Client) error {
+		if err := tc.Workspaces.Create(ctx, &workspaces.Workspace{Name: "held"}); err != nil {
+			return err
+		}
+		if err := tc.Workspaces.Create(ctx, &workspaces.Workspace{Name: "gone"}); err != nil {
+			return err
+		}
+		if err := tc.ACL.Layers().Add(ctx, acl.Rule{Workspace: "gone", Layer: "*", Operation: acl.OpRead, Roles: []string{"R"}}); err != nil {
+			return err
+		}
+		return errors.New("publish failed")
+	})
+	if err == nil || !strings.Contains(err.Error(), "publish failed") {
+		t.Fatalf("InTx = %v", err)
+	}
+	var apiErr *geoserver.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("InTx error lacks the failed undo: %v", err)
+	}
+	if !strings.Contains(err.Error(), "cannot be undone") {
+		t.Fatalf("InTx error lacks the ACL step: %v", err)
+	}
+	if _, err := c.Workspaces.Get(ctx, "gone"); !errors.Is(err, geoserver.ErrNotFound) {
+		t.Fatalf("workspace gone after rollback: %v", err)
+	}
+	if n := strings.Count(logs.String(), "tx undo failed"); n != 2 {
+		t.Fatalf("logged %d failed undo steps:\n%s", n, logs.String())
+	}
+}
+
+func TestTx_DeletesThatCannotBeUndone(t *testing.T) {
+	ctx := context.Background()
+	c := txCatalog(t)
+	rule := acl.Rule{Workspace: "topp", Layer: "*", Operation: acl.OpRead, Roles: []string{"R"}}
+	if err := c.ACL.Layers().Add(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Security.Users().Create(ctx, &security.User{Name: "bob", Enabled: true, Password: "pw"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := c.Begin()
+	tc := tx.Client()
+	if err := tc.Layers.InWorkspace("topp").Delete(ctx, "roads", layers.DeleteOptions{}); err != nil {
+		t.Fatalf("delete layer: %v", err)
+	}
+	if err := tc.ACL.Layers().Delete(ctx, rule); err != nil {
+		t.Fatalf("delete ACL rule: %v", err)
+	}
+	if err := tc.Security.Users().Delete(ctx, "bob"); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	recorded := tx.Steps()
+	if len(recorded) != 3 {
+		t.Fatalf("recorded %+v, want 3 steps", recorded)
+	}
+	for _, s := range recorded {
+		if s.Method != http.MethodDelete || s.Undo != "" {
+			t.Fatalf("step %+v, want a DELETE that cannot be undone", s)
+		}
+	}
+
+	err := tx.Rollback(ctx)
+	if n := strings.Count(fmt.Sprint(err), "cannot be undone"); n != 3 {
+		t.Fatalf("Rollback = %v, want 3 steps that cannot be undone", err)
+	}
+	if _, err := c.FeatureTypes.InWorkspace("topp").InDatastore("pg").Get(ctx, "roads"); err != nil {
+		t.Fatalf("feature type after layer delete: %v", err)
+	}
+}
+
+func TestClient_InTxCommits(t *testing.T) {
+	ctx := context.Background()
+	c := txCatalog(t)
+	err := c.InTx(ctx, func(ctx context.Context, tc *geoserver.Client) error {
+		return tc.Workspaces.Create(ctx, &workspaces.Workspace{Name: "kept"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Workspaces.Get(ctx, "kept"); err != nil {
+		t.Fatalf("committed workspace: %v", err)
+	}
+}
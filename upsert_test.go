@@ -0,0 +1,81 @@
+package geoserver_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/hishamkaram/geoserver/v2/geoservertest"
+	"github.com/hishamkaram/geoserver/v2/rest/datastores"
+	"github.com/hishamkaram/geoserver/v2/rest/featuretypes"
+	"github.com/hishamkaram/geoserver/v2/rest/security"
+	"github.com/hishamkaram/geoserver/v2/rest/styles"
+	"github.com/hishamkaram/geoserver/v2/rest/workspaces"
+)
+
+// Every package's Outcome is the same type, so one helper checks them all.
+func expectOutcome(t *testing.T, what string, want workspaces.Outcome) func(workspaces.Outcome, error) {
+	return func(got workspaces.Outcome, err error) {
+		t.Helper()
+		if err != nil || got != want {
+			t.Fatalf("%s = %v, %v; want %v", what, got, err, want)
+		}
+	}
+}
+
+func TestEnsureAndUpsert(t *testing.T) {
+	ctx := context.Background()
+	c := geoservertest.New(t, geoservertest.Options{}).Client()
+
+	ws := &workspaces.Workspace{Name: "topp"}
+	expectOutcome(t, "Workspaces.Ensure", workspaces.Created)(c.Workspaces.Ensure(ctx, ws))
+	expectOutcome(t, "Workspaces.Ensure again", workspaces.Unchanged)(c.Workspaces.Ensure(ctx, ws))
+	expectOutcome(t, "Workspaces.Upsert", workspaces.Unchanged)(c.Workspaces.Upsert(ctx, ws))
+
+	dc := c.Datastores.InWorkspace("topp")
+	pg := datastores.PostGIS{Name: "pg", Host: "db", Port: 5432, Database: "gis", User: "u", Password: "secret"}
+	expectOutcome(t, "Datastores.Upsert", datastores.Created)(dc.Upsert(ctx, pg))
+	expectOutcome(t, "Datastores.Upsert again", datastores.Unchanged)(dc.Upsert(ctx, pg))
+	pg.Host = "db2"
+	expectOutcome(t, "Datastores.Upsert new host", datastores.Updated)(dc.Upsert(ctx, pg))
+	ds, err := dc.Get(ctx, "pg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{}
+	for _, e := range ds.ConnectionParameters.Entry {
+		params[e.Key] = e.Value
+	}
+	if params["host"] != "db2" || params["database"] != "gis" {
+		t.Fatalf("connection parameters after Upsert = %v", params)
+	}
+
+	fc := c.FeatureTypes.InWorkspace("topp").InDatastore("pg")
+	ft := &featuretypes.FeatureType{Name: "roads", Title: "Roads"}
+	expectOutcome(t, "FeatureTypes.Upsert", featuretypes.Created)(fc.Upsert(ctx, ft))
+	// The server fills in fields ft leaves unset; those don't count.
+	expectOutcome(t, "FeatureTypes.Upsert again", featuretypes.Unchanged)(fc.Upsert(ctx, ft))
+	ft.Title = "Main roads"
+	expectOutcome(t, "FeatureTypes.Upsert new title", featuretypes.Updated)(fc.Upsert(ctx, ft))
+	expectOutcome(t, "FeatureTypes.Ensure", featuretypes.Unchanged)(fc.Ensure(ctx, &featuretypes.FeatureType{Name: "roads", Title: "ignored"}))
+	if got, err := fc.Get(ctx, "roads"); err != nil || got.Title != "Main roads" {
+		t.Fatalf("feature type = %+v, %v", got, err)
+	}
+
+	sc := c.Styles.InWorkspace("topp")
+	expectOutcome(t, "Styles.Upsert", styles.Created)(sc.Upsert(ctx, &styles.Style{Name: "line"}))
+	expectOutcome(t, "Styles.Upsert again", styles.Unchanged)(sc.Upsert(ctx, &styles.Style{Name: "line"}))
+
+	uc := c.Security.Users()
+	alice := &security.User{Name: "alice", Password: "secret", Enabled: true}
+	expectOutcome(t, "Users.Upsert", security.Created)(uc.Upsert(ctx, alice))
+	alice.Password = "changed" // never compared
+	expectOutcome(t, "Users.Upsert again", security.Unchanged)(uc.Upsert(ctx, alice))
+	alice.Enabled = false
+	expectOutcome(t, "Users.Upsert disabled", security.Updated)(uc.Upsert(ctx, alice))
+	expectOutcome(t, "Users.Ensure", security.Unchanged)(uc.Ensure(ctx, &security.User{Name: "alice", Password: "x", Enabled: true}))
+	users, err := uc.List(ctx, security.ListOptions{})
+	if err != nil || !slices.ContainsFunc(users, func(u security.User) bool { return u.Name == "alice" && !u.Enabled }) {
+		t.Fatalf("users = %+v, %v", users, err)
+	}
+}